@@ -17,23 +17,38 @@ package artifact
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
+	"github.com/falcosecurity/falcoctl/cmd/artifact/bundle"
 	artifactconfig "github.com/falcosecurity/falcoctl/cmd/artifact/config"
+	"github.com/falcosecurity/falcoctl/cmd/artifact/diff"
 	"github.com/falcosecurity/falcoctl/cmd/artifact/follow"
 	"github.com/falcosecurity/falcoctl/cmd/artifact/info"
+	"github.com/falcosecurity/falcoctl/cmd/artifact/inspect"
 	"github.com/falcosecurity/falcoctl/cmd/artifact/install"
 	"github.com/falcosecurity/falcoctl/cmd/artifact/list"
 	"github.com/falcosecurity/falcoctl/cmd/artifact/manifest"
+	"github.com/falcosecurity/falcoctl/cmd/artifact/pin"
+	"github.com/falcosecurity/falcoctl/cmd/artifact/rollback"
+	"github.com/falcosecurity/falcoctl/cmd/artifact/rules"
 	"github.com/falcosecurity/falcoctl/cmd/artifact/search"
+	"github.com/falcosecurity/falcoctl/cmd/artifact/uninstall"
+	"github.com/falcosecurity/falcoctl/cmd/artifact/validate"
 	"github.com/falcosecurity/falcoctl/internal/config"
 	"github.com/falcosecurity/falcoctl/pkg/index/cache"
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
 	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
 )
 
 // NewArtifactCmd return the artifact command.
 func NewArtifactCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Command {
+	var registryRewrites []string
+	var proxy commonoptions.Proxy
+
 	cmd := &cobra.Command{
 		Use:                   "artifact",
 		DisableFlagsInUseLine: true,
@@ -49,6 +64,13 @@ func NewArtifactCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Comma
 				return err
 			}
 
+			if err = proxy.Configure(cmd); err != nil {
+				return err
+			}
+			if err = commonoptions.ConfigureTLS(); err != nil {
+				return err
+			}
+
 			// add indexes if needed
 			// Set up basic authentication
 			if indexes, err = config.Indexes(); err != nil {
@@ -59,6 +81,30 @@ func NewArtifactCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Comma
 			if indexCache, err = cache.NewFromConfig(ctx, config.IndexesFile, config.IndexesDir, indexes); err != nil {
 				return err
 			}
+			for _, c := range indexCache.Conflicts() {
+				opt.Printer.Logger.Debug("Artifact found in more indexes",
+					opt.Printer.Logger.Args("name", c.Name, "index", c.Index, "shadowed", strings.Join(c.Shadowed, ", ")))
+			}
+			// Override "registry-rewrite" flag with viper config if not set by user.
+			f := cmd.Flags().Lookup("registry-rewrite")
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag registry-rewrite")
+			} else if !f.Changed && viper.IsSet(config.RegistryRewriteKey) {
+				val, err := config.RegistryRewrites()
+				if err != nil {
+					return err
+				}
+				if err := cmd.Flags().Set(f.Name, strings.Join(val, ",")); err != nil {
+					return fmt.Errorf("unable to overwrite \"registry-rewrite\" flag: %w", err)
+				}
+			}
+			rules, err := index.ParseRewriteRules(registryRewrites)
+			if err != nil {
+				return err
+			}
+			indexCache.SetRewriteRules(rules...)
+
 			// Save the index cache for later use by the sub commands.
 			opt.Initialize(commonoptions.WithIndexCache(indexCache))
 
@@ -66,6 +112,10 @@ func NewArtifactCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Comma
 		},
 	}
 
+	cmd.PersistentFlags().StringSliceVar(&registryRewrites, "registry-rewrite", nil,
+		"Rewrite rules, in the \"<from>=><to>\" format, applied in order to the references resolved from the indexes (first match wins)")
+	proxy.AddFlags(cmd)
+
 	cmd.AddCommand(search.NewArtifactSearchCmd(ctx, opt))
 	cmd.AddCommand(install.NewArtifactInstallCmd(ctx, opt))
 	cmd.AddCommand(list.NewArtifactListCmd(ctx, opt))
@@ -73,6 +123,14 @@ func NewArtifactCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Comma
 	cmd.AddCommand(follow.NewArtifactFollowCmd(ctx, opt))
 	cmd.AddCommand(artifactconfig.NewArtifactConfigCmd(ctx, opt))
 	cmd.AddCommand(manifest.NewArtifactManifestCmd(ctx, opt))
+	cmd.AddCommand(inspect.NewArtifactInspectCmd(ctx, opt))
+	cmd.AddCommand(diff.NewArtifactDiffCmd(ctx, opt))
+	cmd.AddCommand(validate.NewArtifactValidateCmd(ctx, opt))
+	cmd.AddCommand(rules.NewArtifactRulesCmd(ctx, opt))
+	cmd.AddCommand(pin.NewArtifactPinCmd(ctx, opt))
+	cmd.AddCommand(rollback.NewArtifactRollbackCmd(ctx, opt))
+	cmd.AddCommand(uninstall.NewArtifactUninstallCmd(ctx, opt))
+	cmd.AddCommand(bundle.NewArtifactBundleCmd(ctx, opt))
 
 	return cmd
 }
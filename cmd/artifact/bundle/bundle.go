@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+// NewArtifactBundleCmd returns the artifact bundle command.
+func NewArtifactBundleCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "bundle",
+		DisableFlagsInUseLine: true,
+		Short:                 "Export artifacts to a bundle and install them offline from it",
+		Long:                  "Export artifacts to a bundle and install them offline from it",
+	}
+
+	cmd.AddCommand(newBundleExportCmd(ctx, opt))
+	cmd.AddCommand(newBundleImportCmd(ctx, opt))
+
+	return cmd
+}
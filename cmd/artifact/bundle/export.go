@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/falcosecurity/falcoctl/cmd/artifact/install"
+	"github.com/falcosecurity/falcoctl/internal/bundle"
+	"github.com/falcosecurity/falcoctl/internal/signature"
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
+	"github.com/falcosecurity/falcoctl/pkg/oci/repository"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const longExport = `Resolve the given artifacts, with their dependencies, and pack them in a bundle: a gzip compressed
+tarball to be installed with "artifact bundle import" on hosts that cannot reach any registry.
+
+A reference is either a simple name or a fully qualified reference ("<registry>/<repository>"),
+optionally followed by ":<tag>" (":latest" is assumed by default when no tag is given).
+
+Each artifact is stored in the bundle as an OCI image layout with all its platforms, along with its
+cosign signature, if it has one, and the entry of the index it has been resolved from. Since the
+signatures cannot be verified offline, they are verified by this command, as "artifact install" does.
+
+Example - Bundle the "k8saudit-rules" and "k8saudit" artifacts, with their dependencies:
+	falcoctl artifact bundle export falco-bundle.tar.gz k8saudit-rules k8saudit
+`
+
+type bundleExportOptions struct {
+	*options.Common
+	*options.Registry
+	platform    string
+	resolveDeps bool
+	noVerify    bool
+}
+
+func newBundleExportCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := bundleExportOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "export bundle ref1 [ref2 ...] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Pack a list of artifacts, with their dependencies, in a bundle",
+		Long:                  longExport,
+		Args:                  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunBundleExport(ctx, args[0], args[1:])
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.platform, install.FlagPlatform, fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		"os and architecture, in OS/ARCH format, of the artifacts whose dependencies are resolved")
+	cmd.Flags().BoolVar(&o.resolveDeps, install.FlagResolveDeps, true,
+		"whether this command should resolve dependencies or not")
+	cmd.Flags().BoolVar(&o.noVerify, install.FlagNoVerify, false,
+		"whether this command should skip signature verification")
+
+	return cmd
+}
+
+// RunBundleExport executes the business logic for the artifact bundle export command.
+func (o *bundleExportOptions) RunBundleExport(ctx context.Context, path string, args []string) error {
+	logger := o.Printer.Logger
+
+	platformOS, platformArch, ok := strings.Cut(o.platform, "/")
+	if !ok {
+		return fmt.Errorf("invalid %q: must be in the format OS/Arch", install.FlagPlatform)
+	}
+
+	puller, err := ociutils.Puller(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return err
+	}
+
+	// The entries are looked up before resolving the references, which are then full ones.
+	entries := make(map[string]*index.Entry)
+	refs := make([]string, len(args))
+	for i, arg := range args {
+		if refs[i], err = o.IndexCache.ResolveReference(arg); err != nil {
+			return err
+		}
+		if entry, ok := o.IndexCache.EntryForIndexRef(arg); ok {
+			entries[refs[i]] = entry
+		}
+	}
+
+	if o.resolveDeps {
+		logger.Info("Resolving dependencies ...")
+		refs, err = install.ResolveDeps(func(ref string) (*oci.RegistryResult, error) {
+			ref, err := o.IndexCache.ResolveReference(ref)
+			if err != nil {
+				return nil, err
+			}
+			artifactConfig, err := puller.ArtifactConfig(ctx, ref, platformOS, platformArch)
+			if err != nil {
+				return nil, err
+			}
+			return &oci.RegistryResult{Config: *artifactConfig}, nil
+		}, func(name string) ([]string, error) {
+			ref, err := o.IndexCache.ResolveReference(name)
+			if err != nil {
+				return nil, err
+			}
+			repoRef, err := utils.RepositoryFromRef(ref)
+			if err != nil {
+				return nil, err
+			}
+			repo, err := repository.NewRepository(repoRef, repository.WithClient(puller.Client), repository.WithPlainHTTP(o.PlainHTTP))
+			if err != nil {
+				return nil, err
+			}
+			return repo.Tags(ctx)
+		}, refs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	b, err := bundle.New()
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	logger.Info("Exporting artifacts", logger.Args("refs", refs))
+	for i, ref := range refs {
+		resolvedRef, err := o.IndexCache.ResolveReference(ref)
+		if err != nil {
+			return err
+		}
+		entry, ok := entries[resolvedRef]
+		if !ok {
+			entry, _ = o.IndexCache.EntryForIndexRef(ref)
+		}
+
+		a := bundle.Artifact{Ref: resolvedRef, Layout: bundle.LayoutPath(i), Entry: entry}
+		if err := o.export(ctx, puller, b, &a); err != nil {
+			return err
+		}
+		b.Artifacts = append(b.Artifacts, a)
+	}
+
+	if err := b.Write(path); err != nil {
+		return err
+	}
+	logger.Info("Bundle written", logger.Args("path", path, "artifacts", len(b.Artifacts)))
+	return nil
+}
+
+// export copies a, with its signature, to its layout in b and verifies the signature, if needed.
+func (o *bundleExportOptions) export(ctx context.Context, puller *ocipuller.Puller, b *bundle.Bundle, a *bundle.Artifact) error {
+	logger := o.Printer.Logger
+	logger.Info("Preparing to pull artifact", logger.Args("ref", a.Ref))
+
+	res, err := puller.PullLayout(ctx, a.Ref, b.LayoutDir(a))
+	if err != nil {
+		return err
+	}
+	a.Digest, a.Type = res.RootDigest, res.Type.String()
+
+	repo, err := utils.RepositoryFromRef(a.Ref)
+	if err != nil {
+		return err
+	}
+	digestRef := fmt.Sprintf("%s@%s", repo, a.Digest)
+	if a.Entry != nil && a.Entry.Signature != nil && !o.noVerify {
+		logger.Info("Verifying signature for artifact", logger.Args("digest", digestRef))
+		if err := signature.Verify(ctx, digestRef, a.Entry.Signature); err != nil {
+			return fmt.Errorf("error while verifying signature for %s: %w", digestRef, err)
+		}
+		logger.Info("Signature successfully verified!")
+		a.Verified = true
+	}
+
+	// The cosign signature is stored by cosign under a tag derived from the digest of the artifact.
+	sigTag := strings.Replace(a.Digest, ":", "-", 1) + ".sig"
+	switch _, err := puller.PullLayout(ctx, repo+":"+sigTag, b.LayoutDir(a)); {
+	case err == nil:
+		a.Signature = sigTag
+	case errors.Is(err, errdef.ErrNotFound):
+		logger.Debug("Artifact has no signature", logger.Args("digest", digestRef))
+	default:
+		return fmt.Errorf("unable to pull the signature of %s: %w", digestRef, err)
+	}
+	return nil
+}
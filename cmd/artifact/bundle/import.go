@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/falcosecurity/falcoctl/cmd/artifact/install"
+	"github.com/falcosecurity/falcoctl/internal/bundle"
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/installer"
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/enum"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const longImport = `Install the artifacts of a bundle written by "artifact bundle export", without reaching any registry.
+
+The artifacts are installed in the order they have been exported, dependencies included, in a single
+transaction and in the same directories as "artifact install". Their signatures are not verified
+again: a warning is printed for the signed artifacts whose signature has not been verified when
+exporting, with --no-verify.
+
+Example - Install the artifacts of a bundle:
+	falcoctl artifact bundle import falco-bundle.tar.gz
+`
+
+type bundleImportOptions struct {
+	*options.Common
+	*options.Directory
+	platform      string
+	mergeStrategy *enum.Enum
+}
+
+func newBundleImportCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := bundleImportOptions{
+		Common:        opt,
+		Directory:     &options.Directory{},
+		mergeStrategy: enum.NewEnum(installer.MergeStrategies, string(installer.MergeFail)),
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "import bundle [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Install the artifacts of a bundle offline",
+		Long:                  longImport,
+		Args:                  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Override the directory flags with viper config if not set by user.
+			for flag, key := range map[string]string{
+				options.FlagRulesFilesDir:   config.ArtifactInstallRulesfilesDirKey,
+				options.FlagPluginsFilesDir: config.ArtifactInstallPluginsDirKey,
+				options.FlagAssetsFilesDir:  config.ArtifactInstallAssetsDirKey,
+			} {
+				f := cmd.Flags().Lookup(flag)
+				if f == nil {
+					// should never happen
+					return fmt.Errorf("unable to retrieve flag %q", flag)
+				} else if !f.Changed && viper.IsSet(key) {
+					val := viper.Get(key)
+					if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+						return fmt.Errorf("unable to overwrite %q flag: %w", flag, err)
+					}
+				}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunBundleImport(ctx, args[0])
+		},
+	}
+
+	o.Directory.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.platform, install.FlagPlatform, fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		"os and architecture of the artifact in OS/ARCH format")
+	cmd.Flags().Var(o.mergeStrategy, install.FlagMergeStrategy,
+		"what to do when a file is already installed by another artifact: abort, overwrite it, suffix it with the artifact name "+
+			"or install the artifact in a subdirectory named after it "+o.mergeStrategy.Allowed())
+
+	return cmd
+}
+
+// RunBundleImport executes the business logic for the artifact bundle import command.
+func (o *bundleImportOptions) RunBundleImport(ctx context.Context, path string) error {
+	logger := o.Printer.Logger
+
+	platformOS, platformArch, ok := strings.Cut(o.platform, "/")
+	if !ok {
+		return fmt.Errorf("invalid %q: must be in the format OS/Arch", install.FlagPlatform)
+	}
+
+	b, err := bundle.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	keep, err := config.ArtifactHistoryKeep()
+	if err != nil {
+		return err
+	}
+
+	// Complete or roll back a previous install that did not finish.
+	inst := installer.New(config.InstallManifestFile, config.InstallJournalFile,
+		installer.WithMergeStrategy(installer.MergeStrategy(o.mergeStrategy.String())),
+		installer.WithHistory(installer.NewHistory(config.ArtifactHistoryDir, keep)))
+	recovered, err := inst.Recover()
+	if err != nil {
+		return fmt.Errorf("unable to recover interrupted install: %w", err)
+	}
+	if recovered != nil && recovered.RolledBack {
+		logger.Warn("Rolled back interrupted install", logger.Args("ref", recovered.Artifact.Ref, "directory", recovered.Artifact.Directory))
+	} else if recovered != nil {
+		logger.Warn("Completed interrupted install", logger.Args("ref", recovered.Artifact.Ref, "directory", recovered.Artifact.Directory))
+	}
+
+	tmpDir, err := os.MkdirTemp("", "falcoctl")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The artifacts are read from the layouts of the bundle, no registry is involved.
+	puller := ocipuller.NewPuller(nil, false, nil)
+
+	pending := make([]installer.Pending, 0, len(b.Artifacts))
+	defer func() {
+		for _, p := range pending {
+			if f, ok := p.Tarball.(*os.File); ok {
+				_ = f.Close()
+			}
+		}
+	}()
+	for i := range b.Artifacts {
+		a := &b.Artifacts[i]
+		if a.Entry != nil && a.Entry.Signature != nil && !a.Verified {
+			logger.Warn("Installing artifact whose signature has not been verified", logger.Args("ref", a.Ref, "digest", a.Digest))
+		}
+
+		dir := filepath.Join(tmpDir, strconv.Itoa(i))
+		result, err := puller.PullFromLayout(ctx, b.LayoutDir(a), a.Digest, dir, platformOS, platformArch)
+		if err != nil {
+			return err
+		}
+
+		var destDir string
+		switch result.Type {
+		case oci.Plugin:
+			destDir = o.PluginsDir
+		case oci.Rulesfile:
+			destDir = o.RulesfilesDir
+		case oci.Asset:
+			destDir = o.AssetsDir
+		default:
+			return fmt.Errorf("unrecognized result type %q while pulling artifact", result.Type)
+		}
+
+		// Check if directory exists and is writable.
+		if err := utils.ExistsAndIsWritable(destDir); err != nil {
+			return fmt.Errorf("cannot use directory %q as install destination: %w", destDir, err)
+		}
+
+		repo, err := utils.RepositoryFromRef(a.Ref)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(filepath.Join(dir, result.Filename))
+		if err != nil {
+			return err
+		}
+		pending = append(pending, installer.Pending{
+			Artifact: installer.Artifact{
+				Repository: repo,
+				Ref:        a.Ref,
+				Digest:     result.Digest,
+				Type:       result.Type.String(),
+				Version:    result.Config.Version,
+				Directory:  destDir,
+			},
+			Tarball: f,
+		})
+	}
+
+	if !o.Printer.DisableStyling {
+		o.Printer.Spinner, _ = o.Printer.Spinner.Start("Extracting and installing")
+	}
+	installed, err := inst.InstallAll(ctx, pending)
+	if o.Printer.Spinner != nil {
+		_ = o.Printer.Spinner.Stop()
+	}
+	if err != nil {
+		return fmt.Errorf("cannot install artifacts: %w", err)
+	}
+	for _, a := range installed {
+		logger.Info("Artifact successfully installed", logger.Args("name", a.Ref, "type", a.Type, "digest", a.Digest, "directory", a.Directory))
+	}
+	return nil
+}
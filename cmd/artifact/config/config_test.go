@@ -25,6 +25,7 @@ import (
 	"github.com/falcosecurity/falcoctl/cmd"
 )
 
+//nolint:lll // no need to check for line length.
 var usage = `Usage:
   falcoctl artifact config [ref] [flags]
 
@@ -33,11 +34,14 @@ Flags:
       --plain-http   allows interacting with remote registry via plain http requests
 
 Global Flags:
-      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string              config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string          Set formatting for logs (color, text, json) (default "color")
+      --log-level string           Set level for logs (info, warn, debug, trace) (default "info")
+      --registry-rewrite strings   Rewrite rules, in the "<from>=><to>" format, applied in order to the references resolved from the indexes (first match wins)
+      --socks5-proxy string        SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
+//nolint:lll // no need to check for line length.
 var help = `Get the config layer of an artifact
 
 Usage:
@@ -49,9 +53,11 @@ Flags:
       --platform string   os and architecture of the artifact in OS/ARCH format (default "linux/amd64")
 
 Global Flags:
-      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string              config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string          Set formatting for logs (color, text, json) (default "color")
+      --log-level string           Set level for logs (info, warn, debug, trace) (default "info")
+      --registry-rewrite strings   Rewrite rules, in the "<from>=><to>" format, applied in order to the references resolved from the indexes (first match wins)
+      --socks5-proxy string        SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
 var _ = Describe("Config", func() {
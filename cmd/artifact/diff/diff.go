@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const longDiff = `Compare two versions of an artifact.
+
+Both versions are downloaded into a temporary directory and extracted. For rulesfiles, a unified diff of
+every file that changed is printed. For plugins and assets, the files that were added, removed or modified
+are listed.
+
+Example:
+	falcoctl artifact diff k8saudit-rules:0.5.0 k8saudit-rules:0.6.0
+`
+
+type artifactDiffOptions struct {
+	*options.Common
+	*options.Registry
+	platform string
+	context  int
+}
+
+// version is a version of an artifact, extracted in a directory.
+type version struct {
+	ref string
+	typ oci.ArtifactType
+	dir string
+	// files are the paths of the extracted files, relative to dir.
+	files []string
+}
+
+// NewArtifactDiffCmd returns the artifact diff command.
+func NewArtifactDiffCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := artifactDiffOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "diff old-ref new-ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Compare two versions of an artifact",
+		Long:                  longDiff,
+		Args:                  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactDiff(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.platform, "platform", fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		"os and architecture of the artifact in OS/ARCH format")
+	cmd.Flags().IntVarP(&o.context, "unified", "U", 3, "Number of lines of context in the diff of rulesfiles")
+
+	return cmd
+}
+
+// RunArtifactDiff implements the artifact diff command.
+func (o *artifactDiffOptions) RunArtifactDiff(ctx context.Context, args []string) error {
+	tokens := strings.Split(o.platform, "/")
+	if len(tokens) != 2 {
+		return fmt.Errorf("invalid platform format: %s", o.platform)
+	}
+
+	// Create puller with auto login enabled.
+	puller, err := ociutils.Puller(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "falcoctl-diff-")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	versions := make([]*version, len(args))
+	for i, name := range args {
+		if versions[i], err = o.fetch(ctx, puller, name, filepath.Join(tmpDir, fmt.Sprint(i)), tokens[0], tokens[1]); err != nil {
+			return err
+		}
+	}
+	oldVersion, newVersion := versions[0], versions[1]
+
+	if oldVersion.typ != newVersion.typ {
+		return fmt.Errorf("cannot compare a %s with a %s", oldVersion.typ, newVersion.typ)
+	}
+
+	var out string
+	if oldVersion.typ == oci.Rulesfile {
+		out, err = unifiedDiff(oldVersion, newVersion, o.context)
+	} else {
+		out, err = fileListDiff(oldVersion, newVersion)
+	}
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		o.Printer.DefaultText.Printfln("no changes between %s and %s", oldVersion.ref, newVersion.ref)
+		return nil
+	}
+	o.Printer.DefaultText.Print(out)
+	return nil
+}
+
+// fetch pulls the artifact name and extracts it under dir.
+func (o *artifactDiffOptions) fetch(ctx context.Context, puller *ocipuller.Puller, name, dir, goos, goarch string) (*version, error) {
+	ref, err := o.IndexCache.ResolveReference(name)
+	if err != nil {
+		return nil, err
+	}
+
+	o.Printer.Logger.Debug("Pulling artifact", o.Printer.Logger.Args("ref", ref))
+	pullDir := filepath.Join(dir, "pull")
+	if err := os.MkdirAll(pullDir, 0o700); err != nil {
+		return nil, fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	res, err := puller.Pull(ctx, ref, pullDir, goos, goarch)
+	if err != nil {
+		return nil, err
+	}
+
+	tarball, err := openFile(filepath.Join(pullDir, res.Filename))
+	if err != nil {
+		return nil, err
+	}
+	defer tarball.Close()
+
+	v := &version{ref: ref, typ: res.Type, dir: filepath.Join(dir, "files")}
+	if err := os.MkdirAll(v.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	paths, err := utils.ExtractTarGz(ctx, tarball, v.dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract %q: %w", ref, err)
+	}
+	for _, p := range paths {
+		rel, err := filepath.Rel(v.dir, p)
+		if err != nil {
+			return nil, err
+		}
+		v.files = append(v.files, rel)
+	}
+	sort.Strings(v.files)
+
+	return v, nil
+}
+
+// unifiedDiff returns the unified diff of the files of two versions, in path order.
+// Files that only exist in one of the versions are diffed against /dev/null.
+func unifiedDiff(oldVersion, newVersion *version, contextLines int) (string, error) {
+	var sb strings.Builder
+
+	for _, f := range unionFiles(oldVersion, newVersion) {
+		oldContent, oldName, err := readVersionFile(oldVersion, f)
+		if err != nil {
+			return "", err
+		}
+		newContent, newName, err := readVersionFile(newVersion, f)
+		if err != nil {
+			return "", err
+		}
+		if bytes.Equal(oldContent, newContent) && oldName != "/dev/null" && newName != "/dev/null" {
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(string(oldContent)),
+			B:        splitLines(string(newContent)),
+			FromFile: oldName,
+			ToFile:   newName,
+			Context:  contextLines,
+		})
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(diff)
+	}
+
+	return sb.String(), nil
+}
+
+// fileListDiff lists the files added (+), removed (-) and modified (~) between two versions, in path order.
+func fileListDiff(oldVersion, newVersion *version) (string, error) {
+	var sb strings.Builder
+
+	for _, f := range unionFiles(oldVersion, newVersion) {
+		inOld, inNew := contains(oldVersion.files, f), contains(newVersion.files, f)
+		switch {
+		case !inOld:
+			sb.WriteString(fmt.Sprintf("+ %s\n", f))
+		case !inNew:
+			sb.WriteString(fmt.Sprintf("- %s\n", f))
+		default:
+			oldSum, err := utils.FileDigest(filepath.Join(oldVersion.dir, f))
+			if err != nil {
+				return "", err
+			}
+			newSum, err := utils.FileDigest(filepath.Join(newVersion.dir, f))
+			if err != nil {
+				return "", err
+			}
+			if oldSum != newSum {
+				sb.WriteString(fmt.Sprintf("~ %s\n", f))
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// unionFiles returns the sorted paths of the files present in at least one of the versions.
+func unionFiles(oldVersion, newVersion *version) []string {
+	set := make(map[string]struct{}, len(oldVersion.files)+len(newVersion.files))
+	for _, f := range oldVersion.files {
+		set[f] = struct{}{}
+	}
+	for _, f := range newVersion.files {
+		set[f] = struct{}{}
+	}
+
+	files := make([]string, 0, len(set))
+	for f := range set {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// readVersionFile returns the content of the file f of a version, and the name to show in the diff.
+// A file missing from the version is empty and named /dev/null.
+func readVersionFile(v *version, f string) (content []byte, name string, err error) {
+	if !contains(v.files, f) {
+		return nil, "/dev/null", nil
+	}
+	content, err = os.ReadFile(filepath.Join(v.dir, f))
+	if err != nil {
+		return nil, "", err
+	}
+	return content, fmt.Sprintf("%s/%s", v.ref, filepath.ToSlash(f)), nil
+}
+
+func contains(sorted []string, s string) bool {
+	i := sort.SearchStrings(sorted, s)
+	return i < len(sorted) && sorted[i] == s
+}
+
+func openFile(path string) (*os.File, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %q: %w", path, err)
+	}
+	return f, nil
+}
+
+// splitLines splits content into lines, keeping their line ending.
+// Unlike difflib.SplitLines, no empty line is made up after the final line ending.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
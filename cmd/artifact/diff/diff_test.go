@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersion writes files, by relative path, in a temporary directory.
+func newVersion(t *testing.T, ref string, files map[string]string) *version {
+	t.Helper()
+	v := &version{ref: ref, dir: t.TempDir()}
+	for name, content := range files {
+		path := filepath.Join(v.dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		v.files = append(v.files, name)
+	}
+	sort.Strings(v.files)
+	return v
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	oldVersion := newVersion(t, "rules:1", map[string]string{
+		"rules.yaml":   "- rule: a\n  desc: one\n",
+		"removed.yaml": "removed: true\n",
+		"same.yaml":    "same: true\n",
+	})
+	newVersion := newVersion(t, "rules:2", map[string]string{
+		"rules.yaml": "- rule: a\n  desc: two\n",
+		"added.yaml": "added: true\n",
+		"same.yaml":  "same: true\n",
+	})
+
+	out, err := unifiedDiff(oldVersion, newVersion, 3)
+	require.NoError(t, err)
+	assert.Equal(t, `--- /dev/null
++++ rules:2/added.yaml
+@@ -0,0 +1 @@
++added: true
+--- rules:1/removed.yaml
++++ /dev/null
+@@ -1 +0,0 @@
+-removed: true
+--- rules:1/rules.yaml
++++ rules:2/rules.yaml
+@@ -1,2 +1,2 @@
+ - rule: a
+-  desc: one
++  desc: two
+`, out)
+
+	out, err = unifiedDiff(oldVersion, oldVersion, 3)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestFileListDiff(t *testing.T) {
+	oldVersion := newVersion(t, "plugin:1", map[string]string{
+		"libplugin.so": "v1",
+		"README.md":    "readme",
+		"old.txt":      "old",
+	})
+	newVersion := newVersion(t, "plugin:2", map[string]string{
+		"libplugin.so": "v2",
+		"README.md":    "readme",
+		"new.txt":      "new",
+	})
+
+	out, err := fileListDiff(oldVersion, newVersion)
+	require.NoError(t, err)
+	assert.Equal(t, "~ libplugin.so\n+ new.txt\n- old.txt\n", out)
+
+	out, err = fileListDiff(oldVersion, oldVersion)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
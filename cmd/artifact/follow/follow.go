@@ -18,10 +18,13 @@ package follow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -32,6 +35,10 @@ import (
 	"github.com/falcosecurity/falcoctl/cmd/artifact/install"
 	"github.com/falcosecurity/falcoctl/internal/config"
 	"github.com/falcosecurity/falcoctl/internal/follower"
+	"github.com/falcosecurity/falcoctl/internal/installer"
+	"github.com/falcosecurity/falcoctl/internal/signature"
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/enum"
 	"github.com/falcosecurity/falcoctl/pkg/index/index"
 	"github.com/falcosecurity/falcoctl/pkg/oci"
 	"github.com/falcosecurity/falcoctl/pkg/options"
@@ -41,6 +48,10 @@ import (
 const (
 	timeout = time.Second * 5
 
+	flagWebhook        = "webhook"
+	flagMetricsAddress = "metrics-address"
+	flagDryRun         = "dry-run"
+
 	longFollow = `This command allows you to keep up-to-date one or more given artifacts.
 It checks for updates on a periodic basis and then downloads and installs the latest version, 
 as specified by the passed tags. 
@@ -82,15 +93,20 @@ type artifactFollowOptions struct {
 	*options.Common
 	*options.Registry
 	*options.Directory
-	tmpDir        string
-	every         time.Duration
-	cron          string
-	falcoVersions string
-	versions      config.FalcoVersions
-	timeout       time.Duration
-	closeChan     chan bool
-	allowedTypes  oci.ArtifactTypeSlice
-	noVerify      bool
+	tmpDir         string
+	every          time.Duration
+	cron           string
+	falcoVersions  string
+	versions       config.FalcoVersions
+	timeout        time.Duration
+	closeChan      chan bool
+	allowedTypes   oci.ArtifactTypeSlice
+	noVerify       bool
+	verify         bool
+	verifyMode     *enum.Enum
+	webhooks       []string
+	metricsAddress string
+	dryRun         bool
 }
 
 // NewArtifactFollowCmd returns the artifact follow command.
@@ -98,11 +114,12 @@ type artifactFollowOptions struct {
 //nolint:gocyclo // unknown reason for cyclomatic complexity
 func NewArtifactFollowCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	o := artifactFollowOptions{
-		Common:    opt,
-		Registry:  &options.Registry{},
-		Directory: &options.Directory{},
-		closeChan: make(chan bool),
-		versions:  config.FalcoVersions{},
+		Common:     opt,
+		Registry:   &options.Registry{},
+		Directory:  &options.Directory{},
+		closeChan:  make(chan bool),
+		versions:   config.FalcoVersions{},
+		verifyMode: enum.NewEnum(signature.Modes, string(signature.ModeEnforce)),
 	}
 
 	cmd := &cobra.Command{
@@ -221,6 +238,66 @@ func NewArtifactFollowCmd(ctx context.Context, opt *options.Common) *cobra.Comma
 				}
 			}
 
+			// Override "verify" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(install.FlagVerify)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", install.FlagVerify)
+			} else if !f.Changed && viper.IsSet(config.ArtifactVerifyEnabledKey) {
+				val := viper.Get(config.ArtifactVerifyEnabledKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", install.FlagVerify, err)
+				}
+			}
+
+			// Override "verify-mode" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(install.FlagVerifyMode)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", install.FlagVerifyMode)
+			} else if !f.Changed && viper.IsSet(config.ArtifactVerifyModeKey) {
+				val := viper.Get(config.ArtifactVerifyModeKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", install.FlagVerifyMode, err)
+				}
+			}
+
+			// Override "webhook" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(flagWebhook)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", flagWebhook)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowWebhooksKey) {
+				val, err := config.ArtifactFollowWebhooks()
+				if err != nil {
+					return err
+				}
+				if err := cmd.Flags().Set(f.Name, strings.Join(val, ",")); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", flagWebhook, err)
+				}
+			}
+			// Override "metrics-address" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(flagMetricsAddress)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", flagMetricsAddress)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowMetricsAddressKey) {
+				val := viper.Get(config.ArtifactFollowMetricsAddressKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", flagMetricsAddress, err)
+				}
+			}
+
+			for _, w := range o.webhooks {
+				if u, err := url.Parse(w); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("invalid webhook %q, expected an http or https URL", w)
+				}
+			}
+
+			if o.verify && o.noVerify {
+				return fmt.Errorf("%q and %q cannot be used together", install.FlagVerify, install.FlagNoVerify)
+			}
+
 			// Get Falco versions via HTTP endpoint
 			if err := o.retrieveFalcoVersions(ctx); err != nil {
 				return fmt.Errorf("unable to retrieve Falco versions, please check if it is running "+
@@ -252,6 +329,19 @@ Examples:
 	--%s=rulesfile --%s=plugin`, install.FlagAllowedTypes, install.FlagAllowedTypes, install.FlagAllowedTypes))
 	cmd.Flags().BoolVar(&o.noVerify, install.FlagNoVerify, false,
 		"whether this command should skip signature verification")
+	cmd.Flags().BoolVar(&o.verify, install.FlagVerify, false,
+		"require a valid cosign signature for every artifact, signed by the signer configured in artifact.verify.cosign "+
+			"or, if not configured, by the one of its index")
+	cmd.Flags().Var(o.verifyMode, install.FlagVerifyMode,
+		"what to do when "+install.FlagVerify+" is set and a signature is missing or does not match: "+
+			"skip the update or only warn "+o.verifyMode.Allowed())
+	cmd.Flags().StringSliceVar(&o.webhooks, flagWebhook, nil,
+		"URL notified with a JSON payload after every update of the followed artifacts, successful or not. "+
+			"It accepts comma separated values or it can be repeated multiple times")
+	cmd.Flags().StringVar(&o.metricsAddress, flagMetricsAddress, "",
+		"address, e.g. \":9090\", where to serve the Prometheus metrics of the followers on /metrics. Disabled if empty")
+	cmd.Flags().BoolVar(&o.dryRun, flagDryRun, false,
+		"check the artifacts once, then only print the files that would be written, without installing them nor following the artifacts")
 	cmd.MarkFlagsMutuallyExclusive("cron", "every")
 
 	return cmd
@@ -284,9 +374,47 @@ func (o *artifactFollowOptions) RunArtifactFollow(ctx context.Context, args []st
 		sched = scheduledDuration{o.every}
 	}
 
+	var policy *signature.Policy
+	if o.verify {
+		if policy, err = signature.ConfiguredPolicy(o.verifyMode.String()); err != nil {
+			return err
+		}
+	}
+
+	keep, err := config.ArtifactHistoryKeep()
+	if err != nil {
+		return err
+	}
+	history := installer.NewHistory(config.ArtifactHistoryDir, keep)
+
+	var notifier follower.Notifier
+	if len(o.webhooks) > 0 {
+		notifier = follower.NewWebhooks(o.webhooks, follower.DefaultWebhookTimeout)
+	}
+
+	configuredHooks, err := config.ArtifactFollowHooks()
+	if err != nil {
+		return err
+	}
+	hooks := make([]follower.Hook, len(configuredHooks))
+	for k, h := range configuredHooks {
+		if hooks[k], err = follower.NewHook(h); err != nil {
+			return fmt.Errorf("%s[%d]: %w", config.ArtifactFollowHooksKey, k, err)
+		}
+	}
+
+	var metrics *follower.Metrics
+	if o.metricsAddress != "" && !o.dryRun {
+		metrics = follower.NewMetrics()
+		if err := o.serveMetrics(ctx, metrics); err != nil {
+			return err
+		}
+	}
+
 	var wg sync.WaitGroup
 	// For each artifact create a follower.
 	var followers = make(map[string]*follower.Follower, 0)
+	var refs []string
 	for _, a := range args {
 		if o.cron != "" {
 			logger.Info("Creating follower", logger.Args("artifact", a, "cron", o.cron))
@@ -300,22 +428,37 @@ func (o *artifactFollowOptions) RunArtifactFollow(ctx context.Context, args []st
 
 		var sig *index.Signature
 		if !o.noVerify {
+			// With --verify, the signer of the index is the fallback of the configured one.
 			sig = o.IndexCache.SignatureForIndexRef(a)
 		}
 
+		var refHooks []follower.Hook
+		for k, h := range configuredHooks {
+			if h.Ref == "" || h.Ref == a || h.Ref == ref {
+				refHooks = append(refHooks, hooks[k])
+			}
+		}
+
 		cfg := &follower.Config{
-			WaitGroup:         &wg,
-			Resync:            sched,
-			RulesfilesDir:     o.RulesfilesDir,
-			PluginsDir:        o.PluginsDir,
-			AssetsDir:         o.AssetsDir,
-			ArtifactReference: ref,
-			PlainHTTP:         o.PlainHTTP,
-			CloseChan:         o.closeChan,
-			TmpDir:            o.tmpDir,
-			FalcoVersions:     o.versions,
-			AllowedTypes:      o.allowedTypes,
-			Signature:         sig,
+			WaitGroup:           &wg,
+			Resync:              sched,
+			RulesfilesDir:       o.RulesfilesDir,
+			PluginsDir:          o.PluginsDir,
+			AssetsDir:           o.AssetsDir,
+			ArtifactReference:   ref,
+			PlainHTTP:           o.PlainHTTP,
+			CloseChan:           o.closeChan,
+			TmpDir:              o.tmpDir,
+			FalcoVersions:       o.versions,
+			AllowedTypes:        o.allowedTypes,
+			Signature:           sig,
+			VerifyPolicy:        policy,
+			History:             history,
+			InstallManifestFile: config.InstallManifestFile,
+			Notifier:            notifier,
+			Hooks:               refHooks,
+			Metrics:             metrics,
+			DryRun:              o.dryRun,
 		}
 		fol, err := follower.New(ref, o.Printer, cfg)
 		if err != nil {
@@ -323,6 +466,18 @@ func (o *artifactFollowOptions) RunArtifactFollow(ctx context.Context, args []st
 		}
 		wg.Add(1)
 		followers[ref] = fol
+		refs = append(refs, ref)
+	}
+
+	if o.dryRun {
+		// Check each artifact once, without following it.
+		var errs []error
+		for _, ref := range refs {
+			if err := followers[ref].Check(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+			}
+		}
+		return errors.Join(errs...)
 	}
 
 	for k, f := range followers {
@@ -355,6 +510,33 @@ func (o *artifactFollowOptions) RunArtifactFollow(ctx context.Context, args []st
 	return nil
 }
 
+// serveMetrics serves the metrics on /metrics at the configured address, until ctx is done.
+func (o *artifactFollowOptions) serveMetrics(ctx context.Context, metrics *follower.Metrics) error {
+	logger := o.Printer.Logger
+
+	ln, err := net.Listen("tcp", o.metricsAddress)
+	if err != nil {
+		return fmt.Errorf("unable to serve metrics on %q: %w", o.metricsAddress, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: timeout}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Unable to serve metrics", logger.Args("address", o.metricsAddress, "reason", err.Error()))
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	logger.Info("Serving metrics", logger.Args("address", ln.Addr().String(), "path", "/metrics"))
+	return nil
+}
+
 func (o *artifactFollowOptions) retrieveFalcoVersions(ctx context.Context) error {
 	_, err := url.ParseRequestURI(o.falcoVersions)
 	if err != nil {
@@ -371,7 +553,7 @@ func (o *artifactFollowOptions) retrieveFalcoVersions(ctx context.Context) error
 
 	client := &http.Client{
 		Transport: &backoffTransport{
-			Base:    http.DefaultTransport,
+			Base:    utils.Transport(),
 			Printer: o.Printer,
 			Config:  backoffConfig,
 		},
@@ -33,6 +33,13 @@ import (
 type artifactInfoOptions struct {
 	*options.Common
 	*options.Registry
+	*options.Output
+}
+
+// artifactInfo is the machine-readable representation of an artifact's versions.
+type artifactInfo struct {
+	Ref  string   `json:"ref" yaml:"ref"`
+	Tags []string `json:"tags" yaml:"tags"`
 }
 
 // NewArtifactInfoCmd returns the artifact info command.
@@ -40,6 +47,7 @@ func NewArtifactInfoCmd(ctx context.Context, opt *options.Common) *cobra.Command
 	o := artifactInfoOptions{
 		Common:   opt,
 		Registry: &options.Registry{},
+		Output:   &options.Output{},
 	}
 
 	cmd := &cobra.Command{
@@ -48,18 +56,23 @@ func NewArtifactInfoCmd(ctx context.Context, opt *options.Common) *cobra.Command
 		Short:                 "Retrieve all available versions of a given artifact",
 		Long:                  "Retrieve all available versions of a given artifact",
 		Args:                  cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Output.Validate()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.RunArtifactInfo(ctx, args)
 		},
 	}
 
 	o.Registry.AddFlags(cmd)
+	o.Output.AddFlags(cmd)
 
 	return cmd
 }
 
 func (o *artifactInfoOptions) RunArtifactInfo(ctx context.Context, args []string) error {
 	var data [][]string
+	infos := []artifactInfo{}
 	logger := o.Printer.Logger
 
 	client, err := ociutils.Client(true)
@@ -99,8 +112,13 @@ func (o *artifactInfoOptions) RunArtifactInfo(ctx context.Context, args []string
 			return err
 		}
 
-		joinedTags := strings.Join(filterOutSigTags(tags), ", ")
-		data = append(data, []string{ref, joinedTags})
+		tags = filterOutSigTags(tags)
+		data = append(data, []string{ref, strings.Join(tags, ", ")})
+		infos = append(infos, artifactInfo{Ref: ref, Tags: tags})
+	}
+
+	if o.Structured() {
+		return o.Printer.PrintStructured(o.Format, infos)
 	}
 
 	// Print the table header + data only if there is data.
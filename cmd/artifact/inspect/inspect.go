@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+type artifactInspectOptions struct {
+	*options.Common
+	*options.Registry
+	*options.Output
+	platform string
+	noFiles  bool
+}
+
+// NewArtifactInspectCmd returns the artifact inspect command.
+func NewArtifactInspectCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := artifactInspectOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+		Output:   &options.Output{},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "inspect [ref] [flags]",
+		Short: "Show the layers and contents of an artifact without installing it",
+		Long: `Show the layers and contents of an artifact without installing it.
+
+It fetches the manifest and the config layer of the artifact and prints its annotations, dependencies,
+requirements, such as the plugin API version, and the media type of each layer. The layers are streamed,
+without being written to disk, to list the files they would install, unless --no-files is given.
+`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Output.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactInspect(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	o.Output.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.platform, "platform", fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		"os and architecture of the artifact in OS/ARCH format")
+	cmd.Flags().BoolVar(&o.noFiles, "no-files", false, "Do not download the layers to list the files they contain")
+
+	return cmd
+}
+
+func (o *artifactInspectOptions) RunArtifactInspect(ctx context.Context, args []string) error {
+	var (
+		puller     *ocipuller.Puller
+		ref        string
+		inspection *ocipuller.Inspection
+		err        error
+	)
+
+	// Create puller with auto login enabled.
+	if puller, err = ociutils.Puller(o.PlainHTTP, o.Printer); err != nil {
+		return err
+	}
+
+	// Resolve the artifact reference.
+	if ref, err = o.IndexCache.ResolveReference(args[0]); err != nil {
+		return err
+	}
+
+	tokens := strings.Split(o.platform, "/")
+	if len(tokens) != 2 {
+		return fmt.Errorf("invalid platform format: %s", o.platform)
+	}
+
+	if inspection, err = puller.Inspect(ctx, ref, tokens[0], tokens[1], !o.noFiles); err != nil {
+		return err
+	}
+
+	if o.Structured() {
+		return o.Printer.PrintStructured(o.Format, inspection)
+	}
+
+	o.printInspection(inspection)
+	return nil
+}
+
+// printInspection prints the inspection in a human readable form.
+func (o *artifactInspectOptions) printInspection(i *ocipuller.Inspection) {
+	p := o.Printer.DefaultText
+
+	p.Printf("Ref:    %s\n", i.Ref)
+	p.Printf("Digest: %s\n", i.Digest)
+	p.Printf("Type:   %s\n", i.Type)
+
+	if len(i.Annotations) > 0 {
+		p.Printf("Annotations:\n")
+		o.printAnnotations(i.Annotations, "  ")
+	}
+
+	if len(i.Requirements) > 0 {
+		p.Printf("Requirements:\n")
+		for _, r := range i.Requirements {
+			p.Printf("  %s:%s\n", r.Name, r.Version)
+		}
+	}
+
+	if len(i.Dependencies) > 0 {
+		p.Printf("Dependencies:\n")
+		for _, d := range i.Dependencies {
+			dep := fmt.Sprintf("%s:%s", d.Name, d.Version)
+			for _, alt := range d.Alternatives {
+				dep += fmt.Sprintf("|%s:%s", alt.Name, alt.Version)
+			}
+			p.Printf("  %s\n", dep)
+		}
+	}
+
+	p.Printf("Layers:\n")
+	for _, l := range i.Layers {
+		p.Printf("  - %s\n", l.Digest)
+		p.Printf("    Media type: %s\n", l.MediaType)
+		p.Printf("    Size:       %d\n", l.Size)
+		if len(l.Annotations) > 0 {
+			p.Printf("    Annotations:\n")
+			o.printAnnotations(l.Annotations, "      ")
+		}
+		if len(l.Files) > 0 {
+			p.Printf("    Files:\n")
+			for _, f := range l.Files {
+				p.Printf("      %s\n", f)
+			}
+		}
+	}
+}
+
+// printAnnotations prints the annotations sorted by key, with the given indentation.
+func (o *artifactInspectOptions) printAnnotations(annotations map[string]string, indent string) {
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		o.Printer.DefaultText.Printf("%s%s: %s\n", indent, k, annotations[k])
+	}
+}
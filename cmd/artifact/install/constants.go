@@ -27,4 +27,28 @@ const (
 
 	// FlagNoVerify is the name of the flag to disable signature verification.
 	FlagNoVerify = "no-verify"
+
+	// FlagVerify is the name of the flag to require the signature verification of all the artifacts.
+	FlagVerify = "verify"
+
+	// FlagVerifyMode is the name of the flag to set what to do when the signature verification fails.
+	FlagVerifyMode = "verify-mode"
+
+	// FlagMergeStrategy is the name of the flag to set the strategy used on file collisions.
+	FlagMergeStrategy = "merge-strategy"
+
+	// FlagMaxParallel is the name of the flag to set how many artifacts are pulled in parallel.
+	FlagMaxParallel = "max-parallel"
+
+	// FlagWriteLock is the name of the flag to record the digests of the installed artifacts in the lock file.
+	FlagWriteLock = "write-lock"
+
+	// FlagLocked is the name of the flag to install the digests recorded in the lock file.
+	FlagLocked = "locked"
+
+	// FlagLockFile is the name of the flag to set the path of the lock file.
+	FlagLockFile = "lock-file"
+
+	// FlagDryRun is the name of the flag to only print the files that would be written.
+	FlagDryRun = "dry-run"
 )
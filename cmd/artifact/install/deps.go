@@ -18,6 +18,7 @@ package install
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/blang/semver"
 
@@ -25,13 +26,18 @@ import (
 )
 
 type artifactConfigResolver func(ref string) (*oci.RegistryResult, error)
-type depsMapType map[string]*depInfo
+
+// artifactVersionLister lists the tags of the artifact name, to find the versions satisfying a range.
+type artifactVersionLister func(name string) ([]string, error)
 
 var (
 	// ErrCannotSatisfyDependencies is the error returned when we cannot correctly resolve dependencies.
 	ErrCannotSatisfyDependencies = errors.New("cannot satisfy dependencies")
 )
 
+// maxResolveRounds bounds the rounds of ResolveDeps, each of which may select other versions of the dependencies.
+const maxResolveRounds = 100
+
 type depInfo struct {
 	// ref is the remote reference to this artifact
 	ref string
@@ -39,163 +45,252 @@ type depInfo struct {
 	config *oci.ArtifactConfig
 	// ver represents the semver version of this artifact
 	ver *semver.Version
-	// ok is used to mark this dependency as fully processed, with its own
-	// dependencies and alternatives
-	ok bool
 }
 
-func copyDepsMap(in depsMapType) (out depsMapType) {
-	out = make(depsMapType, len(in))
-	for k, v := range in {
-		out[k] = v
+// constraint is a requirement on the version of an artifact.
+type constraint struct {
+	// raw is the constraint as written in the artifact config.
+	raw string
+	// by is the name of the artifact requiring it, empty when requested by the user.
+	by string
+	// ver and ref are set when raw is a plain version, which is also a candidate.
+	ver *semver.Version
+	ref string
+	// satisfied reports whether a version satisfies the constraint.
+	satisfied semver.Range
+}
+
+// parseConstraint parses the version required for the artifact name. A plain version requires a
+// compatible one: the same major, at least as recent. Otherwise it is a range, such as ">=0.7.0 <0.9.0".
+func parseConstraint(name, raw string) (*constraint, error) {
+	if ver, err := semver.Parse(raw); err == nil {
+		return &constraint{
+			raw: raw,
+			ver: &ver,
+			ref: name + ":" + raw,
+			satisfied: func(v semver.Version) bool {
+				return v.Major == ver.Major && v.GTE(ver)
+			},
+		}, nil
+	}
+	r, err := semver.ParseRange(raw)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid artifact config: version %q is not semver compatible`, raw)
 	}
-	return
+	return &constraint{raw: raw, satisfied: r}, nil
 }
 
-// ResolveDeps resolves dependencies to a list of references.
-func ResolveDeps(resolver artifactConfigResolver, inRefs ...string) (outRefs []string, err error) {
-	depMap := make(depsMapType)
-	// configMap is used to avoid getting a remote config layer more than once
-	configMap := make(map[string]*oci.ArtifactConfig)
+func (c *constraint) String() string {
+	if c.by == "" {
+		return c.raw + " (requested)"
+	}
+	return c.raw + " (required by " + c.by + ")"
+}
 
-	retrieveConfig := func(ref string) (*oci.ArtifactConfig, error) {
-		config, ok := configMap[ref]
-		if !ok {
-			res, err := resolver(ref)
-			if err != nil {
-				return nil, err
-			}
+// ResolveDeps resolves dependencies to a list of references, given in install order: each artifact comes
+// after its own dependencies. Every artifact gets the most recent version satisfying the constraints of
+// all the artifacts depending on it; ranges are resolved against the versions listed by lister.
+func ResolveDeps(resolver artifactConfigResolver, lister artifactVersionLister, inRefs ...string) (outRefs []string, err error) {
+	// configMap is used to avoid getting a remote config layer more than once
+	configMap := make(map[string]*depInfo)
+	// tagsMap is used to avoid listing the versions of an artifact more than once
+	tagsMap := make(map[string][]string)
 
-			configMap[ref] = &res.Config
-			return &res.Config, nil
+	retrieveInfo := func(ref string) (*depInfo, error) {
+		if info, ok := configMap[ref]; ok {
+			return info, nil
 		}
-
-		return config, nil
-	}
-
-	upsertMap := func(ref string) error {
-		// fetch artifact config layer metadata
-		config, err := retrieveConfig(ref)
+		res, err := resolver(ref)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
+		config := &res.Config
 		if config.Version == "" {
-			return fmt.Errorf("empty version for ref %q: config may be corrupted", ref)
+			return nil, fmt.Errorf("empty version for ref %q: config may be corrupted", ref)
 		}
-
 		ver, err := semver.Parse(config.Version)
 		if err != nil {
-			return fmt.Errorf("unable to parse version %q for ref %q, %w", config.Version, ref, err)
+			return nil, fmt.Errorf("unable to parse version %q for ref %q, %w", config.Version, ref, err)
 		}
+		info := &depInfo{ref: ref, config: config, ver: &ver}
+		configMap[ref] = info
+		return info, nil
+	}
 
-		depMap[config.Name] = &depInfo{
-			ref:    ref,
-			config: config,
-			ver:    &ver,
+	listVersions := func(name string) ([]string, error) {
+		if tags, ok := tagsMap[name]; ok || lister == nil {
+			return tags, nil
+		}
+		tags, err := lister(name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the versions of %q: %w", name, err)
 		}
-		return nil
+		tagsMap[name] = tags
+		return tags, nil
 	}
 
-	// Prepare initial map from user inputs
+	// choose selects the most recent candidate satisfying all the constraints on name.
+	choose := func(name string, constraints []*constraint) (*depInfo, error) {
+		candidates := make(map[string]string)
+		ranged := false
+		for _, c := range constraints {
+			if c.ver == nil {
+				ranged = true
+			} else if _, ok := candidates[c.ver.String()]; !ok {
+				candidates[c.ver.String()] = c.ref
+			}
+		}
+		if ranged {
+			tags, err := listVersions(name)
+			if err != nil {
+				return nil, err
+			}
+			for _, tag := range tags {
+				if _, ok := candidates[tag]; !ok {
+					candidates[tag] = name + ":" + tag
+				}
+			}
+		}
+
+		var best *semver.Version
+		var bestRef string
+		for candidate, ref := range candidates {
+			ver, err := semver.Parse(candidate)
+			if err != nil {
+				// Not a version, such as the latest tag.
+				continue
+			}
+			if best != nil && ver.LTE(*best) {
+				continue
+			}
+			ok := true
+			for _, c := range constraints {
+				ok = ok && c.satisfied(ver)
+			}
+			if ok {
+				best, bestRef = &ver, ref
+			}
+		}
+		if best == nil {
+			required := make([]string, len(constraints))
+			for i, c := range constraints {
+				required[i] = c.String()
+			}
+			return nil, fmt.Errorf("%w: no version of %s satisfies %s", ErrCannotSatisfyDependencies, name, strings.Join(required, ", "))
+		}
+		return retrieveInfo(bestRef)
+	}
+
+	// Prepare the constraints from user inputs
+	var roots []string
+	rootConstraints := make(map[string]*constraint)
 	for _, ref := range inRefs {
-		config, err := retrieveConfig(ref)
+		info, err := retrieveInfo(ref)
 		if err != nil {
 			return nil, err
 		}
-		name := config.Name
+		name := info.config.Name
 
 		// todo: shall we shadow?
-		if info, ok := depMap[name]; ok {
-			return nil, fmt.Errorf(`cannot provide multiple references for %q: %q, %q`, name, info.ref, ref)
+		if c, ok := rootConstraints[name]; ok {
+			return nil, fmt.Errorf(`cannot provide multiple references for %q: %q, %q`, name, c.ref, ref)
 		}
 
-		if err := upsertMap(ref); err != nil {
+		c, err := parseConstraint(name, info.ver.String())
+		if err != nil {
 			return nil, err
 		}
+		c.ref = ref
+		rootConstraints[name] = c
+		roots = append(roots, name)
 	}
 
-	for {
-		allOk := true
-		// Since we are updating depMap in this for loop, let's copy the map for iterating it
-		// while we continue inserting new values in the real depMap map.
-		for name, info := range copyDepsMap(depMap) {
-			if info.ok {
-				continue
+	selected := make(map[string]*depInfo)
+	for round := 0; round < maxResolveRounds; round++ {
+		// Walk the dependency graph of the versions selected so far, collecting the constraints.
+		constraints := make(map[string][]*constraint)
+		edges := make(map[string][]string)
+		var closure []string
+		add := func(name string, c *constraint) {
+			if _, ok := constraints[name]; !ok {
+				closure = append(closure, name)
 			}
-			for _, required := range info.config.Dependencies {
-				// Does already exist in the map?
-				if existing, ok := depMap[required.Name]; ok {
-					requiredVer, err := semver.Parse(required.Version)
-					if err != nil {
-						return nil, fmt.Errorf(`invalid artifact config: version %q is not semver compatible`, required.Version)
-					}
-
-					// Is the existing dep compatible?
-					if existing.ver.Major != requiredVer.Major {
-						return nil, fmt.Errorf(
-							`%w: %s depends on %s:%s but an incompatible version %s:%s is required by other artifacts`,
-							ErrCannotSatisfyDependencies, name, required.Name, required.Version, required.Name, existing.ver.String(),
-						)
-					}
-
-					// Is required version greater than existing one?
-					if requiredVer.Compare(*existing.ver) <= 0 {
-						continue
-					}
+			constraints[name] = append(constraints[name], c)
+		}
+		for _, name := range roots {
+			add(name, rootConstraints[name])
+		}
+		for k := 0; k < len(closure); k++ {
+			name := closure[k]
+			info, ok := selected[name]
+			if !ok {
+				if info, err = choose(name, constraints[name]); err != nil {
+					return nil, err
 				}
-
-				// Are alternatives already in the map?
-				var foundAlternative = false
-				for _, alternative := range required.Alternatives {
-					existing, ok := depMap[alternative.Name]
-					if !ok {
-						continue
-					}
-
-					foundAlternative = true
-
-					alternativeVer, err := semver.Parse(alternative.Version)
-					if err != nil {
-						return nil, fmt.Errorf(`invalid artifact config: version %q is not semver compatible`, required.Version)
-					}
-
-					// Is the alternative specified by the user compatible?
-					if existing.ver.Major != alternativeVer.Major {
-						return nil, fmt.Errorf(
-							`%w: %s depends on %s:%s but an incompatible version %s:%s is required by other artifacts`,
-							ErrCannotSatisfyDependencies, name, required.Name, required.Version, required.Name, existing.ver.String(),
-						)
-					}
-
-					if alternativeVer.Compare(*existing.ver) > 0 {
-						if err := upsertMap(alternative.Name + ":" + alternativeVer.String()); err != nil {
-							return nil, err
+				selected[name] = info
+			}
+			for _, required := range info.config.Dependencies {
+				target, version := required.Name, required.Version
+				// An alternative already required satisfies the dependency.
+				if _, ok := constraints[target]; !ok {
+					for _, alternative := range required.Alternatives {
+						if _, ok := constraints[alternative.Name]; ok {
+							target, version = alternative.Name, alternative.Version
+							break
 						}
 					}
-
-					break
-				}
-				if foundAlternative {
-					continue
 				}
-
-				// dep to be added or bumped
-				if err := upsertMap(required.Name + ":" + required.Version); err != nil {
+				c, err := parseConstraint(target, version)
+				if err != nil {
 					return nil, err
 				}
-				allOk = false
+				c.by = name
+				add(target, c)
+				edges[name] = append(edges[name], target)
 			}
+		}
 
-			// dep processed
-			info.ok = true
+		// Select the versions again, now that all the constraints are known.
+		changed := false
+		for name := range selected {
+			if _, ok := constraints[name]; !ok {
+				// No longer required.
+				delete(selected, name)
+				changed = true
+			}
+		}
+		for _, name := range closure {
+			info, err := choose(name, constraints[name])
+			if err != nil {
+				return nil, err
+			}
+			if info.ref != selected[name].ref {
+				selected[name] = info
+				changed = true
+			}
+		}
+		if changed {
+			continue
 		}
 
-		if allOk {
-			for _, info := range depMap {
-				outRefs = append(outRefs, info.ref)
+		// Dependencies are installed before the artifacts depending on them.
+		visited := make(map[string]bool)
+		var visit func(name string)
+		visit = func(name string) {
+			if visited[name] {
+				return
+			}
+			visited[name] = true
+			for _, dep := range edges[name] {
+				visit(dep)
 			}
-			return
+			outRefs = append(outRefs, selected[name].ref)
 		}
+		for _, name := range roots {
+			visit(name)
+		}
+		return outRefs, nil
 	}
+
+	return nil, fmt.Errorf("%w: no stable set of versions found after %d rounds", ErrCannotSatisfyDependencies, maxResolveRounds)
 }
@@ -17,6 +17,7 @@ package install
 
 import (
 	"errors"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
@@ -226,7 +227,7 @@ func TestResolveDeps(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		outRef, err := ResolveDeps(testCase.resolver, testCase.inRef...)
+		outRef, err := ResolveDeps(testCase.resolver, nil, testCase.inRef...)
 		if err != nil && !errors.Is(err, testCase.expectedErr) {
 			t.Fatalf("unexpected error in scenario %q, %q: %v",
 				testCase.scenario, testCase.description, err)
@@ -238,3 +239,115 @@ func TestResolveDeps(t *testing.T) {
 		}
 	}
 }
+
+// configs returns a resolver of the given artifact configs, by "name:version" reference.
+func configs(in ...oci.ArtifactConfig) artifactConfigResolver {
+	return func(ref string) (*oci.RegistryResult, error) {
+		for _, c := range in {
+			if ref == c.Name+":"+c.Version {
+				return &oci.RegistryResult{Config: c}, nil
+			}
+		}
+		return nil, errors.New("not found: " + ref)
+	}
+}
+
+func TestResolveDepsConstraints(t *testing.T) {
+	lister := artifactVersionLister(func(name string) ([]string, error) {
+		switch name {
+		case "dep1":
+			return []string{"latest", "1.2.3", "1.3.0", "1.4.0", "2.0.0"}, nil
+		case "dep2":
+			return []string{"0.1.0", "0.2.0"}, nil
+		default:
+			return nil, errors.New("unknown artifact " + name)
+		}
+	})
+	deps := []oci.ArtifactConfig{
+		{Name: "dep1", Version: "1.2.3"},
+		{Name: "dep1", Version: "1.3.0"},
+		{Name: "dep1", Version: "1.4.0", Dependencies: []oci.ArtifactDependency{{Name: "dep2", Version: ">=0.2.0"}}},
+		{Name: "dep1", Version: "2.0.0"},
+		{Name: "dep2", Version: "0.1.0"},
+		{Name: "dep2", Version: "0.2.0"},
+	}
+
+	testCases := []struct {
+		scenario       string
+		inRef          []string
+		configs        []oci.ArtifactConfig
+		expectedOutRef []string
+		expectedErr    error
+	}{
+		{
+			scenario: "range resolved to the most recent version satisfying it, after its dependencies",
+			inRef:    []string{"ref1:0.1.0"},
+			configs: []oci.ArtifactConfig{
+				{Name: "ref1", Version: "0.1.0", Dependencies: []oci.ArtifactDependency{{Name: "dep1", Version: ">=1.2.0 <2.0.0"}}},
+			},
+			expectedOutRef: []string{"dep2:0.2.0", "dep1:1.4.0", "ref1:0.1.0"},
+		},
+		{
+			scenario: "ranges of several artifacts are intersected",
+			inRef:    []string{"ref1:0.1.0", "ref2:0.1.0"},
+			configs: []oci.ArtifactConfig{
+				{Name: "ref1", Version: "0.1.0", Dependencies: []oci.ArtifactDependency{{Name: "dep1", Version: ">=1.2.0 <2.0.0"}}},
+				{Name: "ref2", Version: "0.1.0", Dependencies: []oci.ArtifactDependency{{Name: "dep1", Version: "<1.4.0"}}},
+			},
+			expectedOutRef: []string{"dep1:1.3.0", "ref1:0.1.0", "ref2:0.1.0"},
+		},
+		{
+			scenario: "conflicting ranges",
+			inRef:    []string{"ref1:0.1.0", "ref2:0.1.0"},
+			configs: []oci.ArtifactConfig{
+				{Name: "ref1", Version: "0.1.0", Dependencies: []oci.ArtifactDependency{{Name: "dep1", Version: ">=1.4.0"}}},
+				{Name: "ref2", Version: "0.1.0", Dependencies: []oci.ArtifactDependency{{Name: "dep1", Version: "<1.3.0"}}},
+			},
+			expectedErr: ErrCannotSatisfyDependencies,
+		},
+		{
+			scenario: "range conflicting with the version requested",
+			inRef:    []string{"ref1:0.1.0", "dep1:2.0.0"},
+			configs: []oci.ArtifactConfig{
+				{Name: "ref1", Version: "0.1.0", Dependencies: []oci.ArtifactDependency{{Name: "dep1", Version: "1.x"}}},
+			},
+			expectedErr: ErrCannotSatisfyDependencies,
+		},
+		{
+			scenario: "range satisfied by an alternative",
+			inRef:    []string{"ref1:0.1.0", "dep2:0.1.0"},
+			configs: []oci.ArtifactConfig{
+				{Name: "ref1", Version: "0.1.0", Dependencies: []oci.ArtifactDependency{{
+					Name:         "dep1",
+					Version:      ">=1.0.0",
+					Alternatives: []oci.Dependency{{Name: "dep2", Version: ">=0.1.0 <0.2.0"}},
+				}}},
+			},
+			expectedOutRef: []string{"dep2:0.1.0", "ref1:0.1.0"},
+		},
+		{
+			scenario: "invalid range",
+			inRef:    []string{"ref1:0.1.0"},
+			configs: []oci.ArtifactConfig{
+				{Name: "ref1", Version: "0.1.0", Dependencies: []oci.ArtifactDependency{{Name: "dep1", Version: "~>1"}}},
+			},
+			expectedErr: errors.New("not semver compatible"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		outRef, err := ResolveDeps(configs(append(testCase.configs, deps...)...), lister, testCase.inRef...)
+		switch {
+		case testCase.expectedErr == nil && err != nil:
+			t.Fatalf("unexpected error in scenario %q: %v", testCase.scenario, err)
+		case testCase.expectedErr != nil && err == nil:
+			t.Fatalf("expected error in scenario %q, got refs %v", testCase.scenario, outRef)
+		case testCase.expectedErr != nil && !errors.Is(err, testCase.expectedErr) && !strings.Contains(err.Error(), testCase.expectedErr.Error()):
+			t.Fatalf("unexpected error in scenario %q: %v", testCase.scenario, err)
+		}
+		if !slices.Equal(outRef, testCase.expectedOutRef) {
+			t.Fatalf("dependencies not correctly resolved in scenario %q:\n got %v, expected %v",
+				testCase.scenario, outRef, testCase.expectedOutRef)
+		}
+	}
+}
@@ -21,16 +21,24 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/installer"
+	"github.com/falcosecurity/falcoctl/internal/lockfile"
 	"github.com/falcosecurity/falcoctl/internal/signature"
 	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/enum"
 	"github.com/falcosecurity/falcoctl/pkg/index/index"
 	"github.com/falcosecurity/falcoctl/pkg/oci"
+	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
+	"github.com/falcosecurity/falcoctl/pkg/oci/repository"
 	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 )
@@ -74,20 +82,30 @@ type artifactInstallOptions struct {
 	*options.Common
 	*options.Registry
 	*options.Directory
-	allowedTypes oci.ArtifactTypeSlice
-	platform     string // Raw string from command line
-	platformArch string // Architecture portion of parsed platform string
-	platformOS   string // OS portion of parsed platform string
-	resolveDeps  bool
-	noVerify     bool
+	allowedTypes  oci.ArtifactTypeSlice
+	platform      string // Raw string from command line
+	platformArch  string // Architecture portion of parsed platform string
+	platformOS    string // OS portion of parsed platform string
+	resolveDeps   bool
+	noVerify      bool
+	verify        bool
+	verifyMode    *enum.Enum
+	mergeStrategy *enum.Enum
+	maxParallel   int
+	writeLock     bool
+	locked        bool
+	lockFile      string
+	dryRun        bool
 }
 
 // NewArtifactInstallCmd returns the artifact install command.
 func NewArtifactInstallCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	o := artifactInstallOptions{
-		Common:    opt,
-		Registry:  &options.Registry{},
-		Directory: &options.Directory{},
+		Common:        opt,
+		Registry:      &options.Registry{},
+		Directory:     &options.Directory{},
+		verifyMode:    enum.NewEnum(signature.Modes, string(signature.ModeEnforce)),
+		mergeStrategy: enum.NewEnum(installer.MergeStrategies, string(installer.MergeFail)),
 	}
 
 	cmd := &cobra.Command{
@@ -169,6 +187,73 @@ func NewArtifactInstallCmd(ctx context.Context, opt *options.Common) *cobra.Comm
 				}
 			}
 
+			f = cmd.Flags().Lookup(FlagVerify)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", FlagVerify)
+			} else if !f.Changed && viper.IsSet(config.ArtifactVerifyEnabledKey) {
+				val := viper.Get(config.ArtifactVerifyEnabledKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagVerify, err)
+				}
+			}
+
+			f = cmd.Flags().Lookup(FlagVerifyMode)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", FlagVerifyMode)
+			} else if !f.Changed && viper.IsSet(config.ArtifactVerifyModeKey) {
+				val := viper.Get(config.ArtifactVerifyModeKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagVerifyMode, err)
+				}
+			}
+
+			if o.verify && o.noVerify {
+				return fmt.Errorf("%q and %q cannot be used together", FlagVerify, FlagNoVerify)
+			}
+
+			f = cmd.Flags().Lookup(FlagMergeStrategy)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", FlagMergeStrategy)
+			} else if !f.Changed && viper.IsSet(config.ArtifactInstallMergeStrategyKey) {
+				val := viper.Get(config.ArtifactInstallMergeStrategyKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagMergeStrategy, err)
+				}
+			}
+
+			f = cmd.Flags().Lookup(FlagMaxParallel)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", FlagMaxParallel)
+			} else if !f.Changed && viper.IsSet(config.ArtifactInstallMaxParallelKey) {
+				val := viper.Get(config.ArtifactInstallMaxParallelKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagMaxParallel, err)
+				}
+			}
+			if o.maxParallel < 1 {
+				return fmt.Errorf("invalid %q %d: at least one artifact must be pulled at a time", FlagMaxParallel, o.maxParallel)
+			}
+
+			for flag, key := range map[string]string{FlagLocked: config.ArtifactInstallLockedKey, FlagLockFile: config.ArtifactInstallLockFileKey} {
+				f = cmd.Flags().Lookup(flag)
+				if f == nil {
+					// should never happen
+					return fmt.Errorf("unable to retrieve flag %q", flag)
+				} else if !f.Changed && viper.IsSet(key) {
+					val := viper.Get(key)
+					if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+						return fmt.Errorf("unable to overwrite %q flag: %w", flag, err)
+					}
+				}
+			}
+			if o.writeLock && o.locked {
+				return fmt.Errorf("%q and %q cannot be used together", FlagWriteLock, FlagLocked)
+			}
+
 			// Parse "platform" into OS and Arch
 			if len(o.platform) > 0 {
 				parts := strings.Split(o.platform, "/")
@@ -199,6 +284,24 @@ Examples:
 		"whether this command should resolve dependencies or not")
 	cmd.Flags().BoolVar(&o.noVerify, FlagNoVerify, false,
 		"whether this command should skip signature verification")
+	cmd.Flags().BoolVar(&o.verify, FlagVerify, false,
+		"require a valid cosign signature for every artifact, signed by the signer configured in artifact.verify.cosign "+
+			"or, if not configured, by the one of its index")
+	cmd.Flags().Var(o.verifyMode, FlagVerifyMode,
+		"what to do when "+FlagVerify+" is set and a signature is missing or does not match: fail the install or only warn "+o.verifyMode.Allowed())
+	cmd.Flags().Var(o.mergeStrategy, FlagMergeStrategy,
+		"what to do when a file is already installed by another artifact: abort, overwrite it, suffix it with the artifact name "+
+			"or install the artifact in a subdirectory named after it "+o.mergeStrategy.Allowed())
+	cmd.Flags().IntVar(&o.maxParallel, FlagMaxParallel, 1,
+		"how many artifacts, and layers of each artifact, are pulled in parallel. They are then installed one at a time, in order")
+	cmd.Flags().BoolVar(&o.writeLock, FlagWriteLock, false,
+		"record in the lock file the digests of the installed artifacts, dependencies included")
+	cmd.Flags().BoolVar(&o.locked, FlagLocked, false,
+		"install the digests recorded in the lock file, which must have been written for the same artifacts")
+	cmd.Flags().StringVar(&o.lockFile, FlagLockFile, lockfile.DefaultFile, "path of the lock file")
+	cmd.Flags().BoolVar(&o.dryRun, FlagDryRun, false,
+		"resolve, pull and verify the artifacts, then only print the files that would be written, without installing them")
+	cmd.MarkFlagsMutuallyExclusive(FlagDryRun, FlagWriteLock)
 
 	return cmd
 }
@@ -220,6 +323,46 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 		args = configuredInstaller.Artifacts
 	}
 
+	// The lock file is checked against the artifacts as requested, before resolving them.
+	requested := slices.Clone(args)
+	var lock *lockfile.Lock
+	if o.locked {
+		if lock, err = lockfile.Load(o.lockFile); err != nil {
+			return err
+		}
+		if err := lock.Check(requested); err != nil {
+			return fmt.Errorf("%w, run with --%s to update it", err, FlagWriteLock)
+		}
+	}
+
+	var policy *signature.Policy
+	if o.verify {
+		if policy, err = signature.ConfiguredPolicy(o.verifyMode.String()); err != nil {
+			return err
+		}
+	}
+
+	keep, err := config.ArtifactHistoryKeep()
+	if err != nil {
+		return err
+	}
+
+	// Complete or roll back a previous install that did not finish.
+	inst := installer.New(config.InstallManifestFile, config.InstallJournalFile,
+		installer.WithMergeStrategy(installer.MergeStrategy(o.mergeStrategy.String())),
+		installer.WithHistory(installer.NewHistory(config.ArtifactHistoryDir, keep)))
+	if !o.dryRun {
+		recovered, err := inst.Recover()
+		if err != nil {
+			return fmt.Errorf("unable to recover interrupted install: %w", err)
+		}
+		if recovered != nil && recovered.RolledBack {
+			logger.Warn("Rolled back interrupted install", logger.Args("ref", recovered.Artifact.Ref, "directory", recovered.Artifact.Directory))
+		} else if recovered != nil {
+			logger.Warn("Completed interrupted install", logger.Args("ref", recovered.Artifact.Ref, "directory", recovered.Artifact.Directory))
+		}
+	}
+
 	// Create temp dir where to put pulled artifacts
 	tmpDir, err := os.MkdirTemp("", "falcoctl")
 	if err != nil {
@@ -228,7 +371,7 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 	defer os.RemoveAll(tmpDir)
 
 	// Create registry puller with auto login enabled
-	puller, err := ociutils.Puller(o.PlainHTTP, o.Printer)
+	puller, err := o.puller()
 	if err != nil {
 		return err
 	}
@@ -250,6 +393,23 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 		}, nil
 	})
 
+	// Specify how to list the versions available for the dependencies required with a range.
+	lister := artifactVersionLister(func(name string) ([]string, error) {
+		ref, err := o.IndexCache.ResolveReference(name)
+		if err != nil {
+			return nil, err
+		}
+		repoRef, err := utils.RepositoryFromRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		repo, err := repository.NewRepository(repoRef, repository.WithClient(puller.Client), repository.WithPlainHTTP(o.PlainHTTP))
+		if err != nil {
+			return nil, err
+		}
+		return repo.Tags(ctx)
+	})
+
 	signatures := make(map[string]*index.Signature)
 
 	// Compute input to install dependencies
@@ -265,20 +425,27 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 	}
 
 	var refs []string
-	if o.resolveDeps {
+	switch {
+	case lock != nil:
+		// The dependencies have been resolved when writing the lock file.
+		for _, a := range lock.Artifacts {
+			refs = append(refs, a.Ref)
+		}
+	case o.resolveDeps:
 		// Solve dependencies
 		logger.Info("Resolving dependencies ...")
-		refs, err = ResolveDeps(resolver, args...)
+		refs, err = ResolveDeps(resolver, lister, args...)
 		if err != nil {
 			return err
 		}
-	} else {
+	default:
 		refs = args
 	}
 
 	logger.Info("Installing artifacts", logger.Args("refs", refs))
 
-	for _, ref := range refs {
+	pulls := make([]*pulledArtifact, len(refs))
+	for i, ref := range refs {
 		resolvedRef, err := o.IndexCache.ResolveReference(ref)
 		if err != nil {
 			return err
@@ -289,39 +456,44 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 				signatures[resolvedRef] = sig
 			}
 		}
-
-		logger.Info("Preparing to pull artifact", logger.Args("ref", resolvedRef))
-
-		if err := puller.CheckAllowedType(ctx, resolvedRef, o.platformOS, o.platformArch, o.allowedTypes.Types); err != nil {
-			return err
-		}
-
-		// Install will always install artifact for the current OS and architecture
-		result, err := puller.Pull(ctx, resolvedRef, tmpDir, o.platformOS, o.platformArch)
-		if err != nil {
-			return err
-		}
-
-		sig := signatures[resolvedRef]
-
-		if sig != nil && !o.noVerify {
-			repo, err := utils.RepositoryFromRef(resolvedRef)
-			if err != nil {
+		pullRef := resolvedRef
+		if lock != nil {
+			if pullRef, err = lock.Artifacts[i].PinnedRef(); err != nil {
 				return err
 			}
+		}
+		// Each artifact gets its own directory, since several of them can ship a file with the same name.
+		pulls[i] = &pulledArtifact{
+			ref:      pullRef,
+			resolved: resolvedRef,
+			dir:      filepath.Join(tmpDir, strconv.Itoa(i)),
+			sig:      signatures[resolvedRef],
+		}
+	}
 
-			// In order to prevent TOCTOU issues we'll perform signature verification after we complete a pull
-			// and obtained a digest but before files are written to disk. This way we ensure that we're verifying
-			// the exact digest that we just pulled, even if the tag gets overwritten in the meantime.
-			digestRef := fmt.Sprintf("%s@%s", repo, result.RootDigest)
+	// Pull and verify the artifacts in parallel, then install them in order.
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(o.maxParallel)
+	for _, p := range pulls {
+		g.Go(func() error {
+			return o.pull(gctx, puller, policy, p)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
 
-			logger.Info("Verifying signature for artifact", logger.Args("digest", digestRef))
-			err = signature.Verify(ctx, digestRef, sig)
-			if err != nil {
-				return fmt.Errorf("error while verifying signature for %s: %w", digestRef, err)
+	// All the artifacts are installed in a single transaction, so that dependencies are never left half installed.
+	pending := make([]installer.Pending, len(pulls))
+	defer func() {
+		for _, p := range pending {
+			if f, ok := p.Tarball.(*os.File); ok {
+				_ = f.Close()
 			}
-			logger.Info("Signature successfully verified!")
 		}
+	}()
+	for i, p := range pulls {
+		resolvedRef, result := p.ref, p.result
 
 		var destDir string
 		switch result.Type {
@@ -341,34 +513,161 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 			return fmt.Errorf("cannot use directory %q as install destination: %w", destDir, err)
 		}
 
-		logger.Info("Extracting and installing artifact", logger.Args("type", result.Type, "file", result.Filename))
+		repo, err := utils.RepositoryFromRef(resolvedRef)
+		if err != nil {
+			return err
+		}
 
-		if !o.Printer.DisableStyling {
-			o.Printer.Spinner, _ = o.Printer.Spinner.Start("Extracting and installing")
+		f, err := os.Open(filepath.Join(p.dir, result.Filename))
+		if err != nil {
+			return err
 		}
 
-		result.Filename = filepath.Join(tmpDir, result.Filename)
+		if !o.dryRun {
+			logger.Info("Extracting and installing artifact", logger.Args("type", result.Type, "file", result.Filename))
+		}
+		pending[i] = installer.Pending{
+			Artifact: installer.Artifact{
+				Repository: repo,
+				Ref:        resolvedRef,
+				Digest:     result.Digest,
+				Type:       result.Type.String(),
+				Version:    result.Config.Version,
+				Directory:  destDir,
+			},
+			Tarball: f,
+		}
+	}
 
-		f, err := os.Open(result.Filename)
-		if err != nil {
+	if o.dryRun {
+		return o.printPlan(ctx, inst, pending)
+	}
+
+	if !o.Printer.DisableStyling {
+		o.Printer.Spinner, _ = o.Printer.Spinner.Start("Extracting and installing")
+	}
+	// Extract the artifacts and swap them into their destination directory
+	installed, err := inst.InstallAll(ctx, pending)
+	if o.Printer.Spinner != nil {
+		_ = o.Printer.Spinner.Stop()
+	}
+	if err != nil {
+		return fmt.Errorf("cannot install artifacts: %w", err)
+	}
+	for _, a := range installed {
+		logger.Info("Artifact successfully installed", logger.Args("name", a.Ref, "type", a.Type, "digest", a.Digest, "directory", a.Directory))
+	}
+
+	if o.writeLock {
+		lock := lockfile.New(requested)
+		for _, p := range pulls {
+			lock.Add(p.resolved, p.result.RootDigest, p.result.Type.String())
+		}
+		if err := lock.Write(o.lockFile); err != nil {
 			return err
 		}
-		// Extract artifact and move it to its destination directory
-		_, err = utils.ExtractTarGz(ctx, f, destDir, 0)
-		if err != nil {
-			return fmt.Errorf("cannot extract %q to %q: %w", result.Filename, destDir, err)
+		logger.Info("Lock file written", logger.Args("path", o.lockFile, "artifacts", len(lock.Artifacts)))
+	}
+
+	return nil
+}
+
+// printPlan prints the files that installing the pending artifacts would write.
+func (o *artifactInstallOptions) printPlan(ctx context.Context, inst *installer.Installer, pending []installer.Pending) error {
+	logger := o.Printer.Logger
+	plans, err := inst.Plan(ctx, pending)
+	if err != nil {
+		return fmt.Errorf("cannot install artifacts: %w", err)
+	}
+	for _, plan := range plans {
+		a := plan.Artifact
+		logger.Info("Would install artifact", logger.Args("name", a.Ref, "type", a.Type, "digest", a.Digest, "directory", a.Directory))
+		for _, f := range plan.Files {
+			args := []any{"path", f.Path}
+			if f.Owner != "" {
+				args = append(args, "owner", f.Owner)
+			}
+			switch f.Action {
+			case installer.ActionCreate:
+				logger.Info("Would create file", logger.Args(args...))
+			case installer.ActionOverwrite:
+				logger.Info("Would overwrite file", logger.Args(args...))
+			case installer.ActionUnchanged:
+				logger.Info("File already up to date", logger.Args(args...))
+			}
 		}
+	}
+	return nil
+}
+
+// pulledArtifact is an artifact being pulled to dir, before being installed.
+type pulledArtifact struct {
+	// ref is the pulled reference: the one resolved, or its locked digest when installing from the lock file.
+	ref      string
+	resolved string
+	dir      string
+	sig      *index.Signature
+	result   *oci.RegistryResult
+}
+
+// puller returns the registry puller. When pulling in parallel, the progress bars are disabled, since they cannot be shared.
+func (o *artifactInstallOptions) puller() (*ocipuller.Puller, error) {
+	if o.maxParallel <= 1 {
+		return ociutils.Puller(o.PlainHTTP, o.Printer)
+	}
+	client, err := ociutils.Client(true)
+	if err != nil {
+		return nil, err
+	}
+	return ocipuller.NewPuller(client, o.PlainHTTP, nil, ocipuller.WithConcurrency(o.maxParallel)), nil
+}
 
-		err = os.Remove(result.Filename)
+// pull pulls p and verifies its signature, if needed.
+func (o *artifactInstallOptions) pull(ctx context.Context, puller *ocipuller.Puller, policy *signature.Policy, p *pulledArtifact) error {
+	logger := o.Printer.Logger
+	logger.Info("Preparing to pull artifact", logger.Args("ref", p.ref))
+
+	if err := puller.CheckAllowedType(ctx, p.ref, o.platformOS, o.platformArch, o.allowedTypes.Types); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(p.dir, 0o700); err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+
+	// Install will always install artifact for the current OS and architecture
+	result, err := puller.Pull(ctx, p.ref, p.dir, o.platformOS, o.platformArch)
+	if err != nil {
+		return err
+	}
+
+	if policy != nil || (p.sig != nil && !o.noVerify) {
+		repo, err := utils.RepositoryFromRef(p.ref)
 		if err != nil {
 			return err
 		}
 
-		if o.Printer.Spinner != nil {
-			_ = o.Printer.Spinner.Stop()
+		// In order to prevent TOCTOU issues we'll perform signature verification after we complete a pull
+		// and obtained a digest but before files are written to disk. This way we ensure that we're verifying
+		// the exact digest that we just pulled, even if the tag gets overwritten in the meantime.
+		digestRef := fmt.Sprintf("%s@%s", repo, result.RootDigest)
+
+		logger.Info("Verifying signature for artifact", logger.Args("digest", digestRef))
+		if policy != nil {
+			err = policy.Verify(ctx, digestRef, p.sig)
+		} else {
+			err = signature.Verify(ctx, digestRef, p.sig)
+		}
+		switch {
+		case err == nil:
+			logger.Info("Signature successfully verified!")
+		case policy != nil && policy.Mode == signature.ModeWarn:
+			logger.Warn("Installing artifact without a valid signature", logger.Args("digest", digestRef, "reason", err.Error()))
+		default:
+			return fmt.Errorf("error while verifying signature for %s: %w", digestRef, err)
 		}
-		logger.Info("Artifact successfully installed", logger.Args("name", resolvedRef, "type", result.Type, "digest", result.Digest, "directory", destDir))
 	}
 
+	p.result = result
 	return nil
 }
@@ -51,9 +51,11 @@ Flags:
       --rulesfiles-dir string             directory where to install rules. (default "/etc/falco")
 
 Global Flags:
-      --config string     config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string              config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string          Set formatting for logs (color, text, json) (default "color")
+      --log-level string           Set level for logs (info, warn, debug, trace) (default "info")
+      --registry-rewrite strings   Rewrite rules, in the "<from>=><to>" format, applied in order to the references resolved from the indexes (first match wins)
+      --socks5-proxy string        SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 
 `
 
@@ -18,9 +18,14 @@ package list
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/installer"
 	"github.com/falcosecurity/falcoctl/pkg/oci"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 	"github.com/falcosecurity/falcoctl/pkg/output"
@@ -31,22 +36,41 @@ const CommandName = "list"
 
 type artifactListOptions struct {
 	*options.Common
+	*options.Output
 	artifactType oci.ArtifactType
 	index        string
+	installed    bool
+}
+
+// installedArtifact is an artifact recorded in the install manifest, as printed by --installed.
+type installedArtifact struct {
+	Name        string    `json:"name" yaml:"name"`
+	Repository  string    `json:"repository" yaml:"repository"`
+	Ref         string    `json:"ref" yaml:"ref"`
+	Type        string    `json:"type" yaml:"type"`
+	Version     string    `json:"version,omitempty" yaml:"version,omitempty"`
+	Digest      string    `json:"digest" yaml:"digest"`
+	Directory   string    `json:"directory" yaml:"directory"`
+	Files       []string  `json:"files" yaml:"files"`
+	InstalledAt time.Time `json:"installedAt" yaml:"installedAt"`
 }
 
 // NewArtifactListCmd returns the artifact search command.
 func NewArtifactListCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	o := artifactListOptions{
 		Common: opt,
+		Output: &options.Output{},
 	}
 
 	cmd := &cobra.Command{
 		Use:                   fmt.Sprintf("%s [flags]", CommandName),
 		DisableFlagsInUseLine: true,
 		Short:                 "List all artifacts",
-		Long:                  "List all artifacts",
+		Long:                  "List all artifacts of the configured indexes or, with --installed, the artifacts installed locally",
 		Aliases:               []string{"ls"},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Output.Validate()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.RunArtifactList(ctx, args)
 		},
@@ -54,11 +78,19 @@ func NewArtifactListCmd(ctx context.Context, opt *options.Common) *cobra.Command
 
 	cmd.Flags().Var(&o.artifactType, "type", `Only list artifacts with a specific type. Allowed values: "rulesfile", "plugin", "asset"`)
 	cmd.Flags().StringVar(&o.index, "index", "", "Only display artifacts from a configured index")
+	cmd.Flags().BoolVar(&o.installed, "installed", false,
+		"List the artifacts installed by artifact install and artifact follow, as recorded in the install manifest")
+	o.Output.AddFlags(cmd)
+	cmd.MarkFlagsMutuallyExclusive("installed", "index")
 
 	return cmd
 }
 
 func (o *artifactListOptions) RunArtifactList(_ context.Context, _ []string) error {
+	if o.installed {
+		return o.listInstalled()
+	}
+
 	var data [][]string
 	for _, entry := range o.IndexCache.MergedIndexes.Entries {
 		if o.artifactType != "" && o.artifactType != oci.ArtifactType(entry.Type) {
@@ -74,5 +106,63 @@ func (o *artifactListOptions) RunArtifactList(_ context.Context, _ []string) err
 		data = append(data, row)
 	}
 
+	if o.Structured() {
+		entries := make([]map[string]string, len(data))
+		for i, row := range data {
+			entries[i] = map[string]string{"index": row[0], "name": row[1], "type": row[2], "registry": row[3], "repository": row[4]}
+		}
+		return o.Printer.PrintStructured(o.Format, entries)
+	}
 	return o.Printer.PrintTable(output.ArtifactSearch, data)
 }
+
+// listInstalled lists the artifacts recorded in the install manifest.
+func (o *artifactListOptions) listInstalled() error {
+	m, err := installer.LoadManifest(config.InstallManifestFile)
+	if err != nil {
+		return err
+	}
+
+	artifacts := []installedArtifact{}
+	for _, a := range m.Artifacts {
+		if o.artifactType != "" && o.artifactType != oci.ArtifactType(a.Type) {
+			continue
+		}
+		files := make([]string, len(a.Files))
+		for i, f := range a.Files {
+			files[i] = f.Path
+		}
+		artifacts = append(artifacts, installedArtifact{
+			Name:        path.Base(a.Repository),
+			Repository:  a.Repository,
+			Ref:         a.Ref,
+			Type:        a.Type,
+			Version:     a.Version,
+			Digest:      a.Digest,
+			Directory:   a.Directory,
+			Files:       files,
+			InstalledAt: a.InstalledAt,
+		})
+	}
+
+	if o.Structured() {
+		return o.Printer.PrintStructured(o.Format, artifacts)
+	}
+	data := make([][]string, 0, len(artifacts))
+	for _, a := range artifacts {
+		data = append(data, []string{a.Name, a.Type, a.Version, shortDigest(a.Digest), a.Directory, a.Ref, a.InstalledAt.Local().Format(time.DateTime)})
+	}
+	return o.Printer.PrintTable(output.ArtifactInstalled, data)
+}
+
+// shortDigest returns the first 12 characters of the hex part of a digest.
+func shortDigest(digest string) string {
+	_, hex, found := strings.Cut(digest, ":")
+	if !found {
+		hex = digest
+	}
+	if len(hex) > 12 {
+		hex = hex[:12]
+	}
+	return hex
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/falcosecurity/falcoctl/cmd"
 )
 
+//nolint:lll // no need to check for line length.
 var usage = `Usage:
   falcoctl artifact manifest [ref] [flags]
 
@@ -35,11 +36,14 @@ Flags:
       --platform string   os and architecture of the artifact in OS/ARCH format (default "linux/amd64")
 
 Global Flags:
-      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string              config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string          Set formatting for logs (color, text, json) (default "color")
+      --log-level string           Set level for logs (info, warn, debug, trace) (default "info")
+      --registry-rewrite strings   Rewrite rules, in the "<from>=><to>" format, applied in order to the references resolved from the indexes (first match wins)
+      --socks5-proxy string        SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
+//nolint:lll // no need to check for line length.
 var help = `Get the manifest layer of an artifact
 
 Usage:
@@ -51,9 +55,11 @@ Flags:
       --platform string   os and architecture of the artifact in OS/ARCH format (default "linux/amd64")
 
 Global Flags:
-      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string              config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string          Set formatting for logs (color, text, json) (default "color")
+      --log-level string           Set level for logs (info, warn, debug, trace) (default "info")
+      --registry-rewrite strings   Rewrite rules, in the "<from>=><to>" format, applied in order to the references resolved from the indexes (first match wins)
+      --socks5-proxy string        SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
 var _ = Describe("Manifest", func() {
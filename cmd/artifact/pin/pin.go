@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	longPin = `This command pins the artifacts configured in the artifact.install.refs and artifact.follow.refs
+lists of the config file to the digests their tags currently resolve to, e.g. "falco-rules:3" is rewritten
+to "falco-rules@sha256:...". The previous reference is kept as a comment of the entry.
+
+Either pass the configured references to pin, or --all to pin all of them. Already pinned references are skipped.
+
+Example - Preview the pinning of all the configured artifacts:
+	falcoctl artifact pin --all --dry-run
+
+Example - Pin a single configured artifact:
+	falcoctl artifact pin falco-rules:3
+`
+)
+
+type artifactPinOptions struct {
+	*options.Common
+	*options.Registry
+	all    bool
+	dryRun bool
+}
+
+// NewArtifactPinCmd returns the artifact pin command.
+func NewArtifactPinCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := artifactPinOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "pin [ref1 [ref2 ...]] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Pin the configured artifacts to the digests their tags resolve to",
+		Long:                  longPin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactPin(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.all, "all", false, "Pin all the configured artifacts")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "Only print the pinned references, without rewriting the config file")
+
+	return cmd
+}
+
+// RunArtifactPin executes the business logic for the artifact pin command.
+func (o *artifactPinOptions) RunArtifactPin(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+
+	switch {
+	case o.all && len(args) > 0:
+		return errors.New("--all cannot be used along with references")
+	case !o.all && len(args) == 0:
+		return errors.New("either pass the references to pin or --all")
+	}
+
+	configured, err := configuredRefs()
+	if err != nil {
+		return err
+	}
+
+	refs := configured
+	if !o.all {
+		for _, arg := range args {
+			if !slices.Contains(configured, arg) {
+				return fmt.Errorf("%q is not among the configured artifacts", arg)
+			}
+		}
+		refs = args
+	}
+
+	puller, err := ociutils.Puller(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return err
+	}
+
+	pins := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		if strings.Contains(ref, "@") {
+			logger.Info("Artifact already pinned", logger.Args("ref", ref))
+			continue
+		}
+
+		resolvedRef, err := o.IndexCache.ResolveReference(ref)
+		if err != nil {
+			return err
+		}
+
+		desc, err := puller.Descriptor(ctx, resolvedRef)
+		if err != nil {
+			return fmt.Errorf("unable to resolve %q: %w", resolvedRef, err)
+		}
+
+		pins[ref] = pinnedRef(ref, desc.Digest.String())
+		if o.dryRun {
+			logger.Info("Would pin artifact", logger.Args("ref", ref, "pinned", pins[ref]))
+		}
+	}
+
+	if o.dryRun || len(pins) == 0 {
+		return nil
+	}
+
+	pinned, err := config.PinArtifactRefs(o.ConfigFile, pins)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if to, ok := pins[ref]; ok {
+			logger.Info("Pinned artifact", logger.Args("ref", ref, "pinned", to))
+		}
+	}
+	if pinned == 0 {
+		logger.Warn("No entry rewritten, the artifacts may be configured through environment variables", logger.Args("config", o.ConfigFile))
+	}
+
+	return nil
+}
+
+// configuredRefs returns the references configured for the installer and the follower, without duplicates.
+func configuredRefs() ([]string, error) {
+	install, err := config.Installer()
+	if err != nil {
+		return nil, err
+	}
+	follow, err := config.Follower()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []string
+	for _, ref := range append(install.Artifacts, follow.Artifacts...) {
+		if ref != "" && !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// pinnedRef replaces the tag of ref, if any, with the given digest.
+func pinnedRef(ref, digest string) string {
+	if parsed, err := registry.ParseReference(ref); err == nil {
+		return fmt.Sprintf("%s/%s@%s", parsed.Registry, parsed.Repository, digest)
+	}
+
+	// Name of an artifact from the indexes, optionally followed by a tag.
+	name, _, _ := strings.Cut(ref, ":")
+	return name + "@" + digest
+}
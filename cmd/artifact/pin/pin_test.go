@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pin_test
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+)
+
+//nolint:lll // no need to check for line length.
+var artifactPinHelp = `This command pins the artifacts configured in the artifact.install.refs and artifact.follow.refs
+lists of the config file to the digests their tags currently resolve to, e.g. "falco-rules:3" is rewritten
+to "falco-rules@sha256:...". The previous reference is kept as a comment of the entry.
+
+Either pass the configured references to pin, or --all to pin all of them. Already pinned references are skipped.
+
+Example - Preview the pinning of all the configured artifacts:
+	falcoctl artifact pin --all --dry-run
+
+Example - Pin a single configured artifact:
+	falcoctl artifact pin falco-rules:3
+
+Usage:
+  falcoctl artifact pin [ref1 [ref2 ...]] [flags]
+
+Flags:
+      --all          Pin all the configured artifacts
+      --dry-run      Only print the pinned references, without rewriting the config file
+  -h, --help         help for pin
+      --plain-http   allows interacting with remote registry via plain http requests
+
+Global Flags:
+      --config string              config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string          Set formatting for logs (color, text, json) (default "color")
+      --log-level string           Set level for logs (info, warn, debug, trace) (default "info")
+      --registry-rewrite strings   Rewrite rules, in the "<from>=><to>" format, applied in order to the references resolved from the indexes (first match wins)
+      --socks5-proxy string        SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
+`
+
+// writeConfig writes a config file with the local index and the given ref configured for both the installer and the follower.
+func writeConfig(ref string) {
+	content := fmt.Sprintf(`indexes:
+- name: local
+  url: %s/index.yaml
+artifact:
+  install:
+    refs:
+    - %s
+  follow:
+    refs:
+    - %s
+`, indexServer.URL, ref, ref)
+	Expect(os.WriteFile(configFile, []byte(content), 0o600)).Should(Succeed())
+}
+
+var _ = Describe("pin", func() {
+
+	var (
+		artifactCmd = "artifact"
+		pinCmd      = "pin"
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("help message", func() {
+		BeforeEach(func() {
+			args = []string{artifactCmd, pinCmd, "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(artifactPinHelp)))
+		})
+	})
+
+	Context("pin", func() {
+		When("with --all", func() {
+			BeforeEach(func() {
+				writeConfig(rulesRef)
+				args = []string{artifactCmd, pinCmd, "--all", "--plain-http", "--config", configFile}
+			})
+
+			It("should rewrite the configured refs to the resolved digest", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Pinned artifact"))
+				content, err := os.ReadFile(configFile)
+				Expect(err).ShouldNot(HaveOccurred())
+				pinned := fmt.Sprintf("- %s/rules@%s # %s\n", registry, rulesDigest, rulesRef)
+				Expect(regexp.MustCompile(regexp.QuoteMeta(pinned)).FindAllString(string(content), -1)).Should(HaveLen(2))
+			})
+		})
+
+		When("with a reference", func() {
+			BeforeEach(func() {
+				writeConfig(rulesRef)
+				args = []string{artifactCmd, pinCmd, rulesRef, "--plain-http", "--config", configFile}
+			})
+
+			It("should rewrite the given ref", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				content, err := os.ReadFile(configFile)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(string(content)).Should(ContainSubstring(fmt.Sprintf("%s/rules@%s", registry, rulesDigest)))
+			})
+		})
+
+		When("with --dry-run", func() {
+			BeforeEach(func() {
+				writeConfig(rulesRef)
+				args = []string{artifactCmd, pinCmd, "--all", "--dry-run", "--plain-http", "--config", configFile}
+			})
+
+			It("should not rewrite the config file", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Would pin artifact"))
+				content, err := os.ReadFile(configFile)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(string(content)).ShouldNot(ContainSubstring(rulesDigest))
+			})
+		})
+	})
+
+	Context("failure", func() {
+		When("without references and --all", func() {
+			BeforeEach(func() {
+				writeConfig(rulesRef)
+				args = []string{artifactCmd, pinCmd, "--config", configFile}
+			})
+
+			It("check that fails", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta("ERROR either pass the references to pin or --all")))
+			})
+		})
+
+		When("with a reference that is not configured", func() {
+			BeforeEach(func() {
+				writeConfig(rulesRef)
+				args = []string{artifactCmd, pinCmd, "other:1.0.0", "--config", configFile}
+			})
+
+			It("check that fails", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta(`ERROR "other:1.0.0" is not among the configured artifacts`)))
+			})
+		})
+	})
+})
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollback
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/installer"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	longRollback = `This command restores the previous version of an artifact installed by "artifact install" or
+updated by "artifact follow".
+
+Each time an artifact is replaced by a new version, the files of the replaced one are kept under
+~/.config/falcoctl/history. The number of versions kept per artifact is set by artifact.history.keep
+in the config file (default 3, 0 disables it). The artifact is identified by its repository or by the
+last component of it. Rolling back again restores the version before the restored one.
+
+Example - List the versions kept for the falco-rules artifact:
+	falcoctl artifact rollback falco-rules --list
+
+Example - Restore the previous version of the falco-rules artifact:
+	falcoctl artifact rollback falco-rules
+`
+)
+
+type artifactRollbackOptions struct {
+	*options.Common
+	list bool
+}
+
+// NewArtifactRollbackCmd returns the artifact rollback command.
+func NewArtifactRollbackCmd(_ context.Context, opt *options.Common) *cobra.Command {
+	o := artifactRollbackOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "rollback name [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Restore the previous version of an artifact",
+		Long:                  longRollback,
+		Args:                  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactRollback(args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.list, "list", false, "Only list the versions kept for the artifact, the most recent first")
+
+	return cmd
+}
+
+// RunArtifactRollback executes the business logic for the artifact rollback command.
+func (o *artifactRollbackOptions) RunArtifactRollback(name string) error {
+	logger := o.Printer.Logger
+
+	keep, err := config.ArtifactHistoryKeep()
+	if err != nil {
+		return err
+	}
+	history := installer.NewHistory(config.ArtifactHistoryDir, keep)
+
+	if o.list {
+		repository, err := history.Resolve(name)
+		if err != nil {
+			return err
+		}
+		versions, err := history.Versions(repository)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			logger.Info("Kept version", logger.Args("ref", v.Ref, "digest", v.Digest, "directory", v.Directory,
+				"replacedAt", v.ReplacedAt.Local().String()))
+		}
+		return nil
+	}
+
+	// Complete or roll back a previous install that did not finish.
+	inst := installer.New(config.InstallManifestFile, config.InstallJournalFile, installer.WithHistory(history))
+	recovered, err := inst.Recover()
+	if err != nil {
+		return fmt.Errorf("unable to recover interrupted install: %w", err)
+	}
+	if recovered != nil && recovered.RolledBack {
+		logger.Warn("Rolled back interrupted install", logger.Args("ref", recovered.Artifact.Ref, "directory", recovered.Artifact.Directory))
+	} else if recovered != nil {
+		logger.Warn("Completed interrupted install", logger.Args("ref", recovered.Artifact.Ref, "directory", recovered.Artifact.Directory))
+	}
+
+	v, err := inst.Rollback(name)
+	if err != nil {
+		return fmt.Errorf("unable to roll back %q: %w", name, err)
+	}
+	logger.Info("Artifact rolled back", logger.Args("ref", v.Ref, "digest", v.Digest, "directory", v.Directory))
+
+	return nil
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+// NewArtifactRulesCmd returns the artifact rules command.
+func NewArtifactRulesCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "rules",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check rules files and rulesfile artifacts",
+		Long:                  "Check rules files and rulesfile artifacts",
+	}
+
+	cmd.AddCommand(newRulesValidateCmd(ctx, opt))
+
+	return cmd
+}
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/rulesfile"
+)
+
+const longValidate = `Statically check rules files, without running Falco.
+
+Each argument is either a rules file, a gzip compressed tarball of rules files, or the reference of a
+rulesfile artifact, which is pulled without being installed. The checks are:
+  - the YAML syntax and the structure of the items;
+  - the fields required by each rule definition;
+  - the required_engine_version, against the engine version of the target Falco given by --engine-version;
+  - rules defined more than once, across all the given files.
+
+The same checks are run by "registry push --type rulesfile" before pushing.
+
+Example - Validate a local rules file against the 0.40.0 engine version:
+	falcoctl artifact rules validate my_rules.yaml --engine-version 0.40.0
+
+Example - Validate the "latest" tag of the "k8saudit-rules" artifact:
+	falcoctl artifact rules validate k8saudit-rules
+`
+
+// ErrInvalidRules is the error returned when issues are found in the rules files.
+var ErrInvalidRules = errors.New("rules files are not valid")
+
+type rulesValidateOptions struct {
+	*options.Common
+	*options.Registry
+	engineVersion string
+}
+
+func newRulesValidateCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := rulesValidateOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "validate [file|ref ...] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Statically check rules files and rulesfile artifacts",
+		Long:                  longValidate,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunRulesValidate(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.engineVersion, "engine-version", "",
+		"engine version of the target Falco, as printed by \"falco --version\", checked against the required_engine_version")
+
+	return cmd
+}
+
+// RunRulesValidate executes the business logic for the artifact rules validate command.
+func (o *rulesValidateOptions) RunRulesValidate(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+
+	var opts rulesfile.Options
+	if o.engineVersion != "" {
+		v, err := semver.ParseTolerant(o.engineVersion)
+		if err != nil {
+			return fmt.Errorf("invalid --engine-version %q: %w", o.engineVersion, err)
+		}
+		opts.EngineVersion = &v
+	}
+
+	tmpDir, err := os.MkdirTemp("", "falcoctl-rules-validate-")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var files []rulesfile.File
+	for _, arg := range args {
+		if _, err := os.Stat(arg); err == nil {
+			loaded, err := rulesfile.Load(ctx, arg)
+			if err != nil {
+				return err
+			}
+			files = append(files, loaded...)
+			continue
+		}
+
+		ref, tarball, err := o.pull(ctx, arg, tmpDir)
+		if err != nil {
+			return err
+		}
+		loaded, err := rulesfile.Load(ctx, tarball)
+		if err != nil {
+			return err
+		}
+		// Report the issues against the reference rather than the temporary tarball.
+		for i := range loaded {
+			loaded[i].Name = ref + strings.TrimPrefix(loaded[i].Name, tarball)
+		}
+		files = append(files, loaded...)
+	}
+
+	issues := rulesfile.Validate(files, opts)
+	for _, issue := range issues {
+		o.Printer.DefaultText.Printfln("%s", issue)
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%w: %d issues found in %d files", ErrInvalidRules, len(issues), len(files))
+	}
+	logger.Info("All rules files successfully validated", logger.Args("files", len(files)))
+	return nil
+}
+
+// pull pulls the rulesfile artifact name in a temporary directory under dir and returns
+// its resolved reference and the path of its tarball.
+func (o *rulesValidateOptions) pull(ctx context.Context, name, dir string) (ref, tarball string, err error) {
+	if ref, err = o.IndexCache.ResolveReference(name); err != nil {
+		return "", "", err
+	}
+
+	puller, err := ociutils.Puller(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := puller.CheckAllowedType(ctx, ref, runtime.GOOS, runtime.GOARCH, []oci.ArtifactType{oci.Rulesfile}); err != nil {
+		return "", "", err
+	}
+
+	artifactDir, err := os.MkdirTemp(dir, "artifact")
+	if err != nil {
+		return "", "", fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+
+	result, err := puller.Pull(ctx, ref, artifactDir, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", "", err
+	}
+
+	return ref, filepath.Join(artifactDir, result.Filename), nil
+}
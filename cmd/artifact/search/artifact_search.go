@@ -17,11 +17,19 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
+	"regexp"
+	"strings"
 
+	"github.com/blang/semver"
 	"github.com/spf13/cobra"
 
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
 	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/repository"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 	"github.com/falcosecurity/falcoctl/pkg/output"
 )
@@ -34,8 +42,15 @@ const (
 
 type artifactSearchOptions struct {
 	*options.Common
+	*options.Registry
+	*options.Output
 	minScore     float64
 	artifactType oci.ArtifactType
+	registry     string
+	regex        bool
+	glob         bool
+	tags         string
+	tagsRange    semver.Range
 }
 
 func (o *artifactSearchOptions) Validate() error {
@@ -43,21 +58,46 @@ func (o *artifactSearchOptions) Validate() error {
 		return fmt.Errorf("minScore must be a number within (0,1]")
 	}
 
-	return nil
+	if o.tags != "" {
+		r, err := semver.ParseRange(o.tags)
+		if err != nil {
+			return fmt.Errorf("invalid --tags range %q: %w", o.tags, err)
+		}
+		o.tagsRange = r
+	}
+
+	return o.Output.Validate()
+}
+
+// searchResult is the machine-readable representation of a search match.
+type searchResult struct {
+	Index      string   `json:"index" yaml:"index"`
+	Name       string   `json:"name" yaml:"name"`
+	Type       string   `json:"type" yaml:"type"`
+	Registry   string   `json:"registry" yaml:"registry"`
+	Repository string   `json:"repository" yaml:"repository"`
+	Tags       []string `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 // NewArtifactSearchCmd returns the artifact search command.
 func NewArtifactSearchCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	o := artifactSearchOptions{
-		Common: opt,
+		Common:   opt,
+		Registry: &options.Registry{},
+		Output:   &options.Output{},
 	}
 
 	cmd := &cobra.Command{
 		Use:                   fmt.Sprintf("%s [keyword1 [keyword2 ...]] [flags]", CommandName),
 		DisableFlagsInUseLine: true,
 		Short:                 "Search an artifact by keywords",
-		Long:                  "Search an artifact by keywords",
-		Args:                  cobra.MinimumNArgs(1),
+		Long: `Search an artifact by keywords.
+
+By default keywords are fuzzy matched against the artifact names and partially matched against their keywords.
+With --regex or --glob they are instead treated as patterns that must match a whole name or keyword.
+With --tags, only the artifacts having tags within the given semver range are shown, together with those tags.
+`,
+		Args: cobra.MinimumNArgs(1),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return o.Validate()
 		},
@@ -70,22 +110,148 @@ func NewArtifactSearchCmd(ctx context.Context, opt *options.Common) *cobra.Comma
 		"the minimum score used to match artifact names with search keywords")
 
 	cmd.Flags().Var(&o.artifactType, "type", `Only search artifacts with a specific type. Allowed values: "rulesfile", "plugin", "asset"`)
+	cmd.Flags().StringVar(&o.registry, "registry", "", "Only search artifacts hosted on the given registry")
+	cmd.Flags().BoolVar(&o.regex, "regex", false, "Treat keywords as regular expressions matched against artifact names and keywords")
+	cmd.Flags().BoolVar(&o.glob, "glob", false, "Treat keywords as glob patterns matched against artifact names and keywords")
+	cmd.Flags().StringVar(&o.tags, "tags", "",
+		`Only show artifacts with tags in the given semver range, e.g. ">=0.10.0 <1.0.0", listing the matching tags`)
+	cmd.MarkFlagsMutuallyExclusive("regex", "glob")
+
+	o.Registry.AddFlags(cmd)
+	o.Output.AddFlags(cmd)
 
 	return cmd
 }
 
-func (o *artifactSearchOptions) RunArtifactSearch(_ context.Context, args []string) error {
-	resultEntries := o.IndexCache.MergedIndexes.SearchByKeywords(o.minScore, args...)
+func (o *artifactSearchOptions) RunArtifactSearch(ctx context.Context, args []string) error {
+	resultEntries, err := o.search(args)
+	if err != nil {
+		return err
+	}
 
-	var data [][]string
+	results := []searchResult{}
 	for _, entry := range resultEntries {
 		if o.artifactType != "" && o.artifactType != oci.ArtifactType(entry.Type) {
 			continue
 		}
-		indexName := o.IndexCache.MergedIndexes.IndexByEntry(entry).Name
-		row := []string{indexName, entry.Name, entry.Type, entry.Registry, entry.Repository}
+		if o.registry != "" && o.registry != entry.Registry {
+			continue
+		}
+		results = append(results, searchResult{
+			Index:      o.IndexCache.MergedIndexes.IndexByEntry(entry).Name,
+			Name:       entry.Name,
+			Type:       entry.Type,
+			Registry:   entry.Registry,
+			Repository: entry.Repository,
+		})
+	}
+
+	if o.tagsRange != nil {
+		if results, err = o.filterByTags(ctx, results); err != nil {
+			return err
+		}
+	}
+
+	if o.Structured() {
+		return o.Printer.PrintStructured(o.Format, results)
+	}
+
+	var data [][]string
+	for _, r := range results {
+		row := []string{r.Index, r.Name, r.Type, r.Registry, r.Repository}
+		if o.tagsRange != nil {
+			row = append(row, strings.Join(r.Tags, ", "))
+		}
 		data = append(data, row)
 	}
 
+	if o.tagsRange != nil {
+		return o.Printer.PrintTable(output.ArtifactSearchTags, data)
+	}
 	return o.Printer.PrintTable(output.ArtifactSearch, data)
 }
+
+// search returns the entries matching the keywords, according to the selected matching mode.
+func (o *artifactSearchOptions) search(keywords []string) ([]*index.Entry, error) {
+	merged := o.IndexCache.MergedIndexes
+
+	switch {
+	case o.regex:
+		var patterns []*regexp.Regexp
+		for _, k := range keywords {
+			re, err := regexp.Compile("^(?:" + k + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q: %w", k, err)
+			}
+			patterns = append(patterns, re)
+		}
+		return merged.SearchByMatch(func(s string) bool {
+			for _, re := range patterns {
+				if re.MatchString(s) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case o.glob:
+		for _, k := range keywords {
+			if _, err := path.Match(k, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob pattern %q: %w", k, err)
+			}
+		}
+		return merged.SearchByMatch(func(s string) bool {
+			for _, k := range keywords {
+				if ok, _ := path.Match(k, s); ok {
+					return true
+				}
+			}
+			return false
+		}), nil
+	default:
+		return merged.SearchByKeywords(o.minScore, keywords...), nil
+	}
+}
+
+// filterByTags keeps the results having at least one tag within the requested range,
+// and records those tags. Artifacts whose tags cannot be listed are skipped with a warning.
+func (o *artifactSearchOptions) filterByTags(ctx context.Context, results []searchResult) ([]searchResult, error) {
+	logger := o.Printer.Logger
+
+	client, err := ociutils.Client(true)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []searchResult{}
+	for _, r := range results {
+		ref := fmt.Sprintf("%s/%s", r.Registry, r.Repository)
+		repo, err := repository.NewRepository(ref,
+			repository.WithClient(client),
+			repository.WithPlainHTTP(o.PlainHTTP))
+		if err != nil {
+			return nil, err
+		}
+
+		tags, err := repo.Tags(ctx)
+		if errors.Is(err, context.Canceled) {
+			// When the context is canceled we exit, since we receive a termination signal.
+			return nil, err
+		} else if err != nil {
+			logger.Warn("Cannot retrieve tags from", logger.Args("ref", ref, "reason", err.Error()))
+			continue
+		}
+
+		for _, t := range tags {
+			v, err := semver.ParseTolerant(t)
+			if err != nil || !o.tagsRange(v) {
+				continue
+			}
+			r.Tags = append(r.Tags, t)
+		}
+		if len(r.Tags) > 0 {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return filtered, nil
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uninstall
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/installer"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	longUninstall = `This command removes the files installed by "artifact install" or "artifact follow" for the given artifacts.
+
+The installed files are recorded in ~/.config/falcoctl/installed.yaml together with their digest. An artifact
+is identified by its repository, possibly with a tag or digest, or by the last component of it. Files modified
+since the install are not removed, and the command fails listing them, unless --force is given. The removed
+version is kept in the history, so that "artifact rollback" can restore its files.
+
+Example - Remove the files of the k8saudit-rules artifact:
+	falcoctl artifact uninstall k8saudit-rules
+
+Example - Remove the files of the falco-rules artifact, even if they have been modified:
+	falcoctl artifact uninstall ghcr.io/falcosecurity/rules/falco-rules --force
+`
+)
+
+type artifactUninstallOptions struct {
+	*options.Common
+	force bool
+}
+
+// NewArtifactUninstallCmd returns the artifact uninstall command.
+func NewArtifactUninstallCmd(_ context.Context, opt *options.Common) *cobra.Command {
+	o := artifactUninstallOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "uninstall [ref1 [ref2 ...]] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Remove the files installed by artifacts",
+		Long:                  longUninstall,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactUninstall(args)
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.force, "force", false, "Remove the files even if they have been modified since the install")
+
+	return cmd
+}
+
+// RunArtifactUninstall executes the business logic for the artifact uninstall command.
+func (o *artifactUninstallOptions) RunArtifactUninstall(args []string) error {
+	logger := o.Printer.Logger
+
+	keep, err := config.ArtifactHistoryKeep()
+	if err != nil {
+		return err
+	}
+
+	// Complete or roll back a previous install that did not finish.
+	inst := installer.New(config.InstallManifestFile, config.InstallJournalFile,
+		installer.WithHistory(installer.NewHistory(config.ArtifactHistoryDir, keep)))
+	recovered, err := inst.Recover()
+	if err != nil {
+		return fmt.Errorf("unable to recover interrupted install: %w", err)
+	}
+	if recovered != nil && recovered.RolledBack {
+		logger.Warn("Rolled back interrupted install", logger.Args("ref", recovered.Artifact.Ref, "directory", recovered.Artifact.Directory))
+	} else if recovered != nil {
+		logger.Warn("Completed interrupted install", logger.Args("ref", recovered.Artifact.Ref, "directory", recovered.Artifact.Directory))
+	}
+
+	for _, ref := range args {
+		a, err := inst.Uninstall(ref, o.force)
+		if err != nil {
+			return fmt.Errorf("unable to uninstall %q: %w", ref, err)
+		}
+		logger.Info("Artifact uninstalled", logger.Args("ref", a.Ref, "files", len(a.Files), "directory", a.Directory))
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	// FlagFalcoBin is the name of the flag to specify the Falco binary used to validate rules files.
+	FlagFalcoBin = "falco-bin"
+
+	defaultFalcoBin = "falco"
+
+	longValidate = `This command pulls one or more rulesfile artifacts and validates the rules files
+they ship using the Falco rule loader (i.e. "falco --validate <file>"), without installing them.
+
+The validation result is reported for each rules file. The command fails if at least one file
+fails validation, making it suitable to gate bad rules out before they are installed.
+
+A reference is either a simple name or a fully qualified reference ("<registry>/<repository>"),
+optionally followed by ":<tag>" (":latest" is assumed by default when no tag is given).
+
+Example - Validate the "latest" tag of "k8saudit-rules" artifact by relying on index metadata:
+	falcoctl artifact validate k8saudit-rules
+
+Example - Validate using a Falco binary that is not in PATH:
+	falcoctl artifact validate k8saudit-rules --falco-bin /usr/local/bin/falco
+`
+)
+
+// ErrValidationFailed is the error returned when at least one rules file fails validation.
+var ErrValidationFailed = errors.New("rules files validation failed")
+
+type artifactValidateOptions struct {
+	*options.Common
+	*options.Registry
+	falcoBin string
+}
+
+// NewArtifactValidateCmd returns the artifact validate command.
+func NewArtifactValidateCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := artifactValidateOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "validate [ref1 [ref2 ...]] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Validate the rules files of a list of artifacts without installing them",
+		Long:                  longValidate,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactValidate(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.falcoBin, FlagFalcoBin, defaultFalcoBin,
+		"path to the Falco binary used to validate the rules files")
+
+	return cmd
+}
+
+// RunArtifactValidate executes the business logic for the artifact validate command.
+func (o *artifactValidateOptions) RunArtifactValidate(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+
+	falcoBin, err := exec.LookPath(o.falcoBin)
+	if err != nil {
+		return fmt.Errorf("unable to find Falco binary %q, please set it using the --%s flag: %w", o.falcoBin, FlagFalcoBin, err)
+	}
+
+	// Create temp dir where to put pulled artifacts
+	tmpDir, err := os.MkdirTemp("", "falcoctl")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	puller, err := ociutils.Puller(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return err
+	}
+
+	var total, failed int
+	for _, arg := range args {
+		ref, err := o.IndexCache.ResolveReference(arg)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Preparing to pull artifact", logger.Args("ref", ref))
+		if err := puller.CheckAllowedType(ctx, ref, runtime.GOOS, runtime.GOARCH, []oci.ArtifactType{oci.Rulesfile}); err != nil {
+			return err
+		}
+
+		artifactDir, err := os.MkdirTemp(tmpDir, "artifact")
+		if err != nil {
+			return fmt.Errorf("cannot create temporary directory: %w", err)
+		}
+
+		result, err := puller.Pull(ctx, ref, artifactDir, runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			return err
+		}
+
+		files, err := extract(ctx, filepath.Join(artifactDir, result.Filename), artifactDir)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			total++
+			name := filepath.Base(file)
+			out, err := validate(ctx, falcoBin, file)
+			if err != nil {
+				failed++
+				logger.Error("Rules file validation failed", logger.Args("ref", ref, "file", name, "reason", err.Error(), "output", out))
+				continue
+			}
+			logger.Info("Rules file successfully validated", logger.Args("ref", ref, "file", name))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d files are not valid", ErrValidationFailed, failed, total)
+	}
+	logger.Info("All rules files successfully validated", logger.Args("files", total))
+	return nil
+}
+
+// extract extracts the artifact tarball into destDir and returns the path of the extracted rules files.
+func extract(ctx context.Context, tarball, destDir string) ([]string, error) {
+	f, err := os.Open(filepath.Clean(tarball))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	paths, err := utils.ExtractTarGz(ctx, f, destDir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot extract %q to %q: %w", tarball, destDir, err)
+	}
+
+	var files []string
+	for _, p := range paths {
+		ext := filepath.Ext(p)
+		if ext == ".yaml" || ext == ".yml" {
+			files = append(files, p)
+		}
+	}
+	return files, nil
+}
+
+// validate runs the Falco rule validator against the given file and returns its output.
+func validate(ctx context.Context, falcoBin, file string) (string, error) {
+	out, err := exec.CommandContext(ctx, falcoBin, "--validate", file).CombinedOutput() //nolint:gosec // binary is set by the user
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	ocipusher "github.com/falcosecurity/falcoctl/pkg/oci/pusher"
+)
+
+// fakeFalco writes a fake Falco binary that exits with the given code, printing its arguments.
+func fakeFalco(exitCode string) string {
+	bin := filepath.Join(GinkgoT().TempDir(), "falco")
+	script := "#!/bin/sh\necho \"validating $2\"\nexit " + exitCode + "\n"
+	Expect(os.WriteFile(bin, []byte(script), 0o700)).To(Succeed())
+	return bin
+}
+
+var _ = Describe("validate", func() {
+	const (
+		artifactCmd = "artifact"
+		validateCmd = "validate"
+		repoAndTag  = "/validate-repo:tag"
+	)
+
+	var (
+		pusher *ocipusher.Pusher
+		ref    string
+	)
+
+	pushArtifact := func(artifactType oci.ArtifactType) {
+		pusher = ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), true, nil)
+		ref = registry + repoAndTag
+		var filePaths ocipusher.Option
+		if artifactType == oci.Plugin {
+			filePaths = ocipusher.WithFilepathsAndPlatforms([]string{plugintgz}, []string{"linux/amd64"})
+		} else {
+			filePaths = ocipusher.WithFilepaths([]string{rulesfiletgz})
+		}
+		config := ocipusher.WithArtifactConfig(oci.ArtifactConfig{
+			Name:    "validate1",
+			Version: "0.0.1",
+		})
+		result, err := pusher.Push(ctx, artifactType, ref, filePaths, config)
+		Expect(err).To(BeNil())
+		Expect(result).ToNot(BeNil())
+	}
+
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("failure", func() {
+		When("without artifact", func() {
+			BeforeEach(func() {
+				args = []string{artifactCmd, validateCmd, "--config", configFile}
+			})
+
+			It("should fail", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta("ERROR requires at least 1 arg(s), only received 0")))
+			})
+		})
+
+		When("the falco binary cannot be found", func() {
+			BeforeEach(func() {
+				args = []string{artifactCmd, validateCmd, registry + repoAndTag, "--plain-http", "--config", configFile,
+					"--falco-bin", "/not/existing/falco"}
+			})
+
+			It("should fail", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta(`ERROR unable to find Falco binary "/not/existing/falco"`)))
+			})
+		})
+
+		When("the artifact is not a rulesfile", func() {
+			BeforeEach(func() {
+				pushArtifact(oci.Plugin)
+				args = []string{artifactCmd, validateCmd, ref, "--plain-http", "--config", configFile,
+					"--falco-bin", fakeFalco("0")}
+			})
+
+			It("should fail", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta(`ERROR cannot download artifact of type "plugin": type not permitted`)))
+			})
+		})
+
+		When("the rules files are not valid", func() {
+			BeforeEach(func() {
+				pushArtifact(oci.Rulesfile)
+				args = []string{artifactCmd, validateCmd, ref, "--plain-http", "--config", configFile,
+					"--falco-bin", fakeFalco("1")}
+			})
+
+			It("should report the failed file and fail", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Rules file validation failed"))
+				Expect(output).Should(gbytes.Say("aws_cloudtrail_rules.yaml"))
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta("ERROR rules files validation failed: 1 of 1 files are not valid")))
+			})
+		})
+	})
+
+	Context("success", func() {
+		When("the rules files are valid", func() {
+			var destDir string
+
+			BeforeEach(func() {
+				pushArtifact(oci.Rulesfile)
+				destDir = GinkgoT().TempDir()
+				args = []string{artifactCmd, validateCmd, ref, "--plain-http", "--config", configFile,
+					"--falco-bin", fakeFalco("0")}
+			})
+
+			It("should validate and not install anything", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Rules file successfully validated"))
+				Expect(output).Should(gbytes.Say("aws_cloudtrail_rules.yaml"))
+				Expect(output).Should(gbytes.Say("All rules files successfully validated"))
+				entries, err := os.ReadDir(destDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(entries).To(BeEmpty())
+			})
+		})
+	})
+})
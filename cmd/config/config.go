@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	configvalidate "github.com/falcosecurity/falcoctl/cmd/config/validate"
+	"github.com/falcosecurity/falcoctl/internal/config"
+	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+// NewConfigCmd returns the config command.
+func NewConfigCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "config",
+		DisableFlagsInUseLine: true,
+		Short:                 "Interact with the falcoctl configuration",
+		Long:                  "Interact with the falcoctl configuration",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			opt.Initialize()
+			return config.Load(opt.ConfigFile)
+		},
+	}
+
+	cmd.AddCommand(configvalidate.NewConfigValidateCmd(ctx, opt))
+
+	return cmd
+}
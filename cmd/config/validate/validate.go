@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configvalidate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/login"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+type configValidateOptions struct {
+	*options.Common
+	checkAuth bool
+}
+
+// NewConfigValidateCmd returns the config validate command.
+func NewConfigValidateCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := configValidateOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "validate [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Validate the falcoctl configuration",
+		Long: `Validate the falcoctl configuration, checking that each entry of the registries section is well-formed.
+With --check-auth, the connection to each registry is tested with its configured auth, without storing any credential.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunConfigValidate(ctx)
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.checkAuth, "check-auth", false, "Test the connection to each configured registry with its auth")
+
+	return cmd
+}
+
+// RunConfigValidate implements the config validate command.
+func (o *configValidateOptions) RunConfigValidate(ctx context.Context) error {
+	registries, err := config.Registries()
+	if err != nil {
+		return err
+	}
+
+	if err := config.ValidateRegistries(registries); err != nil {
+		return fmt.Errorf("invalid %s section in %q: %w", config.RegistriesKey, o.ConfigFile, err)
+	}
+
+	failed := 0
+	for i := range registries {
+		r := &registries[i]
+		if !o.checkAuth {
+			o.Printer.Logger.Debug("Registry entry is valid", o.Printer.Logger.Args("registry", r.Host, "auth", r.Auth))
+			continue
+		}
+
+		if err := login.CheckRegistryAuth(ctx, r); err != nil {
+			o.Printer.Logger.Error("Unable to authenticate to registry", o.Printer.Logger.Args("registry", r.Host, "auth", r.Auth, "reason", err))
+			failed++
+			continue
+		}
+		o.Printer.Logger.Info("Registry auth checked", o.Printer.Logger.Args("registry", r.Host, "auth", r.Auth))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("unable to authenticate to %d registries", failed)
+	}
+
+	o.Printer.Logger.Info("Configuration is valid", o.Printer.Logger.Args("config", o.ConfigFile, "registries", len(registries)))
+	return nil
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configvalidate_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+)
+
+//nolint:lll // no need to check for line length.
+var configValidateHelp = `Validate the falcoctl configuration, checking that each entry of the registries section is well-formed.
+With --check-auth, the connection to each registry is tested with its configured auth, without storing any credential.
+
+Usage:
+  falcoctl config validate [flags]
+
+Flags:
+      --check-auth   Test the connection to each configured registry with its auth
+  -h, --help         help for validate
+
+Global Flags:
+      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string   Set formatting for logs (color, text, json) (default "color")
+      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+`
+
+const invalidRegistries = `registries:
+- host: ghcr.io
+  auth: basic
+  basic:
+    user: user
+- host: ghcr.io
+  auth: none
+- auth: none
+- host: registry.example.com
+  auth: token
+`
+
+const unreachableRegistry = `registries:
+- host: localhost:1
+  auth: none
+`
+
+// writeConfig writes the given content to a new config file.
+func writeConfig(content string) string {
+	file := filepath.Join(GinkgoT().TempDir(), "falcoctl.yaml")
+	Expect(os.WriteFile(file, []byte(content), 0o600)).Should(Succeed())
+	return file
+}
+
+var _ = Describe("validate", func() {
+
+	var (
+		configCmd   = "config"
+		validateCmd = "validate"
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("help message", func() {
+		BeforeEach(func() {
+			args = []string{configCmd, validateCmd, "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(configValidateHelp)))
+		})
+	})
+
+	Context("valid configuration", func() {
+		BeforeEach(func() {
+			args = []string{configCmd, validateCmd, "--config", configFile}
+		})
+
+		It("should succeed", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(output).Should(gbytes.Say("Configuration is valid"))
+		})
+	})
+
+	Context("failure", func() {
+		When("with malformed registries entries", func() {
+			BeforeEach(func() {
+				args = []string{configCmd, validateCmd, "--config", writeConfig(invalidRegistries)}
+			})
+
+			It("should report each malformed entry", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring(`entry 0: registry "ghcr.io": one of basic.password and basic.passwordEnv is mandatory for "basic" auth`))
+				Expect(err.Error()).Should(ContainSubstring("entry 2: host is mandatory"))
+				Expect(err.Error()).Should(ContainSubstring(`entry 3: registry "registry.example.com": auth must be one of none, basic, oauth, cloud, got "token"`))
+			})
+		})
+
+		When("with unreachable registries and --check-auth", func() {
+			BeforeEach(func() {
+				args = []string{configCmd, validateCmd, "--check-auth", "--config", writeConfig(unreachableRegistry)}
+			})
+
+			It("should report the registries it could not authenticate to", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Unable to authenticate to registry"))
+				Expect(err.Error()).Should(Equal("unable to authenticate to 1 registries"))
+			})
+		})
+	})
+})
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drivercheck
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	driverkernel "github.com/falcosecurity/falcoctl/pkg/driver/kernel"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const longCheck = `Inspect the node and report which driver types are viable, before attempting an install.
+The kernel release and version, the architecture, the BTF availability, the presence of the kernel headers
+and the secure boot state are reported, together with the reasons making a driver type not viable.
+The preferred driver type is the one driver install would pick, among the allowed ones.
+It exits with an error when no driver type is viable.
+`
+
+// ErrNoViableDriver is returned when none of the driver types is viable on the node.
+var ErrNoViableDriver = errors.New("no viable driver type found for the node")
+
+type driverCheckOptions struct {
+	*options.Common
+	*options.Driver
+	*options.Output
+	features  func(kernelRelease, hostRoot string) driverkernel.Features
+	supported func(t drivertype.DriverType, kr kernelrelease.KernelRelease) bool
+}
+
+// Report is the compatibility report of the node.
+type Report struct {
+	KernelRelease string             `json:"kernelRelease" yaml:"kernelRelease"`
+	KernelVersion string             `json:"kernelVersion" yaml:"kernelVersion"`
+	Arch          string             `json:"arch" yaml:"arch"`
+	Distro        string             `json:"distro" yaml:"distro"`
+	BTF           bool               `json:"btf" yaml:"btf"`
+	KernelHeaders bool               `json:"kernelHeaders" yaml:"kernelHeaders"`
+	SecureBoot    string             `json:"secureBoot" yaml:"secureBoot"`
+	Preferred     string             `json:"preferred,omitempty" yaml:"preferred,omitempty"`
+	Drivers       []DriverTypeReport `json:"drivers" yaml:"drivers"`
+}
+
+// DriverTypeReport tells whether a driver type is viable on the node.
+type DriverTypeReport struct {
+	Type   string   `json:"type" yaml:"type"`
+	Viable bool     `json:"viable" yaml:"viable"`
+	Notes  []string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// NewDriverCheckCmd reports which driver types are viable on the node.
+func NewDriverCheckCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverCheckOptions{
+		Common:   opt,
+		Driver:   driver,
+		Output:   &options.Output{},
+		features: driverkernel.DetectFeatures,
+		supported: func(t drivertype.DriverType, kr kernelrelease.KernelRelease) bool {
+			return t.Supported(kr)
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "check [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Report which driver types are viable on the node",
+		Long:                  longCheck,
+		Annotations:           map[string]string{options.DriverInspectOnlyAnnotation: "true"},
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			return o.Output.Validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return o.RunDriverCheck(ctx)
+		},
+	}
+
+	o.Output.AddFlags(cmd)
+	return cmd
+}
+
+// RunDriverCheck implements the driver check command.
+func (o *driverCheckOptions) RunDriverCheck(_ context.Context) error {
+	report := o.report()
+
+	if o.Structured() {
+		if err := o.Printer.PrintStructured(o.Format, report); err != nil {
+			return err
+		}
+	} else {
+		o.Printer.Logger.Info("Node", o.Printer.Logger.Args(
+			"kernel release", report.KernelRelease,
+			"kernel version", report.KernelVersion,
+			"arch", report.Arch,
+			"distro", report.Distro,
+			"btf", report.BTF,
+			"kernel headers", report.KernelHeaders,
+			"secure boot", report.SecureBoot,
+			"preferred", report.Preferred))
+		data := make([][]string, 0, len(report.Drivers))
+		for _, d := range report.Drivers {
+			data = append(data, []string{d.Type, strconv.FormatBool(d.Viable), strings.Join(d.Notes, "; ")})
+		}
+		if err := o.Printer.PrintTable(output.DriverCheck, data); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range report.Drivers {
+		if d.Viable {
+			return nil
+		}
+	}
+	return ErrNoViableDriver
+}
+
+// report inspects the node and tells, for each driver type, whether it is viable.
+func (o *driverCheckOptions) report() *Report {
+	features := o.features(o.Kr.String(), o.HostRoot)
+	report := &Report{
+		KernelRelease: o.Kr.String(),
+		KernelVersion: o.Kr.KernelVersion,
+		Arch:          o.Kr.Architecture.ToNonDeb(),
+		BTF:           features.BTF,
+		KernelHeaders: features.Headers,
+		SecureBoot:    features.SecureBoot,
+	}
+	if o.Distro != nil {
+		report.Distro = o.Distro.String()
+	}
+	if o.Driver.Type != nil {
+		report.Preferred = o.Driver.Type.String()
+	}
+
+	types := drivertype.GetTypes()
+	sort.Strings(types)
+	for _, t := range types {
+		dType, err := drivertype.Parse(t)
+		if err != nil {
+			continue
+		}
+		d := DriverTypeReport{Type: t, Viable: o.supported(dType, o.Kr)}
+		switch t {
+		case drivertype.TypeModernBpf:
+			if !d.Viable {
+				d.Notes = append(d.Notes, "the eBPF tracing programs or ring buffer maps are not available, or cannot be probed without privileges")
+			}
+			if !features.BTF {
+				d.Viable = false
+				d.Notes = append(d.Notes, "BTF is not available")
+			}
+		case drivertype.TypeKmod:
+			if !d.Viable {
+				d.Notes = append(d.Notes, "not supported by the kernel release")
+			}
+			if features.SecureBoot == driverkernel.SecureBootEnabled {
+				d.Viable = false
+				d.Notes = append(d.Notes, "secure boot is enabled, only kernel modules signed with an enrolled key can be loaded")
+			}
+		default:
+			if !d.Viable {
+				d.Notes = append(d.Notes, "not supported by the kernel release")
+			}
+		}
+		if d.Viable && dType.HasArtifacts() && !features.Headers {
+			d.Notes = append(d.Notes, "kernel headers not found, only prebuilt drivers can be installed")
+		}
+		report.Drivers = append(report.Drivers, d)
+	}
+	return report
+}
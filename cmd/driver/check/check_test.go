@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drivercheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	driverkernel "github.com/falcosecurity/falcoctl/pkg/driver/kernel"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func TestRunDriverCheck(t *testing.T) {
+	kr := kernelrelease.FromString("6.1.0-9-amd64")
+	kr.Architecture = "amd64"
+
+	testCases := []struct {
+		name        string
+		features    driverkernel.Features
+		unsupported []string
+		expected    []DriverTypeReport
+		expectedErr error
+	}{
+		{
+			name:     "every driver type viable",
+			features: driverkernel.Features{BTF: true, Headers: true, SecureBoot: driverkernel.SecureBootDisabled},
+			expected: []DriverTypeReport{
+				{Type: drivertype.TypeBpf, Viable: true},
+				{Type: drivertype.TypeKmod, Viable: true},
+				{Type: drivertype.TypeModernBpf, Viable: true},
+			},
+		},
+		{
+			name:        "secure boot without BTF nor headers",
+			features:    driverkernel.Features{SecureBoot: driverkernel.SecureBootEnabled},
+			unsupported: []string{drivertype.TypeBpf},
+			expected: []DriverTypeReport{
+				{Type: drivertype.TypeBpf, Notes: []string{"not supported by the kernel release"}},
+				{Type: drivertype.TypeKmod, Notes: []string{"secure boot is enabled, only kernel modules signed with an enrolled key can be loaded"}},
+				{Type: drivertype.TypeModernBpf, Notes: []string{"BTF is not available"}},
+			},
+			expectedErr: ErrNoViableDriver,
+		},
+		{
+			name:        "no kernel headers",
+			features:    driverkernel.Features{BTF: true, SecureBoot: driverkernel.SecureBootUnknown},
+			unsupported: []string{drivertype.TypeModernBpf},
+			expected: []DriverTypeReport{
+				{Type: drivertype.TypeBpf, Viable: true, Notes: []string{"kernel headers not found, only prebuilt drivers can be installed"}},
+				{Type: drivertype.TypeKmod, Viable: true, Notes: []string{"kernel headers not found, only prebuilt drivers can be installed"}},
+				{Type: drivertype.TypeModernBpf, Notes: []string{
+					"the eBPF tracing programs or ring buffer maps are not available, or cannot be probed without privileges",
+				}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			o := driverCheckOptions{
+				Common: &options.Common{Printer: output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, &buf)},
+				Driver: &options.Driver{Name: "falco", HostRoot: "/", Kr: kr},
+				Output: &options.Output{Format: output.FormatJSON},
+				features: func(string, string) driverkernel.Features {
+					return tc.features
+				},
+				supported: func(dType drivertype.DriverType, _ kernelrelease.KernelRelease) bool {
+					for _, u := range tc.unsupported {
+						if dType.String() == u {
+							return false
+						}
+					}
+					return true
+				},
+			}
+
+			err := o.RunDriverCheck(context.Background())
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var report Report
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+			assert.Equal(t, "6.1.0-9-amd64", report.KernelRelease)
+			assert.Equal(t, "x86_64", report.Arch)
+			assert.Equal(t, tc.features.SecureBoot, report.SecureBoot)
+			assert.Equal(t, tc.expected, report.Drivers)
+		})
+	}
+}
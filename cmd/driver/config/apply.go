@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+// Request is a driver configuration, as done by the driver config command.
+// Use NewRequest to get the defaults of the command: the zero value of a Request
+// neither updates Falco nor backs up or verifies the updated configurations.
+type Request struct {
+	// Common provides the printer and the falcoctl config file where the driver configuration is stored.
+	*options.Common
+	// Driver is the driver to configure.
+	*options.Driver
+	// Update sets the driver type in the Falco configuration.
+	Update bool
+	// EngineOnly only updates the Falco configuration, without storing the driver configuration.
+	EngineOnly bool
+	// Strict fails, with ErrEngineNotDriverDriven, instead of skipping the Falco configurations not running with a driver.
+	Strict bool
+	// RequireDriver fails, instead of warning, when the driver was not installed under the host root.
+	RequireDriver bool
+	// DryRun only reports the changes, without applying them.
+	DryRun bool
+	// Backup saves a copy of the Falco config file before updating it.
+	Backup bool
+	// Restart restarts the Falco systemd service after updating the Falco config file.
+	Restart bool
+	// Rollout restarts Falco after a successful update: the daemonsets and deployments of the namespaces
+	// of the updated configmaps on Kubernetes, or the Falco systemd service as Restart does.
+	Rollout bool
+	// AllowDowngrade allows storing a driver version older than the stored one.
+	AllowDowngrade bool
+	// SkipValidation skips checking that the driver type is supported by the target kernel, and was installed.
+	SkipValidation bool
+	// MatchContext selects the engine.kind to edit in the Falco config file.
+	MatchContext string
+	// FalcoConfig is the Falco config file, relative to the driver host root.
+	FalcoConfig string
+	// CreateIfMissing creates a minimal Falco config file, setting the engine kind, when it does not exist.
+	CreateIfMissing bool
+	// LockTimeout bounds the time waiting for the lock of the Falco config file held by other runs.
+	LockTimeout time.Duration
+
+	// Namespace, or AllNamespaces, selects the Falco deployment in Kubernetes instead of the local Falco config file.
+	Namespace     string
+	AllNamespaces bool
+	// OpenShift discovers the Falco namespace from the Falco operator instance when no namespace is given.
+	OpenShift bool
+	// KubeClient and DynamicClient, if set, are used instead of building clients from KubeConfig and KubeContext.
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+	KubeConfig    string
+	KubeContext   string
+	// CAFile is a PEM bundle used to verify the Kubernetes API server, instead of the one of the kubeconfig.
+	CAFile        string
+	InstanceLabel string
+	// Selector and FieldSelector select the Kubernetes resources to update, Selector replacing InstanceLabel.
+	Selector      string
+	FieldSelector string
+	ConfigMapKey  string
+	// Watch keeps patching the configmaps whose engine kind drifts, every ResyncPeriod and on changes,
+	// until the context is cancelled.
+	Watch        bool
+	ResyncPeriod time.Duration
+	// EmitEvents records a Kubernetes event on each updated configmap.
+	EmitEvents bool
+	// Force updates the configmaps even when they currently run different driver types.
+	Force bool
+	// Verify reads back the patched configmaps, checking that they hold the new driver type.
+	Verify bool
+	// Target is the kind of Kubernetes resources to update: configmap, daemonset or auto.
+	Target      string
+	MaxParallel int
+	// Timeout bounds the time spent updating the Kubernetes resources, zero meaning no limit.
+	Timeout       time.Duration
+	Retries       int
+	RetryInterval time.Duration
+}
+
+// NewRequest returns the request configuring the given driver with the defaults of the driver config command.
+func NewRequest(opt *options.Common, driver *options.Driver) Request {
+	return Request{
+		Common:        opt,
+		Driver:        driver,
+		Update:        true,
+		Backup:        true,
+		Verify:        true,
+		FalcoConfig:   defaultFalcoConfig,
+		LockTimeout:   defaultLockTimeout,
+		InstanceLabel: defaultInstanceLabel,
+		ConfigMapKey:  configMapEngineKindKey,
+		ResyncPeriod:  defaultResyncPeriod,
+		Target:        targetConfigMap,
+		MaxParallel:   defaultMaxParallel,
+		Timeout:       defaultTimeout,
+		Retries:       defaultRetries,
+		RetryInterval: defaultRetryInterval,
+	}
+}
+
+var errDowngrade = errors.New("driver version downgrade")
+
+var errNothingToDo = errors.New("nothing to do: --engine-only skips storing the driver configuration " +
+	"while --update-falco=false skips updating Falco")
+
+// Apply configures the driver as requested: the driver type is set in the Falco configuration,
+// either the local Falco config file or the Kubernetes resources, and the driver configuration is stored.
+// The returned Result is the outcome, filled as far as the configuration went in case of errors.
+func Apply(ctx context.Context, req Request) (Result, error) {
+	o := driverConfigOptions{Request: req}
+	err := o.apply(ctx)
+	o.result.summarize()
+	return o.result, err
+}
+
+func (o *driverConfigOptions) apply(ctx context.Context) error {
+	if o.EngineOnly && !o.Update {
+		return errNothingToDo
+	}
+	if err := o.checkWatch(); err != nil {
+		return err
+	}
+	o.Printer.Logger.Info("Running falcoctl driver config", o.Printer.Logger.Args(
+		"name", o.Driver.Name,
+		"version", o.Driver.Version,
+		"type", o.Driver.Type.String(),
+		"host-root", o.Driver.HostRoot,
+		"repos", strings.Join(o.Driver.EffectiveRepos(), ",")))
+
+	o.result = Result{
+		Name:     o.Driver.Name,
+		Version:  o.Driver.Version,
+		Type:     o.Driver.Type.String(),
+		HostRoot: o.Driver.HostRoot,
+		DryRun:   o.DryRun,
+	}
+	if !o.SkipValidation {
+		if err := o.validateDriverType(); err != nil {
+			return err
+		}
+		// On Kubernetes the drivers are installed on the nodes, not where falcoctl runs.
+		if !o.onKubernetes() {
+			if err := o.checkDriverArtifact(); err != nil {
+				return err
+			}
+		}
+	}
+	if !o.EngineOnly {
+		if err := o.checkDowngrade(); err != nil {
+			return err
+		}
+	}
+	if o.Update {
+		_, err := o.commit(ctx, o.Driver.Type)
+		if err := o.skipNotDriverDriven(err); err != nil {
+			return err
+		}
+	}
+	if err := o.storeDriver(); err != nil {
+		return err
+	}
+	if o.Update && o.Rollout && o.onKubernetes() {
+		if err := o.rolloutFalco(ctx); err != nil {
+			return err
+		}
+	}
+	if o.Watch {
+		return o.watchConfigMaps(ctx, o.Driver.Type)
+	}
+	return nil
+}
+
+func (o *driverConfigOptions) storeDriver() error {
+	if o.EngineOnly {
+		o.Printer.Logger.Info("Engine only, driver configuration not stored", o.Printer.Logger.Args("config", o.ConfigFile))
+		return nil
+	}
+	if o.DryRun {
+		o.Printer.Logger.Info("Dry run, driver configuration not stored", o.Printer.Logger.Args("config", o.ConfigFile))
+		return nil
+	}
+	return config.StoreDriver(o.Driver.ToDriverConfig(), o.historyTarget(), o.ConfigFile)
+}
+
+// checkDowngrade fails, unless allowed, when the driver version is older than the stored one.
+// Versions that are not semantic versions cannot be compared, and are always accepted.
+func (o *driverConfigOptions) checkDowngrade() error {
+	stored, err := config.LoadDriver(o.ConfigFile)
+	if err != nil || stored.Version == "" || o.Driver.Version == "" {
+		// First configuration.
+		return nil
+	}
+	storedVer, err := semver.Parse(stored.Version)
+	if err != nil {
+		o.Printer.Logger.Debug("Unable to compare with the stored driver version", o.Printer.Logger.Args("stored", stored.Version, "reason", err))
+		return nil
+	}
+	newVer, err := semver.Parse(o.Driver.Version)
+	if err != nil {
+		o.Printer.Logger.Debug("Unable to compare with the stored driver version", o.Printer.Logger.Args("version", o.Driver.Version, "reason", err))
+		return nil
+	}
+	if !newVer.LT(storedVer) {
+		return nil
+	}
+	o.Printer.Logger.Warn("Downgrading the driver version", o.Printer.Logger.Args("stored", stored.Version, "version", o.Driver.Version))
+	if !o.AllowDowngrade {
+		return fmt.Errorf("%w: %s is older than the stored %s, use --allow-downgrade to proceed", errDowngrade, o.Driver.Version, stored.Version)
+	}
+	return nil
+}
+
+// historyTarget describes where the driver type was applied, for the driver configuration history.
+func (o *driverConfigOptions) historyTarget() string {
+	switch {
+	case !o.Update:
+		return ""
+	case o.AllNamespaces:
+		return "kubernetes:*"
+	case o.Namespace != "":
+		return "kubernetes:" + o.Namespace
+	}
+	falcoCfgFile, err := falcoConfigPath(o.Driver.HostRoot, o.FalcoConfig)
+	if err != nil {
+		return o.FalcoConfig
+	}
+	return falcoCfgFile
+}
@@ -0,0 +1,400 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/driverkit/cmd"
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	driverdistro "github.com/falcosecurity/falcoctl/pkg/driver/distro"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func newApplyTestRequest(t *testing.T) Request {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	falcoctlConfig := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(falcoctlConfig, nil, 0o600))
+	var out bytes.Buffer
+	return Request{
+		Common: &options.Common{
+			Printer:    output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, &out),
+			ConfigFile: falcoctlConfig,
+		},
+		Driver: &options.Driver{Type: driverType, Name: "falco", Version: "7.0.0+driver", HostRoot: "/"},
+		Update: true,
+		// Probing modern_ebpf support needs privileges.
+		SkipValidation: true,
+	}
+}
+
+func TestApplyFalcoConfig(t *testing.T) {
+	req := newApplyTestRequest(t)
+	req.FalcoConfig = filepath.Join(t.TempDir(), "falco.yaml")
+	require.NoError(t, os.WriteFile(req.FalcoConfig, []byte("engine:\n  kind: kmod\n"), 0o600))
+
+	res, err := Apply(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, Result{
+		Name:        "falco",
+		Version:     "7.0.0+driver",
+		Type:        drivertype.TypeModernBpf,
+		HostRoot:    "/",
+		FalcoConfig: req.FalcoConfig,
+		Targets: []TargetResult{{
+			Target:       req.FalcoConfig,
+			Action:       TargetUpdated,
+			PreviousHash: contentHash([]byte("engine:\n  kind: kmod\n")),
+			Hash:         contentHash([]byte("engine:\n  kind: modern_ebpf\n")),
+		}},
+	}, res)
+
+	data, err := os.ReadFile(req.FalcoConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "engine:\n  kind: modern_ebpf\n", string(data))
+	driverCfg, err := config.LoadDriver(req.ConfigFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{drivertype.TypeModernBpf}, driverCfg.Type)
+}
+
+func TestApplyNewRequest(t *testing.T) {
+	base := newApplyTestRequest(t)
+	req := NewRequest(base.Common, base.Driver)
+	assert.True(t, req.Update)
+	assert.True(t, req.Backup)
+	assert.True(t, req.Verify)
+	assert.Equal(t, defaultTimeout, req.Timeout)
+	assert.Equal(t, defaultLockTimeout, req.LockTimeout)
+
+	req.SkipValidation = true
+	req.FalcoConfig = filepath.Join(t.TempDir(), "falco.yaml")
+	require.NoError(t, os.WriteFile(req.FalcoConfig, []byte("engine:\n  kind: kmod\n"), 0o600))
+	_, err := Apply(context.Background(), req)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(req.FalcoConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "engine:\n  kind: modern_ebpf\n", string(data))
+}
+
+func TestApplyUpdateAndStoreCombinations(t *testing.T) {
+	tests := []struct {
+		name           string
+		update         bool
+		engineOnly     bool
+		expectedErr    error
+		expectedKind   string
+		expectedStored bool
+	}{
+		{name: "update and store", update: true, expectedKind: drivertype.TypeModernBpf, expectedStored: true},
+		{name: "engine only", update: true, engineOnly: true, expectedKind: drivertype.TypeModernBpf},
+		{name: "store only", expectedKind: drivertype.TypeKmod, expectedStored: true},
+		{name: "nothing to do", engineOnly: true, expectedErr: errNothingToDo, expectedKind: drivertype.TypeKmod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newApplyTestRequest(t)
+			req.Update = tt.update
+			req.EngineOnly = tt.engineOnly
+			req.FalcoConfig = filepath.Join(t.TempDir(), "falco.yaml")
+			require.NoError(t, os.WriteFile(req.FalcoConfig, []byte("engine:\n  kind: kmod\n"), 0o600))
+
+			_, err := Apply(context.Background(), req)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			data, err := os.ReadFile(req.FalcoConfig)
+			require.NoError(t, err)
+			assert.Equal(t, "engine:\n  kind: "+tt.expectedKind+"\n", string(data))
+			driverCfg, err := config.LoadDriver(req.ConfigFile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStored, len(driverCfg.Type) > 0)
+		})
+	}
+}
+
+func TestApplyKubernetes(t *testing.T) {
+	var patched []string
+	cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod), newConfigMap("falco-gvisor", "gvisor"))
+	allowAccessReviews(cl, "list", "patch")
+	cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patched = append(patched, action.(k8stesting.PatchAction).GetName())
+		return true, &corev1.ConfigMap{}, nil
+	})
+
+	req := newApplyTestRequest(t)
+	req.Namespace = "falco"
+	req.KubeClient = cl
+	res, err := Apply(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"falco"}, patched)
+	assert.Equal(t, []string{"falco/falco"}, res.ConfigMaps)
+	assert.Equal(t, []string{"falco/falco-gvisor"}, res.SkippedConfigMaps)
+	assert.True(t, res.Skipped)
+
+	t.Run("strict", func(t *testing.T) {
+		patched = nil
+		req.Strict = true
+		_, err := Apply(context.Background(), req)
+		assert.ErrorIs(t, err, ErrEngineNotDriverDriven)
+		assert.Empty(t, patched)
+	})
+}
+
+// outOfTreeType is a driver type registered by the tests, as an out-of-tree build would do.
+type outOfTreeType struct{}
+
+func (d *outOfTreeType) String() string                                    { return "out_of_tree" }
+func (d *outOfTreeType) Cleanup(_ *output.Printer, _ string) error         { return nil }
+func (d *outOfTreeType) Load(_ *output.Printer, _, _ string, _ bool) error { return nil }
+func (d *outOfTreeType) Extension() string                                 { return ".o" }
+func (d *outOfTreeType) HasArtifacts() bool                                { return true }
+func (d *outOfTreeType) ToOutput(_ string) cmd.OutputOptions               { return cmd.OutputOptions{} }
+func (d *outOfTreeType) Supported(_ kernelrelease.KernelRelease) bool      { return true }
+
+func TestApplyRegisteredDriverType(t *testing.T) {
+	drivertype.Register("out_of_tree", func() drivertype.DriverType { return &outOfTreeType{} })
+	t.Cleanup(func() { drivertype.Unregister("out_of_tree") })
+	assert.NoError(t, checkFalcoRunsWithDrivers("out_of_tree"))
+
+	driverType, err := drivertype.Parse("out_of_tree")
+	require.NoError(t, err)
+	req := newApplyTestRequest(t)
+	req.Driver.Type = driverType
+	req.SkipValidation = false
+	req.FalcoConfig = filepath.Join(t.TempDir(), "falco.yaml")
+	require.NoError(t, os.WriteFile(req.FalcoConfig, []byte("engine:\n  kind: kmod\n"), 0o600))
+
+	_, err = Apply(context.Background(), req)
+	require.NoError(t, err)
+	data, err := os.ReadFile(req.FalcoConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "engine:\n  kind: out_of_tree\n", string(data))
+
+	// The registered type is recognized as driver driven when switching back.
+	modernBpf, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	req.Driver.Type = modernBpf
+	req.SkipValidation = true
+	res, err := Apply(context.Background(), req)
+	require.NoError(t, err)
+	assert.False(t, res.Skipped)
+}
+
+func TestApplyTargetResults(t *testing.T) {
+	cl := fake.NewSimpleClientset(
+		newConfigMap("falco-a", drivertype.TypeKmod),
+		newConfigMap("falco-b", "gvisor"),
+		newConfigMap("falco-c", drivertype.TypeKmod),
+	)
+	allowAccessReviews(cl, "list", "patch")
+	cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.PatchAction).GetName() == "falco-c" {
+			return true, nil, apierrors.NewBadRequest("invalid patch")
+		}
+		return true, &corev1.ConfigMap{}, nil
+	})
+
+	req := newApplyTestRequest(t)
+	req.Namespace = "falco"
+	req.KubeClient = cl
+	res, err := Apply(context.Background(), req)
+	assert.ErrorContains(t, err, `unable to patch configMap "falco-c" in namespace "falco": invalid patch`)
+	require.Len(t, res.Targets, 3)
+	assert.Equal(t, TargetResult{
+		Target:       "configmap/falco/falco-a",
+		Action:       TargetUpdated,
+		PreviousHash: contentHash([]byte(drivertype.TypeKmod)),
+		Hash:         contentHash([]byte(drivertype.TypeModernBpf)),
+	}, res.Targets[0])
+	assert.Equal(t, TargetResult{
+		Target: "configmap/falco/falco-b",
+		Action: TargetSkipped,
+		Error:  "engine.kind is not driver driven: gvisor",
+	}, res.Targets[1])
+	assert.Equal(t, "configmap/falco/falco-c", res.Targets[2].Target)
+	assert.Equal(t, TargetFailed, res.Targets[2].Action)
+	assert.Contains(t, res.Targets[2].Error, "invalid patch")
+}
+
+func TestApplyDowngrade(t *testing.T) {
+	tests := []struct {
+		name            string
+		stored          string
+		version         string
+		allowDowngrade  bool
+		expectedErr     error
+		expectedVersion string
+	}{
+		{name: "first configuration", version: "7.0.0+driver", expectedVersion: "7.0.0+driver"},
+		{name: "upgrade", stored: "6.0.0+driver", version: "7.0.0+driver", expectedVersion: "7.0.0+driver"},
+		{name: "same version", stored: "7.0.0+driver", version: "7.0.0+driver", expectedVersion: "7.0.0+driver"},
+		{name: "downgrade blocked", stored: "7.0.0+driver", version: "6.0.0+driver", expectedErr: errDowngrade, expectedVersion: "7.0.0+driver"},
+		{name: "downgrade allowed", stored: "7.0.0+driver", version: "6.0.0+driver", allowDowngrade: true, expectedVersion: "6.0.0+driver"},
+		{name: "not a semver", stored: "master", version: "6.0.0+driver", expectedVersion: "6.0.0+driver"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newApplyTestRequest(t)
+			req.Update = false
+			req.Driver.Version = tt.version
+			req.AllowDowngrade = tt.allowDowngrade
+			if tt.stored != "" {
+				require.NoError(t, os.WriteFile(req.ConfigFile, []byte("driver:\n  type: [kmod]\n  version: "+tt.stored+"\n"), 0o600))
+			}
+
+			_, err := Apply(context.Background(), req)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			driverCfg, err := config.LoadDriver(req.ConfigFile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedVersion, driverCfg.Version)
+		})
+	}
+}
+
+func TestCheckDriverArtifact(t *testing.T) {
+	kr := kernelrelease.FromString("6.1.0-12-amd64")
+	kr.KernelVersion = "1"
+	kr.Architecture = kernelrelease.Architecture("amd64")
+	distro, err := driverdistro.Discover(kr, t.TempDir())
+	require.ErrorIs(t, err, driverdistro.ErrUnsupported)
+
+	tests := []struct {
+		name        string
+		driverType  string
+		installed   bool
+		require     bool
+		expectedErr bool
+		expectedLog string
+	}{
+		{name: "installed", driverType: drivertype.TypeKmod, installed: true},
+		{name: "missing", driverType: drivertype.TypeKmod, expectedLog: "Driver not found"},
+		{name: "missing required", driverType: drivertype.TypeBpf, require: true, expectedErr: true},
+		{name: "no artifacts", driverType: drivertype.TypeModernBpf, require: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostRoot := t.TempDir()
+			driverType, err := drivertype.Parse(tt.driverType)
+			require.NoError(t, err)
+			var out bytes.Buffer
+			o := driverConfigOptions{Request: Request{
+				Common: &options.Common{Printer: output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, &out)},
+				Driver: &options.Driver{
+					Type: driverType, Name: "falco", Version: "7.0.0+driver", Kr: kr, Distro: distro, HostRoot: hostRoot,
+				},
+				RequireDriver: tt.require,
+			}}
+			path := filepath.Join(hostRoot, driverdistro.LocalPath(distro, kr, "falco", driverType, "7.0.0+driver"))
+			if tt.installed {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				require.NoError(t, os.WriteFile(path, nil, 0o600))
+			}
+
+			err = o.checkDriverArtifact()
+			if tt.expectedErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), path)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.expectedLog != "" {
+				assert.Contains(t, out.String(), tt.expectedLog)
+			} else {
+				assert.NotContains(t, out.String(), "Driver not found")
+			}
+		})
+	}
+}
+
+func TestApplyAlreadyConfigured(t *testing.T) {
+	t.Run("falco config", func(t *testing.T) {
+		req := newApplyTestRequest(t)
+		req.Backup = true
+		req.FalcoConfig = filepath.Join(t.TempDir(), "falco.yaml")
+		require.NoError(t, os.WriteFile(req.FalcoConfig, []byte("engine:\n  kind: modern_ebpf\n"), 0o600))
+		old := time.Now().Add(-time.Hour).Truncate(time.Second)
+		require.NoError(t, os.Chtimes(req.FalcoConfig, old, old))
+
+		res, err := Apply(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Targets, 1)
+		assert.Equal(t, TargetUnchanged, res.Targets[0].Action)
+
+		// Neither written nor backed up.
+		stat, err := os.Stat(req.FalcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, old, stat.ModTime())
+		backups, err := listFalcoConfigBackups(req.FalcoConfig)
+		require.NoError(t, err)
+		assert.Empty(t, backups)
+
+		// The desired state is stored anyway.
+		driverCfg, err := config.LoadDriver(req.ConfigFile)
+		require.NoError(t, err)
+		assert.Equal(t, []string{drivertype.TypeModernBpf}, driverCfg.Type)
+	})
+
+	t.Run("configmaps", func(t *testing.T) {
+		var patched []string
+		cl := newFakeClient([]*corev1.ConfigMap{
+			newConfigMap("falco", drivertype.TypeModernBpf),
+			newConfigMap("falco-embedded", "engine:\n  kind: modern_ebpf\n"),
+		}, &patched)
+		allowAccessReviews(cl.(*fake.Clientset), "get", "list", "patch")
+
+		req := newApplyTestRequest(t)
+		req.Namespace = "falco"
+		req.KubeClient = cl
+		req.Verify = true
+		res, err := Apply(context.Background(), req)
+		require.NoError(t, err)
+		assert.Empty(t, patched)
+		assert.Empty(t, res.ConfigMaps)
+		require.Len(t, res.Targets, 2)
+		for _, target := range res.Targets {
+			assert.Equal(t, TargetUnchanged, target.Action, target.Target)
+		}
+	})
+}
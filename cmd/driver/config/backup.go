@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+const backupSuffix = ".bak-"
+
+// backupFalcoConfig writes a timestamped copy of the Falco configuration next to the original one,
+// returning the path of the backup.
+func backupFalcoConfig(falcoCfgFile string, content []byte, mode os.FileMode) (string, error) {
+	backup := falcoCfgFile + backupSuffix + time.Now().UTC().Format(time.RFC3339)
+	if err := os.WriteFile(backup, content, mode); err != nil {
+		return "", fmt.Errorf("unable to back up Falco configuration %q: %w", falcoCfgFile, err)
+	}
+	return backup, nil
+}
+
+// listFalcoConfigBackups returns the backups of the Falco configuration, from the most recent to the oldest.
+func listFalcoConfigBackups(falcoCfgFile string) ([]string, error) {
+	matches, err := filepath.Glob(falcoCfgFile + backupSuffix + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		path string
+		time time.Time
+	}
+	backups := make([]backup, 0, len(matches))
+	prefix := falcoCfgFile + backupSuffix
+	for _, match := range matches {
+		t, err := time.Parse(time.RFC3339, strings.TrimPrefix(match, prefix))
+		if err != nil {
+			// Not a backup made by us.
+			continue
+		}
+		backups = append(backups, backup{path: match, time: t})
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths, nil
+}
+
+// restoreFalcoConfig overwrites the Falco configuration with the content of the given backup.
+func restoreFalcoConfig(falcoCfgFile, backup string) error {
+	content, err := os.ReadFile(filepath.Clean(backup))
+	if err != nil {
+		return fmt.Errorf("unable to read backup %q: %w", backup, err)
+	}
+	// Keep the permissions of the current configuration, if any.
+	stat, err := os.Stat(falcoCfgFile)
+	if err != nil {
+		if stat, err = os.Stat(backup); err != nil {
+			return err
+		}
+	}
+	return utils.WriteFileAtomic(falcoCfgFile, content, stat.Mode())
+}
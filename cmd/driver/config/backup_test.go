@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+func TestReplaceDriverTypeInFalcoConfigBackup(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	original := "engine:\n  kind: kmod\n"
+
+	falcoConfig := filepath.Join(t.TempDir(), "falco.yaml")
+	require.NoError(t, os.WriteFile(falcoConfig, []byte(original), 0o600))
+
+	o := newTestOptions(true)
+	o.FalcoConfig = falcoConfig
+	o.Backup = true
+	require.NoError(t, o.replaceDriverTypeInFalcoConfig(driverType))
+
+	backups, err := listFalcoConfigBackups(falcoConfig)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	data, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+
+	data, err = os.ReadFile(falcoConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "engine:\n  kind: modern_ebpf\n", string(data))
+
+	r := &driverConfigRestoreOptions{Common: o.Common, Driver: o.Driver, FalcoConfig: falcoConfig}
+	require.NoError(t, r.RunDriverConfigRestore(""))
+	data, err = os.ReadFile(falcoConfig)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+func TestListFalcoConfigBackups(t *testing.T) {
+	dir := t.TempDir()
+	falcoConfig := filepath.Join(dir, "falco.yaml")
+	for _, name := range []string{
+		"falco.yaml.bak-2024-01-02T10:00:00Z",
+		"falco.yaml.bak-2024-03-01T10:00:00Z",
+		"falco.yaml.bak-2023-12-31T10:00:00Z",
+		"falco.yaml.bak-manual",
+		"falco_rules.yaml.bak-2024-05-01T10:00:00Z",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600))
+	}
+
+	backups, err := listFalcoConfigBackups(falcoConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		falcoConfig + ".bak-2024-03-01T10:00:00Z",
+		falcoConfig + ".bak-2024-01-02T10:00:00Z",
+		falcoConfig + ".bak-2023-12-31T10:00:00Z",
+	}, backups)
+}
+
+func TestRunDriverConfigRestore(t *testing.T) {
+	dir := t.TempDir()
+	falcoConfig := filepath.Join(dir, "falco.yaml")
+	require.NoError(t, os.WriteFile(falcoConfig, []byte("engine:\n  kind: modern_ebpf\n"), 0o600))
+	require.NoError(t, os.WriteFile(falcoConfig+".bak-2024-01-02T10:00:00Z", []byte("engine:\n  kind: ebpf\n"), 0o600))
+	require.NoError(t, os.WriteFile(falcoConfig+".bak-2024-03-01T10:00:00Z", []byte("engine:\n  kind: kmod\n"), 0o600))
+
+	o := newTestOptions(true)
+	r := &driverConfigRestoreOptions{Common: o.Common, Driver: o.Driver, FalcoConfig: falcoConfig}
+
+	t.Run("list", func(t *testing.T) {
+		r.List = true
+		defer func() { r.List = false }()
+		require.NoError(t, r.RunDriverConfigRestore(""))
+		data, err := os.ReadFile(falcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "engine:\n  kind: modern_ebpf\n", string(data))
+	})
+
+	t.Run("given backup", func(t *testing.T) {
+		require.NoError(t, r.RunDriverConfigRestore("falco.yaml.bak-2024-01-02T10:00:00Z"))
+		data, err := os.ReadFile(falcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "engine:\n  kind: ebpf\n", string(data))
+	})
+
+	t.Run("most recent backup", func(t *testing.T) {
+		require.NoError(t, r.RunDriverConfigRestore(""))
+		data, err := os.ReadFile(falcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "engine:\n  kind: kmod\n", string(data))
+	})
+
+	t.Run("missing backup", func(t *testing.T) {
+		assert.ErrorContains(t, r.RunDriverConfigRestore("falco.yaml.bak-foo"), "unable to find backup")
+	})
+
+	t.Run("no backups", func(t *testing.T) {
+		empty := &driverConfigRestoreOptions{Common: o.Common, Driver: o.Driver, FalcoConfig: filepath.Join(dir, "other.yaml")}
+		assert.ErrorContains(t, empty.RunDriverConfigRestore(""), "no backups found")
+	})
+}
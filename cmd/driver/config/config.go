@@ -16,17 +16,30 @@
 package driverconfig
 
 import (
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"golang.org/x/net/context"
-	"gopkg.in/yaml.v3"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -34,32 +47,60 @@ import (
 
 	"github.com/falcosecurity/falcoctl/internal/config"
 	"github.com/falcosecurity/falcoctl/internal/utils"
+	driverdistro "github.com/falcosecurity/falcoctl/pkg/driver/distro"
 	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/enum"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 )
 
 const (
 	configMapEngineKindKey = "engine.kind"
+	defaultInstanceLabel   = "app.kubernetes.io/instance=falco"
+	defaultFalcoConfig     = "/etc/falco/falco.yaml"
+	defaultMaxParallel     = 8
+	defaultTimeout         = 30 * time.Second
+	defaultLockTimeout     = 10 * time.Second
+	lockSuffix             = ".lock"
 	longConfig             = `Configure a driver for future usages with other driver subcommands.
 It will also update local Falco configuration or k8s configmap depending on the environment where it is running, to let Falco use chosen driver.
 Only supports deployments of Falco that use a driver engine, ie: one between kmod, ebpf and modern-ebpf.
 If engine.kind key is set to a non-driver driven engine, Falco configuration won't be touched.
+Use --strict to fail instead of skipping Falco configurations that do not run with a driver.
+When a namespace (or --all-namespaces) is given, the configmaps are updated, unless --target selects the daemonsets driver env vars;
+the permissions to list and patch the target resources are checked before updating them.
+When Falco is managed by the Falco operator, --target=operator sets the engine kind in its Falco custom resources instead,
+so that the change survives the reconciliation of the configmaps by the operator.
+The Kubernetes API server is reached through the proxy set by the HTTPS_PROXY and NO_PROXY env variables, if any,
+and --ca-file can provide the CAs verifying it.
+On OpenShift, --openshift finds the Falco deployment from the Falco operator instance, falling back to the namespace
+falcoctl runs in (or the falco one) when the operator is not installed.
+Unless --backup=false is given, a timestamped copy of the Falco config file is saved next to it before updating it;
+use the restore subcommand to reinstate it.
+Use --engine-only to only update Falco, or --update-falco=false to only store the driver configuration.
+The default namespace and kubeconfig can be set in the falcoctl config file, as driver.config.namespace and driver.config.kubeconfig,
+or through the FALCOCTL_DRIVER_CONFIG_NAMESPACE and FALCOCTL_DRIVER_CONFIG_KUBECONFIG env variables.
+Use --quiet to only log the errors; with --output, stdout only holds the structured outcome, the logs going to stderr.
 `
 )
 
 type driverConfigOptions struct {
-	*options.Common
-	*options.Driver
-	Update     bool
-	Namespace  string
-	KubeConfig string
+	Request
+	Output string
+	// Quiet only logs the errors.
+	Quiet      bool
+	targetFlag *enum.Enum
+	result     Result
+	// errOut receives the logs when the outcome is printed as structured output, defaulting to stderr.
+	errOut io.Writer
+	// falcoConfigHashes are the fingerprints of the local Falco config file, set when editing it.
+	falcoConfigHashes contentHashes
 }
 
 // NewDriverConfigCmd configures a driver and stores it in config.
 func NewDriverConfigCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
 	o := driverConfigOptions{
-		Common: opt,
-		Driver: driver,
+		Request:    NewRequest(opt, driver),
+		targetFlag: enum.NewEnum(targets, targetConfigMap),
 	}
 
 	cmd := &cobra.Command{
@@ -67,32 +108,228 @@ func NewDriverConfigCmd(ctx context.Context, opt *options.Common, driver *option
 		DisableFlagsInUseLine: true,
 		Short:                 "Configure a driver",
 		Long:                  longConfig,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.validateOutput(); err != nil {
+				return err
+			}
+			return setFlagDefaults(cmd)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			o.errOut = cmd.ErrOrStderr()
 			return o.RunDriverConfig(ctx)
 		},
 	}
 
-	cmd.Flags().BoolVar(&o.Update, "update-falco", true, "Whether to update Falco config/configmap.")
-	cmd.Flags().StringVar(&o.Namespace, "namespace", "", "Kubernetes namespace.")
+	cmd.Flags().BoolVar(&o.Update, "update-falco", true,
+		"Whether to update Falco config/configmap. If false, only the driver configuration is stored.")
+	cmd.Flags().BoolVar(&o.EngineOnly, "engine-only", false,
+		"Only update Falco config/configmap, without storing the driver configuration. Cannot be used with --update-falco=false.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "Only print the changes to Falco config/configmap, without applying them.")
+	cmd.Flags().BoolVar(&o.Backup, "backup", true, "Whether to back up the Falco config file before updating it.")
+	cmd.Flags().BoolVar(&o.Restart, "restart", false,
+		"Restart the Falco systemd service after updating the Falco config file, so that the new driver is used.")
+	cmd.Flags().BoolVar(&o.Rollout, "rollout", false,
+		"Restart Falco after a successful update: the daemonsets and deployments of the namespaces of the updated configmaps, "+
+			"or the Falco systemd service when updating the Falco config file.")
+	cmd.Flags().BoolVar(&o.AllowDowngrade, "allow-downgrade", false,
+		"Allow storing a driver version older than the stored one.")
+	cmd.Flags().BoolVar(&o.SkipValidation, "skip-validation", false,
+		"Skip checking that the driver type is supported by the target kernel, and was installed.")
+	cmd.Flags().BoolVar(&o.Strict, "strict", false, "Fail, with exit code 2, instead of skipping Falco config/configmaps that do not run with a driver.")
+	cmd.Flags().BoolVar(&o.RequireDriver, "require-driver", false,
+		"Fail, instead of warning, when the driver of the configured type was not installed under the host root.")
+	cmd.Flags().StringVar(&o.MatchContext, "match-context", "",
+		"Regex matched against the comments preceding each engine block, to select the engine.kind to edit (prefix with '!' to negate). "+
+			"Defaults to the top-level one.")
+	cmd.Flags().BoolVar(&o.CreateIfMissing, "create-if-missing", false,
+		"Create a minimal Falco config file, only setting engine.kind, when it does not exist.")
+	cmd.Flags().StringVar(&o.FalcoConfig, "falco-config", defaultFalcoConfig,
+		"Path of the Falco configuration file to update, relative to the driver host root.")
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "", "Kubernetes namespace, or comma separated list of namespaces.")
+	cmd.Flags().Var(o.targetFlag, "target", "Kubernetes resources to update when a namespace is given: the configmaps engine.kind, "+
+		"the daemonsets driver env vars (restarting them), auto to fall back to the daemonsets when no configmap was updated, "+
+		"or the Falco custom resources of the Falco operator "+o.targetFlag.Allowed())
+	cmd.Flags().BoolVar(&o.AllNamespaces, "all-namespaces", false, "Update the Falco configmaps in all the Kubernetes namespaces.")
 	cmd.Flags().StringVar(&o.KubeConfig, "kubeconfig", "", "Kubernetes config.")
+	cmd.Flags().StringVar(&o.KubeContext, "context", "", "Kubernetes context to use, instead of the current one of the kubeconfig.")
+	cmd.Flags().BoolVar(&o.OpenShift, "openshift", false,
+		"Discover the namespace and the configmaps of Falco from the Falco operator instance, when no namespace is given.")
+	cmd.Flags().StringVar(&o.CAFile, "ca-file", "", "PEM bundle of the CAs verifying the Kubernetes API server, instead of the ones of the kubeconfig.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", defaultTimeout,
+		"Maximum time spent updating the Kubernetes resources, 0 meaning no limit.")
+	cmd.Flags().DurationVar(&o.LockTimeout, "lock-timeout", defaultLockTimeout,
+		"Maximum time waiting for other falcoctl runs to release the lock of the Falco config file.")
+	cmd.Flags().IntVar(&o.Retries, "retries", defaultRetries,
+		"Number of retries of the Kubernetes API calls failing with transient errors (conflicts, timeouts, throttling).")
+	cmd.Flags().DurationVar(&o.RetryInterval, "retry-interval", defaultRetryInterval,
+		"Interval before the first retry of a Kubernetes API call, doubled at each retry.")
+	cmd.Flags().IntVar(&o.MaxParallel, "max-parallel", defaultMaxParallel, "Maximum number of Falco configmaps patched concurrently.")
+	cmd.Flags().StringVar(&o.InstanceLabel, "instance-label", defaultInstanceLabel,
+		"Label, in the \"key=value\" format, selecting the Falco configmaps to update (e.g. to match a different Helm release name).")
+	cmd.Flags().BoolVar(&o.Watch, "watch", false,
+		"Keep running after the update, patching again the Falco configmaps whose engine.kind drifts from the driver type.")
+	cmd.Flags().DurationVar(&o.ResyncPeriod, "resync-period", defaultResyncPeriod,
+		"Interval at which all the watched Falco configmaps are checked again, 0 meaning only on changes.")
+	cmd.Flags().BoolVar(&o.EmitEvents, "emit-events", false,
+		"Record a Kubernetes event on each updated Falco configmap (requires the permission to create events).")
+	cmd.Flags().BoolVar(&o.Force, "force", false,
+		"Update the Falco configmaps even when they currently run different driver types.")
+	cmd.Flags().BoolVar(&o.Verify, "verify", true,
+		"Read back the patched Falco configmaps, failing if they do not hold the new driver type (e.g. rewritten by a webhook).")
+	cmd.Flags().StringVar(&o.ConfigMapKey, "configmap-key", configMapEngineKindKey,
+		"Data key of the Falco configmaps holding either the engine kind or the whole Falco configuration (e.g. falco.yaml).")
+	cmd.Flags().StringVar(&o.Selector, "selector", "",
+		"Label selector of the Falco configmaps (or daemonsets) to update, instead of the instance label.")
+	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", "",
+		"Field selector further filtering the Falco configmaps (or daemonsets) to update (e.g. metadata.name=falco).")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "",
+		"Print the outcome as structured output instead of logs, the errors being logged to stderr. One of 'yaml' or 'json'")
+	cmd.Flags().BoolVarP(&o.Quiet, "quiet", "q", false, "Only log the errors.")
+	cmd.MarkFlagsMutuallyExclusive("namespace", "all-namespaces")
+	cmd.MarkFlagsMutuallyExclusive("selector", "instance-label")
+
+	cmd.AddCommand(newDriverConfigRestoreCmd(opt, driver))
+	cmd.AddCommand(newDriverConfigShowCmd(ctx, opt, driver))
+	cmd.AddCommand(newDriverConfigDiffCmd(opt, driver))
+	cmd.AddCommand(newDriverConfigHistoryCmd(opt))
+	cmd.AddCommand(newDriverConfigValidateCmd(ctx, opt, driver))
 	return cmd
 }
 
+// flagDefaults are the flags of the driver config commands whose defaults can be set in the falcoctl config file.
+var flagDefaults = []struct {
+	flag string
+	key  string
+}{
+	{flag: "namespace", key: config.DriverConfigNamespaceKey},
+	{flag: "kubeconfig", key: config.DriverConfigKubeConfigKey},
+}
+
+// setFlagDefaults sets the flags not given by the user to the values of the falcoctl config file,
+// or of the FALCOCTL_DRIVER_CONFIG_* env variables, as the driver command does for the driver flags.
+// The default namespace is ignored when the Falco deployment is otherwise selected.
+func setFlagDefaults(cmd *cobra.Command) error {
+	for _, d := range flagDefaults {
+		f := cmd.Flags().Lookup(d.flag)
+		if f == nil || f.Changed || !viper.IsSet(d.key) {
+			continue
+		}
+		if d.flag == "namespace" && (cmd.Flags().Changed("all-namespaces") || cmd.Flags().Changed("openshift")) {
+			continue
+		}
+		if err := cmd.Flags().Set(d.flag, viper.GetString(d.key)); err != nil {
+			return fmt.Errorf("unable to overwrite %q flag: %w", d.flag, err)
+		}
+	}
+	return nil
+}
+
 // RunDriverConfig implements the driver configuration command.
 func (o *driverConfigOptions) RunDriverConfig(ctx context.Context) error {
-	o.Printer.Logger.Info("Running falcoctl driver config", o.Printer.Logger.Args(
-		"name", o.Driver.Name,
-		"version", o.Driver.Version,
-		"type", o.Driver.Type.String(),
-		"host-root", o.Driver.HostRoot,
-		"repos", strings.Join(o.Driver.Repos, ",")))
-
-	if o.Update {
-		if err := o.commit(ctx, o.Driver.Type); err != nil {
-			return err
+	if o.Quiet || o.Output != "" {
+		// The outcome is reported through the structured output, or not at all, only keep the errors.
+		o.keepErrorLogs()
+	}
+	if o.Output != "" {
+		// Keep stdout for the structured output only, so that it can be piped.
+		errOut := o.errOut
+		if errOut == nil {
+			errOut = os.Stderr
 		}
+		o.Printer.Logger = o.Printer.Logger.WithWriter(errOut)
+	}
+	if o.targetFlag != nil {
+		o.Target = o.targetFlag.Value
+	}
+	res, err := Apply(ctx, o.Request)
+	o.result = res
+	o.logTargets(res.Targets)
+	if errors.Is(err, ErrRollout) && o.Output != "" {
+		// The Falco configurations were updated, report them along with the rollout errors.
+		if printErr := o.printResult(); printErr != nil {
+			return printErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if o.Output != "" {
+		return o.printResult()
+	}
+	return nil
+}
+
+// keepErrorLogs raises the log level to errors, unless the logs are already more restricted.
+func (o *driverConfigOptions) keepErrorLogs() {
+	if o.Printer.Logger.Level != pterm.LogLevelDisabled && o.Printer.Logger.Level < pterm.LogLevelError {
+		o.Printer.Logger.Level = pterm.LogLevelError
 	}
-	return config.StoreDriver(o.Driver.ToDriverConfig(), o.ConfigFile)
+}
+
+// validateDriverType checks that the driver type is supported by the target kernel,
+// without downloading anything.
+func (o *driverConfigOptions) validateDriverType() error {
+	if o.Driver.Type.Supported(o.Driver.Kr) {
+		return nil
+	}
+	types := drivertype.GetTypes()
+	sort.Strings(types)
+	supported := make([]string, 0, len(types))
+	for _, t := range types {
+		if dType, err := drivertype.Parse(t); err == nil && dType.Supported(o.Driver.Kr) {
+			supported = append(supported, t)
+		}
+	}
+	return fmt.Errorf("driver type %q is not supported by kernel %s (%s), supported types: [%s]",
+		o.Driver.Type.String(), o.Driver.Kr.String(), o.Driver.Kr.Architecture.ToNonDeb(), strings.Join(supported, ", "))
+}
+
+// checkDriverArtifact checks that the driver of the configured type, for the discovered distro
+// and the target kernel, was downloaded or built by driver install under the host root: without it,
+// Falco fails to start. A missing driver is a warning, or an error with RequireDriver. Types without
+// artifacts, like modern_ebpf, are not checked.
+func (o *driverConfigOptions) checkDriverArtifact() error {
+	if !o.Driver.Type.HasArtifacts() {
+		return nil
+	}
+	if o.Driver.Distro == nil || o.Driver.Version == "" {
+		o.Printer.Logger.Debug("Unknown distro or driver version, driver not checked")
+		return nil
+	}
+	path, err := utils.ResolveHostPath(o.Driver.HostRoot,
+		driverdistro.LocalPath(o.Driver.Distro, o.Driver.Kr, o.Driver.Name, o.Driver.Type, o.Driver.Version))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(path)
+	switch {
+	case err == nil:
+		return nil
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("unable to check driver %q: %w", path, err)
+	case o.RequireDriver:
+		return fmt.Errorf("driver %s not found at %q, run driver install first", o.Driver.Type.String(), path)
+	default:
+		o.Printer.Logger.Warn("Driver not found, run driver install first or Falco will fail to load it",
+			o.Printer.Logger.Args("type", o.Driver.Type.String(), "path", path))
+		return nil
+	}
+}
+
+// ErrEngineNotDriverDriven is returned, wrapped, when the Falco configuration is not updated
+// because it does not run with a driver. It is only returned by the config command in strict mode,
+// otherwise the update is skipped with a warning.
+var ErrEngineNotDriverDriven = errors.New("engine.kind is not driver driven")
+
+// engineKindError is an engine.kind error matching ErrEngineNotDriverDriven.
+type engineKindError string
+
+func (e engineKindError) Error() string {
+	return string(e)
+}
+
+func (e engineKindError) Is(target error) bool {
+	return target == ErrEngineNotDriverDriven
 }
 
 func checkFalcoRunsWithDrivers(engineKind string) error {
@@ -100,102 +337,614 @@ func checkFalcoRunsWithDrivers(engineKind string) error {
 	// This ensures that we modify the config only for Falcos running with drivers, and not plugins/gvisor.
 	// Scenario: user has multiple Falco pods deployed in its cluster, one running with driver,
 	// other running with plugins. We must only touch the one running with driver.
+	if engineKind == "" {
+		return engineKindError("engine.kind is not set")
+	}
 	if _, err := drivertype.Parse(engineKind); err != nil {
-		return fmt.Errorf("engine.kind is not driver driven: %s", engineKind)
+		return fmt.Errorf("%w: %s", ErrEngineNotDriverDriven, engineKind)
 	}
 	return nil
 }
 
+// canonicalEngineKind returns the canonical form of a driver driven engine kind, e.g. "kmod" for " Kmod",
+// or the kind itself otherwise.
+func canonicalEngineKind(engineKind string) string {
+	if dType, err := drivertype.Parse(engineKind); err == nil {
+		return dType.String()
+	}
+	return engineKind
+}
+
+// falcoConfigPath returns the path of the Falco configuration file, honoring the driver host root.
+func falcoConfigPath(hostRoot, falcoConfig string) (string, error) {
+	if falcoConfig == "" {
+		falcoConfig = defaultFalcoConfig
+	}
+	return utils.ResolveHostPath(hostRoot, falcoConfig)
+}
+
 func (o *driverConfigOptions) replaceDriverTypeInFalcoConfig(driverType drivertype.DriverType) error {
-	falcoCfgFile := filepath.Clean(filepath.Join(string(os.PathSeparator), "etc", "falco", "falco.yaml"))
-	type engineCfg struct {
-		Kind string `yaml:"kind"`
+	falcoCfgFile, err := falcoConfigPath(o.Driver.HostRoot, o.FalcoConfig)
+	if err != nil {
+		return err
+	}
+	o.result.FalcoConfig = falcoCfgFile
+	matcher, err := newContextMatcher(o.MatchContext)
+	if err != nil {
+		return err
 	}
-	type falcoCfg struct {
-		Engine engineCfg `yaml:"engine"`
+	if _, err := os.Stat(falcoCfgFile); errors.Is(err, os.ErrNotExist) {
+		if !o.CreateIfMissing {
+			return fmt.Errorf("unable to find Falco configuration %q, use --falco-config to point at the Falco config file, "+
+				"or --create-if-missing to create it: %w", falcoCfgFile, err)
+		}
+		if !o.DryRun {
+			// The lock file lives next to the Falco config file.
+			if err := os.MkdirAll(filepath.Dir(falcoCfgFile), 0o755); err != nil { //nolint:gosec // the Falco config dir is world readable
+				return fmt.Errorf("unable to create Falco configuration directory: %w", err)
+			}
+		}
+	}
+	if !o.DryRun {
+		// Hold the lock from the read to the write, so that concurrent runs do not lose updates.
+		unlock, err := utils.LockFile(falcoCfgFile+lockSuffix, o.LockTimeout)
+		if err != nil {
+			return fmt.Errorf("unable to lock Falco configuration %q, another falcoctl may be updating it: %w", falcoCfgFile, err)
+		}
+		defer func() { _ = unlock() }()
+	}
+	stat, err := os.Stat(falcoCfgFile)
+	if errors.Is(err, os.ErrNotExist) && o.CreateIfMissing {
+		return o.createFalcoConfig(falcoCfgFile, driverType)
 	}
-	yamlFile, err := os.ReadFile(filepath.Clean(falcoCfgFile))
 	if err != nil {
 		return err
 	}
-	cfg := falcoCfg{}
-	if err = yaml.Unmarshal(yamlFile, &cfg); err != nil {
+	yamlFile, err := os.ReadFile(falcoCfgFile)
+	if err != nil {
 		return err
 	}
-	if err = checkFalcoRunsWithDrivers(cfg.Engine.Kind); err != nil {
-		o.Printer.Logger.Warn("Avoid updating Falco configuration",
-			o.Printer.Logger.Args("config", falcoCfgFile, "reason", err))
+	edited, previous, err := editEngineKind(string(yamlFile), matcher, driverType.String())
+	if err != nil {
+		return fmt.Errorf("unable to update Falco configuration %q: %w", falcoCfgFile, err)
+	}
+	o.falcoConfigHashes = contentHashes{previous: contentHash(yamlFile), current: contentHash([]byte(edited))}
+	if o.falcoConfigHashes.unchanged() {
+		// Nothing to write, nor to back up.
+		o.Printer.Logger.Info("Falco configuration already configured", o.Printer.Logger.Args(
+			"config", falcoCfgFile, "type", driverType.String()))
+		return nil
+	}
+	if o.DryRun {
+		o.Printer.Logger.Info("Would update Falco configuration", o.Printer.Logger.Args(
+			"config", falcoCfgFile, "current", previous, "new", driverType.String()))
 		return nil
 	}
-	const configKindKey = "kind: "
-	return utils.ReplaceTextInFile(falcoCfgFile, configKindKey+cfg.Engine.Kind, configKindKey+driverType.String(), 1)
+	if o.Backup {
+		backup, err := backupFalcoConfig(falcoCfgFile, yamlFile, stat.Mode())
+		if err != nil {
+			return err
+		}
+		o.Printer.Logger.Info("Backed up Falco configuration", o.Printer.Logger.Args("config", falcoCfgFile, "backup", backup))
+	}
+	return utils.WriteFileAtomic(falcoCfgFile, []byte(edited), stat.Mode())
 }
 
-func (o *driverConfigOptions) replaceDriverTypeInK8SConfigMap(ctx context.Context, driverType drivertype.DriverType) error {
-	var (
-		err error
-		cfg *rest.Config
-	)
-
-	if o.KubeConfig != "" {
-		cfg, err = clientcmd.BuildConfigFromFlags("", o.KubeConfig)
-	} else {
-		cfg, err = rest.InClusterConfig()
+// createFalcoConfig writes a minimal Falco config file, only setting the engine kind to the driver type.
+func (o *driverConfigOptions) createFalcoConfig(falcoCfgFile string, driverType drivertype.DriverType) error {
+	if o.DryRun {
+		o.Printer.Logger.Info("Would create Falco configuration", o.Printer.Logger.Args(
+			"config", falcoCfgFile, "new", driverType.String()))
+		return nil
+	}
+	content := fmt.Sprintf("engine:\n  kind: %s\n", driverType.String())
+	o.falcoConfigHashes = contentHashes{current: contentHash([]byte(content))}
+	if err := utils.WriteFileAtomic(falcoCfgFile, []byte(content), 0o644); err != nil { //nolint:gosec // as Falco packages do
+		return fmt.Errorf("unable to create Falco configuration %q: %w", falcoCfgFile, err)
 	}
+	o.Printer.Logger.Info("Created Falco configuration", o.Printer.Logger.Args("config", falcoCfgFile, "new", driverType.String()))
+	return nil
+}
+
+func (o *driverConfigOptions) replaceDriverTypeInK8S(ctx context.Context, driverType drivertype.DriverType) error {
+	cl, err := o.kubeClient()
 	if err != nil {
 		return err
 	}
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+	if o.OpenShift && o.Namespace == "" && !o.AllNamespaces {
+		if err := o.discoverOpenShiftFalco(ctx); err != nil {
+			return fmt.Errorf("unable to discover the Falco deployment: %w", err)
+		}
+	}
+	err = o.replaceDriverTypeInTargets(ctx, cl, driverType)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("the Kubernetes API server did not answer within %s, consider increasing --timeout: %w", o.Timeout, err)
+	}
+	return err
+}
+
+// kubeClient returns the given client, or one built from the given kubeconfig, or from the in-cluster config.
+func (o *driverConfigOptions) kubeClient() (kubernetes.Interface, error) {
+	if o.KubeClient != nil {
+		return o.KubeClient, nil
+	}
+	cfg, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(cfg)
+}
+
+// restConfig returns the config of the Kubernetes client, honoring the standard proxy env variables
+// and the custom CA bundle, if any.
+func (o *driverConfigOptions) restConfig() (*rest.Config, error) {
+	cfg, err := o.loadRestConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Proxy = proxyFromEnvironment()
+	if o.CAFile != "" {
+		if err := checkCAFile(o.CAFile); err != nil {
+			return nil, err
+		}
+		cfg.CAFile = o.CAFile
+		// CAData has precedence over CAFile.
+		cfg.CAData = nil
+	}
+	return cfg, nil
+}
 
-	cl, err := kubernetes.NewForConfig(cfg)
+func (o *driverConfigOptions) loadRestConfig() (*rest.Config, error) {
+	switch {
+	case o.KubeContext != "":
+		// Load the kubeconfig as kubectl does, selecting the given context.
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		loadingRules.ExplicitPath = o.KubeConfig
+		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+			&clientcmd.ConfigOverrides{CurrentContext: o.KubeContext}).ClientConfig()
+	case o.KubeConfig != "":
+		return clientcmd.BuildConfigFromFlags("", o.KubeConfig)
+	default:
+		return rest.InClusterConfig()
+	}
+}
+
+// proxyFromEnvironment returns the proxy set by the HTTPS_PROXY and NO_PROXY env variables (or their lowercase forms).
+// Unlike http.ProxyFromEnvironment, the env variables are read when the client is built, not once per process.
+func proxyFromEnvironment() func(*http.Request) (*url.URL, error) {
+	proxyFunc := httpproxy.FromEnvironment().ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// checkCAFile makes sure the given file is a valid PEM bundle of certificates.
+func checkCAFile(caFile string) error {
+	data, err := os.ReadFile(filepath.Clean(caFile))
+	if err != nil {
+		return fmt.Errorf("unable to read CA file: %w", err)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(data) {
+		return fmt.Errorf("CA file %q does not contain any valid PEM certificate", caFile)
+	}
+	return nil
+}
+
+// listOptions returns the options listing the resources of the Falco instance: the ones matching the given selectors,
+// or the instance label if no label selector is given.
+func (o *driverConfigOptions) listOptions() (metav1.ListOptions, error) {
+	labelSelector := o.Selector
+	if labelSelector != "" {
+		if _, err := labels.Parse(labelSelector); err != nil {
+			return metav1.ListOptions{}, fmt.Errorf("invalid label selector %q: %w", labelSelector, err)
+		}
+	} else {
+		labelSelector = o.InstanceLabel
+		if labelSelector == "" {
+			labelSelector = defaultInstanceLabel
+		}
+		if _, err := labels.Parse(labelSelector); err != nil {
+			return metav1.ListOptions{}, fmt.Errorf("invalid instance label %q: %w", labelSelector, err)
+		}
+	}
+	if o.FieldSelector != "" {
+		if _, err := fields.ParseSelector(o.FieldSelector); err != nil {
+			return metav1.ListOptions{}, fmt.Errorf("invalid field selector %q: %w", o.FieldSelector, err)
+		}
+	}
+	return metav1.ListOptions{LabelSelector: labelSelector, FieldSelector: o.FieldSelector}, nil
+}
+
+// describeSelectors describes the selectors of the list options, for the error messages.
+func describeSelectors(opts metav1.ListOptions) string {
+	if opts.FieldSelector == "" {
+		return fmt.Sprintf("%q label", opts.LabelSelector)
+	}
+	return fmt.Sprintf("%q label and %q field selector", opts.LabelSelector, opts.FieldSelector)
+}
+
+// noMatchesError is returned when no Falco resources match the selectors.
+type noMatchesError struct {
+	resource string
+	opts     metav1.ListOptions
+}
+
+func (e *noMatchesError) Error() string {
+	return fmt.Sprintf("no %s matching %s were found", e.resource, describeSelectors(e.opts))
+}
+
+func (o *driverConfigOptions) replaceDriverTypeInConfigMaps(ctx context.Context, cl kubernetes.Interface, driverType drivertype.DriverType) error {
+	listOpts, err := o.listOptions()
 	if err != nil {
 		return err
 	}
+	configMaps, listErr := o.listConfigMaps(ctx, cl, listOpts)
+	if len(configMaps) == 0 {
+		if listErr != nil {
+			return listErr
+		}
+		return &noMatchesError{resource: "configmaps", opts: listOpts}
+	}
 
-	configMapList, err := cl.CoreV1().ConfigMaps(o.Namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: "app.kubernetes.io/instance: falco",
-	})
+	matcher, err := newContextMatcher(o.MatchContext)
 	if err != nil {
 		return err
 	}
-	if configMapList.Size() == 0 {
-		return errors.New(`no configmaps matching "app.kubernetes.io/instance: falco" label were found`)
+	type configMapPatch struct {
+		configMap corev1.ConfigMap
+		current   string
+		payload   []byte
+		hashes    contentHashes
+	}
+	// Collect the configMaps to be patched first, so that in strict mode
+	// we fail before touching any of them.
+	toPatch := make([]configMapPatch, 0, len(configMaps))
+	for i := range configMaps {
+		configMap := configMaps[i]
+		edited, currEngineKind, err := o.editConfigMapEngineKind(&configMap, matcher, driverType.String())
+		if err != nil {
+			if o.Strict {
+				o.result.addTarget(configMapTarget(&configMap), TargetFailed, err)
+				return fmt.Errorf("unable to update Falco configMap %q: %w", configMap.Name, err)
+			}
+			o.Printer.Logger.Warn("Avoid updating Falco configMap",
+				o.Printer.Logger.Args("configMap", configMap.Name, "namespace", configMap.Namespace, "reason", err))
+			o.result.addTarget(configMapTarget(&configMap), TargetSkipped, err)
+			o.result.skip(err)
+			continue
+		}
+		toPatch = append(toPatch, configMapPatch{
+			configMap: configMap,
+			current:   currEngineKind,
+			payload:   o.configMapPatch(edited),
+			hashes:    contentHashes{previous: contentHash([]byte(configMap.Data[o.configMapKey()])), current: contentHash([]byte(edited))},
+		})
+	}
+	currentKinds := make([]string, 0, len(toPatch))
+	for _, p := range toPatch {
+		currentKinds = append(currentKinds, p.current)
+	}
+	if err := o.checkMixedEngineKinds(currentKinds, driverType); err != nil {
+		return err
+	}
+
+	// The configMaps already configured are not patched, avoiding needless generation bumps.
+	pending := toPatch[:0]
+	for _, p := range toPatch {
+		if !p.hashes.unchanged() {
+			pending = append(pending, p)
+			continue
+		}
+		o.result.addTarget(configMapTarget(&p.configMap), TargetUnchanged, nil).setHashes(p.hashes)
+		o.Printer.Logger.Info("Falco configMap already configured", o.Printer.Logger.Args(
+			"configMap", p.configMap.Name, "namespace", p.configMap.Namespace, "type", driverType.String()))
 	}
+	toPatch = pending
 
+	patchOpts := metav1.PatchOptions{}
+	if o.DryRun {
+		// Let the API server validate the patch, without persisting it.
+		patchOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	// The patches and the options are shared by the workers: they are never modified once built.
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	g := errgroup.Group{}
+	g.SetLimit(o.maxParallel())
+	for _, p := range toPatch {
+		configMap := p.configMap
+		g.Go(func() error {
+			// Patch the configMap
+			err := o.withRetries(ctx, func() error {
+				_, err := cl.CoreV1().ConfigMaps(configMap.Namespace).Patch(
+					ctx, configMap.Name, types.JSONPatchType, p.payload, patchOpts)
+				return err
+			})
+			if err != nil {
+				err = fmt.Errorf("unable to patch configMap %q in namespace %q: %w", configMap.Name, configMap.Namespace, err)
+			} else if o.Verify && !o.DryRun {
+				err = o.verifyConfigMap(ctx, cl, &configMap, matcher, driverType.String())
+			}
+			if err == nil && o.EmitEvents && !o.DryRun {
+				o.emitConfigMapEvent(ctx, cl, &configMap, p.current, driverType.String())
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			o.result.addTarget(configMapTarget(&configMap), targetAction(err), err).setHashes(p.hashes)
+			if err != nil {
+				// Keep going with the other configMaps, reporting all the failures at the end.
+				errs = append(errs, err)
+				return nil
+			}
+			if o.DryRun {
+				o.Printer.Logger.Info("Would update Falco configMap", o.Printer.Logger.Args(
+					"configMap", configMap.Name, "namespace", configMap.Namespace,
+					"current", p.current, "new", driverType.String()))
+			}
+			return nil
+		})
+	}
+	// The workers never fail, their errors are collected instead.
+	_ = g.Wait()
+	return errors.Join(append([]error{listErr}, errs...)...)
+}
+
+// listConfigMaps lists the configMaps matching the list options in each target namespace.
+// The namespaces that cannot be listed do not prevent listing the other ones, their errors are joined.
+func (o *driverConfigOptions) listConfigMaps(ctx context.Context, cl kubernetes.Interface,
+	listOpts metav1.ListOptions) ([]corev1.ConfigMap, error) {
+	var (
+		configMaps []corev1.ConfigMap
+		errs       []error
+	)
+	for _, namespace := range o.namespaces() {
+		var configMapList *corev1.ConfigMapList
+		err := o.withRetries(ctx, func() (err error) {
+			configMapList, err = cl.CoreV1().ConfigMaps(namespace).List(ctx, listOpts)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, o.namespaceError(namespace, err))
+			continue
+		}
+		configMaps = append(configMaps, configMapList.Items...)
+	}
+	return configMaps, errors.Join(errs...)
+}
+
+// configMapPatch returns the JSON patch replacing the value of the configMap data key holding the engine kind.
+func (o *driverConfigOptions) configMapPatch(value string) []byte {
 	type patchDriverTypeValue struct {
 		Op    string `json:"op"`
 		Path  string `json:"path"`
 		Value string `json:"value"`
 	}
-	payload := []patchDriverTypeValue{{
+	plBytes, _ := json.Marshal([]patchDriverTypeValue{{
 		Op:    "replace",
-		Path:  "/data/" + configMapEngineKindKey,
-		Value: driverType.String(),
-	}}
-	plBytes, _ := json.Marshal(payload)
-
-	for i := 0; i < configMapList.Size(); i++ {
-		configMap := configMapList.Items[i]
-		currEngineKind := configMap.Data[configMapEngineKindKey]
-		if err = checkFalcoRunsWithDrivers(currEngineKind); err != nil {
-			o.Printer.Logger.Warn("Avoid updating Falco configMap",
-				o.Printer.Logger.Args("configMap", configMap.Name, "reason", err))
+		Path:  "/data/" + o.configMapKey(),
+		Value: value,
+	}})
+	return plBytes
+}
+
+// configMapTarget identifies a configMap in the results.
+func configMapTarget(configMap *corev1.ConfigMap) string {
+	return fmt.Sprintf("%s/%s/%s", configMapKind, configMap.Namespace, configMap.Name)
+}
+
+// checkMixedEngineKinds fails, unless forced, when the configMaps to patch currently run different driver types:
+// they may be set per pod on purpose, so they are not blindly set to the same one.
+func (o *driverConfigOptions) checkMixedEngineKinds(currentKinds []string, driverType drivertype.DriverType) error {
+	distinct := make(map[string]struct{})
+	for _, kind := range currentKinds {
+		distinct[canonicalEngineKind(kind)] = struct{}{}
+	}
+	if len(distinct) < 2 {
+		return nil
+	}
+	kinds := make([]string, 0, len(distinct))
+	for kind := range distinct {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	o.Printer.Logger.Warn("Falco configMaps run different driver types",
+		o.Printer.Logger.Args("types", strings.Join(kinds, ", "), "new", driverType.String()))
+	if o.Force {
+		return nil
+	}
+	return fmt.Errorf("the Falco configMaps run different driver types [%s], use --force to set all of them to %s",
+		strings.Join(kinds, ", "), driverType.String())
+}
+
+// verifyConfigMap reads back the patched configMap, checking that it holds the expected engine kind:
+// admission webhooks, for instance, may have rewritten it.
+func (o *driverConfigOptions) verifyConfigMap(ctx context.Context, cl kubernetes.Interface, configMap *corev1.ConfigMap,
+	matcher *contextMatcher, expected string) error {
+	var patched *corev1.ConfigMap
+	err := o.withRetries(ctx, func() (err error) {
+		patched, err = cl.CoreV1().ConfigMaps(configMap.Namespace).Get(ctx, configMap.Name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to verify configMap %q in namespace %q: %w", configMap.Name, configMap.Namespace, err)
+	}
+	kind, err := o.configMapEngineKind(patched, matcher)
+	if err != nil {
+		return fmt.Errorf("unable to verify configMap %q in namespace %q: %w", configMap.Name, configMap.Namespace, err)
+	}
+	if kind != expected {
+		return fmt.Errorf("configMap %q in namespace %q holds engine kind %q instead of %q after the patch",
+			configMap.Name, configMap.Namespace, kind, expected)
+	}
+	return nil
+}
+
+// configMapKey returns the configMap data key holding the engine kind.
+func (o *driverConfigOptions) configMapKey() string {
+	if o.ConfigMapKey == "" {
+		return configMapEngineKindKey
+	}
+	return o.ConfigMapKey
+}
+
+// editConfigMapEngineKind returns the value of the configMap data key with the engine kind set to newKind,
+// along with the current engine kind. The value is either the engine kind itself or an embedded Falco configuration,
+// whose engine.kind is rewritten.
+func (o *driverConfigOptions) editConfigMapEngineKind(configMap *corev1.ConfigMap, matcher *contextMatcher,
+	newKind string) (edited, previous string, err error) {
+	key := o.configMapKey()
+	value, ok := configMap.Data[key]
+	if !ok {
+		// Never let the patch create the key.
+		return "", "", engineKindError(fmt.Sprintf("no %q data key", key))
+	}
+	if isEmbeddedConfig(value) {
+		return editEngineKind(value, matcher, newKind)
+	}
+	if err := checkFalcoRunsWithDrivers(value); err != nil {
+		return "", value, err
+	}
+	return newKind, value, nil
+}
+
+// configMapEngineKind returns the engine kind set in the configMap, empty if not set.
+func (o *driverConfigOptions) configMapEngineKind(configMap *corev1.ConfigMap, matcher *contextMatcher) (string, error) {
+	value := configMap.Data[o.configMapKey()]
+	if !isEmbeddedConfig(value) {
+		return value, nil
+	}
+	kinds, err := findEngineKinds(value)
+	if err != nil {
+		return "", err
+	}
+	if kind, ok := selectEngineKind(kinds, matcher); ok {
+		return kind.kind, nil
+	}
+	return "", nil
+}
+
+// maxParallel returns the number of configMaps patched concurrently.
+func (o *driverConfigOptions) maxParallel() int {
+	if o.MaxParallel < 1 {
+		return defaultMaxParallel
+	}
+	return o.MaxParallel
+}
+
+// namespaces returns the namespaces where to look for the Falco resources: the comma separated list
+// of the given ones, without duplicates, or all of them.
+func (o *driverConfigOptions) namespaces() []string {
+	if o.AllNamespaces {
+		return []string{metav1.NamespaceAll}
+	}
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, namespace := range strings.Split(o.Namespace, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || seen[namespace] {
 			continue
 		}
-		// Patch the configMap
-		if _, err = cl.CoreV1().ConfigMaps(configMap.Namespace).Patch(
-			ctx, configMap.Name, types.JSONPatchType, plBytes, metav1.PatchOptions{}); err != nil {
-			return err
-		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
 	}
+	if len(namespaces) == 0 {
+		return []string{o.Namespace}
+	}
+	return namespaces
+}
+
+// namespaceError adds the namespace to the errors of the resources listed in several namespaces.
+func (o *driverConfigOptions) namespaceError(namespace string, err error) error {
+	if len(o.namespaces()) == 1 {
+		return err
+	}
+	return fmt.Errorf("namespace %q: %w", namespace, err)
+}
+
+// CommitDriverType updates the Falco configuration to use the given driver type, like the config command does:
+// the configmaps are updated when a namespace is given, the local falco.yaml otherwise.
+func CommitDriverType(ctx context.Context, opt *options.Common, driver *options.Driver,
+	namespace, kubeConfig string, driverType drivertype.DriverType) error {
+	o := driverConfigOptions{Request: NewRequest(opt, driver)}
+	o.Namespace = namespace
+	o.KubeConfig = kubeConfig
+	_, err := o.commit(ctx, driverType)
+	return o.skipNotDriverDriven(err)
+}
+
+// skipNotDriverDriven turns an ErrEngineNotDriverDriven error into a warning, unless in strict mode.
+func (o *driverConfigOptions) skipNotDriverDriven(err error) error {
+	if err == nil || o.Strict || !errors.Is(err, ErrEngineNotDriverDriven) {
+		return err
+	}
+	o.Printer.Logger.Warn("Avoid updating Falco configuration", o.Printer.Logger.Args("reason", err))
+	o.result.skip(err)
 	return nil
 }
 
 // commit saves the updated driver type to Falco config,
-// either to the local falco.yaml or updating the deployment configmap.
-func (o *driverConfigOptions) commit(ctx context.Context, driverType drivertype.DriverType) error {
-	if o.Namespace != "" {
+// either to the local falco.yaml, restarting the Falco service if requested, or updating the deployment configmap.
+// The outcome of each Falco configuration is returned, along with the errors.
+func (o *driverConfigOptions) commit(ctx context.Context, driverType drivertype.DriverType) ([]TargetResult, error) {
+	err := o.commitTargets(ctx, driverType)
+	sort.SliceStable(o.result.Targets, func(i, j int) bool {
+		return o.result.Targets[i].Target < o.result.Targets[j].Target
+	})
+	return o.result.Targets, err
+}
+
+// onKubernetes tells whether the Falco deployment to update is on Kubernetes, rather than the local Falco config file.
+func (o *driverConfigOptions) onKubernetes() bool {
+	return o.Namespace != "" || o.AllNamespaces || o.OpenShift
+}
+
+func (o *driverConfigOptions) commitTargets(ctx context.Context, driverType drivertype.DriverType) error {
+	if o.onKubernetes() {
 		// Ok we are on k8s
-		return o.replaceDriverTypeInK8SConfigMap(ctx, driverType)
+		if o.Restart {
+			o.Printer.Logger.Warn("Ignoring --restart, Falco is not deployed as a systemd service on Kubernetes")
+		}
+		return o.replaceDriverTypeInK8S(ctx, driverType)
+	}
+	err := o.replaceDriverTypeInFalcoConfig(driverType)
+	target := o.result.FalcoConfig
+	if target == "" {
+		target = o.FalcoConfig
+	}
+	action := targetAction(err)
+	if err == nil && o.falcoConfigHashes.unchanged() {
+		action = TargetUnchanged
+	}
+	o.result.addTarget(target, action, err).setHashes(o.falcoConfigHashes)
+	if err != nil {
+		return err
+	}
+	if o.Restart || (o.Rollout && action == TargetUpdated) {
+		return o.restartFalco(ctx, driverType)
+	}
+	return nil
+}
+
+// logTargets logs a summary line per Falco configuration considered.
+func (o *driverConfigOptions) logTargets(targets []TargetResult) {
+	for _, t := range targets {
+		switch t.Action {
+		case TargetFailed:
+			o.Printer.Logger.Error("Falco configuration not updated", o.Printer.Logger.Args("target", t.Target, "reason", t.Error))
+		case TargetSkipped:
+			o.Printer.Logger.Warn("Falco configuration skipped", o.Printer.Logger.Args("target", t.Target, "reason", t.Error))
+		case TargetUpdated:
+			o.Printer.Logger.Info("Falco configuration "+string(t.Action), o.Printer.Logger.Args(
+				"target", t.Target, "previousHash", t.PreviousHash, "hash", t.Hash))
+		default:
+			o.Printer.Logger.Info("Falco configuration "+string(t.Action), o.Printer.Logger.Args("target", t.Target))
+		}
 	}
-	return o.replaceDriverTypeInFalcoConfig(driverType)
 }
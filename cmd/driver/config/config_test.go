@@ -30,15 +30,136 @@ var driverConfigHelp = `Configure a driver for future usages with other driver s
 It will also update local Falco configuration or k8s configmap depending on the environment where it is running, to let Falco use chosen driver.
 Only supports deployments of Falco that use a driver engine, ie: one between kmod, ebpf and modern-ebpf.
 If engine.kind key is set to a non-driver driven engine, Falco configuration won't be touched.
+Use --strict to fail instead of skipping Falco configurations that do not run with a driver.
+When a namespace (or --all-namespaces) is given, the configmaps are updated, unless --target selects the daemonsets driver env vars;
+the permissions to list and patch the target resources are checked before updating them.
+When Falco is managed by the Falco operator, --target=operator sets the engine kind in its Falco custom resources instead,
+so that the change survives the reconciliation of the configmaps by the operator.
+The Kubernetes API server is reached through the proxy set by the HTTPS_PROXY and NO_PROXY env variables, if any,
+and --ca-file can provide the CAs verifying it.
+On OpenShift, --openshift finds the Falco deployment from the Falco operator instance, falling back to the namespace
+falcoctl runs in (or the falco one) when the operator is not installed.
+Unless --backup=false is given, a timestamped copy of the Falco config file is saved next to it before updating it;
+use the restore subcommand to reinstate it.
+Use --engine-only to only update Falco, or --update-falco=false to only store the driver configuration.
+The default namespace and kubeconfig can be set in the falcoctl config file, as driver.config.namespace and driver.config.kubeconfig,
+or through the FALCOCTL_DRIVER_CONFIG_NAMESPACE and FALCOCTL_DRIVER_CONFIG_KUBECONFIG env variables.
+Use --quiet to only log the errors; with --output, stdout only holds the structured outcome, the logs going to stderr.
 
 Usage:
   falcoctl driver config [flags]
+  falcoctl driver config [command]
+
+Available Commands:
+  diff        Preview the change to the Falco config file
+  history     Show the history of the driver configuration changes
+  restore     Restore the Falco config file from a backup
+  show        Show the stored driver configuration
+  validate    Check the permissions needed to update the Falco configmaps
+
+Flags:
+      --all-namespaces            Update the Falco configmaps in all the Kubernetes namespaces.
+      --allow-downgrade           Allow storing a driver version older than the stored one.
+      --backup                    Whether to back up the Falco config file before updating it. (default true)
+      --ca-file string            PEM bundle of the CAs verifying the Kubernetes API server, instead of the ones of the kubeconfig.
+      --configmap-key string      Data key of the Falco configmaps holding either the engine kind or the whole Falco configuration (e.g. falco.yaml). (default "engine.kind")
+      --context string            Kubernetes context to use, instead of the current one of the kubeconfig.
+      --create-if-missing         Create a minimal Falco config file, only setting engine.kind, when it does not exist.
+      --dry-run                   Only print the changes to Falco config/configmap, without applying them.
+      --emit-events               Record a Kubernetes event on each updated Falco configmap (requires the permission to create events).
+      --engine-only               Only update Falco config/configmap, without storing the driver configuration. Cannot be used with --update-falco=false.
+      --falco-config string       Path of the Falco configuration file to update, relative to the driver host root. (default "/etc/falco/falco.yaml")
+      --field-selector string     Field selector further filtering the Falco configmaps (or daemonsets) to update (e.g. metadata.name=falco).
+      --force                     Update the Falco configmaps even when they currently run different driver types.
+  -h, --help                      help for config
+      --instance-label string     Label, in the "key=value" format, selecting the Falco configmaps to update (e.g. to match a different Helm release name). (default "app.kubernetes.io/instance=falco")
+      --kubeconfig string         Kubernetes config.
+      --lock-timeout duration     Maximum time waiting for other falcoctl runs to release the lock of the Falco config file. (default 10s)
+      --match-context string      Regex matched against the comments preceding each engine block, to select the engine.kind to edit (prefix with '!' to negate). Defaults to the top-level one.
+      --max-parallel int          Maximum number of Falco configmaps patched concurrently. (default 8)
+      --namespace string          Kubernetes namespace, or comma separated list of namespaces.
+      --openshift                 Discover the namespace and the configmaps of Falco from the Falco operator instance, when no namespace is given.
+  -o, --output string             Print the outcome as structured output instead of logs, the errors being logged to stderr. One of 'yaml' or 'json'
+  -q, --quiet                     Only log the errors.
+      --require-driver            Fail, instead of warning, when the driver of the configured type was not installed under the host root.
+      --restart                   Restart the Falco systemd service after updating the Falco config file, so that the new driver is used.
+      --resync-period duration    Interval at which all the watched Falco configmaps are checked again, 0 meaning only on changes. (default 10m0s)
+      --retries int               Number of retries of the Kubernetes API calls failing with transient errors (conflicts, timeouts, throttling). (default 3)
+      --retry-interval duration   Interval before the first retry of a Kubernetes API call, doubled at each retry. (default 1s)
+      --rollout                   Restart Falco after a successful update: the daemonsets and deployments of the namespaces of the updated configmaps, or the Falco systemd service when updating the Falco config file.
+      --selector string           Label selector of the Falco configmaps (or daemonsets) to update, instead of the instance label.
+      --skip-validation           Skip checking that the driver type is supported by the target kernel, and was installed.
+      --strict                    Fail, with exit code 2, instead of skipping Falco config/configmaps that do not run with a driver.
+      --target string             Kubernetes resources to update when a namespace is given: the configmaps engine.kind, the daemonsets driver env vars (restarting them), auto to fall back to the daemonsets when no configmap was updated, or the Falco custom resources of the Falco operator (configmap, daemonset, auto, operator) (default "configmap")
+      --timeout duration          Maximum time spent updating the Kubernetes resources, 0 meaning no limit. (default 30s)
+      --update-falco              Whether to update Falco config/configmap. If false, only the driver configuration is stored. (default true)
+      --verify                    Read back the patched Falco configmaps, failing if they do not hold the new driver type (e.g. rewritten by a webhook). (default true)
+      --watch                     Keep running after the update, patching again the Falco configmaps whose engine.kind drifts from the driver type.
+
+Global Flags:
+      --config string          config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --host-root string       Driver host root to be used. (default "/")
+      --kernelrelease string   Specify the kernel release for which to download/build the driver in the same format used by 'uname -r' (e.g. '6.1.0-10-cloud-amd64')
+      --kernelversion string   Specify the kernel version for which to download/build the driver in the same format used by 'uname -v' (e.g. '#1 SMP PREEMPT_DYNAMIC Debian 6.1.38-2 (2023-07-27)')
+      --log-format string      Set formatting for logs (color, text, json) (default "color")
+      --log-level string       Set level for logs (info, warn, debug, trace) (default "info")
+      --name string            Driver name to be used. (default "falco")
+      --prepend-repo strings   Driver repos to be tried, in descending priority order, before the ones set by --repo.
+      --repo strings           Driver repo to be used. (default [https://download.falco.org/driver])
+      --type strings           Driver types allowed in descending priority order (ebpf, kmod, modern_ebpf) (default [modern_ebpf,kmod,ebpf])
+      --version string         Driver version to be used.
+
+Use "falcoctl driver config [command] --help" for more information about a command.
+`
+
+//nolint:lll // no need to check for line length.
+var driverConfigRestoreHelp = `Restore the Falco config file from one of the backups made by the driver config command.
+Available backups are listed from the most recent to the oldest one.
+If no backup is given, the most recent one is restored.
+
+Usage:
+  falcoctl driver config restore [backup] [flags]
+
+Flags:
+      --falco-config string   Path of the Falco configuration file to restore, relative to the driver host root. (default "/etc/falco/falco.yaml")
+  -h, --help                  help for restore
+      --list                  Only list the available backups, without restoring any.
+
+Global Flags:
+      --config string          config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --host-root string       Driver host root to be used. (default "/")
+      --kernelrelease string   Specify the kernel release for which to download/build the driver in the same format used by 'uname -r' (e.g. '6.1.0-10-cloud-amd64')
+      --kernelversion string   Specify the kernel version for which to download/build the driver in the same format used by 'uname -v' (e.g. '#1 SMP PREEMPT_DYNAMIC Debian 6.1.38-2 (2023-07-27)')
+      --log-format string      Set formatting for logs (color, text, json) (default "color")
+      --log-level string       Set level for logs (info, warn, debug, trace) (default "info")
+      --name string            Driver name to be used. (default "falco")
+      --prepend-repo strings   Driver repos to be tried, in descending priority order, before the ones set by --repo.
+      --repo strings           Driver repo to be used. (default [https://download.falco.org/driver])
+      --type strings           Driver types allowed in descending priority order (ebpf, kmod, modern_ebpf) (default [modern_ebpf,kmod,ebpf])
+      --version string         Driver version to be used.
+`
+
+//nolint:lll // no need to check for line length.
+var driverConfigShowHelp = `Show the driver configuration stored by the driver config command.
+The engine.kind currently set in the Falco config file, or in the Falco configmaps when a namespace is given,
+is shown too, flagging any drift from the stored driver types.
+
+Usage:
+  falcoctl driver config show [flags]
 
 Flags:
-  -h, --help                help for config
-      --kubeconfig string   Kubernetes config.
-      --namespace string    Kubernetes namespace.
-      --update-falco        Whether to update Falco config/configmap. (default true)
+      --ca-file string          PEM bundle of the CAs verifying the Kubernetes API server, instead of the ones of the kubeconfig.
+      --configmap-key string    Data key of the Falco configmaps holding either the engine kind or the whole Falco configuration (e.g. falco.yaml). (default "engine.kind")
+      --context string          Kubernetes context to use, instead of the current one of the kubeconfig.
+      --falco-config string     Path of the Falco configuration file to read, relative to the driver host root. (default "/etc/falco/falco.yaml")
+      --field-selector string   Field selector further filtering the Falco configmaps to read.
+  -h, --help                    help for show
+      --instance-label string   Label, in the "key=value" format, selecting the Falco configmaps to read (e.g. to match a different Helm release name). (default "app.kubernetes.io/instance=falco")
+      --kubeconfig string       Kubernetes config.
+      --match-context string    Regex matched against the comments preceding each engine block, to select the engine.kind to show (prefix with '!' to negate). Defaults to the top-level one.
+      --namespace string        Kubernetes namespace.
+  -o, --output string           Print the driver configuration as structured output instead of a table. One of 'yaml' or 'json'
+      --selector string         Label selector of the Falco configmaps to read, instead of the instance label.
 
 Global Flags:
       --config string          config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
@@ -48,6 +169,7 @@ Global Flags:
       --log-format string      Set formatting for logs (color, text, json) (default "color")
       --log-level string       Set level for logs (info, warn, debug, trace) (default "info")
       --name string            Driver name to be used. (default "falco")
+      --prepend-repo strings   Driver repos to be tried, in descending priority order, before the ones set by --repo.
       --repo strings           Driver repo to be used. (default [https://download.falco.org/driver])
       --type strings           Driver types allowed in descending priority order (ebpf, kmod, modern_ebpf) (default [modern_ebpf,kmod,ebpf])
       --version string         Driver version to be used.
@@ -88,6 +210,26 @@ var _ = Describe("config", func() {
 		})
 	})
 
+	Context("restore help message", func() {
+		BeforeEach(func() {
+			args = []string{driverCmd, configCmd, "restore", "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(driverConfigRestoreHelp)))
+		})
+	})
+
+	Context("show help message", func() {
+		BeforeEach(func() {
+			args = []string{driverCmd, configCmd, "show", "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(driverConfigShowHelp)))
+		})
+	})
+
 	// Here we are testing failure cases for configuring a driver.
 	Context("failure", func() {
 		When("with non absolute host-root", func() {
@@ -0,0 +1,691 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func newTestOptions(strict bool) *driverConfigOptions {
+	return &driverConfigOptions{Request: Request{
+		Common:    &options.Common{Printer: output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)},
+		Driver:    &options.Driver{},
+		Strict:    strict,
+		Namespace: "falco",
+	}}
+}
+
+func newConfigMap(name, engineKind string) *corev1.ConfigMap {
+	return newLabeledConfigMap(name, engineKind, map[string]string{"app.kubernetes.io/instance": "falco"})
+}
+
+func newLabeledConfigMap(name, engineKind string, labels map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "falco", Labels: labels},
+		Data:       map[string]string{configMapEngineKindKey: engineKind},
+	}
+}
+
+// newFakeClient returns a fake clientset holding the given configMaps, that records the patched ones.
+func newFakeClient(configMaps []*corev1.ConfigMap, patched *[]string) kubernetes.Interface {
+	objects := make([]runtime.Object, 0, len(configMaps))
+	for _, cm := range configMaps {
+		objects = append(objects, cm)
+	}
+	cl := fake.NewSimpleClientset(objects...)
+	cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*patched = append(*patched, action.(k8stesting.PatchAction).GetName())
+		return true, &corev1.ConfigMap{}, nil
+	})
+	return cl
+}
+
+func TestCheckFalcoRunsWithDrivers(t *testing.T) {
+	assert.NoError(t, checkFalcoRunsWithDrivers(drivertype.TypeKmod))
+	assert.NoError(t, checkFalcoRunsWithDrivers(" Kmod "))
+	assert.NoError(t, checkFalcoRunsWithDrivers("module"))
+	assert.NoError(t, checkFalcoRunsWithDrivers("modern-ebpf"))
+	assert.EqualError(t, checkFalcoRunsWithDrivers("modern"), "engine.kind is not driver driven: modern")
+	assert.EqualError(t, checkFalcoRunsWithDrivers("gvisor"), "engine.kind is not driver driven: gvisor")
+	assert.EqualError(t, checkFalcoRunsWithDrivers(""), "engine.kind is not set")
+	assert.ErrorIs(t, checkFalcoRunsWithDrivers("gvisor"), ErrEngineNotDriverDriven)
+	assert.ErrorIs(t, checkFalcoRunsWithDrivers(""), ErrEngineNotDriverDriven)
+}
+
+func TestReplaceDriverTypeInConfigMaps(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name            string
+		strict          bool
+		instanceLabel   string
+		configMaps      []*corev1.ConfigMap
+		expectedErr     string
+		expectedPatched []string
+	}{
+		{
+			name:            "lenient skips non driver configmaps",
+			configMaps:      []*corev1.ConfigMap{newConfigMap("falco", drivertype.TypeKmod), newConfigMap("falco-gvisor", "gvisor")},
+			expectedPatched: []string{"falco"},
+		},
+		{
+			name:            "strict patches configmaps using driver type aliases",
+			strict:          true,
+			configMaps:      []*corev1.ConfigMap{newConfigMap("falco", "modern-ebpf"), newConfigMap("falco-modern", "Modern_BPF")},
+			expectedPatched: []string{"falco-modern", "falco"},
+		},
+		{
+			name:        "lenient fails without configmaps",
+			configMaps:  nil,
+			expectedErr: `no configmaps matching "app.kubernetes.io/instance=falco" label were found`,
+		},
+		{
+			name:        "lenient fails without configmaps matching the instance label",
+			configMaps:  []*corev1.ConfigMap{newLabeledConfigMap("other", drivertype.TypeKmod, map[string]string{"app.kubernetes.io/instance": "other"})},
+			expectedErr: `no configmaps matching "app.kubernetes.io/instance=falco" label were found`,
+		},
+		{
+			name:            "lenient patches a single configmap",
+			configMaps:      []*corev1.ConfigMap{newConfigMap("falco", drivertype.TypeKmod)},
+			expectedPatched: []string{"falco"},
+		},
+		{
+			name:       "lenient skips configmaps without engine.kind",
+			configMaps: []*corev1.ConfigMap{newConfigMap("falco", "")},
+		},
+		{
+			name:            "lenient patches the configmaps with engine.kind only",
+			configMaps:      []*corev1.ConfigMap{newConfigMap("falco", ""), newConfigMap("falco-kmod", drivertype.TypeKmod)},
+			expectedPatched: []string{"falco-kmod"},
+		},
+		{
+			name:            "strict patches driver configmaps",
+			strict:          true,
+			configMaps:      []*corev1.ConfigMap{newConfigMap("falco", drivertype.TypeKmod)},
+			expectedPatched: []string{"falco"},
+		},
+		{
+			name:        "strict fails on non driver configmaps before patching",
+			strict:      true,
+			configMaps:  []*corev1.ConfigMap{newConfigMap("falco", drivertype.TypeKmod), newConfigMap("falco-gvisor", "gvisor")},
+			expectedErr: `unable to update Falco configMap "falco-gvisor": engine.kind is not driver driven: gvisor`,
+		},
+		{
+			name:        "strict fails on missing engine.kind",
+			strict:      true,
+			configMaps:  []*corev1.ConfigMap{newConfigMap("falco", "")},
+			expectedErr: `unable to update Falco configMap "falco": engine.kind is not set`,
+		},
+		{
+			name:        "strict fails without configmaps",
+			strict:      true,
+			configMaps:  nil,
+			expectedErr: `no configmaps matching "app.kubernetes.io/instance=falco" label were found`,
+		},
+		{
+			name: "only configmaps with the instance label are matched",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("falco", drivertype.TypeKmod),
+				newLabeledConfigMap("other", drivertype.TypeKmod, map[string]string{"app.kubernetes.io/instance": "other"}),
+				newLabeledConfigMap("unlabeled", drivertype.TypeKmod, nil),
+			},
+			expectedPatched: []string{"falco"},
+		},
+		{
+			name:          "custom instance label",
+			instanceLabel: "app.kubernetes.io/instance=my-release",
+			configMaps: []*corev1.ConfigMap{
+				newConfigMap("falco", drivertype.TypeKmod),
+				newLabeledConfigMap("my-release", drivertype.TypeKmod, map[string]string{"app.kubernetes.io/instance": "my-release"}),
+			},
+			expectedPatched: []string{"my-release"},
+		},
+		{
+			name:          "invalid instance label",
+			instanceLabel: "app.kubernetes.io/instance: falco",
+			configMaps:    []*corev1.ConfigMap{newConfigMap("falco", drivertype.TypeKmod)},
+			expectedErr:   `invalid instance label "app.kubernetes.io/instance: falco"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var patched []string
+			o := newTestOptions(tc.strict)
+			o.InstanceLabel = tc.instanceLabel
+			err := o.replaceDriverTypeInConfigMaps(context.Background(), newFakeClient(tc.configMaps, &patched), driverType)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			// The configmaps are patched concurrently, in no particular order.
+			assert.ElementsMatch(t, tc.expectedPatched, patched)
+		})
+	}
+}
+
+func TestReplaceDriverTypeInConfigMapsKey(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	falcoYAML := "# Falco configuration\nengine:\n  kind: kmod\n  kmod:\n    buf_size_preset: 4\n"
+
+	testCases := []struct {
+		name          string
+		strict        bool
+		data          map[string]string
+		expectedErr   string
+		expectedPatch string
+	}{
+		{
+			name:          "plain scalar",
+			data:          map[string]string{"engine-kind": drivertype.TypeKmod},
+			expectedPatch: `[{"op":"replace","path":"/data/engine-kind","value":"modern_ebpf"}]`,
+		},
+		{
+			name: "embedded yaml",
+			data: map[string]string{"engine-kind": falcoYAML},
+			expectedPatch: `[{"op":"replace","path":"/data/engine-kind","value":` +
+				`"# Falco configuration\nengine:\n  kind: modern_ebpf\n  kmod:\n    buf_size_preset: 4\n"}]`,
+		},
+		{
+			name:        "embedded yaml not driver driven",
+			strict:      true,
+			data:        map[string]string{"engine-kind": "engine:\n  kind: gvisor\n"},
+			expectedErr: `unable to update Falco configMap "falco": engine.kind is not driver driven: gvisor`,
+		},
+		{
+			name:        "missing key",
+			strict:      true,
+			data:        map[string]string{configMapEngineKindKey: drivertype.TypeKmod},
+			expectedErr: `unable to update Falco configMap "falco": no "engine-kind" data key`,
+		},
+		{
+			name: "missing key not strict",
+			data: map[string]string{configMapEngineKindKey: drivertype.TypeKmod},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configMap := newConfigMap("falco", "")
+			configMap.Data = tc.data
+			var patches []string
+			cl := fake.NewSimpleClientset(configMap)
+			cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				patches = append(patches, string(action.(k8stesting.PatchAction).GetPatch()))
+				return true, &corev1.ConfigMap{}, nil
+			})
+
+			o := newTestOptions(tc.strict)
+			o.ConfigMapKey = "engine-kind"
+			err := o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				assert.Empty(t, patches)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expectedPatch == "" {
+				assert.Empty(t, patches)
+				assert.Equal(t, []string{"falco/falco"}, o.result.targets(configMapKind, TargetSkipped))
+				return
+			}
+			assert.Equal(t, []string{tc.expectedPatch}, patches)
+		})
+	}
+}
+
+func TestReplaceDriverTypeInConfigMapsVerify(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	t.Run("verified", func(t *testing.T) {
+		// The fake clientset applies the JSON patch.
+		cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod))
+		o := newTestOptions(false)
+		o.Verify = true
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+		assert.Equal(t, []string{"falco/falco"}, o.result.targets(configMapKind, TargetUpdated))
+	})
+
+	t.Run("rewritten by a webhook", func(t *testing.T) {
+		cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod))
+		cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			rewritten := newConfigMap("falco", drivertype.TypeBpf)
+			return true, rewritten, cl.Tracker().Update(corev1.SchemeGroupVersion.WithResource("configmaps"), rewritten, "falco")
+		})
+		o := newTestOptions(false)
+		o.Verify = true
+		assert.EqualError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType),
+			`configMap "falco" in namespace "falco" holds engine kind "ebpf" instead of "modern_ebpf" after the patch`)
+		assert.Empty(t, o.result.targets(configMapKind, TargetUpdated))
+	})
+}
+
+func TestIsEmbeddedConfig(t *testing.T) {
+	assert.False(t, isEmbeddedConfig(drivertype.TypeKmod))
+	assert.False(t, isEmbeddedConfig(""))
+	assert.False(t, isEmbeddedConfig("[kmod]"))
+	assert.True(t, isEmbeddedConfig("engine:\n  kind: kmod\n"))
+}
+
+// newAccessReviewClient returns a fake clientset that allows only the given verbs on configMaps.
+func newAccessReviewClient(allowed ...string) *fake.Clientset {
+	cl := fake.NewSimpleClientset()
+	allowAccessReviews(cl, allowed...)
+	return cl
+}
+
+// allowAccessReviews makes the fake clientset allow only the given verbs on all the resources.
+func allowAccessReviews(cl *fake.Clientset, allowed ...string) {
+	cl.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		for _, verb := range allowed {
+			if review.Spec.ResourceAttributes.Verb == verb {
+				review.Status.Allowed = true
+			}
+		}
+		return true, review, nil
+	})
+}
+
+func TestCheckConfigMapsPermissions(t *testing.T) {
+	testCases := []struct {
+		name        string
+		allowed     []string
+		expectedErr string
+	}{
+		{
+			name:    "allowed",
+			allowed: []string{"list", "patch"},
+		},
+		{
+			name:    "missing patch",
+			allowed: []string{"list"},
+			expectedErr: `missing permissions to patch configmaps in namespace "falco", required RBAC rule: ` +
+				`{apiGroups: [""], resources: ["configmaps"], verbs: ["list", "patch"]}`,
+		},
+		{
+			name: "missing list and patch",
+			expectedErr: `missing permissions to list and patch configmaps in namespace "falco", required RBAC rule: ` +
+				`{apiGroups: [""], resources: ["configmaps"], verbs: ["list", "patch"]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newTestOptions(false)
+			err := o.checkConfigMapsPermissions(context.Background(), newAccessReviewClient(tc.allowed...))
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckConfigMapsPermissionsAllNamespaces(t *testing.T) {
+	o := newTestOptions(false)
+	o.AllNamespaces = true
+	assert.EqualError(t, o.checkConfigMapsPermissions(context.Background(), newAccessReviewClient("list")),
+		`missing permissions to patch configmaps in all namespaces, required RBAC rule: `+
+			`{apiGroups: [""], resources: ["configmaps"], verbs: ["list", "patch"]}`)
+}
+
+func TestCheckConfigMapsPermissionsVerify(t *testing.T) {
+	o := newTestOptions(false)
+	o.Verify = true
+	assert.EqualError(t, o.checkConfigMapsPermissions(context.Background(), newAccessReviewClient("list", "patch")),
+		`missing permissions to get configmaps in namespace "falco", required RBAC rule: `+
+			`{apiGroups: [""], resources: ["configmaps"], verbs: ["get", "list", "patch"]}`)
+}
+
+func TestCheckConfigMapsPermissionsWatch(t *testing.T) {
+	o := newTestOptions(false)
+	o.Verify = true
+	o.Watch = true
+	assert.EqualError(t, o.checkConfigMapsPermissions(context.Background(), newAccessReviewClient("get", "list", "patch")),
+		`missing permissions to watch configmaps in namespace "falco", required RBAC rule: `+
+			`{apiGroups: [""], resources: ["configmaps"], verbs: ["get", "list", "patch", "watch"]}`)
+	assert.Equal(t, []string{"list", "patch"}, configMapsPermissions.verbs)
+}
+
+func TestCheckConfigMapsPermissionsReviewError(t *testing.T) {
+	cl := fake.NewSimpleClientset()
+	cl.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("not supported")
+	})
+	o := newTestOptions(true)
+	assert.NoError(t, o.checkConfigMapsPermissions(context.Background(), cl))
+}
+
+// TestReplaceDriverTypeInConfigMapsCountsItems guards against counting the matched configMaps
+// through the serialized size of the list, instead of its items.
+func TestReplaceDriverTypeInConfigMapsCountsItems(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeKmod)
+	require.NoError(t, err)
+
+	var patched []string
+	o := newTestOptions(true)
+	cl := newFakeClient([]*corev1.ConfigMap{newConfigMap("falco", drivertype.TypeModernBpf)}, &patched)
+	assert.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+	assert.Equal(t, []string{"falco"}, patched)
+
+	patched = nil
+	cl = newFakeClient(nil, &patched)
+	assert.EqualError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType),
+		`no configmaps matching "app.kubernetes.io/instance=falco" label were found`)
+	assert.Empty(t, patched)
+}
+
+// patchOptionsClient wraps a clientset to record the options of the configMaps patches,
+// since the fake clientset does not expose them to the reactors.
+type patchOptionsClient struct {
+	kubernetes.Interface
+	opts *[]metav1.PatchOptions
+}
+
+func (c *patchOptionsClient) CoreV1() typedcorev1.CoreV1Interface {
+	return &patchOptionsCoreV1{CoreV1Interface: c.Interface.CoreV1(), opts: c.opts}
+}
+
+type patchOptionsCoreV1 struct {
+	typedcorev1.CoreV1Interface
+	opts *[]metav1.PatchOptions
+}
+
+func (c *patchOptionsCoreV1) ConfigMaps(namespace string) typedcorev1.ConfigMapInterface {
+	return &patchOptionsConfigMaps{ConfigMapInterface: c.CoreV1Interface.ConfigMaps(namespace), opts: c.opts}
+}
+
+type patchOptionsConfigMaps struct {
+	typedcorev1.ConfigMapInterface
+	opts *[]metav1.PatchOptions
+}
+
+func (c *patchOptionsConfigMaps) Patch(ctx context.Context, name string, pt types.PatchType, data []byte,
+	opts metav1.PatchOptions, subresources ...string) (*corev1.ConfigMap, error) {
+	*c.opts = append(*c.opts, opts)
+	return c.ConfigMapInterface.Patch(ctx, name, pt, data, opts, subresources...)
+}
+
+func TestReplaceDriverTypeInConfigMapsDryRun(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	var opts []metav1.PatchOptions
+	cl := &patchOptionsClient{
+		Interface: fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod)),
+		opts:      &opts,
+	}
+
+	o := newTestOptions(true)
+	o.DryRun = true
+	require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+
+	// The patch is sent for server-side validation only.
+	require.Len(t, opts, 1)
+	assert.Equal(t, []string{metav1.DryRunAll}, opts[0].DryRun)
+}
+
+func TestReplaceDriverTypeInConfigMapsAllNamespaces(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	tenant := newConfigMap("falco", drivertype.TypeKmod)
+	tenant.Namespace = "tenant"
+	failing := newConfigMap("falco", drivertype.TypeKmod)
+	failing.Namespace = "failing"
+	newClient := func(patched *[]string) kubernetes.Interface {
+		cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod), tenant, failing)
+		cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			*patched = append(*patched, action.GetNamespace()+"/"+action.(k8stesting.PatchAction).GetName())
+			if action.GetNamespace() == "failing" {
+				return true, nil, errors.New("forbidden")
+			}
+			return true, &corev1.ConfigMap{}, nil
+		})
+		return cl
+	}
+
+	t.Run("single namespace", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(true)
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), newClient(&patched), driverType))
+		assert.Equal(t, []string{"falco/falco"}, patched)
+	})
+
+	t.Run("all namespaces", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(true)
+		o.Namespace = ""
+		o.AllNamespaces = true
+		err := o.replaceDriverTypeInConfigMaps(context.Background(), newClient(&patched), driverType)
+		assert.EqualError(t, err, `unable to patch configMap "falco" in namespace "failing": forbidden`)
+		assert.ElementsMatch(t, []string{"falco/falco", "tenant/falco", "failing/falco"}, patched)
+	})
+}
+
+func TestReplaceDriverTypeInConfigMapsNamespaceList(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	newClient := func(patched *[]string) *fake.Clientset {
+		var objects []runtime.Object
+		for _, namespace := range []string{"falco", "tenant", "other"} {
+			configMap := newConfigMap("falco", drivertype.TypeKmod)
+			configMap.Namespace = namespace
+			objects = append(objects, configMap)
+		}
+		cl := fake.NewSimpleClientset(objects...)
+		cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			*patched = append(*patched, action.GetNamespace()+"/"+action.(k8stesting.PatchAction).GetName())
+			return true, &corev1.ConfigMap{}, nil
+		})
+		return cl
+	}
+
+	t.Run("two of three namespaces", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(true)
+		o.Namespace = "falco, tenant,falco"
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), newClient(&patched), driverType))
+		assert.ElementsMatch(t, []string{"falco/falco", "tenant/falco"}, patched)
+		assert.Equal(t, []string{"falco/falco", "tenant/falco"}, o.result.targets(configMapKind, TargetUpdated))
+	})
+
+	t.Run("matches in some namespaces only", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(true)
+		o.Namespace = "tenant,empty"
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), newClient(&patched), driverType))
+		assert.Equal(t, []string{"tenant/falco"}, patched)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(true)
+		o.Namespace = "empty,void"
+		err := o.replaceDriverTypeInConfigMaps(context.Background(), newClient(&patched), driverType)
+		assert.EqualError(t, err, `no configmaps matching "app.kubernetes.io/instance=falco" label were found`)
+		assert.Empty(t, patched)
+	})
+
+	t.Run("list errors per namespace", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(true)
+		o.Namespace = "falco,tenant,forbidden"
+		cl := newClient(&patched)
+		cl.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetNamespace() == "forbidden" {
+				return true, nil, errors.New("forbidden")
+			}
+			return false, nil, nil
+		})
+		err := o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType)
+		assert.EqualError(t, err, `namespace "forbidden": forbidden`)
+		assert.ElementsMatch(t, []string{"falco/falco", "tenant/falco"}, patched)
+	})
+}
+
+func TestReplaceDriverTypeInConfigMapsMixedKinds(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	configMaps := []*corev1.ConfigMap{newConfigMap("falco-kmod", drivertype.TypeKmod), newConfigMap("falco-ebpf", drivertype.TypeBpf)}
+
+	t.Run("abort", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(false)
+		err := o.replaceDriverTypeInConfigMaps(context.Background(), newFakeClient(configMaps, &patched), driverType)
+		assert.EqualError(t, err, "the Falco configMaps run different driver types [ebpf, kmod], use --force to set all of them to modern_ebpf")
+		assert.Empty(t, patched)
+	})
+
+	t.Run("force", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(false)
+		o.Force = true
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), newFakeClient(configMaps, &patched), driverType))
+		assert.ElementsMatch(t, []string{"falco-kmod", "falco-ebpf"}, patched)
+	})
+}
+
+func TestReplaceDriverTypeInConfigMapsCanonicalKinds(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeKmod)
+	require.NoError(t, err)
+
+	// Differently cased kinds are the same driver type, rewritten in the canonical form.
+	cl := fake.NewSimpleClientset(newConfigMap("falco", "Kmod"), newConfigMap("falco-upper", " KMOD"))
+	o := newTestOptions(false)
+	require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+	for _, name := range []string{"falco", "falco-upper"} {
+		configMap, err := cl.CoreV1().ConfigMaps("falco").Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, drivertype.TypeKmod, configMap.Data[configMapEngineKindKey])
+	}
+}
+
+func TestReplaceDriverTypeInConfigMapsSelectors(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	custom := newLabeledConfigMap("falco-custom", drivertype.TypeKmod, map[string]string{"app": "falco", "tier": "security"})
+
+	t.Run("default selector misses the configmap", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(true)
+		err := o.replaceDriverTypeInConfigMaps(context.Background(), newFakeClient([]*corev1.ConfigMap{custom}, &patched), driverType)
+		assert.EqualError(t, err, `no configmaps matching "app.kubernetes.io/instance=falco" label were found`)
+	})
+
+	t.Run("custom selector", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(true)
+		o.Selector = "app=falco,tier in (security)"
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), newFakeClient([]*corev1.ConfigMap{custom}, &patched), driverType))
+		assert.Equal(t, []string{"falco-custom"}, patched)
+	})
+
+	t.Run("field selector", func(t *testing.T) {
+		var restrictions k8stesting.ListRestrictions
+		cl := fake.NewSimpleClientset()
+		cl.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			restrictions = action.(k8stesting.ListAction).GetListRestrictions()
+			return true, &corev1.ConfigMapList{}, nil
+		})
+		o := newTestOptions(true)
+		o.Selector = "app=falco"
+		o.FieldSelector = "metadata.name=falco-custom"
+		err := o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType)
+		assert.EqualError(t, err, `no configmaps matching "app=falco" label and "metadata.name=falco-custom" field selector were found`)
+		assert.Equal(t, "app=falco", restrictions.Labels.String())
+		assert.Equal(t, "metadata.name=falco-custom", restrictions.Fields.String())
+	})
+
+	t.Run("invalid selectors", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.Selector = "app in (falco"
+		_, err := o.listOptions()
+		assert.ErrorContains(t, err, `invalid label selector "app in (falco"`)
+
+		o.Selector = ""
+		o.FieldSelector = "metadata.name"
+		_, err = o.listOptions()
+		assert.ErrorContains(t, err, `invalid field selector "metadata.name"`)
+	})
+}
+
+func TestReplaceDriverTypeInConfigMapsParallel(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	objects := make([]runtime.Object, 0, 50)
+	expected := make([]string, 0, 49)
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("falco-%02d", i)
+		objects = append(objects, newConfigMap(name, drivertype.TypeKmod))
+		if name != "falco-07" {
+			expected = append(expected, "falco/"+name)
+		}
+	}
+	cl := fake.NewSimpleClientset(objects...)
+	var (
+		mu      sync.Mutex
+		patched []string
+	)
+	cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		name := action.(k8stesting.PatchAction).GetName()
+		mu.Lock()
+		patched = append(patched, name)
+		mu.Unlock()
+		if name == "falco-07" {
+			return true, nil, errors.New("conflict")
+		}
+		return true, &corev1.ConfigMap{}, nil
+	})
+
+	o := newTestOptions(true)
+	o.MaxParallel = 4
+	err = o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType)
+	assert.EqualError(t, err, `unable to patch configMap "falco-07" in namespace "falco": conflict`)
+	// The failing patch does not prevent the other ones.
+	assert.Len(t, patched, 50)
+	assert.Equal(t, expected, o.result.targets(configMapKind, TargetUpdated))
+}
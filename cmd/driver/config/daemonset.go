@@ -0,0 +1,287 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+const (
+	targetConfigMap = "configmap"
+	targetDaemonSet = "daemonset"
+	targetAuto      = "auto"
+
+	// driverTypeEnv is the env var used by falcoctl, e.g. in the Falco init containers, to select the driver type.
+	driverTypeEnv = "FALCOCTL_DRIVER_TYPE"
+	// bpfProbeEnv is the env var used by older Falco versions to run with the eBPF probe.
+	bpfProbeEnv = "FALCO_BPF_PROBE"
+	// restartedAtAnnotation is the pod template annotation used by "kubectl rollout restart".
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+// targets are the k8s resources that can be updated with the driver type.
+var targets = []string{targetConfigMap, targetDaemonSet, targetAuto, targetOperator}
+
+// target returns the k8s resources to be updated, defaulting to the configMaps.
+func (o *driverConfigOptions) target() string {
+	if o.Target == "" {
+		return targetConfigMap
+	}
+	return o.Target
+}
+
+// replaceDriverTypeInTargets updates the driver type in the k8s resources selected by the target.
+func (o *driverConfigOptions) replaceDriverTypeInTargets(ctx context.Context, cl kubernetes.Interface, driverType drivertype.DriverType) error {
+	switch o.target() {
+	case targetOperator:
+		if err := o.checkFalcoInstancesPermissions(ctx, cl); err != nil {
+			return err
+		}
+		return o.replaceDriverTypeInFalcoInstances(ctx, driverType)
+	case targetDaemonSet:
+		if err := o.checkDaemonSetsPermissions(ctx, cl); err != nil {
+			return err
+		}
+		return o.replaceDriverTypeInDaemonSets(ctx, cl, driverType)
+	case targetAuto:
+		if err := o.checkConfigMapsPermissions(ctx, cl); err != nil {
+			return err
+		}
+		// The configMaps are tried leniently, since the daemonSets are the fallback.
+		strict := o.Strict
+		o.Strict = false
+		err := o.replaceDriverTypeInConfigMaps(ctx, cl, driverType)
+		o.Strict = strict
+		var noMatches *noMatchesError
+		if (err != nil && !errors.As(err, &noMatches)) || (err == nil && len(o.result.targets(configMapKind, TargetUpdated)) > 0) {
+			return err
+		}
+		o.Printer.Logger.Info("No Falco configMap updated, trying with the daemonSets", o.Printer.Logger.Args("namespace", o.Namespace))
+		if err = o.checkDaemonSetsPermissions(ctx, cl); err != nil {
+			return err
+		}
+		return o.replaceDriverTypeInDaemonSets(ctx, cl, driverType)
+	default:
+		if err := o.checkConfigMapsPermissions(ctx, cl); err != nil {
+			return err
+		}
+		return o.replaceDriverTypeInConfigMaps(ctx, cl, driverType)
+	}
+}
+
+// replaceDriverTypeInDaemonSets updates the driver related env vars of the Falco daemonSets,
+// and triggers their rollout restart.
+func (o *driverConfigOptions) replaceDriverTypeInDaemonSets(ctx context.Context, cl kubernetes.Interface, driverType drivertype.DriverType) error {
+	listOpts, err := o.listOptions()
+	if err != nil {
+		return err
+	}
+	daemonSets, listErr := o.listDaemonSets(ctx, cl, listOpts)
+	if len(daemonSets) == 0 {
+		if listErr != nil {
+			return listErr
+		}
+		reason := &noMatchesError{resource: "daemonsets", opts: listOpts}
+		if o.Strict {
+			return reason
+		}
+		o.Printer.Logger.Warn("Avoid updating Falco daemonSet",
+			o.Printer.Logger.Args("namespace", o.Namespace, "reason", reason))
+		o.result.skip(reason)
+		return nil
+	}
+
+	// Build all the patches first, so that in strict mode we fail before touching any daemonSet.
+	patches := make(map[string][]byte, len(daemonSets))
+	toPatch := make([]appsv1.DaemonSet, 0, len(daemonSets))
+	for i := range daemonSets {
+		daemonSet := daemonSets[i]
+		patch, err := driverTypeDaemonSetPatch(&daemonSet, driverType, time.Now())
+		if err != nil {
+			if o.Strict {
+				o.result.addTarget(daemonSetTarget(&daemonSet), TargetFailed, err)
+				return fmt.Errorf("unable to update Falco daemonSet %q: %w", daemonSet.Name, err)
+			}
+			o.Printer.Logger.Warn("Avoid updating Falco daemonSet",
+				o.Printer.Logger.Args("daemonSet", daemonSet.Name, "namespace", daemonSet.Namespace, "reason", err))
+			o.result.addTarget(daemonSetTarget(&daemonSet), TargetSkipped, err)
+			o.result.skip(err)
+			continue
+		}
+		if patch == nil {
+			o.result.addTarget(daemonSetTarget(&daemonSet), TargetUnchanged, nil)
+			o.Printer.Logger.Info("Falco daemonSet already uses the driver type", o.Printer.Logger.Args(
+				"daemonSet", daemonSet.Name, "namespace", daemonSet.Namespace, "type", driverType.String()))
+			continue
+		}
+		patches[daemonSet.Namespace+"/"+daemonSet.Name] = patch
+		toPatch = append(toPatch, daemonSet)
+	}
+
+	patchOpts := metav1.PatchOptions{}
+	if o.DryRun {
+		// Let the API server validate the patch, without persisting it.
+		patchOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	var errs []error
+	for _, daemonSet := range toPatch {
+		key := daemonSet.Namespace + "/" + daemonSet.Name
+		if err = o.withRetries(ctx, func() error {
+			_, err := cl.AppsV1().DaemonSets(daemonSet.Namespace).Patch(
+				ctx, daemonSet.Name, types.JSONPatchType, patches[key], patchOpts)
+			return err
+		}); err != nil {
+			o.result.addTarget(daemonSetTarget(&daemonSet), TargetFailed, err)
+			if !o.AllNamespaces && len(o.namespaces()) == 1 {
+				return err
+			}
+			// Keep going with the other namespaces, reporting all the failures at the end.
+			errs = append(errs, fmt.Errorf("unable to patch daemonSet %q in namespace %q: %w", daemonSet.Name, daemonSet.Namespace, err))
+			continue
+		}
+		o.result.addTarget(daemonSetTarget(&daemonSet), TargetUpdated, nil)
+		if o.DryRun {
+			o.Printer.Logger.Info("Would update and restart Falco daemonSet", o.Printer.Logger.Args(
+				"daemonSet", daemonSet.Name, "namespace", daemonSet.Namespace, "new", driverType.String()))
+			continue
+		}
+		o.Printer.Logger.Info("Updated and restarted Falco daemonSet", o.Printer.Logger.Args(
+			"daemonSet", daemonSet.Name, "namespace", daemonSet.Namespace, "new", driverType.String()))
+	}
+	return errors.Join(append([]error{listErr}, errs...)...)
+}
+
+// listDaemonSets is the same as listConfigMaps, for daemonSets.
+func (o *driverConfigOptions) listDaemonSets(ctx context.Context, cl kubernetes.Interface,
+	listOpts metav1.ListOptions) ([]appsv1.DaemonSet, error) {
+	var (
+		daemonSets []appsv1.DaemonSet
+		errs       []error
+	)
+	for _, namespace := range o.namespaces() {
+		var daemonSetList *appsv1.DaemonSetList
+		err := o.withRetries(ctx, func() (err error) {
+			daemonSetList, err = cl.AppsV1().DaemonSets(namespace).List(ctx, listOpts)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, o.namespaceError(namespace, err))
+			continue
+		}
+		daemonSets = append(daemonSets, daemonSetList.Items...)
+	}
+	return daemonSets, errors.Join(errs...)
+}
+
+// daemonSetTarget identifies a daemonSet in the results.
+func daemonSetTarget(daemonSet *appsv1.DaemonSet) string {
+	return fmt.Sprintf("%s/%s/%s", daemonSetKind, daemonSet.Namespace, daemonSet.Name)
+}
+
+// driverTypeDaemonSetPatch returns the JSON patch updating the driver related env vars
+// of the daemonSet containers, restarting its pods as "kubectl rollout restart" does.
+// A nil patch is returned when the daemonSet already uses the driver type.
+func driverTypeDaemonSetPatch(daemonSet *appsv1.DaemonSet, driverType drivertype.DriverType, now time.Time) ([]byte, error) {
+	type patchOp struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+	var (
+		ops   []patchOp
+		found bool
+	)
+	podSpec := daemonSet.Spec.Template.Spec
+	for _, c := range []struct {
+		field      string
+		containers []corev1.Container
+	}{
+		{field: "initContainers", containers: podSpec.InitContainers},
+		{field: "containers", containers: podSpec.Containers},
+	} {
+		for i := range c.containers {
+			env, containerFound, changed := driverTypeEnvs(c.containers[i].Env, driverType)
+			found = found || containerFound
+			if changed {
+				ops = append(ops, patchOp{
+					Op:    "replace",
+					Path:  fmt.Sprintf("/spec/template/spec/%s/%d/env", c.field, i),
+					Value: env,
+				})
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("none of the containers sets the %s or %s env vars", driverTypeEnv, bpfProbeEnv)
+	}
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	restartedAt := now.Format(time.RFC3339)
+	if daemonSet.Spec.Template.Annotations == nil {
+		ops = append(ops, patchOp{
+			Op:    "add",
+			Path:  "/spec/template/metadata/annotations",
+			Value: map[string]string{restartedAtAnnotation: restartedAt},
+		})
+	} else {
+		ops = append(ops, patchOp{
+			Op:    "add",
+			Path:  "/spec/template/metadata/annotations/" + strings.ReplaceAll(restartedAtAnnotation, "/", "~1"),
+			Value: restartedAt,
+		})
+	}
+	return json.Marshal(ops)
+}
+
+// driverTypeEnvs returns the env vars updated to select the driver type, whether they select
+// a driver type at all and whether they changed.
+func driverTypeEnvs(envs []corev1.EnvVar, driverType drivertype.DriverType) (updated []corev1.EnvVar, found, changed bool) {
+	updated = make([]corev1.EnvVar, 0, len(envs))
+	for _, env := range envs {
+		switch env.Name {
+		case driverTypeEnv:
+			found = true
+			if env.Value != driverType.String() || env.ValueFrom != nil {
+				changed = true
+				env = corev1.EnvVar{Name: driverTypeEnv, Value: driverType.String()}
+			}
+		case bpfProbeEnv:
+			found = true
+			// Setting FALCO_BPF_PROBE makes Falco use the eBPF probe.
+			if driverType.String() != drivertype.TypeBpf {
+				changed = true
+				continue
+			}
+		}
+		updated = append(updated, env)
+	}
+	return updated, found, changed
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+func newDaemonSet(name string, env ...corev1.EnvVar) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "falco", Labels: map[string]string{"app.kubernetes.io/instance": "falco"}},
+		Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "falco-driver-loader", Env: env}},
+					Containers:     []corev1.Container{{Name: "falco", Env: []corev1.EnvVar{{Name: "HOST_ROOT", Value: "/host"}}}},
+				},
+			},
+		},
+	}
+}
+
+func TestReplaceDriverTypeInDaemonSets(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	cl := fake.NewSimpleClientset(
+		newDaemonSet("falco",
+			corev1.EnvVar{Name: "HOST_ROOT", Value: "/host"},
+			corev1.EnvVar{Name: driverTypeEnv, Value: drivertype.TypeKmod},
+			corev1.EnvVar{Name: bpfProbeEnv}),
+		newDaemonSet("falco-plugins"))
+
+	o := newTestOptions(false)
+	require.NoError(t, o.replaceDriverTypeInDaemonSets(context.Background(), cl, driverType))
+	assert.Equal(t, []string{"falco/falco"}, o.result.targets(daemonSetKind, TargetUpdated))
+	assert.Equal(t, []string{"falco/falco-plugins"}, o.result.targets(daemonSetKind, TargetSkipped))
+
+	daemonSet, err := cl.AppsV1().DaemonSets("falco").Get(context.Background(), "falco", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "HOST_ROOT", Value: "/host"},
+		{Name: driverTypeEnv, Value: drivertype.TypeModernBpf},
+	}, daemonSet.Spec.Template.Spec.InitContainers[0].Env)
+	// Untouched containers are left as they are.
+	assert.Equal(t, []corev1.EnvVar{{Name: "HOST_ROOT", Value: "/host"}}, daemonSet.Spec.Template.Spec.Containers[0].Env)
+
+	// The pods are restarted as "kubectl rollout restart" does.
+	restartedAt, ok := daemonSet.Spec.Template.Annotations[restartedAtAnnotation]
+	require.True(t, ok)
+	_, err = time.Parse(time.RFC3339, restartedAt)
+	assert.NoError(t, err)
+
+	// The strict mode fails on daemonSets not selecting the driver through their env.
+	o = newTestOptions(true)
+	assert.ErrorContains(t, o.replaceDriverTypeInDaemonSets(context.Background(), cl, driverType),
+		`unable to update Falco daemonSet "falco-plugins": none of the containers sets the FALCOCTL_DRIVER_TYPE or FALCO_BPF_PROBE env vars`)
+}
+
+func TestDriverTypeDaemonSetPatch(t *testing.T) {
+	now := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	bpf, err := drivertype.Parse(drivertype.TypeBpf)
+	require.NoError(t, err)
+
+	t.Run("already configured", func(t *testing.T) {
+		daemonSet := newDaemonSet("falco", corev1.EnvVar{Name: bpfProbeEnv})
+		patch, err := driverTypeDaemonSetPatch(daemonSet, bpf, now)
+		require.NoError(t, err)
+		assert.Nil(t, patch)
+	})
+
+	t.Run("existing annotations", func(t *testing.T) {
+		daemonSet := newDaemonSet("falco", corev1.EnvVar{Name: driverTypeEnv, Value: drivertype.TypeKmod})
+		daemonSet.Spec.Template.Annotations = map[string]string{"foo": "bar"}
+		patch, err := driverTypeDaemonSetPatch(daemonSet, bpf, now)
+		require.NoError(t, err)
+
+		var ops []map[string]interface{}
+		require.NoError(t, json.Unmarshal(patch, &ops))
+		assert.Equal(t, []map[string]interface{}{
+			{
+				"op":    "replace",
+				"path":  "/spec/template/spec/initContainers/0/env",
+				"value": []interface{}{map[string]interface{}{"name": driverTypeEnv, "value": drivertype.TypeBpf}},
+			},
+			{
+				"op":    "add",
+				"path":  "/spec/template/metadata/annotations/kubectl.kubernetes.io~1restartedAt",
+				"value": "2024-01-02T10:00:00Z",
+			},
+		}, ops)
+	})
+}
+
+func TestReplaceDriverTypeInTargetsAuto(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	newClient := func() *fake.Clientset {
+		cl := fake.NewSimpleClientset(
+			newConfigMap("falco", "gvisor"),
+			newDaemonSet("falco", corev1.EnvVar{Name: driverTypeEnv, Value: drivertype.TypeKmod}))
+		allowAccessReviews(cl, "list", "patch")
+		return cl
+	}
+
+	t.Run("configmap", func(t *testing.T) {
+		o := newTestOptions(false)
+		require.NoError(t, o.replaceDriverTypeInTargets(context.Background(), newClient(), driverType))
+		assert.Empty(t, o.result.targets(configMapKind, TargetUpdated))
+		assert.Empty(t, o.result.targets(daemonSetKind, TargetUpdated))
+	})
+
+	t.Run("auto falls back to the daemonsets", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.Target = targetAuto
+		require.NoError(t, o.replaceDriverTypeInTargets(context.Background(), newClient(), driverType))
+		assert.Empty(t, o.result.targets(configMapKind, TargetUpdated))
+		assert.Equal(t, []string{"falco/falco"}, o.result.targets(daemonSetKind, TargetUpdated))
+		assert.True(t, o.Strict)
+	})
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const longDiff = `Preview, as a unified diff, the change the driver config command would make to the Falco config file.
+Nothing is written: the diff is computed against the driver type given to the driver command.
+`
+
+type driverConfigDiffOptions struct {
+	*options.Common
+	*options.Driver
+	MatchContext string
+	FalcoConfig  string
+}
+
+func newDriverConfigDiffCmd(opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverConfigDiffOptions{
+		Common: opt,
+		Driver: driver,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "diff [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Preview the change to the Falco config file",
+		Long:                  longDiff,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunDriverConfigDiff()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.MatchContext, "match-context", "",
+		"Regex matched against the comments preceding each engine block, to select the engine.kind to update (prefix with '!' to negate). "+
+			"Defaults to the top-level one.")
+	cmd.Flags().StringVar(&o.FalcoConfig, "falco-config", defaultFalcoConfig,
+		"Path of the Falco configuration file to compare, relative to the driver host root.")
+	return cmd
+}
+
+// RunDriverConfigDiff implements the driver config diff command.
+func (o *driverConfigDiffOptions) RunDriverConfigDiff() error {
+	falcoCfgFile, err := falcoConfigPath(o.Driver.HostRoot, o.FalcoConfig)
+	if err != nil {
+		return err
+	}
+	matcher, err := newContextMatcher(o.MatchContext)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(falcoCfgFile)
+	if err != nil {
+		return err
+	}
+
+	edited, _, err := editEngineKind(string(content), matcher, o.Driver.Type.String())
+	switch {
+	case errors.Is(err, ErrEngineNotDriverDriven):
+		o.Printer.DefaultText.Printfln("skipped: not driver-driven (%s)", err)
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to update Falco configuration %q: %w", falcoCfgFile, err)
+	case edited == string(content):
+		o.Printer.DefaultText.Printfln("no changes: %s already uses %s", falcoCfgFile, o.Driver.Type.String())
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(content)),
+		B:        splitLines(edited),
+		FromFile: falcoCfgFile,
+		ToFile:   falcoCfgFile,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	o.Printer.DefaultText.Print(diff)
+	return nil
+}
+
+// splitLines splits content into lines, keeping their line ending.
+// Unlike difflib.SplitLines, no empty line is made up after the final line ending.
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
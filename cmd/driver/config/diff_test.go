@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func TestRunDriverConfigDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:    "change",
+			content: "# Falco engine\nengine:\n  kind: kmod\n  kmod:\n    buf_size_preset: 4\n",
+			expected: `--- FILE
++++ FILE
+@@ -1,5 +1,5 @@
+ # Falco engine
+ engine:
+-  kind: kmod
++  kind: modern_ebpf
+   kmod:
+     buf_size_preset: 4
+`,
+		},
+		{
+			name:     "no changes",
+			content:  "engine:\n  kind: modern_ebpf\n",
+			expected: "no changes: FILE already uses modern_ebpf\n",
+		},
+		{
+			name:     "not driver driven",
+			content:  "engine:\n  kind: gvisor\n",
+			expected: "skipped: not driver-driven (engine.kind is not driver driven: gvisor)\n",
+		},
+	}
+
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			falcoConfig := filepath.Join(t.TempDir(), "falco.yaml")
+			require.NoError(t, os.WriteFile(falcoConfig, []byte(tt.content), 0o600))
+
+			var out bytes.Buffer
+			o := driverConfigDiffOptions{
+				Common:      &options.Common{Printer: output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, &out)},
+				Driver:      &options.Driver{Type: driverType, HostRoot: "/"},
+				FalcoConfig: falcoConfig,
+			}
+			require.NoError(t, o.RunDriverConfigDiff())
+			assert.Equal(t, bytes.ReplaceAll([]byte(tt.expected), []byte("FILE"), []byte(falcoConfig)), out.Bytes())
+
+			// The Falco config file is never written.
+			data, err := os.ReadFile(falcoConfig)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, string(data))
+		})
+	}
+}
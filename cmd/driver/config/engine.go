@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+// engineKind is an engine.kind occurrence in a Falco configuration file.
+// When the engine has no kind, and the driver is expressed by its only driver sub-object
+// (e.g. engine.kmod), the value is the key of the sub-object, so that the whole subtree is renamed.
+type engineKind struct {
+	// line is the index of the line holding the kind value.
+	line int
+	// valueStart and valueEnd delimit the kind value in the line, quotes included.
+	valueStart, valueEnd int
+	kind                 string
+	style                yaml.Style
+	// context holds the comments directly preceding the engine key.
+	context string
+	// nested is set for the engine keys not at the top level of the document, e.g. canary.engine.
+	nested bool
+}
+
+// contextMatcher selects an engine.kind occurrence by matching a regex against its context.
+// A leading "!" selects the first occurrence whose context does not match.
+type contextMatcher struct {
+	re     *regexp.Regexp
+	negate bool
+}
+
+func newContextMatcher(expr string) (*contextMatcher, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	m := &contextMatcher{}
+	if strings.HasPrefix(expr, "!") {
+		m.negate = true
+		expr = expr[1:]
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match context %q: %w", expr, err)
+	}
+	m.re = re
+	return m, nil
+}
+
+func (m *contextMatcher) String() string {
+	if m.negate {
+		return "!" + m.re.String()
+	}
+	return m.re.String()
+}
+
+func (m *contextMatcher) matches(context string) bool {
+	if m == nil {
+		return true
+	}
+	return m.re.MatchString(context) != m.negate
+}
+
+// findEngineKinds returns the engine.kind occurrences found in a Falco configuration file, in document order.
+// The file is parsed to locate the kind scalars, or the driver sub-objects of engines without a kind,
+// so that keys named kind elsewhere are never matched.
+func findEngineKinds(content string) ([]engineKind, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse Falco configuration: %w", err)
+	}
+	var kinds []engineKind
+	for _, root := range doc.Content {
+		walkEngineKinds(root, false, &kinds)
+	}
+	return kinds, nil
+}
+
+func walkEngineKinds(node *yaml.Node, nested bool, kinds *[]engineKind) {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			walkEngineKinds(child, true, kinds)
+		}
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "engine" && value.Kind == yaml.MappingNode {
+			if kind, ok := engineKindOf(value, key.HeadComment); ok {
+				kind.nested = nested
+				*kinds = append(*kinds, kind)
+			}
+		}
+		walkEngineKinds(value, true, kinds)
+	}
+}
+
+// engineKindOf returns the kind of an engine mapping, either set by its kind key or,
+// when not set, implied by its only driver sub-object.
+func engineKindOf(engine *yaml.Node, context string) (engineKind, bool) {
+	var drivers []*yaml.Node
+	for j := 0; j+1 < len(engine.Content); j += 2 {
+		key, value := engine.Content[j], engine.Content[j+1]
+		if key.Value == "kind" && value.Kind == yaml.ScalarNode {
+			return newEngineKind(value, context), true
+		}
+		if _, err := drivertype.Parse(key.Value); err == nil && value.Kind == yaml.MappingNode {
+			drivers = append(drivers, key)
+		}
+	}
+	// Without a kind, more than one driver sub-object is ambiguous.
+	if len(drivers) != 1 {
+		return engineKind{}, false
+	}
+	return newEngineKind(drivers[0], context), true
+}
+
+func newEngineKind(node *yaml.Node, context string) engineKind {
+	k := engineKind{
+		line:       node.Line - 1,
+		valueStart: node.Column - 1,
+		kind:       node.Value,
+		style:      node.Style,
+		context:    context,
+	}
+	k.valueEnd = k.valueStart + len(node.Value)
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		k.valueEnd += 2
+	}
+	return k
+}
+
+// quote formats value with the same quoting style of the replaced kind.
+func (k *engineKind) quote(value string) string {
+	switch {
+	case k.style&yaml.DoubleQuotedStyle != 0:
+		return `"` + value + `"`
+	case k.style&yaml.SingleQuotedStyle != 0:
+		return "'" + value + "'"
+	default:
+		return value
+	}
+}
+
+// selectEngineKind returns the top-level engine.kind occurrence whose context is matched, if any,
+// or else the first nested one. Without a matcher, only the top-level engine.kind, the one used by Falco,
+// is selected: the nested ones are only edited when explicitly matched.
+func selectEngineKind(kinds []engineKind, matcher *contextMatcher) (*engineKind, bool) {
+	for i := range kinds {
+		if !kinds[i].nested && matcher.matches(kinds[i].context) {
+			return &kinds[i], true
+		}
+	}
+	if matcher == nil {
+		return nil, false
+	}
+	for i := range kinds {
+		if kinds[i].nested && matcher.matches(kinds[i].context) {
+			return &kinds[i], true
+		}
+	}
+	return nil, false
+}
+
+// isEmbeddedConfig returns whether the value is a YAML document, like a whole Falco configuration,
+// rather than a plain engine kind.
+func isEmbeddedConfig(value string) bool {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil || len(doc.Content) != 1 {
+		return false
+	}
+	return doc.Content[0].Kind == yaml.MappingNode
+}
+
+// editEngineKind sets to newKind the engine.kind selected by the matcher in the content of a Falco configuration file,
+// returning the edited content along with the previous kind. Only the kind value is replaced in the original content,
+// so that comments, anchors and formatting are preserved.
+// An error is returned, leaving the content untouched, if no engine.kind is selected or if it is not driver driven.
+func editEngineKind(content string, matcher *contextMatcher, newKind string) (edited, previous string, err error) {
+	kinds, err := findEngineKinds(content)
+	if err != nil {
+		return content, "", err
+	}
+	kind, ok := selectEngineKind(kinds, matcher)
+	switch {
+	case !ok && matcher == nil:
+		return content, "", checkFalcoRunsWithDrivers("")
+	case !ok:
+		return content, "", engineKindError(fmt.Sprintf("no engine.kind matching context %q", matcher.String()))
+	}
+	if err := checkFalcoRunsWithDrivers(kind.kind); err != nil {
+		return content, kind.kind, err
+	}
+	lines := strings.Split(content, "\n")
+	line := lines[kind.line]
+	lines[kind.line] = line[:kind.valueStart] + kind.quote(newKind) + line[kind.valueEnd:]
+	return strings.Join(lines, "\n"), kind.kind, nil
+}
@@ -0,0 +1,343 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const falcoConfigWithCanary = `rules_file:
+  - /etc/falco/falco_rules.yaml
+
+# canary
+# engine used by the canary deployment
+canary:
+  engine:
+    kind: ebpf
+    ebpf:
+      probe: ${HOME}/.falco/falco-bpf.o
+
+# active
+engine:
+  # the driver in use
+  kind: kmod
+  kmod:
+    buf_size_preset: 4
+`
+
+const falcoConfigWithUnrelatedKind = `# top level kind, unrelated to the engine
+kind: kmod
+
+plugins:
+  - name: k8saudit
+    init_config:
+      kind: kmod
+
+# engine:
+#   kind: ebpf
+
+engine: &engine
+  # the driver in use
+  kind:   "kmod" # keep the spacing
+  kmod: {buf_size_preset: 4}
+`
+
+func TestEditEngineKindPreservesContent(t *testing.T) {
+	edited, previous, err := editEngineKind(falcoConfigWithUnrelatedKind, nil, "modern_ebpf")
+	require.NoError(t, err)
+	assert.Equal(t, "kmod", previous)
+	expected := strings.Replace(falcoConfigWithUnrelatedKind, `kind:   "kmod"`, `kind:   "modern_ebpf"`, 1)
+	assert.Equal(t, expected, edited)
+}
+
+func TestEditEngineKindSingleQuoted(t *testing.T) {
+	edited, _, err := editEngineKind("engine:\n  kind: 'kmod'\n", nil, "ebpf")
+	require.NoError(t, err)
+	assert.Equal(t, "engine:\n  kind: 'ebpf'\n", edited)
+}
+
+func TestEditEngineKindInvalidYAML(t *testing.T) {
+	content := "engine:\n  kind: [kmod\n"
+	edited, _, err := editEngineKind(content, nil, "ebpf")
+	assert.ErrorContains(t, err, "unable to parse Falco configuration")
+	assert.Equal(t, content, edited)
+}
+
+func TestEditEngineKind(t *testing.T) {
+	testCases := []struct {
+		name         string
+		content      string
+		matchContext string
+		expectedLine string
+		expectedPrev string
+		expectedErr  string
+	}{
+		{
+			name:         "defaults to the top-level engine.kind",
+			content:      falcoConfigWithCanary,
+			expectedLine: "  kind: modern_ebpf",
+			expectedPrev: "kmod",
+		},
+		{
+			name:        "nested engine.kind only",
+			content:     "canary:\n  engine:\n    kind: ebpf\n",
+			expectedErr: "engine.kind is not set",
+		},
+		{
+			name:         "nested engine.kind matched explicitly",
+			content:      "canary:\n  # canary\n  engine:\n    kind: ebpf\n",
+			matchContext: "canary",
+			expectedLine: "    kind: modern_ebpf",
+			expectedPrev: "ebpf",
+		},
+		{
+			name:         "negated context skips the matching block",
+			content:      falcoConfigWithCanary,
+			matchContext: "!# active",
+			expectedLine: "    kind: modern_ebpf",
+			expectedPrev: "ebpf",
+		},
+		{
+			name:         "selects the block matching the context",
+			content:      falcoConfigWithCanary,
+			matchContext: "^# active$",
+			expectedLine: "  kind: modern_ebpf",
+			expectedPrev: "kmod",
+		},
+		{
+			name:         "no block matching the context",
+			content:      falcoConfigWithCanary,
+			matchContext: "staging",
+			expectedErr:  `no engine.kind matching context "staging"`,
+		},
+		{
+			name:        "engine not driver driven",
+			content:     "engine:\n  kind: gvisor\n",
+			expectedErr: "engine.kind is not driver driven: gvisor",
+		},
+		{
+			name:        "missing engine.kind",
+			content:     "engine:\n  gvisor:\n    root: /gvisor\nkind: kmod\n",
+			expectedErr: "engine.kind is not set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matcher, err := newContextMatcher(tc.matchContext)
+			require.NoError(t, err)
+
+			edited, previous, err := editEngineKind(tc.content, matcher, "modern_ebpf")
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				assert.Equal(t, tc.content, edited)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPrev, previous)
+			assert.Contains(t, edited, tc.expectedLine+"\n")
+			// Only one occurrence is edited.
+			assert.Equal(t, len(tc.content)+len("modern_ebpf")-4, len(edited))
+		})
+	}
+}
+
+func TestNewContextMatcherInvalid(t *testing.T) {
+	_, err := newContextMatcher("(")
+	assert.Error(t, err)
+}
+
+func TestReplaceDriverTypeInFalcoConfig(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	t.Run("flag", func(t *testing.T) {
+		falcoConfig := filepath.Join(t.TempDir(), "falco.yaml")
+		require.NoError(t, os.WriteFile(falcoConfig, []byte("engine:\n  kind: kmod\n"), 0o600))
+
+		o := newTestOptions(true)
+		o.FalcoConfig = falcoConfig
+		require.NoError(t, o.replaceDriverTypeInFalcoConfig(driverType))
+
+		data, err := os.ReadFile(falcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "engine:\n  kind: modern_ebpf\n", string(data))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		falcoConfig := filepath.Join(t.TempDir(), "falco.yaml")
+		require.NoError(t, os.WriteFile(falcoConfig, []byte("engine:\n  kind: kmod\n"), 0o600))
+
+		o := newTestOptions(true)
+		o.FalcoConfig = falcoConfig
+		o.DryRun = true
+		require.NoError(t, o.replaceDriverTypeInFalcoConfig(driverType))
+
+		data, err := os.ReadFile(falcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "engine:\n  kind: kmod\n", string(data))
+	})
+
+	t.Run("host root", func(t *testing.T) {
+		hostRoot := t.TempDir()
+		falcoConfig := filepath.Join(hostRoot, "etc", "falco", "falco.yaml")
+		require.NoError(t, os.MkdirAll(filepath.Dir(falcoConfig), 0o750))
+		require.NoError(t, os.WriteFile(falcoConfig, []byte("engine:\n  kind: kmod\n"), 0o600))
+
+		o := newTestOptions(true)
+		o.Driver.HostRoot = hostRoot
+		path, err := falcoConfigPath(o.Driver.HostRoot, o.FalcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, falcoConfig, path)
+		require.NoError(t, o.replaceDriverTypeInFalcoConfig(driverType))
+
+		data, err := os.ReadFile(falcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "engine:\n  kind: modern_ebpf\n", string(data))
+	})
+
+	t.Run("host root traversal", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.Driver.HostRoot = t.TempDir()
+		o.FalcoConfig = "/etc/falco/../../../falco.yaml"
+		assert.EqualError(t, o.replaceDriverTypeInFalcoConfig(driverType), `path "/etc/falco/../../../falco.yaml" escapes the host root`)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.FalcoConfig = filepath.Join(t.TempDir(), "falco", "falco.yaml")
+		err := o.replaceDriverTypeInFalcoConfig(driverType)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.ErrorContains(t, err, "use --falco-config to point at the Falco config file, or --create-if-missing to create it")
+		assert.NoDirExists(t, filepath.Dir(o.FalcoConfig))
+	})
+
+	t.Run("missing created", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.FalcoConfig = filepath.Join(t.TempDir(), "falco", "falco.yaml")
+		o.CreateIfMissing = true
+		require.NoError(t, o.replaceDriverTypeInFalcoConfig(driverType))
+
+		data, err := os.ReadFile(o.FalcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "engine:\n  kind: modern_ebpf\n", string(data))
+		// The created file is a regular Falco config file, further updated as usual.
+		kmod, err := drivertype.Parse(drivertype.TypeKmod)
+		require.NoError(t, err)
+		require.NoError(t, o.replaceDriverTypeInFalcoConfig(kmod))
+		data, err = os.ReadFile(o.FalcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "engine:\n  kind: kmod\n", string(data))
+	})
+
+	t.Run("missing created dry run", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.FalcoConfig = filepath.Join(t.TempDir(), "falco", "falco.yaml")
+		o.CreateIfMissing = true
+		o.DryRun = true
+		require.NoError(t, o.replaceDriverTypeInFalcoConfig(driverType))
+		assert.NoDirExists(t, filepath.Dir(o.FalcoConfig))
+	})
+
+	t.Run("present not recreated", func(t *testing.T) {
+		falcoConfig := filepath.Join(t.TempDir(), "falco.yaml")
+		require.NoError(t, os.WriteFile(falcoConfig, []byte("# custom\nengine:\n  kind: kmod\n"), 0o600))
+
+		o := newTestOptions(true)
+		o.FalcoConfig = falcoConfig
+		o.CreateIfMissing = true
+		require.NoError(t, o.replaceDriverTypeInFalcoConfig(driverType))
+
+		data, err := os.ReadFile(falcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "# custom\nengine:\n  kind: modern_ebpf\n", string(data))
+	})
+}
+
+func TestValidateDriverType(t *testing.T) {
+	kr := kernelrelease.FromString("3.10.0-1160.el7.x86_64")
+	kr.Architecture = kernelrelease.Architecture("amd64")
+
+	kmod, err := drivertype.Parse(drivertype.TypeKmod)
+	require.NoError(t, err)
+	o := newTestOptions(true)
+	o.Driver = &options.Driver{Type: kmod, Kr: kr}
+	assert.NoError(t, o.validateDriverType())
+
+	// The eBPF probe needs at least a 4.14 kernel.
+	bpf, err := drivertype.Parse(drivertype.TypeBpf)
+	require.NoError(t, err)
+	o.Driver.Type = bpf
+	err = o.validateDriverType()
+	assert.ErrorContains(t, err, `driver type "ebpf" is not supported by kernel 3.10.0-1160.el7.x86_64 (x86_64), supported types: [kmod`)
+}
+
+func TestEditEngineKindDriverSubtree(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		expected    string
+		previous    string
+		expectedErr string
+	}{
+		{
+			name:     "driver sub-object without kind",
+			content:  "engine:\n  kmod:\n    buf_size_preset: 4\n    drop_failed_exit: false\n",
+			expected: "engine:\n  modern_ebpf:\n    buf_size_preset: 4\n    drop_failed_exit: false\n",
+			previous: drivertype.TypeKmod,
+		},
+		{
+			name:     "kind takes precedence over the sub-objects",
+			content:  "engine:\n  kind: kmod\n  kmod:\n    buf_size_preset: 4\n  ebpf:\n    probe: /root/.falco/falco-bpf.o\n",
+			expected: "engine:\n  kind: modern_ebpf\n  kmod:\n    buf_size_preset: 4\n  ebpf:\n    probe: /root/.falco/falco-bpf.o\n",
+			previous: drivertype.TypeKmod,
+		},
+		{
+			name:        "plugins only engine is left untouched",
+			content:     "engine:\n  replay:\n    capture_file: /tmp/capture.scap\n",
+			expectedErr: "engine.kind is not set",
+		},
+		{
+			name:        "ambiguous driver sub-objects are left untouched",
+			content:     "engine:\n  kmod:\n    buf_size_preset: 4\n  ebpf:\n    probe: /root/.falco/falco-bpf.o\n",
+			expectedErr: "engine.kind is not set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			edited, previous, err := editEngineKind(tc.content, nil, drivertype.TypeModernBpf)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				assert.Equal(t, tc.content, edited)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.previous, previous)
+			assert.Equal(t, tc.expected, edited)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	eventReasonDriverConfigUpdated = "DriverConfigUpdated"
+	eventComponent                 = "falcoctl"
+)
+
+// emitConfigMapEvent records a Kubernetes event on the patched configMap, for auditability.
+// Failing to create it, e.g. because of missing RBAC permissions, only warns.
+func (o *driverConfigOptions) emitConfigMapEvent(ctx context.Context, cl kubernetes.Interface,
+	configMap *corev1.ConfigMap, previous, current string) {
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: configMap.Name + ".",
+			Namespace:    configMap.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      "v1",
+			Kind:            "ConfigMap",
+			Name:            configMap.Name,
+			Namespace:       configMap.Namespace,
+			UID:             configMap.UID,
+			ResourceVersion: configMap.ResourceVersion,
+		},
+		Reason:         eventReasonDriverConfigUpdated,
+		Message:        fmt.Sprintf("Falco driver type changed from %q to %q", previous, current),
+		Type:           corev1.EventTypeNormal,
+		Source:         corev1.EventSource{Component: eventComponent},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := cl.CoreV1().Events(configMap.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		o.Printer.Logger.Warn("Unable to record the Kubernetes event", o.Printer.Logger.Args(
+			"configMap", configMap.Name, "namespace", configMap.Namespace, "reason", err))
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func TestReplaceDriverTypeInConfigMapsEvents(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	t.Run("recorded", func(t *testing.T) {
+		cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod))
+		o := newTestOptions(false)
+		o.EmitEvents = true
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+
+		events, err := cl.CoreV1().Events("falco").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, events.Items, 1)
+		event := events.Items[0]
+		assert.Equal(t, eventReasonDriverConfigUpdated, event.Reason)
+		assert.Equal(t, `Falco driver type changed from "kmod" to "modern_ebpf"`, event.Message)
+		assert.Equal(t, corev1.EventTypeNormal, event.Type)
+		assert.Equal(t, corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Name: "falco", Namespace: "falco"},
+			event.InvolvedObject)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod))
+		o := newTestOptions(false)
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+
+		events, err := cl.CoreV1().Events("falco").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, events.Items)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod))
+		cl.PrependReactor("create", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "events"}, "", nil)
+		})
+		var out bytes.Buffer
+		o := newTestOptions(false)
+		o.Printer = output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, &out)
+		o.EmitEvents = true
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+		assert.Equal(t, []string{"falco/falco"}, o.result.targets(configMapKind, TargetUpdated))
+		assert.Contains(t, out.String(), "Unable to record the Kubernetes event")
+	})
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const longHistory = `Show the history of the driver configuration changes made by the driver config command, the most recent first.
+Only the last driver.historyLimit changes (20 by default) are kept in the falcoctl config file.
+`
+
+type driverConfigHistoryOptions struct {
+	*options.Common
+	Output string
+}
+
+func newDriverConfigHistoryCmd(opt *options.Common) *cobra.Command {
+	o := driverConfigHistoryOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "history [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the history of the driver configuration changes",
+		Long:                  longHistory,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return output.ValidateFormat(o.Output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunDriverConfigHistory()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Print the history as structured output instead of a table. One of 'yaml' or 'json'")
+	return cmd
+}
+
+// RunDriverConfigHistory implements the driver config history command.
+func (o *driverConfigHistoryOptions) RunDriverConfigHistory() error {
+	driverCfg, err := config.LoadDriver(o.ConfigFile)
+	if err != nil {
+		return err
+	}
+	history := driverCfg.History
+	if history == nil {
+		history = []config.DriverChange{}
+	}
+	if o.Output != "" {
+		return o.Printer.PrintStructured(o.Output, history)
+	}
+
+	data := make([][]string, 0, len(history))
+	for _, change := range history {
+		data = append(data, []string{change.Time, strings.Join(change.Previous, ","), strings.Join(change.Type, ","), change.Target, change.User})
+	}
+	return o.Printer.PrintTable(output.DriverConfigHistory, data)
+}
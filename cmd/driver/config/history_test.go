@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func TestRunDriverConfigHistory(t *testing.T) {
+	req := newApplyTestRequest(t)
+	req.FalcoConfig = filepath.Join(t.TempDir(), "falco.yaml")
+	require.NoError(t, os.WriteFile(req.FalcoConfig, []byte("engine:\n  kind: kmod\n"), 0o600))
+
+	_, err := Apply(context.Background(), req)
+	require.NoError(t, err)
+	ebpf, err := drivertype.Parse(drivertype.TypeBpf)
+	require.NoError(t, err)
+	req.Driver.Type = ebpf
+	req.Update = false
+	_, err = Apply(context.Background(), req)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	o := driverConfigHistoryOptions{
+		Common: &options.Common{
+			Printer:    output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, &out),
+			ConfigFile: req.ConfigFile,
+		},
+		Output: output.FormatJSON,
+	}
+	require.NoError(t, o.RunDriverConfigHistory())
+
+	var history []config.DriverChange
+	require.NoError(t, json.Unmarshal(out.Bytes(), &history))
+	require.Len(t, history, 2)
+	assert.Equal(t, []string{drivertype.TypeModernBpf}, history[0].Previous)
+	assert.Equal(t, []string{drivertype.TypeBpf}, history[0].Type)
+	assert.Empty(t, history[0].Target)
+	assert.Empty(t, history[1].Previous)
+	assert.Equal(t, []string{drivertype.TypeModernBpf}, history[1].Type)
+	assert.Equal(t, req.FalcoConfig, history[1].Target)
+}
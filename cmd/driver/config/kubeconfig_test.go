@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const kubeConfigWithContexts = `apiVersion: v1
+kind: Config
+clusters:
+- name: production
+  cluster:
+    server: https://production.example.com:6443
+- name: staging
+  cluster:
+    server: https://staging.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: production
+  context:
+    cluster: production
+    user: admin
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+current-context: production
+`
+
+func TestRestConfig(t *testing.T) {
+	kubeConfig := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeConfig, []byte(kubeConfigWithContexts), 0o600))
+
+	t.Run("current context", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.KubeConfig = kubeConfig
+		cfg, err := o.restConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "https://production.example.com:6443", cfg.Host)
+	})
+
+	t.Run("selected context", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.KubeConfig = kubeConfig
+		o.KubeContext = "staging"
+		cfg, err := o.restConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "https://staging.example.com:6443", cfg.Host)
+	})
+
+	t.Run("unknown context", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.KubeConfig = kubeConfig
+		o.KubeContext = "development"
+		_, err := o.restConfig()
+		assert.ErrorContains(t, err, `context "development" does not exist`)
+	})
+}
+
+func TestReplaceDriverTypeInK8STimeout(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	// An API server that never answers, until the client gives up or the test ends.
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+	cl, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+
+	o := newTestOptions(false)
+	o.KubeClient = cl
+	o.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	err = o.replaceDriverTypeInK8S(context.Background(), driverType)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "the Kubernetes API server did not answer within 100ms, consider increasing --timeout")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// testCA is a self-signed certificate, only used to check the CA file parsing.
+const testCA = `-----BEGIN CERTIFICATE-----
+MIIBhzCCAS2gAwIBAgIUOXBVOSgoFVjF5RX1wiIwWF3dI2cwCgYIKoZIzj0EAwIw
+GDEWMBQGA1UEAwwNZmFsY29jdGwtdGVzdDAgFw0yNjEwMTQwNzA5MzdaGA8yMTI2
+MDkyMDA3MDkzN1owGDEWMBQGA1UEAwwNZmFsY29jdGwtdGVzdDBZMBMGByqGSM49
+AgEGCCqGSM49AwEHA0IABPRi+dZ7BNjlejJc9sG/8J0oPtjNh0mHrBg97G9Ti2s/
+XdMpsGYXAqYZQUDTAD6HN80rd0l88TeN1jyS2Hp/8w6jUzBRMB0GA1UdDgQWBBT8
+nt0RHnf+DJ7KNWKpgCgCIH28bzAfBgNVHSMEGDAWgBT8nt0RHnf+DJ7KNWKpgCgC
+IH28bzAPBgNVHRMBAf8EBTADAQH/MAoGCCqGSM49BAMCA0gAMEUCIQCX8hGAcHct
+pmd39GQZRE4rz8gE5MdxnXQhSEOBXJjDSQIgMdIabgwNWvbVcbwBwHNfUCg6MDoY
+kTghI5ju0Izoujc=
+-----END CERTIFICATE-----
+`
+
+func TestRestConfigCAFileAndProxy(t *testing.T) {
+	dir := t.TempDir()
+	kubeConfig := filepath.Join(dir, "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeConfig, []byte(kubeConfigWithContexts), 0o600))
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte(testCA), 0o600))
+	t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+	t.Setenv("NO_PROXY", "staging.example.com")
+
+	t.Run("ca file and proxy", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.KubeConfig = kubeConfig
+		o.CAFile = caFile
+		cfg, err := o.restConfig()
+		require.NoError(t, err)
+		assert.Equal(t, caFile, cfg.CAFile)
+		assert.Empty(t, cfg.CAData)
+		require.NotNil(t, cfg.Proxy)
+
+		req, err := http.NewRequest(http.MethodGet, "https://production.example.com:6443", http.NoBody)
+		require.NoError(t, err)
+		proxy, err := cfg.Proxy(req)
+		require.NoError(t, err)
+		require.NotNil(t, proxy)
+		assert.Equal(t, "proxy.example.com:3128", proxy.Host)
+
+		req, err = http.NewRequest(http.MethodGet, "https://staging.example.com:6443", http.NoBody)
+		require.NoError(t, err)
+		proxy, err = cfg.Proxy(req)
+		require.NoError(t, err)
+		assert.Nil(t, proxy)
+	})
+
+	t.Run("no ca file", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.KubeConfig = kubeConfig
+		cfg, err := o.restConfig()
+		require.NoError(t, err)
+		assert.Empty(t, cfg.CAFile)
+	})
+
+	t.Run("missing ca file", func(t *testing.T) {
+		o := newTestOptions(true)
+		o.KubeConfig = kubeConfig
+		o.CAFile = filepath.Join(dir, "missing.pem")
+		_, err := o.restConfig()
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("invalid ca file", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.pem")
+		require.NoError(t, os.WriteFile(invalid, []byte("not a certificate"), 0o600))
+		o := newTestOptions(true)
+		o.KubeConfig = kubeConfig
+		o.CAFile = invalid
+		_, err := o.restConfig()
+		assert.ErrorContains(t, err, "does not contain any valid PEM certificate")
+	})
+}
+
+func TestSetFlagDefaults(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("driver:\n  config:\n    namespace: from-config\n    kubeconfig: /from/config\n"), 0o600))
+
+	tests := []struct {
+		name              string
+		args              []string
+		env               string
+		withConfig        bool
+		expectedNamespace string
+	}{
+		{name: "built-in default", expectedNamespace: ""},
+		{name: "config file", withConfig: true, expectedNamespace: "from-config"},
+		{name: "env", withConfig: true, env: "from-env", expectedNamespace: "from-env"},
+		{name: "flag", withConfig: true, env: "from-env", args: []string{"--namespace", "from-flag"}, expectedNamespace: "from-flag"},
+		{name: "all namespaces", withConfig: true, env: "from-env", args: []string{"--all-namespaces"}, expectedNamespace: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			if tt.env != "" {
+				t.Setenv("FALCOCTL_DRIVER_CONFIG_NAMESPACE", tt.env)
+			}
+			if tt.withConfig {
+				require.NoError(t, config.Load(configFile))
+			}
+			cmd := NewDriverConfigCmd(context.Background(), options.NewOptions(), &options.Driver{})
+			require.NoError(t, cmd.ParseFlags(tt.args))
+			require.NoError(t, setFlagDefaults(cmd))
+
+			assert.Equal(t, tt.expectedNamespace, cmd.Flags().Lookup("namespace").Value.String())
+			expectedKubeConfig := ""
+			if tt.withConfig {
+				expectedKubeConfig = "/from/config"
+			}
+			assert.Equal(t, expectedKubeConfig, cmd.Flags().Lookup("kubeconfig").Value.String())
+		})
+	}
+}
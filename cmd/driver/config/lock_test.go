@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+func TestReplaceDriverTypeInFalcoConfigConcurrent(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	falcoConfig := filepath.Join(t.TempDir(), "falco.yaml")
+
+	for round := 0; round < 50; round++ {
+		require.NoError(t, os.WriteFile(falcoConfig, []byte(falcoConfigWithCanary), 0o600))
+
+		// Each run edits a different engine block: without the lock, one of the edits may be lost.
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		errs := make([]error, 2)
+		for i, matchContext := range []string{"!# active", "^# active$"} {
+			o := newTestOptions(true)
+			o.FalcoConfig = falcoConfig
+			o.MatchContext = matchContext
+			o.LockTimeout = 10 * time.Second
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				errs[i] = o.replaceDriverTypeInFalcoConfig(driverType)
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+
+		data, err := os.ReadFile(falcoConfig)
+		require.NoError(t, err)
+		kinds, err := findEngineKinds(string(data))
+		require.NoError(t, err)
+		require.Len(t, kinds, 2)
+		assert.Equal(t, drivertype.TypeModernBpf, kinds[0].kind, "round %d: canary update lost", round)
+		assert.Equal(t, drivertype.TypeModernBpf, kinds[1].kind, "round %d: active update lost", round)
+	}
+}
+
+func TestReplaceDriverTypeInFalcoConfigLockTimeout(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	original := "engine:\n  kind: kmod\n"
+	falcoConfig := filepath.Join(t.TempDir(), "falco.yaml")
+	require.NoError(t, os.WriteFile(falcoConfig, []byte(original), 0o600))
+
+	unlock, err := utils.LockFile(falcoConfig+lockSuffix, 0)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, unlock()) }()
+
+	o := newTestOptions(true)
+	o.FalcoConfig = falcoConfig
+	o.LockTimeout = 100 * time.Millisecond
+	err = o.replaceDriverTypeInFalcoConfig(driverType)
+	assert.ErrorIs(t, err, utils.ErrLockTimeout)
+	assert.True(t, strings.Contains(err.Error(), "another falcoctl may be updating it"))
+
+	data, err := os.ReadFile(falcoConfig)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+
+	// Dry runs do not need the lock.
+	o.DryRun = true
+	assert.NoError(t, o.replaceDriverTypeInFalcoConfig(driverType))
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// defaultOpenShiftNamespace is the namespace where Falco is looked up when nothing else tells where it runs.
+	defaultOpenShiftNamespace = "falco"
+	instanceLabelKey          = "app.kubernetes.io/instance"
+)
+
+// falcoOperatorGVR is the resource of the Falco instances managed by the Falco operator.
+var falcoOperatorGVR = schema.GroupVersionResource{Group: "instance.falcosecurity.dev", Version: "v1alpha1", Resource: "falcos"}
+
+// serviceAccountNamespaceFile holds the namespace of the pod falcoctl runs in, when running in-cluster.
+var serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// dynamicClient returns the given dynamic client, or one built like the Kubernetes client.
+func (o *driverConfigOptions) dynamicClient() (dynamic.Interface, error) {
+	if o.DynamicClient != nil {
+		return o.DynamicClient, nil
+	}
+	cfg, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(cfg)
+}
+
+// discoverOpenShiftFalco sets the namespace and the instance label of the Falco deployment from the Falco operator
+// custom resource. Without operator, it falls back to the configmaps labeled as usual in the namespace falcoctl
+// runs in, as found in the service account of the pod, or in the well-known Falco namespace.
+func (o *driverConfigOptions) discoverOpenShiftFalco(ctx context.Context) error {
+	cl, err := o.dynamicClient()
+	if err != nil {
+		return err
+	}
+	falcos, err := cl.Resource(falcoOperatorGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	switch {
+	case err == nil && len(falcos.Items) > 0:
+		falco := &falcos.Items[0]
+		if len(falcos.Items) > 1 {
+			o.Printer.Logger.Warn("Multiple Falco operator instances found, using the first one", o.Printer.Logger.Args(
+				"namespace", falco.GetNamespace(), "name", falco.GetName()))
+		}
+		o.Namespace = falco.GetNamespace()
+		if o.Selector == "" && (o.InstanceLabel == "" || o.InstanceLabel == defaultInstanceLabel) {
+			o.InstanceLabel = instanceLabelKey + "=" + falco.GetName()
+		}
+		o.Printer.Logger.Info("Found Falco operator instance", o.Printer.Logger.Args(
+			"namespace", o.Namespace, "name", falco.GetName()))
+		return nil
+	case err == nil, apierrors.IsNotFound(err), meta.IsNoMatchError(err):
+		// The operator, or its custom resource definition, is not installed.
+	case apierrors.IsForbidden(err):
+		o.Printer.Logger.Warn("Not allowed to list the Falco operator instances", o.Printer.Logger.Args("reason", err))
+	default:
+		return err
+	}
+
+	o.Namespace = defaultOpenShiftNamespace
+	if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil && strings.TrimSpace(string(data)) != "" {
+		o.Namespace = strings.TrimSpace(string(data))
+	}
+	o.Printer.Logger.Info("No Falco operator instance found, looking up the Falco configmaps by label", o.Printer.Logger.Args(
+		"namespace", o.Namespace))
+	return nil
+}
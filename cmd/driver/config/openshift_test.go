@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+func newFalcoOperatorInstance(namespace, name string) *unstructured.Unstructured {
+	falco := &unstructured.Unstructured{}
+	falco.SetAPIVersion(falcoOperatorGVR.GroupVersion().String())
+	falco.SetKind("Falco")
+	falco.SetNamespace(namespace)
+	falco.SetName(name)
+	return falco
+}
+
+func newFakeDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{falcoOperatorGVR: "FalcoList"}, objects...)
+}
+
+func newOpenShiftTestOptions(t *testing.T) *driverConfigOptions {
+	o := newTestOptions(true)
+	o.Namespace = ""
+	o.OpenShift = true
+	o.InstanceLabel = defaultInstanceLabel
+
+	saNamespace := serviceAccountNamespaceFile
+	serviceAccountNamespaceFile = filepath.Join(t.TempDir(), "namespace")
+	t.Cleanup(func() { serviceAccountNamespaceFile = saNamespace })
+	return o
+}
+
+func TestDiscoverOpenShiftFalco(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	t.Run("operator instance", func(t *testing.T) {
+		o := newOpenShiftTestOptions(t)
+		o.DynamicClient = newFakeDynamicClient(newFalcoOperatorInstance("falco-system", "falco-prod"))
+		require.NoError(t, o.discoverOpenShiftFalco(context.Background()))
+		assert.Equal(t, "falco-system", o.Namespace)
+		assert.Equal(t, "app.kubernetes.io/instance=falco-prod", o.InstanceLabel)
+
+		configMap := newLabeledConfigMap("falco-prod", drivertype.TypeKmod, map[string]string{"app.kubernetes.io/instance": "falco-prod"})
+		configMap.Namespace = "falco-system"
+		var patched []string
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), newFakeClient([]*corev1.ConfigMap{configMap}, &patched), driverType))
+		assert.Equal(t, []string{"falco-prod"}, patched)
+	})
+
+	t.Run("operator instance with a custom selector", func(t *testing.T) {
+		o := newOpenShiftTestOptions(t)
+		o.Selector = "app=falco"
+		o.DynamicClient = newFakeDynamicClient(newFalcoOperatorInstance("falco-system", "falco-prod"))
+		require.NoError(t, o.discoverOpenShiftFalco(context.Background()))
+		assert.Equal(t, "falco-system", o.Namespace)
+		assert.Equal(t, defaultInstanceLabel, o.InstanceLabel)
+	})
+
+	t.Run("no operator instance", func(t *testing.T) {
+		o := newOpenShiftTestOptions(t)
+		o.DynamicClient = newFakeDynamicClient()
+		require.NoError(t, o.discoverOpenShiftFalco(context.Background()))
+		assert.Equal(t, defaultOpenShiftNamespace, o.Namespace)
+		assert.Equal(t, defaultInstanceLabel, o.InstanceLabel)
+
+		var patched []string
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(),
+			newFakeClient([]*corev1.ConfigMap{newConfigMap("falco", drivertype.TypeKmod)}, &patched), driverType))
+		assert.Equal(t, []string{"falco"}, patched)
+	})
+
+	t.Run("operator not installed", func(t *testing.T) {
+		o := newOpenShiftTestOptions(t)
+		require.NoError(t, os.WriteFile(serviceAccountNamespaceFile, []byte("falco-openshift\n"), 0o600))
+		cl := newFakeDynamicClient()
+		cl.PrependReactor("list", "falcos", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(falcoOperatorGVR.GroupResource(), "")
+		})
+		o.DynamicClient = cl
+		require.NoError(t, o.discoverOpenShiftFalco(context.Background()))
+		assert.Equal(t, "falco-openshift", o.Namespace)
+		assert.Equal(t, defaultInstanceLabel, o.InstanceLabel)
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		o := newOpenShiftTestOptions(t)
+		cl := newFakeDynamicClient()
+		cl.PrependReactor("list", "falcos", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewInternalError(assert.AnError)
+		})
+		o.DynamicClient = cl
+		assert.ErrorContains(t, o.discoverOpenShiftFalco(context.Background()), assert.AnError.Error())
+		assert.Empty(t, o.Namespace)
+	})
+}
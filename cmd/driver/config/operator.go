@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/net/context"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+// targetOperator updates the Falco custom resources of the Falco operator, which owns the configmaps it generates.
+const targetOperator = "operator"
+
+// falcoInstanceEngineKindField is the field of the Falco operator custom resources holding the engine kind.
+var falcoInstanceEngineKindField = []string{"spec", "falco", "engine", "kind"}
+
+var falcoInstancesPermissions = resourcePermissions{
+	apiGroup: falcoOperatorGVR.Group,
+	resource: falcoOperatorGVR.Resource,
+	verbs:    []string{"list", "patch"},
+}
+
+// checkFalcoInstancesPermissions is the same as checkConfigMapsPermissions, for the Falco operator custom resources.
+func (o *driverConfigOptions) checkFalcoInstancesPermissions(ctx context.Context, cl kubernetes.Interface) error {
+	return o.checkPermissions(ctx, cl, falcoInstancesPermissions)
+}
+
+// replaceDriverTypeInFalcoInstances sets the engine kind in the Falco operator custom resources,
+// so that the change survives the reconciliation of the configmaps by the operator.
+func (o *driverConfigOptions) replaceDriverTypeInFalcoInstances(ctx context.Context, driverType drivertype.DriverType) error {
+	cl, err := o.dynamicClient()
+	if err != nil {
+		return err
+	}
+	falcos, listErr := o.listFalcoInstances(ctx, cl)
+	if len(falcos) == 0 {
+		if listErr != nil {
+			return listErr
+		}
+		return fmt.Errorf("no Falco operator instances (%s) found in namespace %q, "+
+			"use --target=configmap if Falco is not managed by the operator", falcoOperatorGVR.GroupResource().String(), o.Namespace)
+	}
+
+	patchOpts := metav1.PatchOptions{}
+	if o.DryRun {
+		// Let the API server validate the patch, without persisting it.
+		patchOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	payload, err := falcoInstancePatch(driverType.String())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range falcos {
+		falco := &falcos[i]
+		target := falcoInstanceTarget(falco)
+		current, _, err := unstructured.NestedString(falco.Object, falcoInstanceEngineKindField...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to read the engine kind of Falco instance %q in namespace %q: %w",
+				falco.GetName(), falco.GetNamespace(), err))
+			o.result.addTarget(target, TargetFailed, err)
+			continue
+		}
+		// Without engine kind, the operator default applies: set it.
+		if current != "" {
+			if err := checkFalcoRunsWithDrivers(current); err != nil {
+				if o.Strict {
+					o.result.addTarget(target, TargetFailed, err)
+					return fmt.Errorf("unable to update Falco instance %q: %w", falco.GetName(), err)
+				}
+				o.Printer.Logger.Warn("Avoid updating Falco instance",
+					o.Printer.Logger.Args("falco", falco.GetName(), "namespace", falco.GetNamespace(), "reason", err))
+				o.result.addTarget(target, TargetSkipped, err)
+				o.result.skip(err)
+				continue
+			}
+			// Aliases of the driver type are still rewritten, to store its canonical form.
+			if current == driverType.String() {
+				o.result.addTarget(target, TargetUnchanged, nil)
+				continue
+			}
+		}
+
+		err = o.withRetries(ctx, func() error {
+			_, err := cl.Resource(falcoOperatorGVR).Namespace(falco.GetNamespace()).Patch(
+				ctx, falco.GetName(), types.MergePatchType, payload, patchOpts)
+			return err
+		})
+		o.result.addTarget(target, targetAction(err), err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to patch Falco instance %q in namespace %q: %w", falco.GetName(), falco.GetNamespace(), err))
+			continue
+		}
+		msg := "Updated Falco instance"
+		if o.DryRun {
+			msg = "Would update Falco instance"
+		}
+		o.Printer.Logger.Info(msg, o.Printer.Logger.Args(
+			"falco", falco.GetName(), "namespace", falco.GetNamespace(), "current", current, "new", driverType.String()))
+	}
+	return errors.Join(append([]error{listErr}, errs...)...)
+}
+
+// listFalcoInstances is the same as listConfigMaps, for the Falco operator custom resources.
+// Only the given label selector applies, the instance label being set on the resources generated by the operator.
+func (o *driverConfigOptions) listFalcoInstances(ctx context.Context, cl dynamic.Interface) ([]unstructured.Unstructured, error) {
+	listOpts := metav1.ListOptions{LabelSelector: o.Selector, FieldSelector: o.FieldSelector}
+	var (
+		falcos []unstructured.Unstructured
+		errs   []error
+	)
+	for _, namespace := range o.namespaces() {
+		var list *unstructured.UnstructuredList
+		err := o.withRetries(ctx, func() (err error) {
+			list, err = cl.Resource(falcoOperatorGVR).Namespace(namespace).List(ctx, listOpts)
+			return err
+		})
+		if meta.IsNoMatchError(err) {
+			return nil, fmt.Errorf("the Falco operator is not installed, %s is unknown: %w", falcoOperatorGVR.GroupResource().String(), err)
+		}
+		if err != nil {
+			errs = append(errs, o.namespaceError(namespace, err))
+			continue
+		}
+		falcos = append(falcos, list.Items...)
+	}
+	return falcos, errors.Join(errs...)
+}
+
+// falcoInstancePatch returns the JSON merge patch setting the engine kind of a Falco operator custom resource.
+func falcoInstancePatch(kind string) ([]byte, error) {
+	patch := map[string]interface{}{}
+	if err := unstructured.SetNestedField(patch, kind, falcoInstanceEngineKindField...); err != nil {
+		return nil, err
+	}
+	return json.Marshal(patch)
+}
+
+// falcoInstanceTarget identifies a Falco operator custom resource in the results.
+func falcoInstanceTarget(falco *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s/%s", falcoInstanceKind, falco.GetNamespace(), falco.GetName())
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+func newFalcoOperatorInstanceWithKind(namespace, name, engineKind string) *unstructured.Unstructured {
+	falco := newFalcoOperatorInstance(namespace, name)
+	if engineKind != "" {
+		_ = unstructured.SetNestedField(falco.Object, engineKind, falcoInstanceEngineKindField...)
+	}
+	return falco
+}
+
+func newOperatorTestOptions(t *testing.T, falcos ...*unstructured.Unstructured) *driverConfigOptions {
+	o := newTestOptions(false)
+	o.Target = targetOperator
+	o.KubeClient = newAccessReviewClient("list", "patch")
+	dynamicCl := newFakeDynamicClient()
+	for _, falco := range falcos {
+		_, err := dynamicCl.Resource(falcoOperatorGVR).Namespace(falco.GetNamespace()).Create(context.Background(), falco, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	o.DynamicClient = dynamicCl
+	return o
+}
+
+func falcoInstanceEngineKind(t *testing.T, o *driverConfigOptions, namespace, name string) string {
+	falco, err := o.DynamicClient.Resource(falcoOperatorGVR).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	kind, _, err := unstructured.NestedString(falco.Object, falcoInstanceEngineKindField...)
+	require.NoError(t, err)
+	return kind
+}
+
+func TestReplaceDriverTypeInFalcoInstances(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	t.Run("patched", func(t *testing.T) {
+		o := newOperatorTestOptions(t,
+			newFalcoOperatorInstanceWithKind("falco", "falco", drivertype.TypeKmod),
+			newFalcoOperatorInstanceWithKind("falco", "falco-defaults", ""),
+			newFalcoOperatorInstanceWithKind("falco", "falco-gvisor", "gvisor"),
+			newFalcoOperatorInstanceWithKind("other", "falco", drivertype.TypeKmod))
+		require.NoError(t, o.replaceDriverTypeInTargets(context.Background(), o.KubeClient, driverType))
+
+		assert.Equal(t, drivertype.TypeModernBpf, falcoInstanceEngineKind(t, o, "falco", "falco"))
+		assert.Equal(t, drivertype.TypeModernBpf, falcoInstanceEngineKind(t, o, "falco", "falco-defaults"))
+		assert.Equal(t, "gvisor", falcoInstanceEngineKind(t, o, "falco", "falco-gvisor"))
+		assert.Equal(t, drivertype.TypeKmod, falcoInstanceEngineKind(t, o, "other", "falco"))
+		assert.Equal(t, []string{"falco/falco", "falco/falco-defaults"}, o.result.targets(falcoInstanceKind, TargetUpdated))
+		assert.True(t, o.result.Skipped)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		o := newOperatorTestOptions(t, newFalcoOperatorInstanceWithKind("falco", "falco", drivertype.TypeModernBpf))
+		require.NoError(t, o.replaceDriverTypeInTargets(context.Background(), o.KubeClient, driverType))
+		assert.Empty(t, o.result.targets(falcoInstanceKind, TargetUpdated))
+		assert.Equal(t, []TargetResult{{Target: "falco/falco/falco", Action: TargetUnchanged}}, o.result.Targets)
+	})
+
+	t.Run("alias", func(t *testing.T) {
+		o := newOperatorTestOptions(t, newFalcoOperatorInstanceWithKind("falco", "falco", "modern-ebpf"))
+		require.NoError(t, o.replaceDriverTypeInTargets(context.Background(), o.KubeClient, driverType))
+		assert.Equal(t, drivertype.TypeModernBpf, falcoInstanceEngineKind(t, o, "falco", "falco"))
+		assert.Equal(t, []string{"falco/falco"}, o.result.targets(falcoInstanceKind, TargetUpdated))
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		o := newOperatorTestOptions(t, newFalcoOperatorInstanceWithKind("falco", "falco", "gvisor"))
+		o.Strict = true
+		err := o.replaceDriverTypeInTargets(context.Background(), o.KubeClient, driverType)
+		assert.ErrorIs(t, err, ErrEngineNotDriverDriven)
+	})
+
+	t.Run("missing instance", func(t *testing.T) {
+		o := newOperatorTestOptions(t, newFalcoOperatorInstanceWithKind("other", "falco", drivertype.TypeKmod))
+		err := o.replaceDriverTypeInTargets(context.Background(), o.KubeClient, driverType)
+		assert.EqualError(t, err, `no Falco operator instances (falcos.instance.falcosecurity.dev) found in namespace "falco", `+
+			"use --target=configmap if Falco is not managed by the operator")
+	})
+
+	t.Run("missing permissions", func(t *testing.T) {
+		o := newOperatorTestOptions(t, newFalcoOperatorInstanceWithKind("falco", "falco", drivertype.TypeKmod))
+		o.KubeClient = newAccessReviewClient("list")
+		err := o.replaceDriverTypeInTargets(context.Background(), o.KubeClient, driverType)
+		assert.ErrorContains(t, err, `missing permissions to patch falcos in namespace "falco"`)
+		assert.Equal(t, drivertype.TypeKmod, falcoInstanceEngineKind(t, o, "falco", "falco"))
+	})
+}
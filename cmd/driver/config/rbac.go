@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// resourcePermissions are the verbs needed on a resource to update the Falco configuration.
+type resourcePermissions struct {
+	apiGroup string
+	resource string
+	verbs    []string
+}
+
+var (
+	configMapsPermissions = resourcePermissions{resource: "configmaps", verbs: []string{"list", "patch"}}
+	daemonSetsPermissions = resourcePermissions{apiGroup: "apps", resource: "daemonsets", verbs: []string{"list", "patch"}}
+)
+
+// checkConfigMapsPermissions verifies, through SelfSubjectAccessReviews, that the current user
+// is allowed to list and patch configMaps in the target namespace, to get them when verifying the patches,
+// and to watch them in watch mode, so that we fail before touching anything.
+// When the review itself cannot be performed, it only warns.
+func (o *driverConfigOptions) checkConfigMapsPermissions(ctx context.Context, cl kubernetes.Interface) error {
+	perms := configMapsPermissions
+	if o.Verify {
+		perms.verbs = append([]string{"get"}, perms.verbs...)
+	}
+	if o.Watch {
+		perms.verbs = append(perms.verbs[:len(perms.verbs):len(perms.verbs)], "watch")
+	}
+	return o.checkPermissions(ctx, cl, perms)
+}
+
+// checkDaemonSetsPermissions is the same as checkConfigMapsPermissions, for daemonSets.
+func (o *driverConfigOptions) checkDaemonSetsPermissions(ctx context.Context, cl kubernetes.Interface) error {
+	return o.checkPermissions(ctx, cl, daemonSetsPermissions)
+}
+
+func (o *driverConfigOptions) checkPermissions(ctx context.Context, cl kubernetes.Interface, perms resourcePermissions) error {
+	var errs []error
+	for _, namespace := range o.namespaces() {
+		if err := o.checkNamespacePermissions(ctx, cl, perms, namespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (o *driverConfigOptions) checkNamespacePermissions(ctx context.Context, cl kubernetes.Interface,
+	perms resourcePermissions, namespace string) error {
+	var missing []string
+	for _, review := range o.reviewPermissions(ctx, cl, perms, namespace) {
+		if review.Error == "" && !review.Allowed {
+			missing = append(missing, review.Verb)
+		}
+	}
+	return missingPermissionsError(perms, namespace, missing)
+}
+
+// permissionReview is the outcome of the access review of a verb on a resource.
+type permissionReview struct {
+	Namespace string `json:"namespace" yaml:"namespace"`
+	Resource  string `json:"resource" yaml:"resource"`
+	Verb      string `json:"verb" yaml:"verb"`
+	Allowed   bool   `json:"allowed" yaml:"allowed"`
+	// Error is set when the review itself could not be performed.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// reviewPermissions reviews, through SelfSubjectAccessReviews, each verb of perms in the namespace.
+// The reviews that cannot be performed are logged as warnings.
+func (o *driverConfigOptions) reviewPermissions(ctx context.Context, cl kubernetes.Interface,
+	perms resourcePermissions, namespace string) []permissionReview {
+	reviews := make([]permissionReview, 0, len(perms.verbs))
+	for _, verb := range perms.verbs {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      verb,
+					Group:     perms.apiGroup,
+					Resource:  perms.resource,
+				},
+			},
+		}
+		outcome := permissionReview{Namespace: namespace, Resource: perms.resource, Verb: verb}
+		res, err := cl.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			o.Printer.Logger.Warn("Unable to check permissions on "+perms.resource,
+				o.Printer.Logger.Args("namespace", namespace, "verb", verb, "reason", err))
+			outcome.Error = err.Error()
+		} else {
+			outcome.Allowed = res.Status.Allowed
+		}
+		reviews = append(reviews, outcome)
+	}
+	return reviews
+}
+
+// missingPermissionsError describes the missing verbs of perms in the namespace, along with the RBAC rule granting them.
+// It returns nil when no verb is missing.
+func missingPermissionsError(perms resourcePermissions, namespace string, missing []string) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	verbs := make([]string, len(perms.verbs))
+	for i, verb := range perms.verbs {
+		verbs[i] = fmt.Sprintf("%q", verb)
+	}
+	scope := fmt.Sprintf("in namespace %q", namespace)
+	if namespace == metav1.NamespaceAll {
+		scope = "in all namespaces"
+	}
+	return fmt.Errorf("missing permissions to %s %s %s, required RBAC rule: "+
+		"{apiGroups: [%q], resources: [%q], verbs: [%s]}",
+		strings.Join(missing, " and "), perms.resource, scope, perms.apiGroup, perms.resource, strings.Join(verbs, ", "))
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const longRestore = `Restore the Falco config file from one of the backups made by the driver config command.
+Available backups are listed from the most recent to the oldest one.
+If no backup is given, the most recent one is restored.
+`
+
+type driverConfigRestoreOptions struct {
+	*options.Common
+	*options.Driver
+	FalcoConfig string
+	List        bool
+}
+
+func newDriverConfigRestoreCmd(opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverConfigRestoreOptions{
+		Common: opt,
+		Driver: driver,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "restore [backup] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Restore the Falco config file from a backup",
+		Long:                  longRestore,
+		Args:                  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backup := ""
+			if len(args) > 0 {
+				backup = args[0]
+			}
+			return o.RunDriverConfigRestore(backup)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.FalcoConfig, "falco-config", defaultFalcoConfig,
+		"Path of the Falco configuration file to restore, relative to the driver host root.")
+	cmd.Flags().BoolVar(&o.List, "list", false, "Only list the available backups, without restoring any.")
+	return cmd
+}
+
+// RunDriverConfigRestore implements the driver config restore command.
+func (o *driverConfigRestoreOptions) RunDriverConfigRestore(backup string) error {
+	falcoCfgFile, err := falcoConfigPath(o.Driver.HostRoot, o.FalcoConfig)
+	if err != nil {
+		return err
+	}
+	backups, err := listFalcoConfigBackups(falcoCfgFile)
+	if err != nil {
+		return err
+	}
+	for _, b := range backups {
+		o.Printer.Logger.Info("Found Falco configuration backup", o.Printer.Logger.Args("backup", b))
+	}
+	if o.List {
+		return nil
+	}
+
+	switch {
+	case backup == "":
+		if len(backups) == 0 {
+			return fmt.Errorf("no backups found for Falco configuration %q", falcoCfgFile)
+		}
+		backup = backups[0]
+	case filepath.Base(backup) == backup:
+		// A bare name refers to a backup next to the Falco configuration.
+		backup = filepath.Join(filepath.Dir(falcoCfgFile), backup)
+	}
+	if _, err = os.Stat(backup); err != nil {
+		return fmt.Errorf("unable to find backup %q: %w", backup, err)
+	}
+
+	if err = restoreFalcoConfig(falcoCfgFile, backup); err != nil {
+		return err
+	}
+	o.Printer.Logger.Info("Restored Falco configuration", o.Printer.Logger.Args("config", falcoCfgFile, "backup", backup))
+	return nil
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+// Result is the machine readable outcome of a driver configuration, printed by the driver config command.
+type Result struct {
+	Name     string `json:"name" yaml:"name"`
+	Version  string `json:"version" yaml:"version"`
+	Type     string `json:"type" yaml:"type"`
+	HostRoot string `json:"hostRoot" yaml:"hostRoot"`
+	// FalcoConfig is the Falco config file that was targeted, if any.
+	FalcoConfig string `json:"falcoConfig,omitempty" yaml:"falcoConfig,omitempty"`
+	// ConfigMaps, SkippedConfigMaps, DaemonSets, SkippedDaemonSets and FalcoInstances summarize the Targets.
+	// ConfigMaps are the Falco configmaps that were updated, as "namespace/name".
+	ConfigMaps []string `json:"configMaps,omitempty" yaml:"configMaps,omitempty"`
+	// SkippedConfigMaps are the Falco configmaps that do not run with a driver, as "namespace/name".
+	SkippedConfigMaps []string `json:"skippedConfigMaps,omitempty" yaml:"skippedConfigMaps,omitempty"`
+	// DaemonSets are the Falco daemonsets that were updated and restarted, as "namespace/name".
+	DaemonSets []string `json:"daemonSets,omitempty" yaml:"daemonSets,omitempty"`
+	// SkippedDaemonSets are the Falco daemonsets that do not select the driver through their env, as "namespace/name".
+	SkippedDaemonSets []string `json:"skippedDaemonSets,omitempty" yaml:"skippedDaemonSets,omitempty"`
+	// FalcoInstances are the Falco operator custom resources that were updated, as "namespace/name".
+	FalcoInstances []string `json:"falcoInstances,omitempty" yaml:"falcoInstances,omitempty"`
+	// Targets are the outcomes of the Falco configurations that were considered, one per config file, configmap, daemonset
+	// or Falco operator custom resource.
+	Targets []TargetResult `json:"targets,omitempty" yaml:"targets,omitempty"`
+	// RolledOut are the Falco workloads restarted by --rollout, as "daemonset/namespace/name" or "deployment/namespace/name".
+	RolledOut []string `json:"rolledOut,omitempty" yaml:"rolledOut,omitempty"`
+	// RolloutErrors are the errors restarting the Falco workloads, the Falco configurations being updated anyway.
+	RolloutErrors []string `json:"rolloutErrors,omitempty" yaml:"rolloutErrors,omitempty"`
+	// Restarted is the Falco systemd unit that was restarted, if any.
+	Restarted string `json:"restarted,omitempty" yaml:"restarted,omitempty"`
+	// Skipped is true when a Falco configuration was not updated since it does not run with a driver.
+	Skipped bool   `json:"skipped" yaml:"skipped"`
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	DryRun  bool   `json:"dryRun" yaml:"dryRun"`
+}
+
+// TargetAction is what was done to a Falco configuration.
+type TargetAction string
+
+const (
+	// TargetUpdated means that the Falco configuration was set to the driver type.
+	TargetUpdated TargetAction = "updated"
+	// TargetUnchanged means that the Falco configuration already used the driver type.
+	TargetUnchanged TargetAction = "unchanged"
+	// TargetSkipped means that the Falco configuration was left untouched since it does not run with a driver.
+	TargetSkipped TargetAction = "skipped"
+	// TargetFailed means that the Falco configuration could not be updated.
+	TargetFailed TargetAction = "failed"
+)
+
+// Kinds of the Kubernetes resources in the targets, as in "configmap/namespace/name".
+const (
+	configMapKind     = "configmap"
+	daemonSetKind     = "daemonset"
+	falcoInstanceKind = "falco"
+)
+
+// TargetResult is the outcome of a single Falco configuration.
+type TargetResult struct {
+	// Target is the Falco config file, or the resource as "configmap/namespace/name" or "daemonset/namespace/name".
+	Target string       `json:"target" yaml:"target"`
+	Action TargetAction `json:"action" yaml:"action"`
+	Error  string       `json:"error,omitempty" yaml:"error,omitempty"`
+	// PreviousHash and Hash fingerprint the Falco configuration before and after the update, see contentHash.
+	PreviousHash string `json:"previousHash,omitempty" yaml:"previousHash,omitempty"`
+	Hash         string `json:"hash,omitempty" yaml:"hash,omitempty"`
+}
+
+// contentHashes are the fingerprints of a Falco configuration before and after the update.
+type contentHashes struct {
+	previous string
+	current  string
+}
+
+// unchanged tells whether the update leaves the Falco configuration as it was.
+func (h contentHashes) unchanged() bool {
+	return h.previous != "" && h.previous == h.current
+}
+
+// addTarget records the outcome of a Falco configuration.
+func (r *Result) addTarget(target string, action TargetAction, err error) *TargetResult {
+	t := TargetResult{Target: target, Action: action}
+	if err != nil {
+		t.Error = err.Error()
+	}
+	r.Targets = append(r.Targets, t)
+	return &r.Targets[len(r.Targets)-1]
+}
+
+// targets returns the resources of the given kind whose outcome is action, as "namespace/name", sorted.
+func (r *Result) targets(kind string, action TargetAction) []string {
+	var names []string
+	for _, t := range r.Targets {
+		if name, ok := strings.CutPrefix(t.Target, kind+"/"); ok && t.Action == action {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// summarize fills the lists of the updated and skipped resources from the targets.
+func (r *Result) summarize() {
+	r.ConfigMaps = r.targets(configMapKind, TargetUpdated)
+	r.SkippedConfigMaps = r.targets(configMapKind, TargetSkipped)
+	r.DaemonSets = r.targets(daemonSetKind, TargetUpdated)
+	r.SkippedDaemonSets = r.targets(daemonSetKind, TargetSkipped)
+	r.FalcoInstances = r.targets(falcoInstanceKind, TargetUpdated)
+}
+
+// setHashes records the fingerprints of the Falco configuration, if known.
+func (t *TargetResult) setHashes(hashes contentHashes) {
+	t.PreviousHash = hashes.previous
+	t.Hash = hashes.current
+}
+
+// contentHash returns a short SHA-256 of the content, the first 12 hex digits, to trace the changes in the logs
+// without dumping them.
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// targetAction returns the action matching the error returned when updating a Falco configuration.
+func targetAction(err error) TargetAction {
+	switch {
+	case err == nil:
+		return TargetUpdated
+	case errors.Is(err, ErrEngineNotDriverDriven):
+		return TargetSkipped
+	default:
+		return TargetFailed
+	}
+}
+
+// skip records that a Falco configuration was not updated for the given reason.
+func (r *Result) skip(reason error) {
+	r.Skipped = true
+	if r.Reason == "" {
+		r.Reason = reason.Error()
+	}
+}
+
+func (o *driverConfigOptions) validateOutput() error {
+	return output.ValidateFormat(o.Output)
+}
+
+// printResult serializes the result in the requested output format.
+func (o *driverConfigOptions) printResult() error {
+	return o.Printer.PrintStructured(o.Output, o.result)
+}
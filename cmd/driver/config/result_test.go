@@ -0,0 +1,274 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func newOutputTestOptions(t *testing.T, out *bytes.Buffer, falcoConfigContent, format string) *driverConfigOptions {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	falcoConfig := filepath.Join(dir, "falco.yaml")
+	require.NoError(t, os.WriteFile(falcoConfig, []byte(falcoConfigContent), 0o600))
+	falcoctlConfig := filepath.Join(dir, "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(falcoctlConfig, nil, 0o600))
+
+	return &driverConfigOptions{
+		Request: Request{
+			Common: &options.Common{
+				Printer:    output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, out),
+				ConfigFile: falcoctlConfig,
+			},
+			Driver:      &options.Driver{Type: driverType, Name: "falco", Version: "7.0.0+driver", HostRoot: "/"},
+			Update:      true,
+			FalcoConfig: falcoConfig,
+			// Probing modern_ebpf support needs privileges.
+			SkipValidation: true,
+		},
+		Output: format,
+	}
+}
+
+func TestRunDriverConfigOutput(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: kmod\n", output.FormatJSON)
+		require.NoError(t, o.RunDriverConfig(context.Background()))
+
+		// Only the structured output is printed.
+		var res Result
+		require.NoError(t, json.Unmarshal(out.Bytes(), &res))
+		assert.Equal(t, Result{
+			Name:        "falco",
+			Version:     "7.0.0+driver",
+			Type:        drivertype.TypeModernBpf,
+			HostRoot:    "/",
+			FalcoConfig: o.FalcoConfig,
+			Targets: []TargetResult{{
+				Target:       o.FalcoConfig,
+				Action:       TargetUpdated,
+				PreviousHash: contentHash([]byte("engine:\n  kind: kmod\n")),
+				Hash:         contentHash([]byte("engine:\n  kind: modern_ebpf\n")),
+			}},
+		}, res)
+	})
+
+	t.Run("json skipped", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: gvisor\n", output.FormatJSON)
+		require.NoError(t, o.RunDriverConfig(context.Background()))
+
+		var res Result
+		require.NoError(t, json.Unmarshal(out.Bytes(), &res))
+		assert.True(t, res.Skipped)
+		assert.Equal(t, fmt.Sprintf("unable to update Falco configuration %q: engine.kind is not driver driven: gvisor", o.FalcoConfig), res.Reason)
+		assert.Equal(t, o.FalcoConfig, res.FalcoConfig)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: kmod\n", output.FormatYAML)
+		o.DryRun = true
+		require.NoError(t, o.RunDriverConfig(context.Background()))
+
+		var res Result
+		require.NoError(t, yaml.Unmarshal(out.Bytes(), &res))
+		assert.True(t, res.DryRun)
+		assert.False(t, res.Skipped)
+		assert.Equal(t, drivertype.TypeModernBpf, res.Type)
+	})
+}
+
+func TestRunDriverConfigStrict(t *testing.T) {
+	t.Run("skipped", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: gvisor\n", "")
+		require.NoError(t, o.RunDriverConfig(context.Background()))
+		assert.True(t, o.result.Skipped)
+		assert.Contains(t, out.String(), "Avoid updating Falco configuration")
+
+		// The driver configuration is stored anyway.
+		driverCfg, err := config.LoadDriver(o.ConfigFile)
+		require.NoError(t, err)
+		assert.Equal(t, []string{drivertype.TypeModernBpf}, driverCfg.Type)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: gvisor\n", "")
+		o.Strict = true
+		err := o.RunDriverConfig(context.Background())
+		assert.ErrorIs(t, err, ErrEngineNotDriverDriven)
+
+		driverCfg, err := config.LoadDriver(o.ConfigFile)
+		require.NoError(t, err)
+		assert.Empty(t, driverCfg.Type)
+	})
+
+	t.Run("strict configmaps", func(t *testing.T) {
+		driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+		require.NoError(t, err)
+
+		var patched []string
+		o := newTestOptions(true)
+		cl := newFakeClient([]*corev1.ConfigMap{newConfigMap("falco", "")}, &patched)
+		assert.ErrorIs(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType), ErrEngineNotDriverDriven)
+		assert.Empty(t, patched)
+	})
+}
+
+func TestReplaceDriverTypeInConfigMapsResult(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	var patched []string
+	o := newTestOptions(false)
+	cl := newFakeClient([]*corev1.ConfigMap{newConfigMap("falco", drivertype.TypeKmod), newConfigMap("falco-gvisor", "gvisor")}, &patched)
+	require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+	assert.Equal(t, []string{"falco/falco"}, o.result.targets(configMapKind, TargetUpdated))
+	assert.Equal(t, []string{"falco/falco-gvisor"}, o.result.targets(configMapKind, TargetSkipped))
+	assert.True(t, o.result.Skipped)
+}
+
+func TestValidateOutput(t *testing.T) {
+	o := &driverConfigOptions{Output: "table"}
+	assert.ErrorIs(t, o.validateOutput(), output.ErrInvalidFormat)
+	o.Output = ""
+	assert.NoError(t, o.validateOutput())
+}
+
+func TestRunDriverConfigQuiet(t *testing.T) {
+	t.Run("quiet", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: gvisor\n", "")
+		o.Quiet = true
+		require.NoError(t, o.RunDriverConfig(context.Background()))
+		assert.True(t, o.result.Skipped)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("log level respected", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: kmod\n", "")
+		o.Printer.Logger.Level = pterm.LogLevelDisabled
+		o.Quiet = true
+		require.NoError(t, o.RunDriverConfig(context.Background()))
+		assert.Empty(t, out.String())
+		assert.Equal(t, pterm.LogLevelDisabled, o.Printer.Logger.Level)
+	})
+
+	t.Run("quiet json", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		o := newOutputTestOptions(t, &stdout, "engine:\n  kind: kmod\n", output.FormatJSON)
+		o.Quiet = true
+		o.errOut = &stderr
+		require.NoError(t, o.RunDriverConfig(context.Background()))
+
+		var res Result
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &res))
+		assert.Equal(t, drivertype.TypeModernBpf, res.Type)
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("quiet json error", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		o := newOutputTestOptions(t, &stdout, "engine:\n  kind: gvisor\n", output.FormatJSON)
+		o.Quiet = true
+		o.Strict = true
+		o.errOut = &stderr
+		err := o.RunDriverConfig(context.Background())
+		require.ErrorIs(t, err, ErrEngineNotDriverDriven)
+
+		// The error is logged, as done by the root command, to stderr only.
+		o.Printer.CheckErr(err)
+		assert.Empty(t, stdout.String())
+		assert.Contains(t, stderr.String(), "engine.kind is not driver driven")
+	})
+}
+
+func TestTargetHashes(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	t.Run("falco config changed", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: kmod\n", "")
+		targets, err := o.commit(context.Background(), driverType)
+		require.NoError(t, err)
+		require.Len(t, targets, 1)
+		assert.Len(t, targets[0].PreviousHash, 12)
+		assert.NotEqual(t, targets[0].PreviousHash, targets[0].Hash)
+
+		data, err := os.ReadFile(o.FalcoConfig)
+		require.NoError(t, err)
+		assert.Equal(t, contentHash(data), targets[0].Hash)
+
+		o.logTargets(targets)
+		assert.Contains(t, out.String(), targets[0].Hash)
+	})
+
+	t.Run("falco config no-op", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newOutputTestOptions(t, &out, "engine:\n  kind: modern_ebpf\n", "")
+		targets, err := o.commit(context.Background(), driverType)
+		require.NoError(t, err)
+		require.Len(t, targets, 1)
+		assert.NotEmpty(t, targets[0].Hash)
+		assert.Equal(t, targets[0].PreviousHash, targets[0].Hash)
+	})
+
+	t.Run("configmaps", func(t *testing.T) {
+		var patched []string
+		o := newTestOptions(false)
+		o.Force = true
+		cl := newFakeClient([]*corev1.ConfigMap{
+			newConfigMap("falco", drivertype.TypeKmod),
+			newConfigMap("falco-same", drivertype.TypeModernBpf),
+		}, &patched)
+		require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+		require.Len(t, o.result.Targets, 2)
+		hashes := map[string]TargetResult{}
+		for _, target := range o.result.Targets {
+			hashes[target.Target] = target
+		}
+		changed := hashes["configmap/falco/falco"]
+		assert.Equal(t, contentHash([]byte(drivertype.TypeKmod)), changed.PreviousHash)
+		assert.Equal(t, contentHash([]byte(drivertype.TypeModernBpf)), changed.Hash)
+		same := hashes["configmap/falco/falco-same"]
+		assert.NotEmpty(t, same.Hash)
+		assert.Equal(t, same.PreviousHash, same.Hash)
+	})
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	defaultRetries       = 3
+	defaultRetryInterval = time.Second
+)
+
+// isRetriable reports whether the k8s API error is likely transient.
+func isRetriable(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsServerTimeout(err) || apierrors.IsTooManyRequests(err)
+}
+
+// withRetries runs fn, retrying it with an exponential backoff as long as it fails with a retriable error,
+// up to the configured number of retries.
+func (o *driverConfigOptions) withRetries(ctx context.Context, fn func() error) error {
+	interval := o.RetryInterval
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= o.Retries || !isRetriable(err) {
+			return err
+		}
+		o.Printer.Logger.Debug("Retrying Kubernetes API call", o.Printer.Logger.Args(
+			"attempt", attempt+1, "interval", interval.String(), "reason", err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
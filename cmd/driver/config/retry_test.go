@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+var configMapsResource = schema.GroupResource{Resource: "configmaps"}
+
+func TestReplaceDriverTypeInConfigMapsRetriesConflicts(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod))
+	attempts := 0
+	cl.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		if attempts == 1 {
+			return true, nil, apierrors.NewConflict(configMapsResource, "falco", errors.New("the object has been modified"))
+		}
+		return true, &corev1.ConfigMap{}, nil
+	})
+
+	o := newTestOptions(true)
+	o.Retries = 3
+	o.RetryInterval = time.Millisecond
+	require.NoError(t, o.replaceDriverTypeInConfigMaps(context.Background(), cl, driverType))
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []string{"falco/falco"}, o.result.targets(configMapKind, TargetUpdated))
+}
+
+func TestWithRetries(t *testing.T) {
+	o := newTestOptions(true)
+	o.Retries = 2
+	o.RetryInterval = time.Millisecond
+
+	t.Run("exhausted", func(t *testing.T) {
+		attempts := 0
+		err := o.withRetries(context.Background(), func() error {
+			attempts++
+			return apierrors.NewTooManyRequests("slow down", 0)
+		})
+		assert.True(t, apierrors.IsTooManyRequests(err))
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("not retriable", func(t *testing.T) {
+		attempts := 0
+		err := o.withRetries(context.Background(), func() error {
+			attempts++
+			return apierrors.NewForbidden(configMapsResource, "falco", errors.New("forbidden"))
+		})
+		assert.True(t, apierrors.IsForbidden(err))
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		o.RetryInterval = time.Hour
+		err := o.withRetries(ctx, func() error {
+			return apierrors.NewServerTimeout(configMapsResource, "patch", 1)
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrRollout is returned, wrapped, when the Falco configurations were updated but Falco could not be restarted
+// to pick them up. The rollout errors are also reported in the Result, apart from the Falco configurations.
+var ErrRollout = errors.New("unable to roll out Falco")
+
+// rolloutPatch is the strategic merge patch restarting the pods of a workload, as "kubectl rollout restart" does.
+func rolloutPatch(now time.Time) []byte {
+	return []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, now.Format(time.RFC3339)))
+}
+
+// rolloutFalco restarts the Falco daemonSets and deployments of the namespaces of the updated configMaps,
+// so that Falco reads the new driver type without waiting for its pods to be recreated.
+// The workloads are selected by the same label as the configMaps, the field selector only applying to the latter.
+func (o *driverConfigOptions) rolloutFalco(ctx context.Context) error {
+	configMaps := o.result.targets(configMapKind, TargetUpdated)
+	if len(configMaps) == 0 {
+		o.Printer.Logger.Info("No Falco configMap updated, nothing to roll out")
+		return nil
+	}
+	cl, err := o.kubeClient()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRollout, err)
+	}
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+	listOpts, err := o.listOptions()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRollout, err)
+	}
+	listOpts.FieldSelector = ""
+
+	var errs []error
+	for _, namespace := range configMapNamespaces(configMaps) {
+		errs = append(errs, o.rolloutNamespace(ctx, cl, namespace, listOpts)...)
+	}
+	for _, err := range errs {
+		o.result.RolloutErrors = append(o.result.RolloutErrors, err.Error())
+		o.Printer.Logger.Error("Falco not restarted", o.Printer.Logger.Args("reason", err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrRollout, errors.Join(errs...))
+	}
+	return nil
+}
+
+// rolloutNamespace restarts the Falco daemonSets and deployments of the namespace, returning the errors.
+func (o *driverConfigOptions) rolloutNamespace(ctx context.Context, cl kubernetes.Interface, namespace string,
+	listOpts metav1.ListOptions) []error {
+	var workloads []string
+	daemonSets, err := cl.AppsV1().DaemonSets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return []error{fmt.Errorf("unable to list daemonSets in namespace %q: %w", namespace, err)}
+	}
+	for i := range daemonSets.Items {
+		workloads = append(workloads, "daemonset/"+daemonSets.Items[i].Name)
+	}
+	deployments, err := cl.AppsV1().Deployments(namespace).List(ctx, listOpts)
+	if err != nil {
+		return []error{fmt.Errorf("unable to list deployments in namespace %q: %w", namespace, err)}
+	}
+	for i := range deployments.Items {
+		workloads = append(workloads, "deployment/"+deployments.Items[i].Name)
+	}
+	if len(workloads) == 0 {
+		o.Printer.Logger.Warn("No Falco daemonSet or deployment found, Falco must be restarted manually",
+			o.Printer.Logger.Args("namespace", namespace, "selector", listOpts.LabelSelector))
+		return nil
+	}
+
+	patch := rolloutPatch(time.Now())
+	patchOpts := metav1.PatchOptions{}
+	if o.DryRun {
+		patchOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	var errs []error
+	for _, workload := range workloads {
+		kind, name, _ := strings.Cut(workload, "/")
+		err := o.withRetries(ctx, func() (err error) {
+			if kind == "daemonset" {
+				_, err = cl.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, patchOpts)
+			} else {
+				_, err = cl.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, patchOpts)
+			}
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to restart %s %q in namespace %q: %w", kind, name, namespace, err))
+			continue
+		}
+		o.result.RolledOut = append(o.result.RolledOut, kind+"/"+namespace+"/"+name)
+		msg := "Restarted Falco"
+		if o.DryRun {
+			msg = "Would restart Falco"
+		}
+		o.Printer.Logger.Info(msg, o.Printer.Logger.Args(kind, name, "namespace", namespace))
+	}
+	return errs
+}
+
+// configMapNamespaces returns the sorted namespaces of the configMaps, given as "namespace/name".
+func configMapNamespaces(configMaps []string) []string {
+	seen := make(map[string]struct{}, len(configMaps))
+	namespaces := make([]string, 0, len(configMaps))
+	for _, cm := range configMaps {
+		namespace, _, _ := strings.Cut(cm, "/")
+		if _, ok := seen[namespace]; ok {
+			continue
+		}
+		seen[namespace] = struct{}{}
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+func newRolloutTestClient(engineKind string) *fake.Clientset {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "falco-k8s-metacollector", Namespace: "falco", Labels: map[string]string{"app.kubernetes.io/instance": "falco"}}}
+	other := newDaemonSet("other")
+	other.Labels = map[string]string{"app.kubernetes.io/instance": "other"}
+	cl := fake.NewSimpleClientset(newConfigMap("falco", engineKind), newDaemonSet("falco"), deployment, other)
+	allowAccessReviews(cl, "list", "patch")
+	return cl
+}
+
+func restartedAt(t *testing.T, cl kubernetes.Interface, kind, name string) string {
+	var template metav1.ObjectMeta
+	if kind == "daemonset" {
+		ds, err := cl.AppsV1().DaemonSets("falco").Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		template = ds.Spec.Template.ObjectMeta
+	} else {
+		deploy, err := cl.AppsV1().Deployments("falco").Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		template = deploy.Spec.Template.ObjectMeta
+	}
+	return template.Annotations[restartedAtAnnotation]
+}
+
+func TestApplyRollout(t *testing.T) {
+	t.Run("rollout", func(t *testing.T) {
+		cl := newRolloutTestClient(drivertype.TypeKmod)
+		req := newApplyTestRequest(t)
+		req.Namespace = "falco"
+		req.KubeClient = cl
+		req.Rollout = true
+		res, err := Apply(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"falco/falco"}, res.ConfigMaps)
+		assert.Equal(t, []string{"daemonset/falco/falco", "deployment/falco/falco-k8s-metacollector"}, res.RolledOut)
+		assert.NotEmpty(t, restartedAt(t, cl, "daemonset", "falco"))
+		assert.NotEmpty(t, restartedAt(t, cl, "deployment", "falco-k8s-metacollector"))
+		assert.Empty(t, restartedAt(t, cl, "daemonset", "other"))
+	})
+
+	t.Run("without the flag", func(t *testing.T) {
+		cl := newRolloutTestClient(drivertype.TypeKmod)
+		req := newApplyTestRequest(t)
+		req.Namespace = "falco"
+		req.KubeClient = cl
+		res, err := Apply(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"falco/falco"}, res.ConfigMaps)
+		assert.Empty(t, res.RolledOut)
+		assert.Empty(t, restartedAt(t, cl, "daemonset", "falco"))
+		assert.Empty(t, restartedAt(t, cl, "deployment", "falco-k8s-metacollector"))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		cl := newRolloutTestClient(drivertype.TypeModernBpf)
+		req := newApplyTestRequest(t)
+		req.Namespace = "falco"
+		req.KubeClient = cl
+		req.Rollout = true
+		res, err := Apply(context.Background(), req)
+		require.NoError(t, err)
+		assert.Empty(t, res.RolledOut)
+		assert.Empty(t, restartedAt(t, cl, "daemonset", "falco"))
+	})
+
+	t.Run("rollout errors", func(t *testing.T) {
+		cl := newRolloutTestClient(drivertype.TypeKmod)
+		cl.PrependReactor("patch", "daemonsets", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("admission webhook denied the request")
+		})
+		req := newApplyTestRequest(t)
+		req.Namespace = "falco"
+		req.KubeClient = cl
+		req.Rollout = true
+		res, err := Apply(context.Background(), req)
+		assert.ErrorIs(t, err, ErrRollout)
+		assert.Equal(t, []TargetResult{{Target: "configmap/falco/falco", Action: TargetUpdated,
+			PreviousHash: contentHash([]byte(drivertype.TypeKmod)), Hash: contentHash([]byte(drivertype.TypeModernBpf))}}, res.Targets)
+		assert.Equal(t, []string{"deployment/falco/falco-k8s-metacollector"}, res.RolledOut)
+		assert.Equal(t, []string{`unable to restart daemonset "falco" in namespace "falco": admission webhook denied the request`},
+			res.RolloutErrors)
+	})
+}
+
+func TestRolloutFalcoConfig(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	t.Run("updated", func(t *testing.T) {
+		cmds := fakeRunCommand(t, nil)
+		o := newRestartTestOptions(t, "falco-modern-bpf.service")
+		o.Restart = false
+		o.Rollout = true
+		_, err := o.commit(context.Background(), driverType)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"systemctl daemon-reload", "systemctl restart falco-modern-bpf.service"}, *cmds)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		cmds := fakeRunCommand(t, nil)
+		o := newRestartTestOptions(t, "falco-modern-bpf.service")
+		require.NoError(t, os.WriteFile(filepath.Join(o.Driver.HostRoot, "etc", "falco", "falco.yaml"),
+			[]byte("engine:\n  kind: modern_ebpf\n"), 0o600))
+		o.Restart = false
+		o.Rollout = true
+		_, err := o.commit(context.Background(), driverType)
+		require.NoError(t, err)
+		assert.Empty(t, *cmds)
+	})
+}
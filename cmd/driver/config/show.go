@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/context"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const longShow = `Show the driver configuration stored by the driver config command.
+The engine.kind currently set in the Falco config file, or in the Falco configmaps when a namespace is given,
+is shown too, flagging any drift from the stored driver types.
+`
+
+type driverConfigShowOptions struct {
+	driverConfigOptions
+}
+
+// driverConfigShow is the stored driver configuration, along with the live engine.kind values.
+type driverConfigShow struct {
+	Name         string           `json:"name" yaml:"name"`
+	Version      string           `json:"version" yaml:"version"`
+	Type         []string         `json:"type" yaml:"type"`
+	HostRoot     string           `json:"hostRoot" yaml:"hostRoot"`
+	Repos        []string         `json:"repos" yaml:"repos"`
+	PrependRepos []string         `json:"prependRepos,omitempty" yaml:"prependRepos,omitempty"`
+	Live         []liveEngineKind `json:"live,omitempty" yaml:"live,omitempty"`
+	Drift        bool             `json:"drift" yaml:"drift"`
+}
+
+// liveEngineKind is the engine.kind set in a Falco config file or configmap.
+type liveEngineKind struct {
+	Source string `json:"source" yaml:"source"`
+	Kind   string `json:"kind" yaml:"kind"`
+	Drift  bool   `json:"drift" yaml:"drift"`
+}
+
+func newDriverConfigShowCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverConfigShowOptions{
+		driverConfigOptions: driverConfigOptions{
+			Request: Request{Common: opt, Driver: driver},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "show [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the stored driver configuration",
+		Long:                  longShow,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.validateOutput(); err != nil {
+				return err
+			}
+			return setFlagDefaults(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunDriverConfigShow(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.MatchContext, "match-context", "",
+		"Regex matched against the comments preceding each engine block, to select the engine.kind to show (prefix with '!' to negate). "+
+			"Defaults to the top-level one.")
+	cmd.Flags().StringVar(&o.FalcoConfig, "falco-config", defaultFalcoConfig,
+		"Path of the Falco configuration file to read, relative to the driver host root.")
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "", "Kubernetes namespace.")
+	cmd.Flags().StringVar(&o.KubeConfig, "kubeconfig", "", "Kubernetes config.")
+	cmd.Flags().StringVar(&o.KubeContext, "context", "", "Kubernetes context to use, instead of the current one of the kubeconfig.")
+	cmd.Flags().StringVar(&o.CAFile, "ca-file", "", "PEM bundle of the CAs verifying the Kubernetes API server, instead of the ones of the kubeconfig.")
+	cmd.Flags().StringVar(&o.InstanceLabel, "instance-label", defaultInstanceLabel,
+		"Label, in the \"key=value\" format, selecting the Falco configmaps to read (e.g. to match a different Helm release name).")
+	cmd.Flags().StringVar(&o.Selector, "selector", "", "Label selector of the Falco configmaps to read, instead of the instance label.")
+	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", "", "Field selector further filtering the Falco configmaps to read.")
+	cmd.Flags().StringVar(&o.ConfigMapKey, "configmap-key", configMapEngineKindKey,
+		"Data key of the Falco configmaps holding either the engine kind or the whole Falco configuration (e.g. falco.yaml).")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Print the driver configuration as structured output instead of a table. One of 'yaml' or 'json'")
+	cmd.MarkFlagsMutuallyExclusive("selector", "instance-label")
+	return cmd
+}
+
+// RunDriverConfigShow implements the driver config show command.
+func (o *driverConfigShowOptions) RunDriverConfigShow(ctx context.Context) error {
+	driverCfg, err := config.LoadDriver(o.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	var live []liveEngineKind
+	if o.Namespace != "" {
+		cl, err := o.kubeClient()
+		if err != nil {
+			return err
+		}
+		if live, err = o.liveEngineKindsInConfigMaps(ctx, cl); err != nil {
+			return err
+		}
+	} else if live, err = o.liveEngineKindInFalcoConfig(); err != nil {
+		return err
+	}
+	return o.printDriverConfig(newDriverConfigShow(driverCfg, live))
+}
+
+func newDriverConfigShow(driverCfg *config.Driver, live []liveEngineKind) *driverConfigShow {
+	show := &driverConfigShow{
+		Name:         driverCfg.Name,
+		Version:      driverCfg.Version,
+		Type:         driverCfg.Type,
+		HostRoot:     driverCfg.HostRoot,
+		Repos:        driverCfg.Repos,
+		PrependRepos: driverCfg.PrependRepos,
+		Live:         live,
+	}
+	for i := range show.Live {
+		show.Live[i].Drift = !slices.Contains(driverCfg.Type, canonicalEngineKind(show.Live[i].Kind))
+		show.Drift = show.Drift || show.Live[i].Drift
+	}
+	return show
+}
+
+// liveEngineKindInFalcoConfig returns the engine.kind set in the Falco config file, if any.
+func (o *driverConfigShowOptions) liveEngineKindInFalcoConfig() ([]liveEngineKind, error) {
+	falcoCfgFile, err := falcoConfigPath(o.Driver.HostRoot, o.FalcoConfig)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(falcoCfgFile)
+	if errors.Is(err, os.ErrNotExist) {
+		o.Printer.Logger.Debug("No Falco configuration found", o.Printer.Logger.Args("config", falcoCfgFile))
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	matcher, err := newContextMatcher(o.MatchContext)
+	if err != nil {
+		return nil, err
+	}
+	kinds, err := findEngineKinds(string(content))
+	if err != nil {
+		return nil, err
+	}
+	kind, ok := selectEngineKind(kinds, matcher)
+	if !ok {
+		return nil, nil
+	}
+	return []liveEngineKind{{Source: falcoCfgFile, Kind: kind.kind}}, nil
+}
+
+// liveEngineKindsInConfigMaps returns the engine.kind set in the Falco configmaps.
+func (o *driverConfigShowOptions) liveEngineKindsInConfigMaps(ctx context.Context, cl kubernetes.Interface) ([]liveEngineKind, error) {
+	listOpts, err := o.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	configMapList, err := cl.CoreV1().ConfigMaps(o.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := newContextMatcher(o.MatchContext)
+	if err != nil {
+		return nil, err
+	}
+	live := make([]liveEngineKind, 0, len(configMapList.Items))
+	for i := range configMapList.Items {
+		configMap := &configMapList.Items[i]
+		kind, err := o.configMapEngineKind(configMap, matcher)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read configMap %q in namespace %q: %w", configMap.Name, configMap.Namespace, err)
+		}
+		live = append(live, liveEngineKind{
+			Source: fmt.Sprintf("configmap/%s/%s", configMap.Namespace, configMap.Name),
+			Kind:   kind,
+		})
+	}
+	return live, nil
+}
+
+func (o *driverConfigShowOptions) printDriverConfig(show *driverConfigShow) error {
+	if o.Output != "" {
+		return o.Printer.PrintStructured(o.Output, show)
+	}
+
+	for _, l := range show.Live {
+		if l.Drift {
+			o.Printer.Logger.Warn("Falco engine.kind drifted from the stored driver configuration", o.Printer.Logger.Args(
+				"source", l.Source, "live", l.Kind, "stored", strings.Join(show.Type, ",")))
+		}
+	}
+	row := []string{show.Name, show.Version, strings.Join(show.Type, ","), show.HostRoot, strings.Join(show.Repos, ",")}
+	data := make([][]string, 0, len(show.Live))
+	for _, l := range show.Live {
+		data = append(data, append(append([]string{}, row...), l.Source, l.Kind, fmt.Sprint(l.Drift)))
+	}
+	if len(data) == 0 {
+		data = append(data, append(row, "", "", ""))
+	}
+	return o.Printer.PrintTable(output.DriverConfigShow, data)
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const storedDriverConfig = `driver:
+  type: [kmod]
+  name: falco
+  version: 7.0.0+driver
+  hostRoot: /
+  repos: [https://download.falco.org/driver]
+`
+
+func newShowTestOptions(t *testing.T, out *bytes.Buffer, engineKind string) *driverConfigShowOptions {
+	dir := t.TempDir()
+	falcoctlConfig := filepath.Join(dir, "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(falcoctlConfig, []byte(storedDriverConfig), 0o600))
+	falcoConfig := filepath.Join(dir, "falco.yaml")
+	require.NoError(t, os.WriteFile(falcoConfig, []byte("engine:\n  kind: "+engineKind+"\n"), 0o600))
+
+	return &driverConfigShowOptions{
+		driverConfigOptions: driverConfigOptions{
+			Request: Request{
+				Common: &options.Common{
+					Printer:    output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, out),
+					ConfigFile: falcoctlConfig,
+				},
+				Driver:      &options.Driver{HostRoot: "/"},
+				FalcoConfig: falcoConfig,
+			},
+			Output: output.FormatJSON,
+		},
+	}
+}
+
+func TestRunDriverConfigShow(t *testing.T) {
+	t.Run("in sync", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newShowTestOptions(t, &out, drivertype.TypeKmod)
+		require.NoError(t, o.RunDriverConfigShow(context.Background()))
+
+		var show driverConfigShow
+		require.NoError(t, json.Unmarshal(out.Bytes(), &show))
+		assert.Equal(t, driverConfigShow{
+			Name:     "falco",
+			Version:  "7.0.0+driver",
+			Type:     []string{drivertype.TypeKmod},
+			HostRoot: "/",
+			Repos:    []string{"https://download.falco.org/driver"},
+			Live:     []liveEngineKind{{Source: o.FalcoConfig, Kind: drivertype.TypeKmod}},
+		}, show)
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newShowTestOptions(t, &out, drivertype.TypeModernBpf)
+		require.NoError(t, o.RunDriverConfigShow(context.Background()))
+
+		var show driverConfigShow
+		require.NoError(t, json.Unmarshal(out.Bytes(), &show))
+		assert.True(t, show.Drift)
+		assert.Equal(t, []liveEngineKind{{Source: o.FalcoConfig, Kind: drivertype.TypeModernBpf, Drift: true}}, show.Live)
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newShowTestOptions(t, &out, drivertype.TypeModernBpf)
+		o.Output = ""
+		require.NoError(t, o.RunDriverConfigShow(context.Background()))
+		assert.Contains(t, out.String(), "Falco engine.kind drifted from the stored driver configuration")
+		assert.Regexp(t, `falco\s+7.0.0\+driver\s+kmod\s+/\s+https://download.falco.org/driver\s+\S+falco.yaml\s+modern_ebpf\s+true`, out.String())
+	})
+}
+
+func TestLiveEngineKindsInConfigMaps(t *testing.T) {
+	var out bytes.Buffer
+	o := newShowTestOptions(t, &out, drivertype.TypeKmod)
+	o.Namespace = "falco"
+	cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeKmod), newConfigMap("falco-canary", drivertype.TypeBpf))
+
+	live, err := o.liveEngineKindsInConfigMaps(context.Background(), cl)
+	require.NoError(t, err)
+	show := newDriverConfigShow(&config.Driver{Type: []string{drivertype.TypeKmod}}, live)
+	assert.True(t, show.Drift)
+	assert.ElementsMatch(t, []liveEngineKind{
+		{Source: "configmap/falco/falco", Kind: drivertype.TypeKmod},
+		{Source: "configmap/falco/falco-canary", Kind: drivertype.TypeBpf, Drift: true},
+	}, show.Live)
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+// systemdUnitDirs are the directories, relative to the host root, where the Falco systemd units are looked up.
+var systemdUnitDirs = []string{"/etc/systemd/system", "/lib/systemd/system", "/usr/lib/systemd/system"}
+
+// systemdUnits maps the driver types to the systemd units shipped by the Falco packages.
+var systemdUnits = map[string]string{
+	drivertype.TypeKmod:      "falco-kmod.service",
+	drivertype.TypeBpf:       "falco-bpf.service",
+	drivertype.TypeModernBpf: "falco-modern-bpf.service",
+}
+
+// runCommand runs the given command, returning its combined output. Replaced in tests.
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// falcoSystemdUnits returns the sorted names of the Falco systemd units found under the host root.
+// No unit is returned when Falco is not deployed as a systemd service, e.g. when running in a container.
+func falcoSystemdUnits(hostRoot string) ([]string, error) {
+	found := make(map[string]struct{})
+	for _, dir := range systemdUnitDirs {
+		pattern, err := utils.ResolveHostPath(hostRoot, dir, "falco*.service")
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			found[filepath.Base(m)] = struct{}{}
+		}
+	}
+	units := make([]string, 0, len(found))
+	for u := range found {
+		units = append(units, u)
+	}
+	sort.Strings(units)
+	return units, nil
+}
+
+// systemdRestartCommands returns the systemctl invocations switching Falco to the unit of the given driver type:
+// a daemon-reload, the stop of the units of the other driver types and the restart of the driver type one.
+// If no unit exists for the driver type, the only Falco unit found, if any, is restarted.
+func systemdRestartCommands(units []string, driverType string) ([][]string, error) {
+	target := systemdUnits[driverType]
+	present := make(map[string]bool, len(units))
+	for _, u := range units {
+		present[u] = true
+	}
+	if !present[target] {
+		if len(units) != 1 {
+			return nil, fmt.Errorf("no Falco systemd unit for driver type %q, found: [%s]", driverType, strings.Join(units, ", "))
+		}
+		target = units[0]
+	}
+
+	cmds := [][]string{{"daemon-reload"}}
+	for _, u := range units {
+		if u != target && isDriverUnit(u) {
+			cmds = append(cmds, []string{"stop", u})
+		}
+	}
+	return append(cmds, []string{"restart", target}), nil
+}
+
+func isDriverUnit(unit string) bool {
+	for _, u := range systemdUnits {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// restartFalco restarts the Falco systemd service so that the updated configuration takes effect.
+// It is a no-op, with a warning, when Falco is not deployed as a systemd service.
+func (o *driverConfigOptions) restartFalco(ctx context.Context, driverType drivertype.DriverType) error {
+	units, err := falcoSystemdUnits(o.Driver.HostRoot)
+	if err != nil {
+		return err
+	}
+	if len(units) == 0 {
+		o.Printer.Logger.Warn("No Falco systemd unit found, Falco must be restarted manually",
+			o.Printer.Logger.Args("host-root", o.Driver.HostRoot))
+		return nil
+	}
+	cmds, err := systemdRestartCommands(units, driverType.String())
+	if err != nil {
+		return err
+	}
+	for _, args := range cmds {
+		if o.DryRun {
+			o.Printer.Logger.Info("Would run systemctl", o.Printer.Logger.Args("args", strings.Join(args, " ")))
+			continue
+		}
+		o.Printer.Logger.Debug("Running systemctl", o.Printer.Logger.Args("args", strings.Join(args, " ")))
+		if out, err := runCommand(ctx, "systemctl", args...); err != nil {
+			return fmt.Errorf("unable to run systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	if !o.DryRun {
+		o.result.Restarted = cmds[len(cmds)-1][1]
+	}
+	return nil
+}
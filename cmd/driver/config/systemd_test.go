@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+// fakeRunCommand records the commands instead of running them.
+func fakeRunCommand(t *testing.T, err error) *[]string {
+	var cmds []string
+	orig := runCommand
+	runCommand = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		cmds = append(cmds, strings.Join(append([]string{name}, args...), " "))
+		return nil, err
+	}
+	t.Cleanup(func() { runCommand = orig })
+	return &cmds
+}
+
+// newRestartTestOptions returns options updating the falco.yaml of a fake host root, with the given systemd units.
+func newRestartTestOptions(t *testing.T, units ...string) *driverConfigOptions {
+	hostRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(hostRoot, "etc", "falco"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(hostRoot, "etc", "falco", "falco.yaml"), []byte("engine:\n  kind: kmod\n"), 0o600))
+	unitDir := filepath.Join(hostRoot, "lib", "systemd", "system")
+	require.NoError(t, os.MkdirAll(unitDir, 0o750))
+	for _, u := range units {
+		require.NoError(t, os.WriteFile(filepath.Join(unitDir, u), nil, 0o600))
+	}
+
+	o := newTestOptions(false)
+	o.Namespace = ""
+	o.Driver.HostRoot = hostRoot
+	o.FalcoConfig = defaultFalcoConfig
+	o.Restart = true
+	return o
+}
+
+func TestRestartFalco(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		units        []string
+		dryRun       bool
+		expectedCmds []string
+		restarted    string
+	}{
+		{
+			name:  "systemd",
+			units: []string{"falco-kmod.service", "falco-modern-bpf.service", "falcoctl-artifact-follow.service"},
+			expectedCmds: []string{
+				"systemctl daemon-reload",
+				"systemctl stop falco-kmod.service",
+				"systemctl restart falco-modern-bpf.service",
+			},
+			restarted: "falco-modern-bpf.service",
+		},
+		{
+			name:         "custom unit",
+			units:        []string{"falco.service"},
+			expectedCmds: []string{"systemctl daemon-reload", "systemctl restart falco.service"},
+			restarted:    "falco.service",
+		},
+		{
+			name: "container",
+		},
+		{
+			name:   "dry run",
+			units:  []string{"falco-modern-bpf.service"},
+			dryRun: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds := fakeRunCommand(t, nil)
+			o := newRestartTestOptions(t, tt.units...)
+			o.DryRun = tt.dryRun
+			_, err := o.commit(context.Background(), driverType)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCmds, *cmds)
+			assert.Equal(t, tt.restarted, o.result.Restarted)
+		})
+	}
+}
+
+func TestRestartFalcoErrors(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	t.Run("no unit for driver type", func(t *testing.T) {
+		cmds := fakeRunCommand(t, nil)
+		o := newRestartTestOptions(t, "falco-kmod.service", "falco-bpf.service")
+		_, err := o.commit(context.Background(), driverType)
+		assert.EqualError(t, err,
+			`no Falco systemd unit for driver type "modern_ebpf", found: [falco-bpf.service, falco-kmod.service]`)
+		assert.Empty(t, *cmds)
+	})
+
+	t.Run("systemctl failure", func(t *testing.T) {
+		fakeRunCommand(t, errors.New("exit status 1"))
+		o := newRestartTestOptions(t, "falco-modern-bpf.service")
+		_, err := o.commit(context.Background(), driverType)
+		assert.EqualError(t, err, "unable to run systemctl daemon-reload: exit status 1: ")
+	})
+
+	t.Run("skipped update", func(t *testing.T) {
+		cmds := fakeRunCommand(t, nil)
+		o := newRestartTestOptions(t, "falco-modern-bpf.service")
+		falcoConfig, err := falcoConfigPath(o.Driver.HostRoot, o.FalcoConfig)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(falcoConfig, []byte("engine:\n  kind: gvisor\n"), 0o600))
+		targets, err := o.commit(context.Background(), driverType)
+		assert.ErrorIs(t, err, ErrEngineNotDriverDriven)
+		require.Len(t, targets, 1)
+		assert.Equal(t, TargetSkipped, targets[0].Action)
+		assert.Empty(t, *cmds)
+	})
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const longValidate = `Check, without changing anything, that the current Kubernetes identity can update the Falco configmaps
+as the driver config command does: the permissions to get, list and patch the configmaps are reviewed
+in each target namespace. It fails when any of them is missing, describing the RBAC rule to grant.
+`
+
+type driverConfigValidateOptions struct {
+	driverConfigOptions
+}
+
+func newDriverConfigValidateCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverConfigValidateOptions{
+		driverConfigOptions: driverConfigOptions{
+			Request: Request{Common: opt, Driver: driver, Verify: true},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "validate [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check the permissions needed to update the Falco configmaps",
+		Long:                  longValidate,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.validateOutput(); err != nil {
+				return err
+			}
+			return setFlagDefaults(cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunDriverConfigValidate(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "", "Kubernetes namespace, or comma separated list of namespaces.")
+	cmd.Flags().BoolVar(&o.AllNamespaces, "all-namespaces", false, "Check the permissions in all the Kubernetes namespaces.")
+	cmd.Flags().StringVar(&o.KubeConfig, "kubeconfig", "", "Kubernetes config.")
+	cmd.Flags().StringVar(&o.KubeContext, "context", "", "Kubernetes context to use, instead of the current one of the kubeconfig.")
+	cmd.Flags().StringVar(&o.CAFile, "ca-file", "", "PEM bundle of the CAs verifying the Kubernetes API server, instead of the ones of the kubeconfig.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Print the permissions as structured output instead of a table. One of 'yaml' or 'json'")
+	cmd.MarkFlagsMutuallyExclusive("namespace", "all-namespaces")
+	return cmd
+}
+
+// RunDriverConfigValidate implements the driver config validate command.
+func (o *driverConfigValidateOptions) RunDriverConfigValidate(ctx context.Context) error {
+	if o.Namespace == "" && !o.AllNamespaces {
+		return errors.New("the permissions are checked in Kubernetes, a namespace or --all-namespaces is needed")
+	}
+	cl, err := o.kubeClient()
+	if err != nil {
+		return err
+	}
+
+	perms := configMapsPermissions
+	perms.verbs = append([]string{"get"}, perms.verbs...)
+	var (
+		reviews []permissionReview
+		errs    []error
+	)
+	for _, namespace := range o.namespaces() {
+		var missing []string
+		for _, review := range o.reviewPermissions(ctx, cl, perms, namespace) {
+			reviews = append(reviews, review)
+			if review.Error == "" && !review.Allowed {
+				missing = append(missing, review.Verb)
+			}
+		}
+		if err := missingPermissionsError(perms, namespace, missing); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := o.printReviews(reviews); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+func (o *driverConfigValidateOptions) printReviews(reviews []permissionReview) error {
+	if o.Output != "" {
+		return o.Printer.PrintStructured(o.Output, reviews)
+	}
+	data := make([][]string, 0, len(reviews))
+	for _, review := range reviews {
+		namespace := review.Namespace
+		if namespace == metav1.NamespaceAll {
+			namespace = "*"
+		}
+		allowed := "no"
+		switch {
+		case review.Error != "":
+			allowed = "unknown"
+		case review.Allowed:
+			allowed = "yes"
+		}
+		data = append(data, []string{namespace, review.Resource, review.Verb, allowed})
+	}
+	return o.Printer.PrintTable(output.DriverConfigValidate, data)
+}
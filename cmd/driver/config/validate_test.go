@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func newValidateTestOptions(out *bytes.Buffer, namespace, format string, allowed ...string) *driverConfigValidateOptions {
+	return &driverConfigValidateOptions{driverConfigOptions: driverConfigOptions{
+		Request: Request{
+			Common:     &options.Common{Printer: output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, out)},
+			Driver:     &options.Driver{},
+			Namespace:  namespace,
+			KubeClient: newAccessReviewClient(allowed...),
+		},
+		Output: format,
+	}}
+}
+
+func TestRunDriverConfigValidate(t *testing.T) {
+	t.Run("allowed", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newValidateTestOptions(&out, "falco", "", "get", "list", "patch")
+		require.NoError(t, o.RunDriverConfigValidate(context.Background()))
+		assert.Equal(t, "NAMESPACE\tRESOURCE  \tVERB \tALLOWED\n"+
+			"falco    \tconfigmaps\tget  \tyes\n"+
+			"falco    \tconfigmaps\tlist \tyes\n"+
+			"falco    \tconfigmaps\tpatch\tyes\n\n", out.String())
+	})
+
+	t.Run("patch denied", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newValidateTestOptions(&out, "falco,tenant", output.FormatJSON, "get", "list")
+		err := o.RunDriverConfigValidate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing permissions to patch configmaps in namespace "falco"`)
+		assert.Contains(t, err.Error(), `missing permissions to patch configmaps in namespace "tenant"`)
+
+		var reviews []permissionReview
+		require.NoError(t, json.Unmarshal(out.Bytes(), &reviews))
+		require.Len(t, reviews, 6)
+		var missing []string
+		for _, review := range reviews {
+			if !review.Allowed {
+				missing = append(missing, review.Namespace+"/"+review.Verb)
+			}
+		}
+		assert.Equal(t, []string{"falco/patch", "tenant/patch"}, missing)
+	})
+
+	t.Run("namespace needed", func(t *testing.T) {
+		var out bytes.Buffer
+		o := newValidateTestOptions(&out, "", "")
+		assert.ErrorContains(t, o.RunDriverConfigValidate(context.Background()), "a namespace or --all-namespaces is needed")
+	})
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+const defaultResyncPeriod = 10 * time.Minute
+
+// checkWatch makes sure that the watch mode is compatible with the other options.
+func (o *driverConfigOptions) checkWatch() error {
+	switch {
+	case !o.Watch:
+		return nil
+	case !o.Update || o.DryRun:
+		return errors.New("--watch keeps updating Falco, it cannot be used with --dry-run or --update-falco=false")
+	case o.Namespace == "" && !o.AllNamespaces && !o.OpenShift:
+		return errors.New("--watch only watches the Falco configmaps, a namespace or --all-namespaces is needed")
+	case len(o.namespaces()) > 1:
+		return errors.New("--watch only watches the Falco configmaps of a single namespace, or of all of them")
+	case o.target() != targetConfigMap:
+		return errors.New("--watch only watches the Falco configmaps, it cannot be used with --target=" + o.target())
+	}
+	return nil
+}
+
+// watchConfigMaps patches again the Falco configMaps whose engine kind drifts from the driver type,
+// until the context is cancelled.
+func (o *driverConfigOptions) watchConfigMaps(ctx context.Context, driverType drivertype.DriverType) error {
+	cl, err := o.kubeClient()
+	if err != nil {
+		return err
+	}
+	listOpts, err := o.listOptions()
+	if err != nil {
+		return err
+	}
+	matcher, err := newContextMatcher(o.MatchContext)
+	if err != nil {
+		return err
+	}
+
+	// checkWatch made sure that there is a single namespace, or all of them.
+	namespace := o.namespaces()[0]
+	factory := informers.NewSharedInformerFactoryWithOptions(cl, o.ResyncPeriod,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = listOpts.LabelSelector
+			opts.FieldSelector = listOpts.FieldSelector
+		}))
+	reconcile := func(obj interface{}) {
+		if configMap, ok := obj.(*corev1.ConfigMap); ok {
+			o.reconcileConfigMap(ctx, cl, configMap, matcher, driverType)
+		}
+	}
+	// Resyncs are delivered as updates too.
+	if _, err := factory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    reconcile,
+		UpdateFunc: func(_, newObj interface{}) { reconcile(newObj) },
+	}); err != nil {
+		return err
+	}
+
+	o.Printer.Logger.Info("Watching Falco configMaps", o.Printer.Logger.Args(
+		"namespace", namespace, "selector", describeSelectors(listOpts), "resync-period", o.ResyncPeriod.String()))
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	factory.Shutdown()
+	o.Printer.Logger.Info("Stopped watching Falco configMaps")
+	return nil
+}
+
+// reconcileConfigMap patches the configMap again when its engine kind drifted from the driver type.
+// As when updating them, the configMaps that do not run with a driver are left alone.
+func (o *driverConfigOptions) reconcileConfigMap(ctx context.Context, cl kubernetes.Interface, configMap *corev1.ConfigMap,
+	matcher *contextMatcher, driverType drivertype.DriverType) {
+	edited, current, err := o.editConfigMapEngineKind(configMap, matcher, driverType.String())
+	if err != nil {
+		o.Printer.Logger.Debug("Ignoring Falco configMap", o.Printer.Logger.Args(
+			"configMap", configMap.Name, "namespace", configMap.Namespace, "reason", err))
+		return
+	}
+	// Aliases of the driver type are still rewritten, to store its canonical form.
+	if current == driverType.String() {
+		return
+	}
+
+	err = o.withRetries(ctx, func() error {
+		_, err := cl.CoreV1().ConfigMaps(configMap.Namespace).Patch(
+			ctx, configMap.Name, types.JSONPatchType, o.configMapPatch(edited), metav1.PatchOptions{})
+		return err
+	})
+	if err != nil {
+		o.Printer.Logger.Warn("Unable to patch drifted Falco configMap", o.Printer.Logger.Args(
+			"configMap", configMap.Name, "namespace", configMap.Namespace, "reason", err))
+		return
+	}
+	o.Printer.Logger.Info("Patched drifted Falco configMap", o.Printer.Logger.Args(
+		"configMap", configMap.Name, "namespace", configMap.Namespace, "current", current, "new", driverType.String()))
+	if o.EmitEvents {
+		o.emitConfigMapEvent(ctx, cl, configMap, current, driverType.String())
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+func TestWatchConfigMaps(t *testing.T) {
+	driverType, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+	cl := fake.NewSimpleClientset(newConfigMap("falco", drivertype.TypeModernBpf), newConfigMap("falco-gvisor", "gvisor"))
+
+	o := newTestOptions(false)
+	o.KubeClient = cl
+	o.ResyncPeriod = 0
+	// The namespace list is parsed as when updating the configmaps.
+	o.Namespace = " falco,"
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- o.watchConfigMaps(ctx, driverType) }()
+
+	engineKind := func(name string) string {
+		configMap, err := cl.CoreV1().ConfigMaps("falco").Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return err.Error()
+		}
+		return configMap.Data[configMapEngineKindKey]
+	}
+
+	// Another tool sets the engine kind back.
+	drifted := newConfigMap("falco", drivertype.TypeKmod)
+	_, err = cl.CoreV1().ConfigMaps("falco").Update(context.Background(), drifted, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return engineKind("falco") == drivertype.TypeModernBpf
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Drifts are fixed as long as the watch runs.
+	_, err = cl.CoreV1().ConfigMaps("falco").Update(context.Background(), newConfigMap("falco", drivertype.TypeBpf), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return engineKind("falco") == drivertype.TypeModernBpf
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watch did not stop with the context")
+	}
+	// The configMaps not running with a driver are left alone.
+	assert.Equal(t, "gvisor", engineKind("falco-gvisor"))
+}
+
+func TestCheckWatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(o *driverConfigOptions)
+		expectedErr string
+	}{
+		{name: "configmaps", setup: func(o *driverConfigOptions) {}},
+		{name: "dry run", setup: func(o *driverConfigOptions) { o.DryRun = true }, expectedErr: "cannot be used with --dry-run"},
+		{name: "no update", setup: func(o *driverConfigOptions) { o.Update = false }, expectedErr: "cannot be used with --dry-run"},
+		{name: "no namespace", setup: func(o *driverConfigOptions) { o.Namespace = "" }, expectedErr: "a namespace or --all-namespaces is needed"},
+		{name: "daemonsets", setup: func(o *driverConfigOptions) { o.Target = targetDaemonSet }, expectedErr: "--target=daemonset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOptions(false)
+			o.Watch = true
+			o.Update = true
+			tt.setup(o)
+			err := o.checkWatch()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
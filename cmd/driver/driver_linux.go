@@ -30,10 +30,15 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	drivercheck "github.com/falcosecurity/falcoctl/cmd/driver/check"
 	drivercleanup "github.com/falcosecurity/falcoctl/cmd/driver/cleanup"
 	driverconfig "github.com/falcosecurity/falcoctl/cmd/driver/config"
 	driverinstall "github.com/falcosecurity/falcoctl/cmd/driver/install"
+	driverlist "github.com/falcosecurity/falcoctl/cmd/driver/list"
 	driverprintenv "github.com/falcosecurity/falcoctl/cmd/driver/printenv"
+	driverprune "github.com/falcosecurity/falcoctl/cmd/driver/prune"
+	driverselect "github.com/falcosecurity/falcoctl/cmd/driver/select"
+	driverstatus "github.com/falcosecurity/falcoctl/cmd/driver/status"
 	"github.com/falcosecurity/falcoctl/internal/config"
 	driverdistro "github.com/falcosecurity/falcoctl/pkg/driver/distro"
 	driverkernel "github.com/falcosecurity/falcoctl/pkg/driver/kernel"
@@ -61,6 +66,9 @@ func NewDriverCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 			if err := config.Load(opt.ConfigFile); err != nil {
 				return err
 			}
+			if err := options.ConfigureTLS(); err != nil {
+				return err
+			}
 
 			// Override "version" flag with viper config if not set by user.
 			f := cmd.Flags().Lookup("version")
@@ -89,6 +97,21 @@ func NewDriverCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 				}
 			}
 
+			// Override "prepend-repo" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup("prepend-repo")
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag prepend-repo")
+			} else if !f.Changed && viper.IsSet(config.DriverPrependReposKey) {
+				val, err := config.DriverPrependRepos()
+				if err != nil {
+					return err
+				}
+				if err := cmd.Flags().Set(f.Name, strings.Join(val, ",")); err != nil {
+					return fmt.Errorf("unable to overwrite \"prepend-repo\" flag: %w", err)
+				}
+			}
+
 			// Override "name" flag with viper config if not set by user.
 			f = cmd.Flags().Lookup("name")
 			if f == nil {
@@ -160,7 +183,11 @@ func NewDriverCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 			}
 			opt.Printer.Logger.Debug("Discovered distro", opt.Printer.Logger.Args("target", driver.Distro))
 
+			_, inspectOnly := cmd.Annotations[options.DriverInspectOnlyAnnotation]
 			driver.Type = driver.Distro.PreferredDriver(driver.Kr, allowedDriverTypes)
+			if driver.Type == nil && inspectOnly {
+				return nil
+			}
 			if driver.Type == nil {
 				return fmt.Errorf("no supported driver found for distro: %s, "+
 					"kernelrelease %s, "+
@@ -178,6 +205,9 @@ func NewDriverCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 			if driver.Version == "" {
 				driver.Version = loadDriverVersion()
 			}
+			if inspectOnly {
+				return nil
+			}
 			return driver.Validate()
 		},
 	}
@@ -186,6 +216,8 @@ func NewDriverCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 		"Driver types allowed in descending priority order "+driverTypesEnum.Allowed())
 	cmd.PersistentFlags().StringVar(&driver.Version, "version", config.DefaultDriver.Version, "Driver version to be used.")
 	cmd.PersistentFlags().StringSliceVar(&driver.Repos, "repo", config.DefaultDriver.Repos, "Driver repo to be used.")
+	cmd.PersistentFlags().StringSliceVar(&driver.PrependRepos, "prepend-repo", nil,
+		"Driver repos to be tried, in descending priority order, before the ones set by --repo.")
 	cmd.PersistentFlags().StringVar(&driver.Name, "name", config.DefaultDriver.Name, "Driver name to be used.")
 	cmd.PersistentFlags().StringVar(&driver.HostRoot, "host-root", config.DefaultDriver.HostRoot, "Driver host root to be used.")
 	cmd.PersistentFlags().StringVar(&driverKernelRelease,
@@ -203,6 +235,11 @@ func NewDriverCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	cmd.AddCommand(driverconfig.NewDriverConfigCmd(ctx, opt, driver))
 	cmd.AddCommand(drivercleanup.NewDriverCleanupCmd(ctx, opt, driver))
 	cmd.AddCommand(driverprintenv.NewDriverPrintenvCmd(ctx, opt, driver))
+	cmd.AddCommand(driverlist.NewDriverListCmd(ctx, opt, driver))
+	cmd.AddCommand(driverprune.NewDriverPruneCmd(ctx, opt, driver))
+	cmd.AddCommand(driverstatus.NewDriverStatusCmd(ctx, opt, driver))
+	cmd.AddCommand(driverselect.NewDriverSelectCmd(ctx, opt, driver))
+	cmd.AddCommand(drivercheck.NewDriverCheckCmd(ctx, opt, driver))
 	return cmd
 }
 
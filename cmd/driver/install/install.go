@@ -21,36 +21,53 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"golang.org/x/net/context"
 
+	"github.com/falcosecurity/falcoctl/internal/config"
 	driverdistro "github.com/falcosecurity/falcoctl/pkg/driver/distro"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 )
 
+const (
+	// flagBuildCacheDir is the name of the flag to set the directory of the driver build cache.
+	flagBuildCacheDir = "build-cache-dir"
+	// flagLocalDir is the name of the flag to set the directory of the prebuilt drivers.
+	flagLocalDir = "local-dir"
+)
+
 type driverDownloadOptions struct {
 	InsecureDownload bool
 	HTTPTimeout      time.Duration
 	HTTPHeaders      string
+	MTLS             driverdistro.MTLSOptions
 }
 
 type driverInstallOptions struct {
 	*options.Common
 	*options.Driver
-	Download bool
-	Compile  bool
+	*options.Registry
+	Download      bool
+	Compile       bool
+	BuildCacheDir string
+	LocalDir      string
+	Publish       string
 	driverDownloadOptions
 }
 
 // NewDriverInstallCmd returns the driver install command.
 func NewDriverInstallCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
 	o := driverInstallOptions{
-		Common: opt,
-		Driver: driver,
+		Common:   opt,
+		Driver:   driver,
+		Registry: &options.Registry{},
 		// Defaults to downloading or building if needed
 		Download: true,
 		Compile:  true,
@@ -60,7 +77,50 @@ func NewDriverInstallCmd(ctx context.Context, opt *options.Common, driver *optio
 		Use:                   "install [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "Install previously configured driver",
-		Long:                  `Install previously configured driver, either downloading it or attempting a build.`,
+		Long: `Install previously configured driver, either downloading it or attempting a build.
+
+The drivers successfully built are kept in the build cache directory, keyed by driver version, kernel
+release and kernel config, and reused instead of building them again, e.g. when the node reboots.
+
+With --publish, the drivers successfully built are also pushed to an OCI repository. The hosts having
+that repository among their driver repos, prefixed by "oci://", then download them instead of building
+them, e.g. --repo oci://ghcr.io/myorg/falco-drivers.
+
+The repos are tried in order, moving to the next one when a repo fails, e.g. with a 404 or 5xx response.
+The repos needing their own headers or TLS settings are configured in the config file:
+
+  driver:
+    mirrors:
+      - url: https://mirror.example.com/driver
+        headers:
+          Authorization: Bearer <token>
+        caCert: /etc/falcoctl/mirror-ca.crt
+        clientCert: /etc/falcoctl/mirror.crt
+        clientKey: /etc/falcoctl/mirror.key
+        insecure: false
+
+With --local-dir, the prebuilt drivers are looked up in a local directory, e.g. an NFS share pre-populated
+by the cluster administrators, laid out like a driver repo: <dir>/<driver version>/<arch>/<driver file>.
+The driver repos are not tried, so no network access is needed unless the driver gets built.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Override "build-cache-dir" and "local-dir" flags with viper config if not set by user.
+			for flag, key := range map[string]string{
+				flagBuildCacheDir: config.DriverInstallBuildCacheDirKey,
+				flagLocalDir:      config.DriverInstallLocalDirKey,
+			} {
+				f := cmd.Flags().Lookup(flag)
+				if f == nil {
+					// should never happen
+					return fmt.Errorf("unable to retrieve flag %q", flag)
+				} else if !f.Changed && viper.IsSet(key) {
+					val := viper.Get(key)
+					if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+						return fmt.Errorf("unable to overwrite %q flag: %w", flag, err)
+					}
+				}
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			dest, err := o.RunDriverInstall(ctx)
 			if dest != "" {
@@ -77,20 +137,39 @@ func NewDriverInstallCmd(ctx context.Context, opt *options.Common, driver *optio
 
 	cmd.Flags().BoolVar(&o.Download, "download", true, "Whether to enable download of prebuilt drivers")
 	cmd.Flags().BoolVar(&o.Compile, "compile", true, "Whether to enable local compilation of drivers")
+	cmd.Flags().StringVar(&o.BuildCacheDir, flagBuildCacheDir, config.DriverBuildCacheDir,
+		"Directory where the locally compiled drivers are cached and reused from; an empty value disables the cache")
+	cmd.Flags().StringVar(&o.LocalDir, flagLocalDir, "",
+		"Directory, laid out like a driver repo, where the prebuilt drivers are looked up instead of downloading them")
+	cmd.Flags().StringVar(&o.Publish, "publish", "",
+		"OCI repository, e.g. ghcr.io/myorg/falco-drivers, where the locally compiled drivers are pushed for the other hosts to download them")
+	o.Registry.AddFlags(cmd)
 	cmd.Flags().BoolVar(&o.InsecureDownload, "http-insecure", false, "Whether you want to allow insecure downloads or not")
 	cmd.Flags().DurationVar(&o.HTTPTimeout, "http-timeout", 60*time.Second, "Timeout for each http try")
 	cmd.Flags().StringVar(&o.HTTPHeaders, "http-headers",
 		"",
 		"Optional comma-separated list of headers for the http GET request "+
 			"(e.g. --http-headers='x-emc-namespace: default,Proxy-Authenticate: Basic'). Not necessary if default repo is used")
+	cmd.Flags().StringVar(&o.MTLS.ClientCert, "repo-client-cert", "", "Client certificate used to authenticate against driver repos requiring mTLS")
+	cmd.Flags().StringVar(&o.MTLS.ClientKey, "repo-client-key", "", "Client key used to authenticate against driver repos requiring mTLS")
+	cmd.Flags().StringVar(&o.MTLS.CACert, "repo-ca-cert", "", "CA bundle used to verify driver repos requiring mTLS")
+	cmd.Flags().StringSliceVar(&o.MTLS.Hosts, "repo-mtls-host", nil,
+		"Host (with optional port) the mTLS settings are applied to; can be repeated. Defaults to every host")
+	cmd.MarkFlagsRequiredTogether("repo-client-cert", "repo-client-key")
 	return cmd
 }
 
 //nolint:gosec // this was an existent option in falco-driver-loader that we are porting.
 func setDefaultHTTPClientOpts(downloadOptions driverDownloadOptions) {
-	// Skip insecure verify
+	// Skip insecure verify, keeping the CA bundle of the TLS settings.
 	if downloadOptions.InsecureDownload {
-		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		t := http.DefaultTransport.(*http.Transport)
+		cfg := &tls.Config{}
+		if t.TLSClientConfig != nil {
+			cfg = t.TLSClientConfig.Clone()
+		}
+		cfg.InsecureSkipVerify = true //nolint:gosec // explicitly requested by the user.
+		t.TLSClientConfig = cfg
 	}
 	http.DefaultClient.Timeout = downloadOptions.HTTPTimeout
 }
@@ -153,13 +232,55 @@ func (o *driverInstallOptions) RunDriverInstall(ctx context.Context) (string, er
 		return "", err
 	}
 
-	if o.Download {
+	if o.Download && o.LocalDir != "" {
+		dest, err = driverdistro.CopyFromDir(o.Distro, o.Printer.WithWriter(&buf), o.Kr, o.Driver.Name,
+			o.Driver.Type, o.Driver.Version, o.LocalDir)
+		if o.Printer.Logger.Formatter == pterm.LogFormatterJSON {
+			// Only print formatted text if we are formatting to json
+			out := strings.ReplaceAll(buf.String(), "\n", ";")
+			o.Printer.Logger.Info("Driver copy", o.Printer.Logger.Args("output", out))
+		} else {
+			// Print much more readable output as-is
+			o.Printer.DefaultText.Print(buf.String())
+		}
+		buf.Reset()
+		if err == nil {
+			o.Printer.Logger.Info("Driver copied from the local directory.", o.Printer.Logger.Args("path", dest))
+			return dest, nil
+		}
+		if errors.Is(err, driverdistro.ErrAlreadyPresent) {
+			o.Printer.Logger.Info("Skipping copy, driver already present.", o.Printer.Logger.Args("path", dest))
+			return dest, nil
+		}
+		// Print the error but go on
+		// attempting a build if requested
+		if o.Compile {
+			o.Printer.Logger.Warn(err.Error())
+		}
+	} else if o.Download {
 		setDefaultHTTPClientOpts(o.driverDownloadOptions)
+		client, clientErr := driverdistro.NewHTTPClient(&o.MTLS)
+		if clientErr != nil {
+			return "", fmt.Errorf("unable to configure driver download http client: %w", clientErr)
+		}
+		mirrors, mirrorsErr := o.mirrors()
+		if mirrorsErr != nil {
+			return "", mirrorsErr
+		}
+		repos := o.Driver.EffectiveRepos()
+		o.Printer.Logger.Info("Driver repos to be tried, in order", o.Printer.Logger.Args("repos", strings.Join(repos, ",")))
 		if !o.Printer.DisableStyling {
 			o.Printer.Spinner, _ = o.Printer.Spinner.Start("Trying to download the driver")
 		}
+		// The registry client is only needed by the OCI driver repos.
+		var ociOpts *driverdistro.OCIOptions
+		if slices.ContainsFunc(repos, func(r string) bool { return strings.HasPrefix(r, driverdistro.OCIRepoPrefix) }) {
+			if ociOpts, err = o.ociOptions(); err != nil {
+				return "", err
+			}
+		}
 		dest, err = driverdistro.Download(ctx, o.Distro, o.Printer.WithWriter(&buf), o.Kr, o.Driver.Name,
-			o.Driver.Type, o.Driver.Version, o.Driver.Repos, o.HTTPHeaders)
+			o.Driver.Type, o.Driver.Version, repos, o.HTTPHeaders, client, ociOpts, mirrors)
 		if o.Printer.Spinner != nil {
 			_ = o.Printer.Spinner.Stop()
 		}
@@ -191,7 +312,11 @@ func (o *driverInstallOptions) RunDriverInstall(ctx context.Context) (string, er
 		if !o.Printer.DisableStyling {
 			o.Printer.Spinner, _ = o.Printer.Spinner.Start("Trying to build the driver")
 		}
-		dest, err = driverdistro.Build(ctx, o.Distro, o.Printer.WithWriter(&buf), o.Kr, o.Driver.Name, o.Driver.Type, o.Driver.Version)
+		var cache *driverdistro.BuildCache
+		if o.BuildCacheDir != "" {
+			cache = driverdistro.NewBuildCache(o.BuildCacheDir)
+		}
+		dest, err = driverdistro.Build(ctx, o.Distro, o.Printer.WithWriter(&buf), o.Kr, o.Driver.Name, o.Driver.Type, o.Driver.Version, cache)
 		if o.Printer.Spinner != nil {
 			_ = o.Printer.Spinner.Stop()
 		}
@@ -205,6 +330,7 @@ func (o *driverInstallOptions) RunDriverInstall(ctx context.Context) (string, er
 		}
 		buf.Reset()
 		if err == nil {
+			o.publish(ctx, dest)
 			return dest, nil
 		}
 		if errors.Is(err, driverdistro.ErrAlreadyPresent) {
@@ -215,3 +341,50 @@ func (o *driverInstallOptions) RunDriverInstall(ctx context.Context) (string, er
 
 	return o.Driver.Name, fmt.Errorf("failed: %w", err)
 }
+
+// mirrors returns the per repo download settings of the config file. It must be called after
+// setDefaultHTTPClientOpts, since the clients of the repos derive from the default one.
+func (o *driverInstallOptions) mirrors() ([]driverdistro.Mirror, error) {
+	cfgs, err := config.DriverMirrors()
+	if err != nil {
+		return nil, err
+	}
+	mirrors := make([]driverdistro.Mirror, 0, len(cfgs))
+	for _, m := range cfgs {
+		mirror := driverdistro.Mirror{URL: m.URL, Headers: m.Headers}
+		if m.CACert != "" || m.ClientCert != "" || m.ClientKey != "" || m.Insecure {
+			tlsOpts := &driverdistro.MTLSOptions{ClientCert: m.ClientCert, ClientKey: m.ClientKey, CACert: m.CACert}
+			if mirror.Client, err = driverdistro.NewMirrorClient(tlsOpts, m.Insecure); err != nil {
+				return nil, fmt.Errorf("unable to configure the http client of driver repo %q: %w", m.URL, err)
+			}
+		}
+		mirrors = append(mirrors, mirror)
+	}
+	return mirrors, nil
+}
+
+// ociOptions returns the options used to pull the drivers from the OCI driver repos and to publish them.
+func (o *driverInstallOptions) ociOptions() (*driverdistro.OCIOptions, error) {
+	client, err := ociutils.Client(true)
+	if err != nil {
+		return nil, err
+	}
+	return &driverdistro.OCIOptions{Client: client, PlainHTTP: o.PlainHTTP}, nil
+}
+
+// publish pushes the driver built at dest to the OCI repository given by --publish, if any.
+// The driver is installed anyway, so a failure is only reported.
+func (o *driverInstallOptions) publish(ctx context.Context, dest string) {
+	if o.Publish == "" {
+		return
+	}
+	ociOpts, err := o.ociOptions()
+	if err == nil {
+		var ref string
+		if ref, err = driverdistro.Publish(ctx, ociOpts, o.Publish, o.Kr, o.Driver.Version, dest); err == nil {
+			o.Printer.Logger.Info("Driver published.", o.Printer.Logger.Args("ref", ref))
+			return
+		}
+	}
+	o.Printer.Logger.Warn("Unable to publish the driver.", o.Printer.Logger.Args("repo", o.Publish, "err", err))
+}
@@ -28,16 +28,48 @@ import (
 //nolint:lll // no need to check for line length.
 var driverInstallHelp = `Install previously configured driver, either downloading it or attempting a build.
 
+The drivers successfully built are kept in the build cache directory, keyed by driver version, kernel
+release and kernel config, and reused instead of building them again, e.g. when the node reboots.
+
+With --publish, the drivers successfully built are also pushed to an OCI repository. The hosts having
+that repository among their driver repos, prefixed by "oci://", then download them instead of building
+them, e.g. --repo oci://ghcr.io/myorg/falco-drivers.
+
+The repos are tried in order, moving to the next one when a repo fails, e.g. with a 404 or 5xx response.
+The repos needing their own headers or TLS settings are configured in the config file:
+
+  driver:
+    mirrors:
+      - url: https://mirror.example.com/driver
+        headers:
+          Authorization: Bearer <token>
+        caCert: /etc/falcoctl/mirror-ca.crt
+        clientCert: /etc/falcoctl/mirror.crt
+        clientKey: /etc/falcoctl/mirror.key
+        insecure: false
+
+With --local-dir, the prebuilt drivers are looked up in a local directory, e.g. an NFS share pre-populated
+by the cluster administrators, laid out like a driver repo: <dir>/<driver version>/<arch>/<driver file>.
+The driver repos are not tried, so no network access is needed unless the driver gets built.
+
 Usage:
   falcoctl driver install [flags]
 
 Flags:
-      --compile                 Whether to enable local compilation of drivers (default true)
-      --download                Whether to enable download of prebuilt drivers (default true)
-  -h, --help                    help for install
-      --http-headers string     Optional comma-separated list of headers for the http GET request (e.g. --http-headers='x-emc-namespace: default,Proxy-Authenticate: Basic'). Not necessary if default repo is used
-      --http-insecure           Whether you want to allow insecure downloads or not
-      --http-timeout duration   Timeout for each http try (default 1m0s)
+      --build-cache-dir string    Directory where the locally compiled drivers are cached and reused from; an empty value disables the cache (default "/var/cache/falcoctl/drivers")
+      --compile                   Whether to enable local compilation of drivers (default true)
+      --download                  Whether to enable download of prebuilt drivers (default true)
+  -h, --help                      help for install
+      --http-headers string       Optional comma-separated list of headers for the http GET request (e.g. --http-headers='x-emc-namespace: default,Proxy-Authenticate: Basic'). Not necessary if default repo is used
+      --http-insecure             Whether you want to allow insecure downloads or not
+      --http-timeout duration     Timeout for each http try (default 1m0s)
+      --local-dir string          Directory, laid out like a driver repo, where the prebuilt drivers are looked up instead of downloading them
+      --plain-http                allows interacting with remote registry via plain http requests
+      --publish string            OCI repository, e.g. ghcr.io/myorg/falco-drivers, where the locally compiled drivers are pushed for the other hosts to download them
+      --repo-ca-cert string       CA bundle used to verify driver repos requiring mTLS
+      --repo-client-cert string   Client certificate used to authenticate against driver repos requiring mTLS
+      --repo-client-key string    Client key used to authenticate against driver repos requiring mTLS
+      --repo-mtls-host strings    Host (with optional port) the mTLS settings are applied to; can be repeated. Defaults to every host
 
 Global Flags:
       --config string          config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
@@ -47,6 +79,7 @@ Global Flags:
       --log-format string      Set formatting for logs (color, text, json) (default "color")
       --log-level string       Set level for logs (info, warn, debug, trace) (default "info")
       --name string            Driver name to be used. (default "falco")
+      --prepend-repo strings   Driver repos to be tried, in descending priority order, before the ones set by --repo.
       --repo strings           Driver repo to be used. (default [https://download.falco.org/driver])
       --type strings           Driver types allowed in descending priority order (ebpf, kmod, modern_ebpf) (default [modern_ebpf,kmod,ebpf])
       --version string         Driver version to be used.
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverlist
+
+import (
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	driverdistro "github.com/falcosecurity/falcoctl/pkg/driver/distro"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+type driverListOptions struct {
+	*options.Common
+	*options.Driver
+}
+
+// NewDriverListCmd lists the locally cached drivers.
+func NewDriverListCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverListOptions{
+		Common: opt,
+		Driver: driver,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "list [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "List locally cached drivers",
+		Long:                  `List the driver artifacts downloaded or built in the local drivers directory.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return o.RunDriverList(ctx)
+		},
+	}
+	return cmd
+}
+
+// RunDriverList implements the driver list command.
+func (o *driverListOptions) RunDriverList(_ context.Context) error {
+	cached, err := driverdistro.ListCached()
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(cached))
+	for i := range cached {
+		c := &cached[i]
+		data = append(data, []string{c.Name, c.Type, c.Version, c.Arch, c.Distro, c.KernelRelease, c.KernelVersion, c.Path})
+	}
+	return o.Printer.PrintTable(output.DriverList, data)
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverlist_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+)
+
+//nolint:lll // no need to check for line length.
+var driverListHelp = `List the driver artifacts downloaded or built in the local drivers directory.
+
+Usage:
+  falcoctl driver list [flags]
+
+Flags:
+  -h, --help   help for list
+
+Global Flags:
+      --config string          config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --host-root string       Driver host root to be used. (default "/")
+      --kernelrelease string   Specify the kernel release for which to download/build the driver in the same format used by 'uname -r' (e.g. '6.1.0-10-cloud-amd64')
+      --kernelversion string   Specify the kernel version for which to download/build the driver in the same format used by 'uname -v' (e.g. '#1 SMP PREEMPT_DYNAMIC Debian 6.1.38-2 (2023-07-27)')
+      --log-format string      Set formatting for logs (color, text, json) (default "color")
+      --log-level string       Set level for logs (info, warn, debug, trace) (default "info")
+      --name string            Driver name to be used. (default "falco")
+      --prepend-repo strings   Driver repos to be tried, in descending priority order, before the ones set by --repo.
+      --repo strings           Driver repo to be used. (default [https://download.falco.org/driver])
+      --type strings           Driver types allowed in descending priority order (ebpf, kmod, modern_ebpf) (default [modern_ebpf,kmod,ebpf])
+      --version string         Driver version to be used.
+`
+
+var _ = Describe("list", func() {
+
+	var (
+		driverCmd = "driver"
+		listCmd   = "list"
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("help message", func() {
+		BeforeEach(func() {
+			args = []string{driverCmd, listCmd, "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(driverListHelp)))
+		})
+	})
+
+	Context("with cached drivers", func() {
+		var home string
+
+		BeforeEach(func() {
+			home = GinkgoT().TempDir()
+			GinkgoT().Setenv("HOME", home)
+			driverPath := filepath.Join(home, ".falco", "7.0.0+driver", "x86_64", "falco_debian_6.1.0-10-amd64_1.ko")
+			Expect(os.MkdirAll(filepath.Dir(driverPath), 0o750)).Should(Succeed())
+			Expect(os.WriteFile(driverPath, nil, 0o600)).Should(Succeed())
+			args = []string{driverCmd, listCmd, "--config", configFile, "--version", "7.0.0+driver"}
+		})
+
+		It("should print them", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(output).Should(gbytes.Say("NAME"))
+			Expect(output).Should(gbytes.Say(`falco\s+kmod\s+7.0.0\+driver\s+x86_64\s+debian\s+6.1.0-10-amd64\s+1`))
+		})
+	})
+})
@@ -27,6 +27,23 @@ import (
 type driverPrintenvOptions struct {
 	*options.Common
 	*options.Driver
+	*options.Output
+}
+
+// driverEnv is the machine-readable representation of the driver variables.
+// Keys match the names of the printed env vars.
+type driverEnv struct {
+	Driver             string `json:"DRIVER" yaml:"DRIVER"`
+	DriversRepo        string `json:"DRIVERS_REPO" yaml:"DRIVERS_REPO"`
+	DriverVersion      string `json:"DRIVER_VERSION" yaml:"DRIVER_VERSION"`
+	DriverName         string `json:"DRIVER_NAME" yaml:"DRIVER_NAME"`
+	HostRoot           string `json:"HOST_ROOT" yaml:"HOST_ROOT"`
+	TargetID           string `json:"TARGET_ID" yaml:"TARGET_ID"`
+	Arch               string `json:"ARCH" yaml:"ARCH"`
+	KernelRelease      string `json:"KERNEL_RELEASE" yaml:"KERNEL_RELEASE"`
+	KernelVersion      string `json:"KERNEL_VERSION" yaml:"KERNEL_VERSION"`
+	FixedKernelRelease string `json:"FIXED_KERNEL_RELEASE" yaml:"FIXED_KERNEL_RELEASE"`
+	FixedKernelVersion string `json:"FIXED_KERNEL_VERSION" yaml:"FIXED_KERNEL_VERSION"`
 }
 
 // NewDriverPrintenvCmd print info about driver falcoctl config as env vars.
@@ -34,6 +51,7 @@ func NewDriverPrintenvCmd(ctx context.Context, opt *options.Common, driver *opti
 	o := driverPrintenvOptions{
 		Common: opt,
 		Driver: driver,
+		Output: &options.Output{},
 	}
 
 	cmd := &cobra.Command{
@@ -41,25 +59,47 @@ func NewDriverPrintenvCmd(ctx context.Context, opt *options.Common, driver *opti
 		DisableFlagsInUseLine: true,
 		Short:                 "Print env vars",
 		Long:                  `Print variables used by driver as env vars.`,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			return o.Output.Validate()
+		},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			return o.RunDriverPrintenv(ctx)
 		},
 	}
+	o.Output.AddFlags(cmd)
 	return cmd
 }
 
 func (o *driverPrintenvOptions) RunDriverPrintenv(_ context.Context) error {
-	o.Printer.DefaultText.Printf("DRIVER=%q\n", o.Driver.Type.String())
-	o.Printer.DefaultText.Printf("DRIVERS_REPO=%q\n", strings.Join(o.Driver.Repos, ", "))
-	o.Printer.DefaultText.Printf("DRIVER_VERSION=%q\n", o.Driver.Version)
-	o.Printer.DefaultText.Printf("DRIVER_NAME=%q\n", o.Driver.Name)
-	o.Printer.DefaultText.Printf("HOST_ROOT=%q\n", o.Driver.HostRoot)
-	o.Printer.DefaultText.Printf("TARGET_ID=%q\n", o.Distro.String())
-	o.Printer.DefaultText.Printf("ARCH=%q\n", o.Kr.Architecture.ToNonDeb())
-	o.Printer.DefaultText.Printf("KERNEL_RELEASE=%q\n", o.Kr.String())
-	o.Printer.DefaultText.Printf("KERNEL_VERSION=%q\n", o.Kr.KernelVersion)
 	fixedKr := o.Distro.FixupKernel(o.Kr)
-	o.Printer.DefaultText.Printf("FIXED_KERNEL_RELEASE=%q\n", fixedKr.String())
-	o.Printer.DefaultText.Printf("FIXED_KERNEL_VERSION=%q\n", fixedKr.KernelVersion)
+	env := driverEnv{
+		Driver:             o.Driver.Type.String(),
+		DriversRepo:        strings.Join(o.Driver.EffectiveRepos(), ", "),
+		DriverVersion:      o.Driver.Version,
+		DriverName:         o.Driver.Name,
+		HostRoot:           o.Driver.HostRoot,
+		TargetID:           o.Distro.String(),
+		Arch:               o.Kr.Architecture.ToNonDeb(),
+		KernelRelease:      o.Kr.String(),
+		KernelVersion:      o.Kr.KernelVersion,
+		FixedKernelRelease: fixedKr.String(),
+		FixedKernelVersion: fixedKr.KernelVersion,
+	}
+
+	if o.Structured() {
+		return o.Printer.PrintStructured(o.Format, env)
+	}
+
+	o.Printer.DefaultText.Printf("DRIVER=%q\n", env.Driver)
+	o.Printer.DefaultText.Printf("DRIVERS_REPO=%q\n", env.DriversRepo)
+	o.Printer.DefaultText.Printf("DRIVER_VERSION=%q\n", env.DriverVersion)
+	o.Printer.DefaultText.Printf("DRIVER_NAME=%q\n", env.DriverName)
+	o.Printer.DefaultText.Printf("HOST_ROOT=%q\n", env.HostRoot)
+	o.Printer.DefaultText.Printf("TARGET_ID=%q\n", env.TargetID)
+	o.Printer.DefaultText.Printf("ARCH=%q\n", env.Arch)
+	o.Printer.DefaultText.Printf("KERNEL_RELEASE=%q\n", env.KernelRelease)
+	o.Printer.DefaultText.Printf("KERNEL_VERSION=%q\n", env.KernelVersion)
+	o.Printer.DefaultText.Printf("FIXED_KERNEL_RELEASE=%q\n", env.FixedKernelRelease)
+	o.Printer.DefaultText.Printf("FIXED_KERNEL_VERSION=%q\n", env.FixedKernelVersion)
 	return nil
 }
@@ -35,7 +35,8 @@ Usage:
   falcoctl driver printenv [flags]
 
 Flags:
-  -h, --help   help for printenv
+  -h, --help            help for printenv
+  -o, --output string   Print the output in a machine-readable format instead of text. One of 'yaml' or 'json'
 
 Global Flags:
       --config string          config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
@@ -45,6 +46,7 @@ Global Flags:
       --log-format string      Set formatting for logs (color, text, json) (default "color")
       --log-level string       Set level for logs (info, warn, debug, trace) (default "info")
       --name string            Driver name to be used. (default "falco")
+      --prepend-repo strings   Driver repos to be tried, in descending priority order, before the ones set by --repo.
       --repo strings           Driver repo to be used. (default [https://download.falco.org/driver])
       --type strings           Driver types allowed in descending priority order (ebpf, kmod, modern_ebpf) (default [modern_ebpf,kmod,ebpf])
       --version string         Driver version to be used.
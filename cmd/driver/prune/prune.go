@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverprune
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	driverdistro "github.com/falcosecurity/falcoctl/pkg/driver/distro"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+type driverPruneOptions struct {
+	*options.Common
+	*options.Driver
+	Keep   int
+	DryRun bool
+}
+
+// NewDriverPruneCmd removes old locally cached drivers.
+func NewDriverPruneCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverPruneOptions{
+		Common: opt,
+		Driver: driver,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "prune [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Remove old locally cached drivers",
+		Long: `Remove old driver artifacts from the local drivers directory, keeping the newest ones for each driver name.
+The driver matching the running kernel is never removed.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return o.RunDriverPrune(ctx)
+		},
+	}
+
+	cmd.Flags().IntVar(&o.Keep, "keep", 1, "Number of newest drivers to be kept for each driver name, besides the one matching the running kernel")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "Only print the drivers that would be removed")
+	return cmd
+}
+
+// RunDriverPrune implements the driver prune command.
+func (o *driverPruneOptions) RunDriverPrune(_ context.Context) error {
+	if o.Keep < 0 {
+		return fmt.Errorf("--keep must be a non negative number (%d)", o.Keep)
+	}
+
+	cached, err := driverdistro.ListCached()
+	if err != nil {
+		return err
+	}
+
+	prune := driverdistro.ToPrune(cached, o.Keep, func(c *driverdistro.CachedDriver) bool {
+		return c.IsRunning(o.Distro, o.Kr)
+	})
+	if len(prune) == 0 {
+		o.Printer.Logger.Info("Nothing to prune.")
+		return nil
+	}
+
+	for i := range prune {
+		c := &prune[i]
+		if o.DryRun {
+			o.Printer.Logger.Info("Would remove driver", o.Printer.Logger.Args("name", c.Name, "kernel release", c.KernelRelease,
+				"kernel version", c.KernelVersion, "path", c.Path))
+			continue
+		}
+		if err := os.Remove(c.Path); err != nil {
+			return fmt.Errorf("unable to remove driver %q: %w", c.Path, err)
+		}
+		o.Printer.Logger.Info("Removed driver", o.Printer.Logger.Args("name", c.Name, "kernel release", c.KernelRelease,
+			"kernel version", c.KernelVersion, "path", c.Path))
+	}
+	return nil
+}
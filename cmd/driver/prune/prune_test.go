@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverprune_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+)
+
+//nolint:lll // no need to check for line length.
+var driverPruneHelp = `Remove old driver artifacts from the local drivers directory, keeping the newest ones for each driver name.
+The driver matching the running kernel is never removed.
+
+Usage:
+  falcoctl driver prune [flags]
+
+Flags:
+      --dry-run    Only print the drivers that would be removed
+  -h, --help       help for prune
+      --keep int   Number of newest drivers to be kept for each driver name, besides the one matching the running kernel (default 1)
+
+Global Flags:
+      --config string          config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --host-root string       Driver host root to be used. (default "/")
+      --kernelrelease string   Specify the kernel release for which to download/build the driver in the same format used by 'uname -r' (e.g. '6.1.0-10-cloud-amd64')
+      --kernelversion string   Specify the kernel version for which to download/build the driver in the same format used by 'uname -v' (e.g. '#1 SMP PREEMPT_DYNAMIC Debian 6.1.38-2 (2023-07-27)')
+      --log-format string      Set formatting for logs (color, text, json) (default "color")
+      --log-level string       Set level for logs (info, warn, debug, trace) (default "info")
+      --name string            Driver name to be used. (default "falco")
+      --prepend-repo strings   Driver repos to be tried, in descending priority order, before the ones set by --repo.
+      --repo strings           Driver repo to be used. (default [https://download.falco.org/driver])
+      --type strings           Driver types allowed in descending priority order (ebpf, kmod, modern_ebpf) (default [modern_ebpf,kmod,ebpf])
+      --version string         Driver version to be used.
+`
+
+var addAssertFailedBehavior = func(specificError string) {
+	It("check that fails and the usage is not printed", func() {
+		Expect(err).To(HaveOccurred())
+		Expect(output).Should(gbytes.Say(regexp.QuoteMeta(specificError)))
+	})
+}
+
+var _ = Describe("prune", func() {
+
+	var (
+		driverCmd = "driver"
+		pruneCmd  = "prune"
+		home      string
+		drivers   []string
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	BeforeEach(func() {
+		home = GinkgoT().TempDir()
+		GinkgoT().Setenv("HOME", home)
+		drivers = nil
+		// Oldest first; the oldest one matches the kernel passed through the kernelrelease/kernelversion flags.
+		for i, kr := range []string{"6.1.0-9-amd64", "6.1.0-10-amd64", "6.1.0-11-amd64"} {
+			driverPath := filepath.Join(home, ".falco", "7.0.0+driver", "x86_64", "falco_undetermined_"+kr+"_1.ko")
+			Expect(os.MkdirAll(filepath.Dir(driverPath), 0o750)).Should(Succeed())
+			Expect(os.WriteFile(driverPath, nil, 0o600)).Should(Succeed())
+			modTime := time.Now().Add(time.Duration(i-3) * time.Hour)
+			Expect(os.Chtimes(driverPath, modTime, modTime)).Should(Succeed())
+			drivers = append(drivers, driverPath)
+		}
+	})
+
+	Context("help message", func() {
+		BeforeEach(func() {
+			args = []string{driverCmd, pruneCmd, "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(driverPruneHelp)))
+		})
+	})
+
+	Context("prune", func() {
+		pruneArgs := func(extraArgs ...string) []string {
+			return append([]string{driverCmd, pruneCmd, "--config", configFile, "--host-root", home, "--version", "7.0.0+driver",
+				"--kernelrelease", "6.1.0-9-amd64", "--kernelversion", "1", "--type", "kmod"}, extraArgs...)
+		}
+
+		When("with dry-run", func() {
+			BeforeEach(func() {
+				args = pruneArgs("--dry-run")
+			})
+
+			It("should only print the drivers to be removed", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta("Would remove driver")))
+				for _, d := range drivers {
+					Expect(d).Should(BeAnExistingFile())
+				}
+			})
+		})
+
+		When("without dry-run", func() {
+			BeforeEach(func() {
+				args = pruneArgs()
+			})
+
+			It("should keep the newest and the running drivers", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(drivers[0]).Should(BeAnExistingFile())
+				Expect(drivers[1]).ShouldNot(BeAnExistingFile())
+				Expect(drivers[2]).Should(BeAnExistingFile())
+			})
+		})
+	})
+
+	Context("failure", func() {
+		When("with negative keep", func() {
+			BeforeEach(func() {
+				args = []string{driverCmd, pruneCmd, "--config", configFile, "--version", "7.0.0+driver", "--keep", "-1"}
+			})
+			addAssertFailedBehavior("ERROR --keep must be a non negative number (-1)")
+		})
+	})
+})
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverselect
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	driverconfig "github.com/falcosecurity/falcoctl/cmd/driver/config"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const longSelect = `Select the best driver type for the running kernel, among the allowed ones.
+The kernel release and version are the ones of the running kernel, unless --kernelrelease and --kernelversion are given;
+the distro is discovered under the driver host root, so that it works from inside a container too.
+The recommended driver type is printed on stdout; use --apply to also update the Falco config/configmap to use it.
+`
+
+type driverSelectOptions struct {
+	*options.Common
+	*options.Driver
+	Apply      bool
+	Namespace  string
+	KubeConfig string
+}
+
+// NewDriverSelectCmd selects the best driver type for the running kernel.
+func NewDriverSelectCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverSelectOptions{
+		Common: opt,
+		Driver: driver,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "select [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Select the best driver type for the running kernel",
+		Long:                  longSelect,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return o.RunDriverSelect(ctx)
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Apply, "apply", false, "Update Falco config/configmap to use the selected driver type.")
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "", "Kubernetes namespace.")
+	cmd.Flags().StringVar(&o.KubeConfig, "kubeconfig", "", "Kubernetes config.")
+	return cmd
+}
+
+// RunDriverSelect implements the driver select command.
+func (o *driverSelectOptions) RunDriverSelect(ctx context.Context) error {
+	// The driver type has already been chosen, among the allowed ones,
+	// by the distro when the driver options were initialized.
+	if o.Driver.Type == nil {
+		return errors.New("no viable driver type found for the running kernel")
+	}
+
+	types := drivertype.GetTypes()
+	sort.Strings(types)
+	for _, t := range types {
+		dType, err := drivertype.Parse(t)
+		if err != nil {
+			return err
+		}
+		o.Printer.Logger.Info("Driver type", o.Printer.Logger.Args(
+			"type", t,
+			"supported", dType.Supported(o.Kr)))
+	}
+
+	o.Printer.Logger.Info("Selected driver type", o.Printer.Logger.Args(
+		"type", o.Driver.Type.String(),
+		"distro", o.Distro.String(),
+		"kernel release", o.Kr.String(),
+		"kernel version", o.Kr.KernelVersion))
+	o.Printer.DefaultText.Println(o.Driver.Type.String())
+
+	if !o.Apply {
+		return nil
+	}
+	return driverconfig.CommitDriverType(ctx, o.Common, o.Driver, o.Namespace, o.KubeConfig, o.Driver.Type)
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverselect_test
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+)
+
+//nolint:lll // no need to check for line length.
+var driverSelectHelp = `Select the best driver type for the running kernel, among the allowed ones.
+The kernel release and version are the ones of the running kernel, unless --kernelrelease and --kernelversion are given;
+the distro is discovered under the driver host root, so that it works from inside a container too.
+The recommended driver type is printed on stdout; use --apply to also update the Falco config/configmap to use it.
+
+Usage:
+  falcoctl driver select [flags]
+
+Flags:
+      --apply               Update Falco config/configmap to use the selected driver type.
+  -h, --help                help for select
+      --kubeconfig string   Kubernetes config.
+      --namespace string    Kubernetes namespace.
+
+Global Flags:
+      --config string          config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --host-root string       Driver host root to be used. (default "/")
+      --kernelrelease string   Specify the kernel release for which to download/build the driver in the same format used by 'uname -r' (e.g. '6.1.0-10-cloud-amd64')
+      --kernelversion string   Specify the kernel version for which to download/build the driver in the same format used by 'uname -v' (e.g. '#1 SMP PREEMPT_DYNAMIC Debian 6.1.38-2 (2023-07-27)')
+      --log-format string      Set formatting for logs (color, text, json) (default "color")
+      --log-level string       Set level for logs (info, warn, debug, trace) (default "info")
+      --name string            Driver name to be used. (default "falco")
+      --prepend-repo strings   Driver repos to be tried, in descending priority order, before the ones set by --repo.
+      --repo strings           Driver repo to be used. (default [https://download.falco.org/driver])
+      --type strings           Driver types allowed in descending priority order (ebpf, kmod, modern_ebpf) (default [modern_ebpf,kmod,ebpf])
+      --version string         Driver version to be used.
+`
+
+var _ = Describe("select", func() {
+
+	var (
+		driverCmd = "driver"
+		selectCmd = "select"
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("help message", func() {
+		BeforeEach(func() {
+			args = []string{driverCmd, selectCmd, "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(driverSelectHelp)))
+		})
+	})
+
+	Context("with a single allowed driver type", func() {
+		BeforeEach(func() {
+			args = []string{driverCmd, selectCmd, "--config", configFile, "--version", "7.0.0+driver",
+				"--type", "kmod", "--kernelrelease", "6.1.0-10-amd64", "--kernelversion", "1"}
+		})
+
+		It("should print it", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(output).Should(gbytes.Say(`Selected driver type`))
+			Expect(output).Should(gbytes.Say(`(?m)^kmod$`))
+		})
+	})
+
+	Context("with an unsupported driver type", func() {
+		BeforeEach(func() {
+			args = []string{driverCmd, selectCmd, "--config", configFile, "--version", "7.0.0+driver",
+				"--type", "ebpf", "--kernelrelease", "3.10.0", "--kernelversion", "1"}
+		})
+
+		It("should fail", func() {
+			Expect(err).Should(HaveOccurred())
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta("ERROR no supported driver found")))
+		})
+	})
+})
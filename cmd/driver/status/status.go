@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverstatus
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+// ErrDriverMismatch is returned when the loaded driver differs from the configured one.
+var ErrDriverMismatch = errors.New("loaded driver does not match the configured one")
+
+type driverStatusOptions struct {
+	*options.Common
+	*options.Driver
+	// falcoConfig is the Falco config file, relative to the driver host root.
+	falcoConfig string
+	loaded      func(driverName string) ([]string, error)
+}
+
+// NewDriverStatusCmd reports whether the loaded driver matches the configured one.
+func NewDriverStatusCmd(ctx context.Context, opt *options.Common, driver *options.Driver) *cobra.Command {
+	o := driverStatusOptions{
+		Common: opt,
+		Driver: driver,
+		loaded: drivertype.Loaded,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "status [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Report whether the loaded driver matches the configured one",
+		Long: `Report the driver currently loaded in the kernel versus the engine.kind configured for Falco.
+It exits with an error on mismatch, so that it can be used as a health check.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return o.RunDriverStatus(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.falcoConfig, "falco-config", "/etc/falco/falco.yaml",
+		"Path of the Falco configuration file, relative to the driver host root.")
+	return cmd
+}
+
+// RunDriverStatus implements the driver status command.
+func (o *driverStatusOptions) RunDriverStatus(_ context.Context) error {
+	configured, source, err := o.configuredDriver()
+	if err != nil {
+		return err
+	}
+	loaded, err := o.loaded(o.Driver.Name)
+	if err != nil {
+		return fmt.Errorf("unable to detect the loaded driver: %w", err)
+	}
+
+	o.Printer.Logger.Info("Driver status", o.Printer.Logger.Args(
+		"configured", configured,
+		"configured from", source,
+		"loaded", strings.Join(loaded, ",")))
+
+	if len(loaded) == 0 {
+		o.Printer.Logger.Info("No loaded driver detected; Falco will use the configured one once started.")
+		return nil
+	}
+
+	var stale []string
+	for _, l := range loaded {
+		if l != configured {
+			stale = append(stale, l)
+		}
+	}
+	if len(stale) == 0 {
+		o.Printer.Logger.Info("Loaded driver matches the configured one.")
+		return nil
+	}
+
+	for _, s := range stale {
+		if s == drivertype.TypeKmod {
+			o.Printer.Logger.Warn("The kernel module is still loaded: restart Falco, then run 'falcoctl driver cleanup --type kmod' "+
+				"if it is still loaded.", o.Printer.Logger.Args("configured", configured))
+		} else {
+			o.Printer.Logger.Warn("Falco is running with a different driver: restart Falco to switch driver.",
+				o.Printer.Logger.Args("loaded", s, "configured", configured))
+		}
+	}
+	return fmt.Errorf("%w: loaded %s, configured %s", ErrDriverMismatch, strings.Join(stale, ","), configured)
+}
+
+// configuredDriver returns the engine.kind set in the Falco configuration, falling back
+// to the driver type configured for falcoctl when the Falco configuration cannot be found.
+func (o *driverStatusOptions) configuredDriver() (kind, source string, err error) {
+	falcoConfigFile, err := utils.ResolveHostPath(o.Driver.HostRoot, o.falcoConfig)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := os.ReadFile(falcoConfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return o.Driver.Type.String(), "falcoctl", nil
+	} else if err != nil {
+		return "", "", err
+	}
+
+	var cfg struct {
+		Engine struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"engine"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return "", "", fmt.Errorf("unable to parse Falco configuration %q: %w", falcoConfigFile, err)
+	}
+	// Compare the canonical type names, engine.kind may hold an alias (e.g. modern-ebpf).
+	driverType, err := drivertype.Parse(cfg.Engine.Kind)
+	if err != nil {
+		return "", "", fmt.Errorf("engine.kind of Falco configuration %q is not driver driven: %q", falcoConfigFile, cfg.Engine.Kind)
+	}
+	return driverType.String(), falcoConfigFile, nil
+}
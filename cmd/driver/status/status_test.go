@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverstatus
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func TestRunDriverStatus(t *testing.T) {
+	modernBpf, err := drivertype.Parse(drivertype.TypeModernBpf)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		falcoConfig string
+		// path is the --falco-config flag, defaulting to /etc/falco/falco.yaml.
+		path        string
+		loaded      []string
+		expectedErr string
+		expectedLog string
+	}{
+		{
+			name:        "matching driver",
+			falcoConfig: "engine:\n  kind: kmod\n",
+			loaded:      []string{drivertype.TypeKmod},
+			expectedLog: "Loaded driver matches the configured one.",
+		},
+		{
+			name:        "matching driver configured through an alias",
+			falcoConfig: "engine:\n  kind: modern-ebpf\n",
+			loaded:      []string{drivertype.TypeModernBpf},
+			expectedLog: "Loaded driver matches the configured one.",
+		},
+		{
+			name:        "kmod still loaded after switching to modern ebpf",
+			falcoConfig: "engine:\n  kind: modern_ebpf\n",
+			loaded:      []string{drivertype.TypeKmod, drivertype.TypeModernBpf},
+			expectedErr: "loaded driver does not match the configured one: loaded kmod, configured modern_ebpf",
+			expectedLog: "falcoctl driver cleanup --type kmod",
+		},
+		{
+			name:        "falco running with another bpf probe",
+			falcoConfig: "engine:\n  kind: modern_ebpf\n",
+			loaded:      []string{drivertype.TypeBpf},
+			expectedErr: "loaded driver does not match the configured one: loaded ebpf, configured modern_ebpf",
+			expectedLog: "restart Falco to switch driver",
+		},
+		{
+			name:        "nothing loaded",
+			falcoConfig: "engine:\n  kind: kmod\n",
+			expectedLog: "No loaded driver detected",
+		},
+		{
+			name:        "missing falco config falls back to the falcoctl one",
+			loaded:      []string{drivertype.TypeModernBpf},
+			expectedLog: "Loaded driver matches the configured one.",
+		},
+		{
+			name:        "falco not running with drivers",
+			falcoConfig: "engine:\n  kind: gvisor\n",
+			expectedErr: "is not driver driven: \"gvisor\"",
+		},
+		{
+			name:        "falco config outside of the host root",
+			path:        "../falco.yaml",
+			expectedErr: "escapes the host root",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hostRoot := t.TempDir()
+			if tc.falcoConfig != "" {
+				require.NoError(t, os.MkdirAll(filepath.Join(hostRoot, "etc", "falco"), 0o750))
+				require.NoError(t, os.WriteFile(filepath.Join(hostRoot, "etc", "falco", "falco.yaml"), []byte(tc.falcoConfig), 0o600))
+			}
+			if tc.path == "" {
+				tc.path = "/etc/falco/falco.yaml"
+			}
+			var buf bytes.Buffer
+			o := driverStatusOptions{
+				Common:      &options.Common{Printer: output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, &buf)},
+				Driver:      &options.Driver{Name: "falco", Type: modernBpf, HostRoot: hostRoot},
+				falcoConfig: tc.path,
+				loaded: func(string) ([]string, error) {
+					return tc.loaded, nil
+				},
+			}
+
+			err := o.RunDriverStatus(context.Background())
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Contains(t, buf.String(), tc.expectedLog)
+		})
+	}
+}
@@ -64,7 +64,12 @@ func (o *IndexAddOptions) RunIndexAdd(ctx context.Context, args []string) error
 	}
 
 	logger.Debug("Creating in-memory cache using", logger.Args("indexes file", config.IndexesFile, "indexes directory", config.IndexesDir))
-	indexCache, err := cache.New(ctx, config.IndexesFile, config.IndexesDir)
+	indexes, err := config.Indexes()
+	if err != nil {
+		return fmt.Errorf("unable to get indexes from configuration: %w", err)
+	}
+
+	indexCache, err := cache.New(ctx, config.IndexesFile, config.IndexesDir, cache.WithConfiguredIndexes(indexes))
 	if err != nil {
 		return fmt.Errorf("unable to create index cache: %w", err)
 	}
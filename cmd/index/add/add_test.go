@@ -33,9 +33,10 @@ Flags:
 -h, --help   help for add
 
 Global Flags:
-      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string         config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string     Set formatting for logs (color, text, json) (default "color")
+      --log-level string      Set level for logs (info, warn, debug, trace) (default "info")
+      --socks5-proxy string   SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
 //nolint:lll // no need to check for line length.
@@ -48,9 +49,10 @@ Flags:
   -h, --help   help for add
 
 Global Flags:
-      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string         config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string     Set formatting for logs (color, text, json) (default "color")
+      --log-level string      Set level for logs (info, warn, debug, trace) (default "info")
+      --socks5-proxy string   SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
 var addAssertFailedBehavior = func(usage, specificError string) {
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/pkg/index/generate"
+	ociregistry "github.com/falcosecurity/falcoctl/pkg/oci/registry"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	// FlagOutputFile is the name of the flag to set the file where the index is written.
+	FlagOutputFile = "output-file"
+	// FlagRegistry is the name of the flag to set the registry of the entries generated from a directory.
+	FlagRegistry = "registry"
+
+	generatedIndexName = "generated"
+)
+
+type indexGenerateOptions struct {
+	*options.Common
+	*options.Registry
+	outputFile string
+	registry   string
+}
+
+// NewIndexGenerateCmd returns the index generate command.
+func NewIndexGenerateCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := indexGenerateOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "generate [REGISTRY[/NAMESPACE] | DIRECTORY] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Generate an index file from the artifacts of a registry or of a directory",
+		Long: `Generate an index file with an entry for each artifact found in a registry namespace or
+in a local directory of OCI image layouts. Each entry is filled with the name, type, registry, repository
+and tags of the artifact. The other fields, such as description and maintainers, are left to the index maintainers.
+
+Registries are scanned using the catalog API, so it must be enabled and accessible with the configured credentials.
+Layouts found in a directory can be stored either as directories or as tarballs; their path relative to the directory
+is used as repository name. Since a directory has no registry, it must be set with --registry.`,
+		Example: `  falcoctl index generate ghcr.io/myorg --output-file index.yaml
+  falcoctl index generate ./artifacts --registry ghcr.io/myorg`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunIndexGenerate(ctx, args[0])
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.outputFile, FlagOutputFile, "", "file where the index is written, defaults to the standard output")
+	cmd.Flags().StringVar(&o.registry, FlagRegistry, "",
+		"registry, with an optional namespace, set in the entries generated from a directory, e.g. ghcr.io/myorg")
+
+	return cmd
+}
+
+// RunIndexGenerate implements the index generate command.
+func (o *indexGenerateOptions) RunIndexGenerate(ctx context.Context, source string) error {
+	logger := o.Printer.Logger
+
+	src, opts, err := o.source(source)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Scanning artifacts", logger.Args("source", source))
+	idx, skipped, err := generate.Generate(ctx, src, generatedIndexName, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range skipped {
+		logger.Warn("Skipping repository", logger.Args("repository", s.Repository, "reason", s.Reason))
+	}
+
+	if o.outputFile != "" {
+		if err := idx.Write(o.outputFile); err != nil {
+			return err
+		}
+		logger.Info("Index generated", logger.Args("file", o.outputFile, "entries", len(idx.Entries)))
+		return nil
+	}
+
+	data, err := yaml.Marshal(idx.Entries)
+	if err != nil {
+		return fmt.Errorf("cannot marshal index: %w", err)
+	}
+	o.Printer.DefaultText.Print(string(data))
+
+	return nil
+}
+
+// source returns the source of the artifacts and the options used to fill the entries.
+func (o *indexGenerateOptions) source(source string) (generate.Source, generate.Options, error) {
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		if o.registry == "" {
+			return nil, generate.Options{}, fmt.Errorf("--%s is required when generating an index from a directory", FlagRegistry)
+		}
+		host, prefix, _ := strings.Cut(strings.Trim(o.registry, "/"), "/")
+		return generate.NewLocalSource(source), generate.Options{Registry: host, Prefix: prefix}, nil
+	}
+
+	if o.registry != "" {
+		return nil, generate.Options{}, fmt.Errorf("--%s can only be used when generating an index from a directory", FlagRegistry)
+	}
+
+	host, namespace, _ := strings.Cut(strings.Trim(source, "/"), "/")
+	client, err := ociutils.Client(true)
+	if err != nil {
+		return nil, generate.Options{}, err
+	}
+
+	reg, err := ociregistry.NewRegistry(host, ociregistry.WithClient(client), ociregistry.WithPlainHTTP(o.PlainHTTP))
+	if err != nil {
+		return nil, generate.Options{}, err
+	}
+
+	return generate.NewRemoteSource(reg, namespace), generate.Options{Registry: host}, nil
+}
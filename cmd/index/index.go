@@ -21,15 +21,20 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/falcosecurity/falcoctl/cmd/index/add"
+	"github.com/falcosecurity/falcoctl/cmd/index/generate"
 	"github.com/falcosecurity/falcoctl/cmd/index/list"
+	"github.com/falcosecurity/falcoctl/cmd/index/merge"
 	"github.com/falcosecurity/falcoctl/cmd/index/remove"
 	"github.com/falcosecurity/falcoctl/cmd/index/update"
+	"github.com/falcosecurity/falcoctl/cmd/index/validate"
 	"github.com/falcosecurity/falcoctl/internal/config"
 	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
 )
 
 // NewIndexCmd returns the index command.
 func NewIndexCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Command {
+	var proxy commonoptions.Proxy
+
 	cmd := &cobra.Command{
 		Use:                   "index",
 		DisableFlagsInUseLine: true,
@@ -37,14 +42,25 @@ func NewIndexCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Command
 		Long:                  "Interact with index",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			opt.Initialize()
-			return config.Load(opt.ConfigFile)
+			if err := config.Load(opt.ConfigFile); err != nil {
+				return err
+			}
+			if err := proxy.Configure(cmd); err != nil {
+				return err
+			}
+			return commonoptions.ConfigureTLS()
 		},
 	}
 
+	proxy.AddFlags(cmd)
+
 	cmd.AddCommand(add.NewIndexAddCmd(ctx, opt))
 	cmd.AddCommand(remove.NewIndexRemoveCmd(ctx, opt))
 	cmd.AddCommand(update.NewIndexUpdateCmd(ctx, opt))
 	cmd.AddCommand(list.NewIndexListCmd(ctx, opt))
+	cmd.AddCommand(generate.NewIndexGenerateCmd(ctx, opt))
+	cmd.AddCommand(validate.NewIndexValidateCmd(ctx, opt))
+	cmd.AddCommand(merge.NewIndexMergeCmd(ctx, opt))
 
 	return cmd
 }
@@ -28,12 +28,23 @@ import (
 
 type indexListOptions struct {
 	*options.Common
+	*options.Output
+}
+
+// indexInfo is the machine-readable representation of a configured index.
+type indexInfo struct {
+	Name    string `json:"name" yaml:"name"`
+	URL     string `json:"url" yaml:"url"`
+	Backend string `json:"backend" yaml:"backend"`
+	Added   string `json:"added" yaml:"added"`
+	Updated string `json:"updated" yaml:"updated"`
 }
 
 // NewIndexListCmd returns the index list command.
 func NewIndexListCmd(_ context.Context, opt *options.Common) *cobra.Command {
 	o := indexListOptions{
 		Common: opt,
+		Output: &options.Output{},
 	}
 
 	cmd := &cobra.Command{
@@ -43,11 +54,16 @@ func NewIndexListCmd(_ context.Context, opt *options.Common) *cobra.Command {
 		Long:                  "List all the added indexes that were configured in falcoctl",
 		Args:                  cobra.ExactArgs(0),
 		Aliases:               []string{"ls"},
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return o.Output.Validate()
+		},
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return o.RunIndexList()
 		},
 	}
 
+	o.Output.AddFlags(cmd)
+
 	return cmd
 }
 
@@ -57,6 +73,20 @@ func (o *indexListOptions) RunIndexList() error {
 		return err
 	}
 
+	if o.Structured() {
+		infos := []indexInfo{}
+		for _, conf := range indexConfig.Configs {
+			infos = append(infos, indexInfo{
+				Name:    conf.Name,
+				URL:     conf.URL,
+				Backend: conf.Backend,
+				Added:   conf.AddedTimestamp,
+				Updated: conf.UpdatedTimestamp,
+			})
+		}
+		return o.Printer.PrintStructured(o.Format, infos)
+	}
+
 	var data [][]string
 	for _, conf := range indexConfig.Configs {
 		newEntry := []string{conf.Name, conf.URL, conf.AddedTimestamp, conf.UpdatedTimestamp}
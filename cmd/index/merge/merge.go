@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/pkg/index/cache"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	// FlagPrecedence is the name of the flag to set the order in which the indexes take precedence.
+	FlagPrecedence = "precedence"
+	// FlagOutputFile is the name of the flag to set the file where the merged index is written.
+	FlagOutputFile = "output-file"
+)
+
+type indexMergeOptions struct {
+	*options.Common
+	precedence []string
+	outputFile string
+}
+
+// NewIndexMergeCmd returns the index merge command.
+func NewIndexMergeCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := indexMergeOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "merge [INDEX1 [INDEX2 ...]] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Merge the configured indexes into a single index file",
+		Long: `Merge the configured indexes, or only the given ones, into a single index file.
+
+When more indexes have an entry with the same name, the entry of the index with the highest priority is used,
+as configured in the "priority" field of the indexes. Among indexes with the same priority, the last configured one wins.
+This is the same resolution used by the artifact commands. The indexes listed in --precedence take precedence
+over all the others, the first one being the strongest.`,
+		Example: `  falcoctl index merge --precedence internal,falcosecurity --output-file index.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunIndexMerge(ctx, args)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&o.precedence, FlagPrecedence, nil,
+		"indexes that take precedence over the others, from the strongest to the weakest")
+	cmd.Flags().StringVar(&o.outputFile, FlagOutputFile, "", "file where the merged index is written, defaults to the standard output")
+
+	return cmd
+}
+
+// RunIndexMerge implements the index merge command.
+func (o *indexMergeOptions) RunIndexMerge(ctx context.Context, names []string) error {
+	logger := o.Printer.Logger
+
+	indexes, err := config.Indexes()
+	if err != nil {
+		return fmt.Errorf("unable to get indexes from configuration: %w", err)
+	}
+
+	if indexes, err = selectIndexes(indexes, names); err != nil {
+		return err
+	}
+	if err = applyPrecedence(indexes, o.precedence); err != nil {
+		return err
+	}
+
+	indexCache, err := cache.NewFromConfig(ctx, config.IndexesFile, config.IndexesDir, indexes)
+	if err != nil {
+		return fmt.Errorf("unable to create index cache: %w", err)
+	}
+
+	for _, c := range indexCache.Conflicts() {
+		logger.Info("Artifact found in more indexes",
+			logger.Args("name", c.Name, "index", c.Index, "shadowed", strings.Join(c.Shadowed, ", ")))
+	}
+
+	merged := &indexCache.MergedIndexes.Index
+	if o.outputFile != "" {
+		if err := merged.Write(o.outputFile); err != nil {
+			return err
+		}
+		logger.Info("Indexes merged", logger.Args("file", o.outputFile, "entries", len(merged.Entries)))
+		return nil
+	}
+
+	if err := merged.Normalize(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(merged.Entries)
+	if err != nil {
+		return fmt.Errorf("cannot marshal index: %w", err)
+	}
+	o.Printer.DefaultText.Print(string(data))
+
+	return nil
+}
+
+// selectIndexes returns the configured indexes with the given names, in configuration order, or all of them if no name is given.
+func selectIndexes(indexes []config.Index, names []string) ([]config.Index, error) {
+	if len(names) == 0 {
+		return indexes, nil
+	}
+
+	var selected []config.Index
+	for _, name := range names {
+		if !slices.ContainsFunc(indexes, func(idx config.Index) bool { return idx.Name == name }) {
+			return nil, fmt.Errorf("index %q not found in the configuration", name)
+		}
+	}
+	for _, idx := range indexes {
+		if slices.Contains(names, idx.Name) {
+			selected = append(selected, idx)
+		}
+	}
+
+	return selected, nil
+}
+
+// applyPrecedence raises the priority of the given indexes above the priority of all the others, keeping their order.
+func applyPrecedence(indexes []config.Index, precedence []string) error {
+	if len(precedence) == 0 {
+		return nil
+	}
+
+	base := 0
+	for _, idx := range indexes {
+		base = max(base, idx.Priority)
+	}
+
+	for i, name := range precedence {
+		pos := slices.IndexFunc(indexes, func(idx config.Index) bool { return idx.Name == name })
+		if pos < 0 {
+			return fmt.Errorf("index %q in --%s is not among the merged indexes", name, FlagPrecedence)
+		}
+		indexes[pos].Priority = base + len(precedence) - i
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	indexConf "github.com/falcosecurity/falcoctl/pkg/index/config"
+	"github.com/falcosecurity/falcoctl/pkg/index/fetch"
+	"github.com/falcosecurity/falcoctl/pkg/index/validate"
+	"github.com/falcosecurity/falcoctl/pkg/oci/repository"
+	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	// FlagOffline is the name of the flag to skip the checks done against the registries.
+	FlagOffline = "offline"
+	// FlagStrict is the name of the flag to fail on warnings too.
+	FlagStrict = "strict"
+)
+
+type indexValidateOptions struct {
+	*options.Common
+	*options.Registry
+	offline bool
+	strict  bool
+}
+
+// NewIndexValidateCmd returns the index validate command.
+func NewIndexValidateCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := indexValidateOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "validate [FILE | URL] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Validate an index file",
+		Long: `Validate an index file, read from the filesystem or fetched from any of the supported index backends.
+
+The following checks are run:
+  - the file is a list of entries without unknown fields;
+  - each entry has a name, a valid type, a registry and a repository;
+  - entry names are unique;
+  - the repositories can be reached and listed (skipped with --offline);
+  - the tags are semver versions, "latest" and signature tags aside.
+
+The command exits with a non-zero code when an error is found, or a warning when --strict is set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunIndexValidate(ctx, args[0])
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.offline, FlagOffline, false, "skip the checks that need to reach the registries")
+	cmd.Flags().BoolVar(&o.strict, FlagStrict, false, "fail on warnings too")
+
+	return cmd
+}
+
+// RunIndexValidate implements the index validate command.
+func (o *indexValidateOptions) RunIndexValidate(ctx context.Context, source string) error {
+	logger := o.Printer.Logger
+
+	data, err := o.read(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	var opts validate.Options
+	if !o.offline {
+		client, err := ociutils.Client(true)
+		if err != nil {
+			return err
+		}
+		opts.TagLister = func(ctx context.Context, ref string) ([]string, error) {
+			repo, err := repository.NewRepository(ref, repository.WithClient(client), repository.WithPlainHTTP(o.PlainHTTP))
+			if err != nil {
+				return nil, err
+			}
+			return repo.Tags(ctx)
+		}
+	}
+
+	issues, err := validate.Validate(ctx, data, opts)
+	if err != nil {
+		return err
+	}
+
+	var errorsCount, warningsCount int
+	for _, issue := range issues {
+		var args []interface{}
+		if issue.Entry != "" {
+			args = append(args, "entry", issue.Entry)
+		}
+		if issue.Severity == validate.SeverityError {
+			errorsCount++
+			logger.Error(issue.Message, logger.Args(args...))
+		} else {
+			warningsCount++
+			logger.Warn(issue.Message, logger.Args(args...))
+		}
+	}
+
+	if errorsCount > 0 || (o.strict && warningsCount > 0) {
+		return fmt.Errorf("index %q is not valid: %d errors, %d warnings", source, errorsCount, warningsCount)
+	}
+
+	logger.Info("Index is valid", logger.Args("index", source, "warnings", warningsCount))
+	return nil
+}
+
+// read returns the content of the index, from the filesystem if a file exists at the given location,
+// otherwise from the backend matching its URL.
+func (o *indexValidateOptions) read(ctx context.Context, source string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Clean(source))
+	if err == nil {
+		return data, nil
+	} else if u, parseErr := url.Parse(source); !errors.Is(err, fs.ErrNotExist) || parseErr != nil || u.Scheme == "" {
+		return nil, fmt.Errorf("unable to read index %q: %w", source, err)
+	}
+
+	data, err = fetch.NewFetcher().FetchRaw(ctx, &indexConf.Entry{Name: "validate", URL: source})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read index %q: %w", source, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package initconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	driverkernel "github.com/falcosecurity/falcoctl/pkg/driver/kernel"
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	// FlagDriverType is the name of the flag to set the driver types.
+	FlagDriverType = "driver-type"
+	// FlagIndex is the name of the flag to set the indexes.
+	FlagIndex = "index"
+	// FlagRulesFilesDir is the name of the flag to set the rulesfiles install dir.
+	FlagRulesFilesDir = "rulesfiles-dir"
+	// FlagPluginsDir is the name of the flag to set the plugins install dir.
+	FlagPluginsDir = "plugins-dir"
+	// FlagAssetsDir is the name of the flag to set the assets install dir.
+	FlagAssetsDir = "assets-dir"
+)
+
+type initOptions struct {
+	*options.Common
+	DriverTypes    []string
+	Indexes        []string
+	RulesfilesDir  string
+	PluginsDir     string
+	AssetsDir      string
+	NonInteractive bool
+	Overwrite      bool
+}
+
+// NewInitCmd returns the init command.
+func NewInitCmd(opt *options.Common) *cobra.Command {
+	o := initOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "init [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Write a starting falcoctl configuration",
+		Long: `Write a starting falcoctl configuration to the file set by --config.
+When running on a terminal, the values not set through flags are interactively asked for;
+use --non-interactive to only rely on flags and defaults. The resulting configuration is printed at the end.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return o.RunInit(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&o.DriverTypes, FlagDriverType, nil,
+		"Driver types allowed in descending priority order. Defaults to the ones supported by the running kernel")
+	cmd.Flags().StringSliceVar(&o.Indexes, FlagIndex, []string{config.DefaultIndex.Name + "=" + config.DefaultIndex.URL},
+		"Indexes to be added, in the form name=url; can be repeated")
+	cmd.Flags().StringVar(&o.RulesfilesDir, FlagRulesFilesDir, config.RulesfilesDir, "Directory where to install rules")
+	cmd.Flags().StringVar(&o.PluginsDir, FlagPluginsDir, config.PluginsDir, "Directory where to install plugins")
+	cmd.Flags().StringVar(&o.AssetsDir, FlagAssetsDir, config.AssetsDir, "Directory where to install assets")
+	cmd.Flags().BoolVar(&o.NonInteractive, "non-interactive", false, "Do not prompt for the values not set through flags")
+	cmd.Flags().BoolVar(&o.Overwrite, "overwrite", false, "Overwrite the config file if it already exists")
+
+	return cmd
+}
+
+// supportedDriverTypes returns the default driver types supported by the running kernel, keeping their priority.
+func supportedDriverTypes() []string {
+	kr, err := driverkernel.FetchInfo("", "")
+	if err != nil {
+		return config.DefaultDriver.Type
+	}
+	supported := make([]string, 0, len(config.DefaultDriver.Type))
+	for _, t := range config.DefaultDriver.Type {
+		dType, err := drivertype.Parse(t)
+		if err == nil && dType.Supported(kr) {
+			supported = append(supported, t)
+		}
+	}
+	if len(supported) == 0 {
+		return config.DefaultDriver.Type
+	}
+	return supported
+}
+
+// RunInit implements the init command.
+func (o *initOptions) RunInit(flags *pflag.FlagSet) error {
+	if _, err := os.Stat(o.ConfigFile); err == nil && !o.Overwrite {
+		return fmt.Errorf("config file %q already exists, use --overwrite to replace it", o.ConfigFile)
+	}
+
+	if len(o.DriverTypes) == 0 {
+		o.DriverTypes = supportedDriverTypes()
+	}
+
+	if !o.NonInteractive && isTerminal(os.Stdin) {
+		if err := o.prompt(flags); err != nil {
+			return err
+		}
+	}
+
+	indexes, err := o.validate()
+	if err != nil {
+		return err
+	}
+
+	if err := o.write(indexes); err != nil {
+		return err
+	}
+
+	// Make sure the written config can be loaded back.
+	if err := config.Load(o.ConfigFile); err != nil {
+		return fmt.Errorf("written config file is not valid: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Clean(o.ConfigFile))
+	if err != nil {
+		return err
+	}
+	o.Printer.Logger.Info("Config file written", o.Printer.Logger.Args("path", o.ConfigFile))
+	o.Printer.DefaultText.Print(string(data))
+	return nil
+}
+
+// prompt asks for the values that were not set through flags.
+func (o *initOptions) prompt(flags *pflag.FlagSet) error {
+	var err error
+	ask := func(flag, text string, value *string) {
+		if err != nil || flags.Changed(flag) {
+			return
+		}
+		var answer string
+		answer, err = pterm.DefaultInteractiveTextInput.WithDefaultValue(*value).Show(text)
+		if err == nil {
+			*value = strings.TrimSpace(answer)
+		}
+	}
+
+	driverTypes := strings.Join(o.DriverTypes, ",")
+	indexes := strings.Join(o.Indexes, ",")
+	ask(FlagDriverType, "Driver types allowed, in descending priority order (comma-separated)", &driverTypes)
+	ask(FlagIndex, "Indexes to be added, in the form name=url (comma-separated)", &indexes)
+	ask(FlagRulesFilesDir, "Directory where to install rules", &o.RulesfilesDir)
+	ask(FlagPluginsDir, "Directory where to install plugins", &o.PluginsDir)
+	ask(FlagAssetsDir, "Directory where to install assets", &o.AssetsDir)
+	if err != nil {
+		return fmt.Errorf("unable to read answer: %w", err)
+	}
+
+	o.DriverTypes = splitList(driverTypes)
+	o.Indexes = splitList(indexes)
+	return nil
+}
+
+// validate checks the collected values, returning the parsed indexes.
+func (o *initOptions) validate() ([]config.Index, error) {
+	if len(o.DriverTypes) == 0 {
+		return nil, errors.New("at least one driver type must be set")
+	}
+	for _, t := range o.DriverTypes {
+		if _, err := drivertype.Parse(t); err != nil {
+			return nil, err
+		}
+	}
+
+	indexes := make([]config.Index, 0, len(o.Indexes))
+	for _, idx := range o.Indexes {
+		name, rawURL, ok := strings.Cut(idx, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("index %q must be in the form name=url", idx)
+		}
+		if _, err := url.ParseRequestURI(rawURL); err != nil {
+			return nil, fmt.Errorf("index %q has an invalid url: %w", name, err)
+		}
+		indexes = append(indexes, config.Index{Name: name, URL: rawURL})
+	}
+
+	for flag, dir := range map[string]string{FlagRulesFilesDir: o.RulesfilesDir, FlagPluginsDir: o.PluginsDir, FlagAssetsDir: o.AssetsDir} {
+		if !filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("--%s must be an absolute path (%s)", flag, dir)
+		}
+	}
+	return indexes, nil
+}
+
+func (o *initOptions) write(indexes []config.Index) error {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	driver := config.DefaultDriver
+	driver.Type = o.DriverTypes
+	v.Set(config.DriverKey, driver)
+	v.Set(config.IndexesKey, indexes)
+	v.Set(config.ArtifactInstallRulesfilesDirKey, o.RulesfilesDir)
+	v.Set(config.ArtifactInstallPluginsDirKey, o.PluginsDir)
+	v.Set(config.ArtifactInstallAssetsDirKey, o.AssetsDir)
+	v.Set(config.ArtifactFollowRulesfilesDirKey, o.RulesfilesDir)
+	v.Set(config.ArtifactFollowPluginsDirKey, o.PluginsDir)
+	v.Set(config.ArtifactFollowAssetsDirKey, o.AssetsDir)
+
+	if err := os.MkdirAll(filepath.Dir(o.ConfigFile), 0o700); err != nil {
+		return fmt.Errorf("unable to create config directory: %w", err)
+	}
+	if err := v.WriteConfigAs(o.ConfigFile); err != nil {
+		return fmt.Errorf("unable to write config file %q: %w", o.ConfigFile, err)
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	values := make([]string, 0)
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
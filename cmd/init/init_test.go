@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package initconfig_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+)
+
+//nolint:lll // no need to check for line length.
+var initHelp = `Write a starting falcoctl configuration to the file set by --config.
+When running on a terminal, the values not set through flags are interactively asked for;
+use --non-interactive to only rely on flags and defaults. The resulting configuration is printed at the end.
+
+Usage:
+  falcoctl init [flags]
+
+Flags:
+      --assets-dir string       Directory where to install assets (default "/etc/falco/assets")
+      --driver-type strings     Driver types allowed in descending priority order. Defaults to the ones supported by the running kernel
+  -h, --help                    help for init
+      --index strings           Indexes to be added, in the form name=url; can be repeated (default [falcosecurity=https://falcosecurity.github.io/falcoctl/index.yaml])
+      --non-interactive         Do not prompt for the values not set through flags
+      --overwrite               Overwrite the config file if it already exists
+      --plugins-dir string      Directory where to install plugins (default "/usr/share/falco/plugins")
+      --rulesfiles-dir string   Directory where to install rules (default "/etc/falco")
+
+Global Flags:
+      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string   Set formatting for logs (color, text, json) (default "color")
+      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+`
+
+var addAssertFailedBehavior = func(specificError string) {
+	It("check that fails and the usage is not printed", func() {
+		Expect(err).To(HaveOccurred())
+		Expect(output).Should(gbytes.Say(regexp.QuoteMeta(specificError)))
+	})
+}
+
+var _ = Describe("init", func() {
+
+	var (
+		initCmd   = "init"
+		newConfig string
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	BeforeEach(func() {
+		newConfig = filepath.Join(GinkgoT().TempDir(), "falcoctl.yaml")
+	})
+
+	Context("help message", func() {
+		BeforeEach(func() {
+			args = []string{initCmd, "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(initHelp)))
+		})
+	})
+
+	Context("non-interactive", func() {
+		BeforeEach(func() {
+			args = []string{initCmd, "--config", newConfig, "--non-interactive", "--driver-type", "kmod,ebpf",
+				"--index", "local=http://localhost:8080/index.yaml", "--rulesfiles-dir", "/tmp/rules"}
+		})
+
+		It("should write and print the config file", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			data, err := os.ReadFile(newConfig)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(data)).Should(ContainSubstring("- kmod\n"))
+			Expect(string(data)).Should(ContainSubstring("url: http://localhost:8080/index.yaml"))
+			Expect(string(data)).Should(ContainSubstring("rulesfilesdir: /tmp/rules"))
+			Expect(output).Should(gbytes.Say("Config file written"))
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta("name: local")))
+		})
+	})
+
+	Context("failure", func() {
+		When("the config file already exists", func() {
+			BeforeEach(func() {
+				args = []string{initCmd, "--config", configFile, "--non-interactive"}
+			})
+			addAssertFailedBehavior("already exists, use --overwrite to replace it")
+		})
+
+		When("with invalid driver type", func() {
+			BeforeEach(func() {
+				args = []string{initCmd, "--config", newConfig, "--non-interactive", "--driver-type", "foo"}
+			})
+			addAssertFailedBehavior("ERROR unsupported driver type specified: foo")
+		})
+
+		When("with invalid index", func() {
+			BeforeEach(func() {
+				args = []string{initCmd, "--config", newConfig, "--non-interactive", "--index", "local"}
+			})
+			addAssertFailedBehavior(`ERROR index "local" must be in the form name=url`)
+		})
+
+		When("with relative install dir", func() {
+			BeforeEach(func() {
+				args = []string{initCmd, "--config", newConfig, "--non-interactive", "--plugins-dir", "plugins"}
+			})
+			addAssertFailedBehavior("ERROR --plugins-dir must be an absolute path (plugins)")
+		})
+	})
+})
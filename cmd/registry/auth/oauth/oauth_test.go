@@ -64,9 +64,10 @@ Flags:
       --token-url string       token URL used to get access and refresh tokens
 
 Global Flags:
-      --config string     config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string         config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string     Set formatting for logs (color, text, json) (default "color")
+      --log-level string      Set level for logs (info, warn, debug, trace) (default "info")
+      --socks5-proxy string   SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 
 `
 
@@ -18,11 +18,17 @@ package pull
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
 
 	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/enum"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
 	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 	"github.com/falcosecurity/falcoctl/pkg/output"
@@ -47,17 +53,36 @@ Example - Pull artifact "myplugin" for platform "linux/arm64" in "myDir" directo
 
 Example - Pull artifact "myrulesfile":
 	falcoctl registry pull localhost:5000/myrulesfile:latest
+
+Example - Pull artifact "myplugin", with all its platforms, as an OCI image layout in "myLayout" directory:
+	falcoctl registry pull localhost:5000/myplugin:latest --output oci-layout --dest-dir=./myLayout
+
+Example - Pull artifact "myplugin" as an OCI image layout tar archive, to move it to a disconnected network:
+	falcoctl registry pull localhost:5000/myplugin:latest --output tar
 `
 )
 
+const (
+	// outputFiles writes the layers of the artifact.
+	outputFiles = "files"
+	// outputLayout writes an OCI image layout directory.
+	outputLayout = "oci-layout"
+	// outputTar writes an OCI image layout tar archive.
+	outputTar = "tar"
+)
+
 type pullOptions struct {
 	*options.Common
 	*options.Artifact
 	*options.Registry
 	destDir string
+	output  *enum.Enum
 }
 
 func (o *pullOptions) Validate() error {
+	if o.output.String() != outputFiles && len(o.Platforms) > 0 {
+		return fmt.Errorf("--platform cannot be used with --output %s, the OCI layout holds all the platforms", o.output.String())
+	}
 	return o.Artifact.Validate()
 }
 
@@ -67,6 +92,7 @@ func NewPullCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 		Common:   opt,
 		Artifact: &options.Artifact{},
 		Registry: &options.Registry{},
+		output:   enum.NewEnum([]string{outputFiles, outputLayout, outputTar}, outputFiles),
 	}
 
 	cmd := &cobra.Command{
@@ -97,6 +123,9 @@ func NewPullCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	o.Registry.AddFlags(cmd)
 	output.ExitOnErr(o.Printer, o.Artifact.AddFlags(cmd))
 	cmd.Flags().StringVarP(&o.destDir, "dest-dir", "o", "", "destination dir where to save the artifacts(default: current directory)")
+	cmd.Flags().Var(o.output, "output",
+		"what to write: the layers of the artifact, or the whole artifact, with all its platforms and digests preserved, "+
+			"as an OCI image layout directory or tar archive "+o.output.Allowed())
 	return cmd
 }
 
@@ -128,6 +157,13 @@ func (o *pullOptions) RunPull(ctx context.Context, args []string) error {
 		logger.Info("Pulling artifact in", logger.Args("directory", o.destDir))
 	}
 
+	switch o.output.String() {
+	case outputLayout:
+		return o.pullLayout(ctx, puller, ref)
+	case outputTar:
+		return o.pullLayoutTar(ctx, puller, ref)
+	}
+
 	os, arch := runtime.GOOS, runtime.GOARCH
 	if len(o.Artifact.Platforms) > 0 {
 		os, arch = o.OSArch(0)
@@ -142,3 +178,46 @@ func (o *pullOptions) RunPull(ctx context.Context, args []string) error {
 
 	return nil
 }
+
+// pullLayout copies the artifact to the OCI image layout in the destination directory.
+func (o *pullOptions) pullLayout(ctx context.Context, puller *ocipuller.Puller, ref string) error {
+	logger := o.Printer.Logger
+	dir := o.destDir
+	if dir == "" {
+		dir = "."
+	}
+
+	res, err := puller.PullLayout(ctx, ref, dir)
+	if err != nil {
+		return err
+	}
+	logger.Info("Artifact pulled as OCI layout", logger.Args("name", ref, "type", res.Type, "digest", res.RootDigest, "directory", dir))
+	return nil
+}
+
+// pullLayoutTar copies the artifact to an OCI image layout tar archive in the destination directory,
+// named after the artifact and its tag.
+func (o *pullOptions) pullLayoutTar(ctx context.Context, puller *ocipuller.Puller, ref string) error {
+	logger := o.Printer.Logger
+
+	name, err := utils.NameFromRef(ref)
+	if err != nil {
+		return err
+	}
+	parsed, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	tag := parsed.Reference
+	if tag == "" {
+		tag = oci.DefaultTag
+	}
+	path := filepath.Join(o.destDir, name+"-"+strings.ReplaceAll(tag, ":", "-")+".tar")
+
+	res, err := puller.PullLayoutTar(ctx, ref, path)
+	if err != nil {
+		return err
+	}
+	logger.Info("Artifact pulled as OCI layout archive", logger.Args("name", ref, "type", res.Type, "digest", res.RootDigest, "file", path))
+	return nil
+}
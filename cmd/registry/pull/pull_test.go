@@ -41,6 +41,7 @@ var registryPullUsage = `Usage:
 Flags:
   -o, --dest-dir string        destination dir where to save the artifacts(default: current directory)
   -h, --help                   help for pull
+      --output string          what to write: the layers of the artifact, or the whole artifact, with all its platforms and digests preserved, as an OCI image layout directory or tar archive (files, oci-layout, tar) (default "files")
       --plain-http             allows interacting with remote registry via plain http requests
       --platform stringArray   os and architecture of the artifact in OS/ARCH format (only for plugins artifacts)
 
@@ -70,6 +71,28 @@ Example - Pull artifact "myplugin" for platform "linux/arm64" in "myDir" directo
 
 Example - Pull artifact "myrulesfile":
 	falcoctl registry pull localhost:5000/myrulesfile:latest
+
+Example - Pull artifact "myplugin", with all its platforms, as an OCI image layout in "myLayout" directory:
+	falcoctl registry pull localhost:5000/myplugin:latest --output oci-layout --dest-dir=./myLayout
+
+Example - Pull artifact "myplugin" as an OCI image layout tar archive, to move it to a disconnected network:
+	falcoctl registry pull localhost:5000/myplugin:latest --output tar
+
+Usage:
+  falcoctl registry pull hostname/repo[:tag|@digest] [flags]
+
+Flags:
+  -o, --dest-dir string        destination dir where to save the artifacts(default: current directory)
+  -h, --help                   help for pull
+      --output string          what to write: the layers of the artifact, or the whole artifact, with all its platforms and digests preserved, as an OCI image layout directory or tar archive (files, oci-layout, tar) (default "files")
+      --plain-http             allows interacting with remote registry via plain http requests
+      --platform stringArray   os and architecture of the artifact in OS/ARCH format (only for plugins artifacts)
+
+Global Flags:
+      --config string         config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string     Set formatting for logs (color, text, json) (default "color")
+      --log-level string      Set level for logs (info, warn, debug, trace) (default "info")
+      --socks5-proxy string   SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
 //nolint:unused // false positive
@@ -241,6 +264,19 @@ var registryPullTests = Describe("pull", func() {
 			})
 		})
 
+		When("invalid output format", func() {
+			BeforeEach(func() {
+				configDir := GinkgoT().TempDir()
+				configFile := filepath.Join(configDir, ".config")
+				_, err := os.Create(configFile)
+				Expect(err).To(BeNil())
+				args = []string{registryCmd, pullCmd, registry + repoAndTag, "--plain-http",
+					"--output", "./myDir", "--config", configFile}
+			})
+			pullAssertFailedBehavior(registryPullUsage,
+				`ERROR invalid argument "./myDir" for "--output" flag: invalid argument "./myDir", please provide one of (files, oci-layout, tar)`)
+		})
+
 		When("wrong digest format", func() {
 			wrongDigest := "sha256:06f961b802bc46ee168555f066d28f4f0e9afdf3f88174c1ee6f9de004fc30a0"
 			BeforeEach(func() {
@@ -27,12 +27,14 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/falcosecurity/falcoctl/internal/signature"
 	"github.com/falcosecurity/falcoctl/internal/utils"
 	"github.com/falcosecurity/falcoctl/pkg/oci"
 	ocipusher "github.com/falcosecurity/falcoctl/pkg/oci/pusher"
 	ociutils "github.com/falcosecurity/falcoctl/pkg/oci/utils"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/rulesfile"
 )
 
 const (
@@ -71,6 +73,16 @@ Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" with multiple d
         falcoctl registry push --type rulesfile --version "0.1.2" localhost:5000/myrulesfile:latest myrulesfile.tar.gz \
 		--depends-on myplugin:1.2.3 \
 		--depends-on otherplugin:3.2.1
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" and sign it with cosign, keyless (e.g. in a CI workflow):
+	falcoctl registry push --type rulesfile --version "0.1.2" localhost:5000/myrulesfile:latest myrulesfile.tar.gz --sign
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" and sign it with a cosign key (its password read from COSIGN_PASSWORD):
+	falcoctl registry push --type rulesfile --version "0.1.2" localhost:5000/myrulesfile:latest myrulesfile.tar.gz \
+	        --sign --sign-key cosign.key
+
+Example - Push the artifact of the OCI image layout archive "myplugin-latest.tar", as pulled with "registry pull --output tar":
+	falcoctl registry push --from-layout myplugin-latest.tar registry.internal:5000/myplugin
 `
 )
 
@@ -78,9 +90,21 @@ type pushOptions struct {
 	*options.Common
 	*options.Artifact
 	*options.Registry
+	sign              bool
+	signKey           string
+	signIdentityToken string
+	signTlogUpload    bool
+	skipRulesCheck    bool
+	fromLayout        string
 }
 
 func (o *pushOptions) validate() error {
+	if !o.sign && (o.signKey != "" || o.signIdentityToken != "") {
+		return fmt.Errorf("--sign-key and --sign-identity-token require --sign")
+	}
+	if o.sign && o.signKey == "" && !o.signTlogUpload {
+		return fmt.Errorf("keyless signing requires --sign-tlog-upload, or sign with a key through --sign-key")
+	}
 	return o.Artifact.Validate()
 }
 
@@ -97,11 +121,21 @@ func NewPushCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 		DisableFlagsInUseLine: true,
 		Short:                 "Push a Falco OCI artifact to remote registry",
 		Long:                  longPush,
-		Args:                  cobra.MinimumNArgs(2),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if o.fromLayout != "" {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.validate(); err != nil {
 				return err
 			}
+			if o.fromLayout != "" {
+				if err := o.validateFromLayout(cmd); err != nil {
+					return err
+				}
+			}
 
 			ref := args[0]
 
@@ -113,11 +147,27 @@ func NewPushCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if o.fromLayout != "" {
+				return o.runPushLayout(ctx, args[0])
+			}
 			return o.runPush(ctx, args)
 		},
 	}
 	o.Registry.AddFlags(cmd)
 	output.ExitOnErr(o.Printer, o.Artifact.AddFlags(cmd))
+	cmd.Flags().BoolVar(&o.sign, "sign", false,
+		"sign the pushed artifact with cosign, attaching the signature to the registry")
+	cmd.Flags().StringVar(&o.signKey, "sign-key", "",
+		"private key file or KMS URI (e.g. awskms:///alias/falco) used by --sign. If not set, the artifact is signed keyless")
+	cmd.Flags().StringVar(&o.signIdentityToken, "sign-identity-token", "",
+		"OIDC identity token used by --sign for keyless signing, instead of the ambient credentials")
+	cmd.Flags().BoolVar(&o.signTlogUpload, "sign-tlog-upload", true,
+		"whether --sign uploads the signature to the Rekor transparency log")
+	cmd.Flags().BoolVar(&o.skipRulesCheck, "skip-rules-validation", false,
+		"do not statically check the rules files of a rulesfile artifact before pushing it, as done by \"artifact rules validate\"")
+	cmd.Flags().StringVar(&o.fromLayout, "from-layout", "",
+		"push, with its digests preserved, the artifact of this OCI image layout directory or tar archive, as written by "+
+			"\"registry pull --output\", instead of building it from files")
 
 	return cmd
 }
@@ -163,6 +213,9 @@ func (o *pushOptions) runPush(ctx context.Context, args []string) error {
 		Version: o.Version,
 	}
 
+	// The files given by the user, before they are archived.
+	sources := append([]string(nil), paths...)
+
 	for i, p := range paths {
 		if err = utils.IsTarGz(filepath.Clean(p)); err != nil && !errors.Is(err, utils.ErrNotTarGz) {
 			return err
@@ -183,6 +236,14 @@ func (o *pushOptions) runPush(ctx context.Context, args []string) error {
 		}
 	}
 
+	if o.ArtifactType == oci.Rulesfile && !o.skipRulesCheck {
+		for _, src := range sources {
+			if err := o.validateRules(ctx, src); err != nil {
+				return err
+			}
+		}
+	}
+
 	if config.Name == "" {
 		// extract artifact name from ref, if not provided by the user
 		if config.Name, err = utils.NameFromRef(ref); err != nil {
@@ -226,6 +287,105 @@ func (o *pushOptions) runPush(ctx context.Context, args []string) error {
 
 	logger.Info("Artifact pushed", logger.Args("name", args[0], "type", res.Type, "digest", res.RootDigest))
 
+	if o.sign {
+		return o.signPushed(ctx, ref, res.RootDigest)
+	}
+
+	return nil
+}
+
+// layoutFlags are the flags that build the artifact from files, meaningless with --from-layout.
+var layoutFlags = []string{"type", "version", "platform", "requires", "depends-on", "annotation-source", "name", "add-floating-tags"}
+
+// validateFromLayout checks the flags used with --from-layout, which has no use for the flags describing the artifact.
+func (o *pushOptions) validateFromLayout(cmd *cobra.Command) error {
+	for _, name := range layoutFlags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			continue
+		}
+		if f.Changed {
+			return fmt.Errorf("--%s cannot be used with --from-layout", name)
+		}
+		// The artifact is already built, the flags required to build it are not.
+		delete(f.Annotations, cobra.BashCompOneRequiredFlag)
+	}
+	return nil
+}
+
+// runPushLayout pushes to ref the artifact of the OCI image layout passed with --from-layout.
+func (o *pushOptions) runPushLayout(ctx context.Context, ref string) error {
+	logger := o.Printer.Logger
+
+	registry, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+	pusher, err := ociutils.Pusher(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return fmt.Errorf("an error occurred while creating the pusher for registry %s: %w", registry, err)
+	}
+	if err = ociutils.CheckConnectionForRegistry(ctx, pusher.Client, o.PlainHTTP, registry); err != nil {
+		return err
+	}
+
+	logger.Info("Preparing to push artifact from OCI layout", logger.Args("name", ref, "layout", o.fromLayout))
+	res, err := pusher.PushLayout(ctx, ref, o.fromLayout, o.Tags...)
+	if err != nil {
+		return err
+	}
+	logger.Info("Artifact pushed", logger.Args("name", ref, "digest", res.RootDigest))
+
+	if o.sign {
+		return o.signPushed(ctx, ref, res.RootDigest)
+	}
+	return nil
+}
+
+// signPushed signs the digest of the artifact pushed to ref with cosign.
+func (o *pushOptions) signPushed(ctx context.Context, ref, rootDigest string) error {
+	logger := o.Printer.Logger
+	repo, err := utils.RepositoryFromRef(ref)
+	if err != nil {
+		return err
+	}
+	// Sign the pushed digest, the tags could be moved in the meantime.
+	digestRef := fmt.Sprintf("%s@%s", repo, rootDigest)
+	// Upload the signature with the credentials used to push the artifact.
+	keychain, err := ociutils.Keychain(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Info("Signing artifact", logger.Args("digest", digestRef))
+	if err := signature.Sign(ctx, digestRef, signature.SignOptions{
+		KeyRef:        o.signKey,
+		IdentityToken: o.signIdentityToken,
+		TlogUpload:    o.signTlogUpload,
+		PlainHTTP:     o.PlainHTTP,
+		Keychain:      keychain,
+	}); err != nil {
+		return fmt.Errorf("artifact pushed, but unable to sign %s: %w", digestRef, err)
+	}
+	logger.Info("Artifact signed", logger.Args("digest", digestRef))
+	return nil
+}
+
+// validateRules statically checks the rules files at path, logging the issues found.
+func (o *pushOptions) validateRules(ctx context.Context, path string) error {
+	logger := o.Printer.Logger
+
+	files, err := rulesfile.Load(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	issues := rulesfile.Validate(files, rulesfile.Options{})
+	for _, issue := range issues {
+		logger.Error("Invalid rules", logger.Args("issue", issue.String()))
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d issues found in the rules files of %q, fix them or push with --skip-rules-validation", len(issues), path)
+	}
 	return nil
 }
 
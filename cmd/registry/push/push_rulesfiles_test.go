@@ -28,6 +28,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
 
 	"github.com/falcosecurity/falcoctl/cmd"
 	"github.com/falcosecurity/falcoctl/internal/utils"
@@ -396,6 +397,31 @@ var _ = Describe("pushing rulesfiles", func() {
 			})
 		})
 
+		When("signed with a key", func() {
+			BeforeEach(func() {
+				GinkgoT().Setenv("COSIGN_PASSWORD", "password")
+				keys, err := cosign.GenerateKeyPair(func(bool) ([]byte, error) { return []byte("password"), nil })
+				Expect(err).ToNot(HaveOccurred())
+				tmpDir := GinkgoT().TempDir()
+				privateKey := filepath.Join(tmpDir, "cosign.key")
+				Expect(os.WriteFile(privateKey, keys.PrivateBytes, 0o600)).To(Succeed())
+
+				rulesfile = rulesfileyaml
+				args = []string{registryCmd, pushCmd, fullRepoName, rulesfile, "--config", configFile, "--type", "rulesfile", "--version", version,
+					"--plain-http", "--tag", pushedTags[0], "--sign", "--sign-key", privateKey, "--sign-tlog-upload=false"}
+			})
+
+			It("should attach the signature to the pushed digest", func() {
+				Expect(output).Should(gbytes.Say("Artifact signed"))
+				// Verifying the signature needs the sigstore TUF root, just check that cosign attached it.
+				repo, err := orasRegistry.Repository(ctx, repoName)
+				Expect(err).ToNot(HaveOccurred())
+				digest := rulesfileData.Descriptor.Digest
+				_, err = repo.Resolve(ctx, fmt.Sprintf("%s-%s.sig", digest.Algorithm(), digest.Encoded()))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
 		Context("rulesfile deps and requirements", func() {
 			When("user provided deps", func() {
 				BeforeEach(func() {
@@ -607,6 +633,19 @@ var _ = Describe("pushing rulesfiles", func() {
 	})
 
 	Context("failure", func() {
+		When("signing options without --sign", func() {
+			BeforeEach(func() {
+				repoName, fullRepoName = randomRulesRepoName(registry, rulesRepoBaseName)
+				args = []string{registryCmd, pushCmd, fullRepoName, rulesfileyaml, "--config", configFile, "--type", "rulesfile", "--version", version,
+					"--plain-http", "--sign-key", "cosign.key"}
+			})
+
+			It("should fail", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta("--sign-key and --sign-identity-token require --sign")))
+			})
+		})
+
 		When("requirement parsed from file -- invalid format (float)", func() {
 			var rulesFile = `
 - required_engine_version: 10.0
@@ -628,6 +667,27 @@ var _ = Describe("pushing rulesfiles", func() {
 			})
 		})
 
+		When("rules file is not valid", func() {
+			var rulesFile = `
+- rule: Incomplete
+  condition: proc.name = zsh
+`
+			BeforeEach(func() {
+				repoName, fullRepoName = randomRulesRepoName(registry, rulesRepoBaseName)
+				tmpDir := GinkgoT().TempDir()
+				rulesfile, err = testutils.WriteToTmpFile(rulesFile, tmpDir)
+				Expect(err).ToNot(HaveOccurred())
+				args = []string{registryCmd, pushCmd, fullRepoName, rulesfile, "--config", configFile, "--type", "rulesfile", "--version", version,
+					"--plain-http"}
+			})
+
+			It("should fail before pushing", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta(`rule "Incomplete" is missing the required fields [desc output priority]`)))
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta("fix them or push with --skip-rules-validation")))
+			})
+		})
+
 		When("requirement parsed from file -- invalid format (not semver)", func() {
 			var rulesFile = `
 - required_engine_version: 10.0notsemver
@@ -31,22 +31,29 @@ var registryPushUsage = `Usage:
   falcoctl registry push hostname/repo[:tag|@digest] file [flags]
 
 Flags:
-      --add-floating-tags          add the floating tags for the major and minor versions
-      --annotation-source string   set annotation source for the artifact
-  -d, --depends-on stringArray     set an artifact dependency (can be specified multiple times). Example: "--depends-on my-plugin:1.2.3"
-  -h, --help                       help for push
-      --name string                set the unique name of the artifact (if not set, the name is extracted from the reference)
-      --plain-http                 allows interacting with remote registry via plain http requests
-      --platform stringArray       os and architecture of the artifact in OS/ARCH format (only for plugins artifacts)
-  -r, --requires stringArray       set an artifact requirement (can be specified multiple times). Example: "--requires plugin_api_version:1.2.3"
-  -t, --tag stringArray            additional artifact tag. Can be repeated multiple times
-      --type ArtifactType          type of artifact to be pushed. Allowed values: "rulesfile", "plugin", "asset" (default )
-      --version string             set the version of the artifact
+      --add-floating-tags            add the floating tags for the major and minor versions
+      --annotation-source string     set annotation source for the artifact
+  -d, --depends-on stringArray       set an artifact dependency (can be specified multiple times). Example: "--depends-on my-plugin:1.2.3"
+      --from-layout string           push, with its digests preserved, the artifact of this OCI image layout directory or tar archive, as written by "registry pull --output", instead of building it from files
+  -h, --help                         help for push
+      --name string                  set the unique name of the artifact (if not set, the name is extracted from the reference)
+      --plain-http                   allows interacting with remote registry via plain http requests
+      --platform stringArray         os and architecture of the artifact in OS/ARCH format (only for plugins artifacts)
+  -r, --requires stringArray         set an artifact requirement (can be specified multiple times). Example: "--requires plugin_api_version:1.2.3"
+      --sign                         sign the pushed artifact with cosign, attaching the signature to the registry
+      --sign-identity-token string   OIDC identity token used by --sign for keyless signing, instead of the ambient credentials
+      --sign-key string              private key file or KMS URI (e.g. awskms:///alias/falco) used by --sign. If not set, the artifact is signed keyless
+      --sign-tlog-upload             whether --sign uploads the signature to the Rekor transparency log (default true)
+      --skip-rules-validation        do not statically check the rules files of a rulesfile artifact before pushing it, as done by "artifact rules validate"
+  -t, --tag stringArray              additional artifact tag. Can be repeated multiple times
+      --type ArtifactType            type of artifact to be pushed. Allowed values: "rulesfile", "plugin", "asset" (default )
+      --version string               set the version of the artifact
 
 Global Flags:
-      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string         config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string     Set formatting for logs (color, text, json) (default "color")
+      --log-level string      Set level for logs (info, warn, debug, trace) (default "info")
+      --socks5-proxy string   SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
 //nolint:lll,unused // no need to check for line length.
@@ -86,26 +93,43 @@ Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" with multiple d
 		--depends-on myplugin:1.2.3 \
 		--depends-on otherplugin:3.2.1
 
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" and sign it with cosign, keyless (e.g. in a CI workflow):
+	falcoctl registry push --type rulesfile --version "0.1.2" localhost:5000/myrulesfile:latest myrulesfile.tar.gz --sign
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" and sign it with a cosign key (its password read from COSIGN_PASSWORD):
+	falcoctl registry push --type rulesfile --version "0.1.2" localhost:5000/myrulesfile:latest myrulesfile.tar.gz \
+	        --sign --sign-key cosign.key
+
+Example - Push the artifact of the OCI image layout archive "myplugin-latest.tar", as pulled with "registry pull --output tar":
+	falcoctl registry push --from-layout myplugin-latest.tar registry.internal:5000/myplugin
+
 Usage:
   falcoctl registry push hostname/repo[:tag|@digest] file [flags]
 
 Flags:
-      --add-floating-tags          add the floating tags for the major and minor versions
-      --annotation-source string   set annotation source for the artifact
-  -d, --depends-on stringArray     set an artifact dependency (can be specified multiple times). Example: "--depends-on my-plugin:1.2.3"
-  -h, --help                       help for push
-      --name string                set the unique name of the artifact (if not set, the name is extracted from the reference)
-      --plain-http                 allows interacting with remote registry via plain http requests
-      --platform stringArray       os and architecture of the artifact in OS/ARCH format (only for plugins artifacts)
-  -r, --requires stringArray       set an artifact requirement (can be specified multiple times). Example: "--requires plugin_api_version:1.2.3"
-  -t, --tag stringArray            additional artifact tag. Can be repeated multiple times
-      --type ArtifactType          type of artifact to be pushed. Allowed values: "rulesfile", "plugin", "asset"
-      --version string             set the version of the artifact
+      --add-floating-tags            add the floating tags for the major and minor versions
+      --annotation-source string     set annotation source for the artifact
+  -d, --depends-on stringArray       set an artifact dependency (can be specified multiple times). Example: "--depends-on my-plugin:1.2.3"
+      --from-layout string           push, with its digests preserved, the artifact of this OCI image layout directory or tar archive, as written by "registry pull --output", instead of building it from files
+  -h, --help                         help for push
+      --name string                  set the unique name of the artifact (if not set, the name is extracted from the reference)
+      --plain-http                   allows interacting with remote registry via plain http requests
+      --platform stringArray         os and architecture of the artifact in OS/ARCH format (only for plugins artifacts)
+  -r, --requires stringArray         set an artifact requirement (can be specified multiple times). Example: "--requires plugin_api_version:1.2.3"
+      --sign                         sign the pushed artifact with cosign, attaching the signature to the registry
+      --sign-identity-token string   OIDC identity token used by --sign for keyless signing, instead of the ambient credentials
+      --sign-key string              private key file or KMS URI (e.g. awskms:///alias/falco) used by --sign. If not set, the artifact is signed keyless
+      --sign-tlog-upload             whether --sign uploads the signature to the Rekor transparency log (default true)
+      --skip-rules-validation        do not statically check the rules files of a rulesfile artifact before pushing it, as done by "artifact rules validate"
+  -t, --tag stringArray              additional artifact tag. Can be repeated multiple times
+      --type ArtifactType            type of artifact to be pushed. Allowed values: "rulesfile", "plugin", "asset"
+      --version string               set the version of the artifact
 
 Global Flags:
-      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
-      --log-format string   Set formatting for logs (color, text, json) (default "color")
-      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+      --config string         config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string     Set formatting for logs (color, text, json) (default "color")
+      --log-level string      Set level for logs (info, warn, debug, trace) (default "info")
+      --socks5-proxy string   SOCKS5 proxy, in the "[user:password@]host:port" format, used to reach registries and indexes (defaults to ALL_PROXY)
 `
 
 var pushAssertFailedBehavior = func(usage, specificError string) {
@@ -172,6 +196,13 @@ var _ = Describe("push", func() {
 			pushAssertFailedBehavior(registryPushUsage, "ERROR requires at least 2 arg(s), only received 1")
 		})
 
+		When("--from-layout with --type", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, pushCmd, "--config", configFile, rulesRepo, "--from-layout", rulesfiletgz, "--type", "rulesfile"}
+			})
+			pushAssertFailedBehavior(registryPushUsage, "ERROR --type cannot be used with --from-layout")
+		})
+
 		When("without registry", func() {
 			BeforeEach(func() {
 				args = []string{registryCmd, pushCmd, "--config", configFile, rulesfiletgz, "--type", "rulesfile"}
@@ -29,6 +29,8 @@ import (
 
 // NewRegistryCmd returns the registry command.
 func NewRegistryCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Command {
+	var proxy commonoptions.Proxy
+
 	cmd := &cobra.Command{
 		Use:                   "registry",
 		DisableFlagsInUseLine: true,
@@ -38,10 +40,19 @@ func NewRegistryCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Comma
 			// Initialize the options.
 			opt.Initialize()
 			// Load configuration from ENV variables and/or config file.
-			return config.Load(opt.ConfigFile)
+			if err := config.Load(opt.ConfigFile); err != nil {
+				return err
+			}
+			// Set up the proxy and the TLS settings used to reach the registries.
+			if err := proxy.Configure(cmd); err != nil {
+				return err
+			}
+			return commonoptions.ConfigureTLS()
 		},
 	}
 
+	proxy.AddFlags(cmd)
+
 	cmd.AddCommand(auth.NewAuthCmd(ctx, opt))
 	cmd.AddCommand(push.NewPushCmd(ctx, opt))
 	cmd.AddCommand(pull.NewPullCmd(ctx, opt))
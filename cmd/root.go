@@ -21,9 +21,12 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/falcosecurity/falcoctl/cmd/artifact"
+	"github.com/falcosecurity/falcoctl/cmd/config"
 	"github.com/falcosecurity/falcoctl/cmd/driver"
 	"github.com/falcosecurity/falcoctl/cmd/index"
+	initconfig "github.com/falcosecurity/falcoctl/cmd/init"
 	"github.com/falcosecurity/falcoctl/cmd/registry"
+	"github.com/falcosecurity/falcoctl/cmd/state"
 	"github.com/falcosecurity/falcoctl/cmd/tls"
 	"github.com/falcosecurity/falcoctl/cmd/version"
 	"github.com/falcosecurity/falcoctl/pkg/options"
@@ -70,6 +73,9 @@ func New(ctx context.Context, opt *options.Common) *cobra.Command {
 	rootCmd.AddCommand(index.NewIndexCmd(ctx, opt))
 	rootCmd.AddCommand(artifact.NewArtifactCmd(ctx, opt))
 	rootCmd.AddCommand(driver.NewDriverCmd(ctx, opt))
+	rootCmd.AddCommand(initconfig.NewInitCmd(opt))
+	rootCmd.AddCommand(state.NewStateCmd(ctx, opt))
+	rootCmd.AddCommand(config.NewConfigCmd(ctx, opt))
 
 	return rootCmd
 }
@@ -44,10 +44,13 @@ Usage:
 Available Commands:
   artifact    Interact with Falco artifacts
   completion  Generate the autocompletion script for the specified shell
+  config      Interact with the falcoctl configuration
   driver      Interact with falcosecurity driver
   help        Help about any command
   index       Interact with index
+  init        Write a starting falcoctl configuration
   registry    Interact with OCI registries
+  state       Export and import the local falcoctl state
   tls         Generate and install TLS material for Falco
   version     Print the falcoctl version information
 
@@ -76,9 +79,12 @@ Usage:
 Available Commands:
   artifact    Interact with Falco artifacts
   completion  Generate the autocompletion script for the specified shell
+  config      Interact with the falcoctl configuration
   help        Help about any command
   index       Interact with index
+  init        Write a starting falcoctl configuration
   registry    Interact with OCI registries
+  state       Export and import the local falcoctl state
   tls         Generate and install TLS material for Falco
   version     Print the falcoctl version information
 
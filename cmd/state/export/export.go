@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stateexport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/internal/state"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+type stateExportOptions struct {
+	*options.Common
+	state.Options
+}
+
+// NewStateExportCmd returns the state export command.
+func NewStateExportCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := stateExportOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "export BUNDLE [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Export the local falcoctl state to a bundle",
+		Long: `Export the local falcoctl state to a tar.gz bundle: the config file, the indexes cache and the install manifest.
+Credential stores are excluded, and the secrets in the config file are blanked, unless --include-secrets is set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunStateExport(ctx, args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.IncludeBlobs, "include-blobs", false, "Also export the files of the installed artifacts")
+	cmd.Flags().BoolVar(&o.IncludeSecrets, "include-secrets", false, "Also export the credential stores and the secrets in the config file")
+
+	return cmd
+}
+
+// RunStateExport implements the state export command.
+func (o *stateExportOptions) RunStateExport(ctx context.Context, bundle string) (err error) {
+	if o.IncludeSecrets {
+		o.Printer.Logger.Warn("The bundle will contain secrets, make sure to store it safely")
+	}
+
+	f, err := os.OpenFile(filepath.Clean(bundle), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to create bundle %q: %w", bundle, err)
+	}
+	defer func() {
+		if errClose := f.Close(); err == nil {
+			err = errClose
+		}
+	}()
+
+	meta, err := state.Export(ctx, f, state.DefaultPaths(o.ConfigFile), o.Options)
+	if err != nil {
+		return fmt.Errorf("unable to export state: %w", err)
+	}
+
+	for _, e := range meta.Entries {
+		o.Printer.Logger.Debug("Exported", o.Printer.Logger.Args("entry", e.Name, "path", e.Path))
+	}
+	o.Printer.Logger.Info("State exported", o.Printer.Logger.Args("bundle", bundle, "entries", len(meta.Entries)))
+	return nil
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stateexport_test
+
+import (
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+)
+
+//nolint:lll // no need to check for line length.
+var stateExportHelp = `Export the local falcoctl state to a tar.gz bundle: the config file, the indexes cache and the install manifest.
+Credential stores are excluded, and the secrets in the config file are blanked, unless --include-secrets is set.
+
+Usage:
+  falcoctl state export BUNDLE [flags]
+
+Flags:
+  -h, --help              help for export
+      --include-blobs     Also export the files of the installed artifacts
+      --include-secrets   Also export the credential stores and the secrets in the config file
+
+Global Flags:
+      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string   Set formatting for logs (color, text, json) (default "color")
+      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+`
+
+var _ = Describe("export", func() {
+
+	var (
+		stateCmd  = "state"
+		exportCmd = "export"
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("help message", func() {
+		BeforeEach(func() {
+			args = []string{stateCmd, exportCmd, "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(stateExportHelp)))
+		})
+	})
+
+	Context("export", func() {
+		var bundle string
+
+		BeforeEach(func() {
+			bundle = filepath.Join(GinkgoT().TempDir(), "bundle.tgz")
+			args = []string{stateCmd, exportCmd, bundle, "--config", configFile}
+		})
+
+		It("should write the bundle", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(bundle).Should(BeARegularFile())
+			Expect(output).Should(gbytes.Say("State exported"))
+		})
+	})
+
+	Context("failure", func() {
+		When("without bundle", func() {
+			BeforeEach(func() {
+				args = []string{stateCmd, exportCmd, "--config", configFile}
+			})
+
+			It("check that fails", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta("ERROR accepts 1 arg(s), received 0")))
+			})
+		})
+	})
+})
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stateimport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/internal/state"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+type stateImportOptions struct {
+	*options.Common
+}
+
+// NewStateImportCmd returns the state import command.
+func NewStateImportCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := stateImportOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "import BUNDLE [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Import the falcoctl state from a bundle",
+		Long: `Import the falcoctl state from a bundle created by "falcoctl state export", overwriting the local one.
+The config file is restored to the one set by --config.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunStateImport(ctx, args[0])
+		},
+	}
+
+	return cmd
+}
+
+// RunStateImport implements the state import command.
+func (o *stateImportOptions) RunStateImport(ctx context.Context, bundle string) error {
+	f, err := os.Open(filepath.Clean(bundle))
+	if err != nil {
+		return fmt.Errorf("unable to open bundle %q: %w", bundle, err)
+	}
+	defer f.Close()
+
+	meta, err := state.Import(ctx, f, state.DefaultPaths(o.ConfigFile))
+	if err != nil {
+		return fmt.Errorf("unable to import state: %w", err)
+	}
+
+	o.Printer.Logger.Info("State imported", o.Printer.Logger.Args("bundle", bundle, "entries", len(meta.Entries),
+		"exported at", meta.CreatedAt))
+	return nil
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stateimport_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/falcosecurity/falcoctl/cmd"
+)
+
+//nolint:lll // no need to check for line length.
+var stateImportHelp = `Import the falcoctl state from a bundle created by "falcoctl state export", overwriting the local one.
+The config file is restored to the one set by --config.
+
+Usage:
+  falcoctl state import BUNDLE [flags]
+
+Flags:
+  -h, --help   help for import
+
+Global Flags:
+      --config string       config file to be used for falcoctl (default "/etc/falcoctl/falcoctl.yaml")
+      --log-format string   Set formatting for logs (color, text, json) (default "color")
+      --log-level string    Set level for logs (info, warn, debug, trace) (default "info")
+`
+
+var _ = Describe("import", func() {
+
+	var (
+		stateCmd  = "state"
+		importCmd = "import"
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("help message", func() {
+		BeforeEach(func() {
+			args = []string{stateCmd, importCmd, "--help"}
+		})
+
+		It("should match the saved one", func() {
+			Expect(output).Should(gbytes.Say(regexp.QuoteMeta(stateImportHelp)))
+		})
+	})
+
+	Context("failure", func() {
+		When("with a missing bundle", func() {
+			BeforeEach(func() {
+				args = []string{stateCmd, importCmd, "missing.tgz", "--config", configFile}
+			})
+
+			It("check that fails", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta(`ERROR unable to open bundle "missing.tgz"`)))
+			})
+		})
+
+		When("with an invalid bundle", func() {
+			BeforeEach(func() {
+				bundle := filepath.Join(GinkgoT().TempDir(), "bundle.tgz")
+				Expect(os.WriteFile(bundle, []byte("not a bundle"), 0o600)).Should(Succeed())
+				args = []string{stateCmd, importCmd, bundle, "--config", configFile}
+			})
+
+			It("check that fails", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(output).Should(gbytes.Say(regexp.QuoteMeta("ERROR unable to import state: unsupported state bundle")))
+			})
+		})
+	})
+})
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	stateexport "github.com/falcosecurity/falcoctl/cmd/state/export"
+	stateimport "github.com/falcosecurity/falcoctl/cmd/state/import"
+	"github.com/falcosecurity/falcoctl/internal/config"
+	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+// NewStateCmd returns the state command.
+func NewStateCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "state",
+		DisableFlagsInUseLine: true,
+		Short:                 "Export and import the local falcoctl state",
+		Long:                  "Export and import the local falcoctl state, i.e. the config file, the indexes cache and the installed artifacts",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			opt.Initialize()
+			return config.Load(opt.ConfigFile)
+		},
+	}
+
+	cmd.AddCommand(stateexport.NewStateExportCmd(ctx, opt))
+	cmd.AddCommand(stateimport.NewStateImportCmd(ctx, opt))
+
+	return cmd
+}
@@ -53,6 +53,11 @@ This command is a convenience to not only generate the TLS material, but also dr
 	cmd.Flags().StringVarP(&options.Name, "name", "n", defaultCertsName, "The name to self sign the TLS cert with")
 	cmd.Flags().IntVarP(&options.Days, "days", "d", defaultCertsDays, "The number of days to make self signed TLS cert valid for")
 	cmd.Flags().StringVarP(&options.Path, "path", "p", defaultCertsPath, "The path to write the TLS cert to")
+	_ = cmd.Flags().MarkDeprecated("path", "please use --output-dir")
+	cmd.Flags().StringVar(&options.Path, "output-dir", defaultCertsPath,
+		"The directory to write the TLS material to, created if missing (defaults to the current directory)")
+	cmd.MarkFlagsMutuallyExclusive("path", "output-dir")
+	cmd.Flags().StringVar(&options.Group, "group", "", "The group (name or id) owning the private keys, allowed to read them")
 	cmd.Flags().IntVarP(&options.RSABits, "rsa-size", "s", tls.DefaultRSABits, "The bit size of the RSA key to generate")
 	cmd.Flags().StringSliceVar(&options.DNSSANs, "alternate-names", nil, "A list of comma-separated subject alternate names as valid DNS domain names")
 	cmd.Flags().StringSliceVar(&options.IPSANs, "alternate-addresses", nil, "A list of comma-separated subject alternate names as valid IP addresses")
@@ -16,22 +16,14 @@
 package version
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 	"runtime"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 
 	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
 )
 
-const (
-	yamlFormat = "yaml"
-	jsonFormat = "json"
-)
-
 var (
 	// Semantic version that refers to ghe version (git tag) of Falcoctl that is released.
 	// For prerelease versions, the build metadata on the
@@ -50,18 +42,16 @@ var (
 
 type options struct {
 	*commonoptions.Common
-	Output string
+	*commonoptions.Output
 }
 
-var errOutputFlag = errors.New("--output must be 'yaml' or 'json'")
-
 type version struct {
-	SemVersion string `json:"semVersion"`
-	GitCommit  string `json:"gitCommit"`
-	BuildDate  string `json:"buildDate"`
-	GoVersion  string `json:"goVersion"`
-	Compiler   string `json:"compiler"`
-	Platform   string `json:"platform"`
+	SemVersion string `json:"semVersion" yaml:"semVersion"`
+	GitCommit  string `json:"gitCommit" yaml:"gitCommit"`
+	BuildDate  string `json:"buildDate" yaml:"buildDate"`
+	GoVersion  string `json:"goVersion" yaml:"goVersion"`
+	Compiler   string `json:"compiler" yaml:"compiler"`
+	Platform   string `json:"platform" yaml:"platform"`
 }
 
 func newVersion() version {
@@ -81,6 +71,7 @@ func newVersion() version {
 func NewVersionCmd(opt *commonoptions.Common) *cobra.Command {
 	o := options{
 		Common: opt,
+		Output: &commonoptions.Output{},
 	}
 
 	v := newVersion()
@@ -96,40 +87,21 @@ func NewVersionCmd(opt *commonoptions.Common) *cobra.Command {
 			return o.Run(&v)
 		},
 	}
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "One of 'yaml' or 'json'")
+	o.Output.AddFlags(cmd)
 
 	return cmd
 }
 
 func (o *options) validate() error {
-	if o.Output != "" && o.Output != yamlFormat && o.Output != jsonFormat {
-		return errOutputFlag
-	}
-
-	return nil
+	return o.Output.Validate()
 }
 
 // Run executes the business logic for the version command.
 func (o *options) Run(v *version) error {
-	switch o.Output {
-	case "":
-		o.Printer.DefaultText.Printf("Client Version: %s\n", v.SemVersion)
-	case yamlFormat:
-		marshaled, err := yaml.Marshal(v)
-		if err != nil {
-			return err
-		}
-		o.Printer.DefaultText.Printf("%s:\n%s\n", "Client Version", string(marshaled))
-	case jsonFormat:
-		marshaled, err := json.MarshalIndent(v, "", "   ")
-		if err != nil {
-			return err
-		}
-		o.Printer.DefaultText.Printf("%s:\n%s \n", "Client Version", string(marshaled))
-	default:
-		// We should never hit this case.
-		return fmt.Errorf("options of the version command were not validated: --output=%q should have been rejected", o.Output)
+	if o.Structured() {
+		return o.Printer.PrintStructured(o.Format, v)
 	}
 
+	o.Printer.DefaultText.Printf("Client Version: %s\n", v.SemVersion)
 	return nil
 }
@@ -26,6 +26,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
 )
 
 var _ = Describe("Version", func() {
@@ -49,7 +50,7 @@ var _ = Describe("Version", func() {
 		cfg.Initialize(commonoptions.WithWriter(writer))
 		opt = &options{
 			Common: cfg,
-			Output: outputFmt,
+			Output: &commonoptions.Output{Format: outputFmt},
 		}
 	})
 
@@ -99,10 +100,9 @@ var _ = Describe("Version", func() {
 					outputYaml = string(marshaled)
 				})
 
-				It("should print the version in yaml", func() {
+				It("should print only the version in yaml", func() {
 					Expect(opt.Run(version)).Error().ShouldNot(HaveOccurred())
-					Expect(writer).Should(gbytes.Say("Client Version:"))
-					Expect(writer).Should(gbytes.Say(outputYaml))
+					Expect(string(writer.Contents())).Should(Equal(outputYaml))
 				})
 			})
 		})
@@ -123,15 +123,14 @@ var _ = Describe("Version", func() {
 
 			Context("run method", func() {
 				BeforeEach(func() {
-					marshaled, err := json.MarshalIndent(version, "", "   ")
+					marshaled, err := json.MarshalIndent(version, "", "  ")
 					Expect(err).ShouldNot(HaveOccurred())
-					outputJSON = string(marshaled)
+					outputJSON = string(marshaled) + "\n"
 				})
 
-				It("should print the version in yaml", func() {
+				It("should print only the version in json", func() {
 					Expect(opt.Run(version)).Error().ShouldNot(HaveOccurred())
-					Expect(writer).Should(gbytes.Say("Client Version:"))
-					Expect(writer).Should(gbytes.Say(outputJSON))
+					Expect(string(writer.Contents())).Should(Equal(outputJSON))
 				})
 			})
 		})
@@ -143,7 +142,7 @@ var _ = Describe("Version", func() {
 
 			Context("validate method", func() {
 				It("should error", func() {
-					Expect(opt.validate()).Error().Should(Equal(errOutputFlag))
+					Expect(opt.validate()).Error().Should(Equal(output.ErrInvalidFormat))
 				})
 			})
 
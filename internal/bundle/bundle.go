@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
+)
+
+const (
+	// ManifestFile is the file, at the root of a bundle, describing its content.
+	ManifestFile = "bundle.yaml"
+	// Version is the version of the bundle format.
+	Version = 1
+
+	// layoutsDir is the directory of the bundle holding the OCI image layouts of the artifacts.
+	layoutsDir = "artifacts"
+)
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	Version int `yaml:"version"`
+	// Artifacts are the artifacts of the bundle, dependencies included, in install order.
+	Artifacts []Artifact `yaml:"artifacts"`
+}
+
+// Artifact is an artifact of a bundle.
+type Artifact struct {
+	// Ref is the reference the artifact has been resolved to when creating the bundle.
+	Ref string `yaml:"ref"`
+	// Digest is the digest Ref resolved to.
+	Digest string `yaml:"digest"`
+	// Type is the artifact type.
+	Type string `yaml:"type,omitempty"`
+	// Layout is the directory of the bundle holding the OCI image layout of the artifact, where it is
+	// found by Digest.
+	Layout string `yaml:"layout"`
+	// Signature is the tag, in the layout, of the cosign signature of the artifact, if it has one.
+	Signature string `yaml:"signature,omitempty"`
+	// Verified tells whether the signature of the artifact has been verified when creating the bundle.
+	Verified bool `yaml:"verified"`
+	// Entry is the index entry the artifact has been resolved from, if any.
+	Entry *index.Entry `yaml:"entry,omitempty"`
+}
+
+// Bundle is a bundle extracted, or being created, in a temporary directory.
+type Bundle struct {
+	Manifest
+	// Dir is the directory holding the content of the bundle.
+	Dir string
+}
+
+// New returns an empty bundle.
+func New() (*Bundle, error) {
+	dir, err := os.MkdirTemp("", "falcoctl-bundle-")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	return &Bundle{Manifest: Manifest{Version: Version}, Dir: dir}, nil
+}
+
+// Open extracts the bundle at path.
+func Open(ctx context.Context, path string) (*Bundle, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bundle %q: %w", path, err)
+	}
+	defer f.Close()
+
+	b, err := New()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.read(ctx, f); err != nil {
+		_ = b.Close()
+		return nil, fmt.Errorf("invalid bundle %q: %w", path, err)
+	}
+	return b, nil
+}
+
+// read extracts the bundle from r and loads its manifest.
+func (b *Bundle) read(ctx context.Context, r io.Reader) error {
+	if _, err := utils.ExtractTarGz(ctx, r, b.Dir, 0); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(b.Dir, ManifestFile))
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, &b.Manifest); err != nil {
+		return err
+	}
+	if b.Version != Version {
+		return fmt.Errorf("unsupported version %d", b.Version)
+	}
+	for _, a := range b.Artifacts {
+		if a.Ref == "" || a.Digest == "" || !filepath.IsLocal(a.Layout) {
+			return fmt.Errorf("each artifact needs a ref, a digest and a layout in the bundle")
+		}
+	}
+	return nil
+}
+
+// LayoutPath returns the layout of the i-th artifact of a bundle.
+func LayoutPath(i int) string {
+	return path.Join(layoutsDir, strconv.Itoa(i))
+}
+
+// LayoutDir returns the directory holding the OCI image layout of a.
+func (b *Bundle) LayoutDir(a *Artifact) string {
+	return filepath.Join(b.Dir, filepath.FromSlash(a.Layout))
+}
+
+// Write writes the bundle, with its manifest, to path.
+func (b *Bundle) Write(path string) error {
+	data, err := yaml.Marshal(&b.Manifest)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(b.Dir, ManifestFile), data, 0o600); err != nil {
+		return err
+	}
+
+	out, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("unable to write bundle %q: %w", path, err)
+	}
+	gzw := gzip.NewWriter(out)
+	err = utils.TarDirectory(gzw, b.Dir)
+	if cerr := gzw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write bundle %q: %w", path, err)
+	}
+	return nil
+}
+
+// Close removes the temporary directory of the bundle.
+func (b *Bundle) Close() error {
+	return os.RemoveAll(b.Dir)
+}
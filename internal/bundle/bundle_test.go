@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
+)
+
+func TestWriteOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+
+	b, err := New()
+	require.NoError(t, err)
+	defer b.Close()
+	a := Artifact{
+		Ref:       "ghcr.io/falcosecurity/rules/falco-rules:3",
+		Digest:    "sha256:aaaa",
+		Type:      "rulesfile",
+		Layout:    LayoutPath(0),
+		Signature: "sha256-aaaa.sig",
+		Verified:  true,
+		Entry: &index.Entry{
+			Name:        "falco-rules",
+			Type:        "rulesfile",
+			Registry:    "ghcr.io",
+			Repository:  "falcosecurity/rules/falco-rules",
+			Keywords:    []string{"falco"},
+			Sources:     []string{"https://github.com/falcosecurity/rules"},
+			Maintainers: index.Maintainer{{Email: "cncf-falco-dev@lists.cncf.io", Name: "The Falco Authors"}},
+		},
+	}
+	require.NoError(t, os.MkdirAll(b.LayoutDir(&a), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(b.LayoutDir(&a), "index.json"), []byte("{}"), 0o600))
+	b.Artifacts = append(b.Artifacts, a)
+	require.NoError(t, b.Write(path))
+
+	opened, err := Open(context.Background(), path)
+	require.NoError(t, err)
+	defer opened.Close()
+	assert.Equal(t, b.Manifest, opened.Manifest)
+	data, err := os.ReadFile(filepath.Join(opened.LayoutDir(&opened.Artifacts[0]), "index.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+}
+
+func TestOpenInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Open(context.Background(), filepath.Join(dir, "missing.tar.gz"))
+	assert.Error(t, err)
+
+	write := func(name string, m Manifest) string {
+		b, err := New()
+		require.NoError(t, err)
+		defer b.Close()
+		b.Manifest = m
+		path := filepath.Join(dir, name)
+		require.NoError(t, b.Write(path))
+		return path
+	}
+
+	_, err = Open(context.Background(), write("version.tar.gz", Manifest{Version: Version + 1}))
+	assert.ErrorContains(t, err, "unsupported version")
+
+	_, err = Open(context.Background(), write("layout.tar.gz", Manifest{
+		Version:   Version,
+		Artifacts: []Artifact{{Ref: "ghcr.io/falcosecurity/rules/falco-rules:3", Digest: "sha256:aaaa", Layout: "../outside"}},
+	}))
+	assert.ErrorContains(t, err, "needs a ref, a digest and a layout")
+}
@@ -29,7 +29,9 @@ import (
 	"github.com/docker/docker/pkg/homedir"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 
+	"github.com/falcosecurity/falcoctl/internal/utils"
 	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
 	"github.com/falcosecurity/falcoctl/pkg/oci"
 )
@@ -45,6 +47,12 @@ var (
 	IndexesDir string
 	// ClientCredentialsFile name of the file where oauth client credentials are stored. It lives under FalcoctlPath.
 	ClientCredentialsFile string
+	// InstallManifestFile name of the file where the installed artifacts are recorded. It lives under FalcoctlPath.
+	InstallManifestFile string
+	// InstallJournalFile name of the write-ahead log of the artifact install in progress. It lives under FalcoctlPath.
+	InstallJournalFile string
+	// ArtifactHistoryDir is where the replaced versions of the artifacts are kept for rollback. It lives under FalcoctlPath.
+	ArtifactHistoryDir string
 	// DefaultIndex is the default index for the falcosecurity organization.
 	DefaultIndex Index
 	// DefaultRegistryCredentialConfPath is the default path for the credential store configuration file.
@@ -71,6 +79,8 @@ const (
 	RulesfilesDir = "/etc/falco"
 	// AssetsDir default path where assets are installed.
 	AssetsDir = "/etc/falco/assets"
+	// DriverBuildCacheDir default path where the drivers built by driver install are cached.
+	DriverBuildCacheDir = "/var/cache/falcoctl/drivers"
 	// FollowResync time interval how often it checks for newer version of the artifact.
 	// Default values is set every 24 hours.
 	FollowResync = time.Hour * 24
@@ -88,6 +98,15 @@ const (
 	RegistryAuthBasicKey = "registry.auth.basic"
 	// RegistryAuthGcpKey is the Viper key for gcp authentication configuration.
 	RegistryAuthGcpKey = "registry.auth.gcp"
+	// RegistryRewriteKey is the Viper key for the rewrite rules applied to the references resolved from the indexes.
+	RegistryRewriteKey = "registry.rewrite"
+	// RegistrySOCKS5ProxyKey is the Viper key for the SOCKS5 proxy used to reach registries and indexes.
+	RegistrySOCKS5ProxyKey = "registry.socks5Proxy"
+
+	// TLSCABundleKey is the Viper key for the CA bundle trusted, on top of the system one, by all the outgoing requests.
+	TLSCABundleKey = "tls.caBundle"
+	// TLSInsecureHostsKey is the Viper key for the hosts whose certificate is not verified.
+	TLSInsecureHostsKey = "tls.insecureHosts"
 
 	// IndexesKey is the Viper key for indexes configuration.
 	IndexesKey = "indexes"
@@ -108,6 +127,12 @@ const (
 	ArtifactFollowAssetsDirKey = "artifact.follow.assetsdir"
 	// ArtifactFollowTmpDirKey is the Viper key for follower "pluginsDir" configuration.
 	ArtifactFollowTmpDirKey = "artifact.follow.tmpdir"
+	// ArtifactFollowWebhooksKey is the Viper key for the webhooks notified by the follower.
+	ArtifactFollowWebhooksKey = "artifact.follow.webhooks"
+	// ArtifactFollowHooksKey is the Viper key for the hooks run after the follower installs an update.
+	ArtifactFollowHooksKey = "artifact.follow.hooks"
+	// ArtifactFollowMetricsAddressKey is the Viper key for the address where the follower serves its metrics.
+	ArtifactFollowMetricsAddressKey = "artifact.follow.metricsAddress"
 
 	// ArtifactInstallArtifactsKey is the Viper key for installer "artifacts" configuration.
 	ArtifactInstallArtifactsKey = "artifact.install.refs"
@@ -119,11 +144,30 @@ const (
 	ArtifactInstallAssetsDirKey = "artifact.install.assetsdir"
 	// ArtifactInstallResolveDepsKey is the Viper key for installer "resolveDeps" configuration.
 	ArtifactInstallResolveDepsKey = "artifact.install.resolveDeps"
+	// ArtifactInstallMergeStrategyKey is the Viper key for installer "mergeStrategy" configuration.
+	ArtifactInstallMergeStrategyKey = "artifact.install.mergeStrategy"
+	// ArtifactInstallMaxParallelKey is the Viper key for installer "maxParallel" configuration.
+	ArtifactInstallMaxParallelKey = "artifact.install.maxParallel"
+	// ArtifactInstallLockedKey is the Viper key for installer "locked" configuration.
+	ArtifactInstallLockedKey = "artifact.install.locked"
+	// ArtifactInstallLockFileKey is the Viper key for installer "lockFile" configuration.
+	ArtifactInstallLockFileKey = "artifact.install.lockFile"
+
+	// ArtifactHistoryKeepKey is the Viper key for the number of replaced versions kept per artifact.
+	ArtifactHistoryKeepKey = "artifact.history.keep"
+	// DefaultArtifactHistoryKeep is the number of replaced versions kept per artifact if not configured.
+	DefaultArtifactHistoryKeep = 3
 
 	// ArtifactAllowedTypesKey is the Viper key for the whitelist of artifacts to be installed in the system.
 	ArtifactAllowedTypesKey = "artifact.allowedTypes"
 	// ArtifactNoVerifyKey is the Viper key for skipping signature verification.
 	ArtifactNoVerifyKey = "artifact.noVerify"
+	// ArtifactVerifyKey is the Viper key for the signature verification required for all the artifacts.
+	ArtifactVerifyKey = "artifact.verify"
+	// ArtifactVerifyEnabledKey is the Viper key for requiring the signature verification of all the artifacts.
+	ArtifactVerifyEnabledKey = "artifact.verify.enabled"
+	// ArtifactVerifyModeKey is the Viper key for what to do when the signature verification fails.
+	ArtifactVerifyModeKey = "artifact.verify.mode"
 
 	// DriverKey is the Viper key for driver structure.
 	DriverKey = "driver"
@@ -133,18 +177,46 @@ const (
 	DriverVersionKey = "driver.version"
 	// DriverReposKey is the Viper key for the driver repositories.
 	DriverReposKey = "driver.repos"
+	// DriverPrependReposKey is the Viper key for the driver repositories to be tried before the ones in DriverReposKey.
+	DriverPrependReposKey = "driver.prependRepos"
 	// DriverNameKey is the Viper key for the driver name.
 	DriverNameKey = "driver.name"
 	// DriverHostRootKey is the Viper key for the driver host root.
-	DriverHostRootKey   = "driver.hostRoot"
-	falcoHostRootEnvKey = "HOST_ROOT"
+	DriverHostRootKey = "driver.hostRoot"
+	// DriverHistoryKey is the Viper key for the history of the driver configuration changes.
+	DriverHistoryKey = "driver.history"
+	// DriverHistoryLimitKey is the Viper key for the maximum number of entries kept in the driver history.
+	DriverHistoryLimitKey = "driver.historyLimit"
+	// DriverConfigNamespaceKey is the Viper key for the default namespace of the Falco deployment updated by driver config.
+	DriverConfigNamespaceKey = "driver.config.namespace"
+	// DriverConfigKubeConfigKey is the Viper key for the default kubeconfig used by driver config.
+	DriverConfigKubeConfigKey = "driver.config.kubeconfig"
+	// DriverMirrorsKey is the Viper key for the settings of the driver repos.
+	DriverMirrorsKey = "driver.mirrors"
+	// DriverInstallBuildCacheDirKey is the Viper key for the directory where driver install caches the built drivers.
+	DriverInstallBuildCacheDirKey = "driver.install.buildCacheDir"
+	// DriverInstallLocalDirKey is the Viper key for the directory where driver install looks up the prebuilt drivers.
+	DriverInstallLocalDirKey = "driver.install.localDir"
+	falcoHostRootEnvKey      = "HOST_ROOT"
 )
 
 // Index represents a configured index.
 type Index struct {
-	Name    string `mapstructure:"name"`
-	URL     string `mapstructure:"url"`
-	Backend string `mapstructure:"backend"`
+	Name    string     `mapstructure:"name"`
+	URL     string     `mapstructure:"url"`
+	Backend string     `mapstructure:"backend"`
+	HTTP    *IndexHTTP `mapstructure:"http" yaml:"http,omitempty"`
+	// Priority decides which entry is used when more indexes have an entry with the same name:
+	// the one of the index with the highest priority. Among indexes with the same priority, the last one wins.
+	Priority int `mapstructure:"priority" yaml:"priority,omitempty"`
+}
+
+// IndexHTTP represents the settings used by the HTTP backend to fetch an index.
+type IndexHTTP struct {
+	Username string            `mapstructure:"username" yaml:"username,omitempty"`
+	Password string            `mapstructure:"password" yaml:"password,omitempty"`
+	Token    string            `mapstructure:"token" yaml:"token,omitempty"`
+	Headers  map[string]string `mapstructure:"headers" yaml:"headers,omitempty"`
 }
 
 // OauthAuth represents an OAuth credential.
@@ -178,6 +250,23 @@ type Follow struct {
 	NoVerify      bool          `mapstructure:"noVerify"`
 }
 
+// FollowHook is an action run after the follower installs a new version of an artifact.
+// Exactly one of Command, PID, PIDFile and URL must be set.
+type FollowHook struct {
+	// Ref is the followed artifact the hook applies to, by name or reference. It applies to all of them if empty.
+	Ref string `mapstructure:"ref"`
+	// Command is the program to execute, followed by its arguments.
+	Command []string `mapstructure:"command"`
+	// PID is the process to send a SIGHUP to.
+	PID int `mapstructure:"pid"`
+	// PIDFile is the file holding the PID of the process to send a SIGHUP to, read on every update.
+	PIDFile string `mapstructure:"pidFile"`
+	// URL is the HTTP endpoint the JSON notification of the update is posted to.
+	URL string `mapstructure:"url"`
+	// Timeout is the time allowed to the command or to the endpoint.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
 // Install represents the installer configuration.
 type Install struct {
 	Artifacts     []string `mapstructure:"artifacts"`
@@ -187,13 +276,69 @@ type Install struct {
 	NoVerify      bool     `mapstructure:"noVerify"`
 }
 
+// Verify represents the signature verification required for all the installed and followed artifacts.
+type Verify struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Mode is what to do when the signature is missing or does not match: "enforce" or "warn".
+	Mode string `mapstructure:"mode"`
+	// Cosign is the expected signer, used instead of the one of the indexes when set.
+	Cosign VerifyCosign `mapstructure:"cosign"`
+}
+
+// VerifyCosign is the expected cosign signer of the artifacts, equivalent to the cosign command line arguments.
+type VerifyCosign struct {
+	CertificateIdentity         string `mapstructure:"certificateIdentity"`
+	CertificateIdentityRegexp   string `mapstructure:"certificateIdentityRegexp"`
+	CertificateOidcIssuer       string `mapstructure:"certificateOidcIssuer"`
+	CertificateOidcIssuerRegexp string `mapstructure:"certificateOidcIssuerRegexp"`
+	KeyRef                      string `mapstructure:"key"`
+	IgnoreTlog                  bool   `mapstructure:"ignoreTlog"`
+}
+
 // Driver represents the internal driver configuration (with Type string).
 type Driver struct {
-	Type     []string `mapstructure:"type"`
-	Name     string   `mapstructure:"name"`
-	Repos    []string `mapstructure:"repos"`
-	Version  string   `mapstructure:"version"`
-	HostRoot string   `mapstructure:"hostRoot"`
+	Type         []string `mapstructure:"type"`
+	Name         string   `mapstructure:"name"`
+	Repos        []string `mapstructure:"repos"`
+	PrependRepos []string `mapstructure:"prependRepos"`
+	Version      string   `mapstructure:"version"`
+	HostRoot     string   `mapstructure:"hostRoot"`
+	// History lists the driver configuration changes, the most recent first.
+	History      []DriverChange `mapstructure:"history" yaml:"history,omitempty"`
+	HistoryLimit int            `mapstructure:"historyLimit" yaml:"historyLimit,omitempty"`
+	// Config holds the defaults of the driver config command flags.
+	Config DriverConfigDefaults `mapstructure:"config" yaml:"config,omitempty"`
+	// Install holds the defaults of the driver install command flags.
+	Install DriverInstallDefaults `mapstructure:"install" yaml:"install,omitempty"`
+	// Mirrors holds the settings of the driver repos that need their own.
+	Mirrors []DriverMirror `mapstructure:"mirrors" yaml:"mirrors,omitempty"`
+}
+
+// DriverMirror holds the settings used to download the drivers from a driver repo, instead of the global ones.
+type DriverMirror struct {
+	// URL is the driver repo the settings apply to, as set in the driver repos.
+	URL string `mapstructure:"url" yaml:"url"`
+	// Headers are added to the requests to the repo, overriding the global ones with the same name.
+	Headers map[string]string `mapstructure:"headers" yaml:"headers,omitempty"`
+	// CACert is the CA bundle used to verify the repo.
+	CACert string `mapstructure:"caCert" yaml:"caCert,omitempty"`
+	// ClientCert and ClientKey are the client certificate and key presented to the repo.
+	ClientCert string `mapstructure:"clientCert" yaml:"clientCert,omitempty"`
+	ClientKey  string `mapstructure:"clientKey" yaml:"clientKey,omitempty"`
+	// Insecure disables the verification of the certificate of the repo.
+	Insecure bool `mapstructure:"insecure" yaml:"insecure,omitempty"`
+}
+
+// DriverConfigDefaults are the defaults of the driver config command flags, when not given.
+type DriverConfigDefaults struct {
+	Namespace  string `mapstructure:"namespace" yaml:"namespace,omitempty"`
+	KubeConfig string `mapstructure:"kubeconfig" yaml:"kubeconfig,omitempty"`
+}
+
+// DriverInstallDefaults are the defaults of the driver install command flags, when not given.
+type DriverInstallDefaults struct {
+	BuildCacheDir string `mapstructure:"buildCacheDir" yaml:"buildCacheDir,omitempty"`
+	LocalDir      string `mapstructure:"localDir" yaml:"localDir,omitempty"`
 }
 
 func init() {
@@ -202,6 +347,9 @@ func init() {
 	IndexesFile = filepath.Join(FalcoctlPath, "indexes.yaml")
 	IndexesDir = filepath.Join(FalcoctlPath, "indexes")
 	ClientCredentialsFile = filepath.Join(FalcoctlPath, "clientcredentials.json")
+	InstallManifestFile = filepath.Join(FalcoctlPath, "installed.yaml")
+	InstallJournalFile = filepath.Join(FalcoctlPath, "install.journal")
+	ArtifactHistoryDir = filepath.Join(FalcoctlPath, "history")
 	DefaultIndex = Index{
 		Name:    "falcosecurity",
 		URL:     "https://falcosecurity.github.io/falcoctl/index.yaml",
@@ -240,6 +388,8 @@ func Load(path string) error {
 	viper.SetDefault(DriverNameKey, DefaultDriver.Name)
 	viper.SetDefault(DriverReposKey, DefaultDriver.Repos)
 	viper.SetDefault(DriverVersionKey, DefaultDriver.Version)
+	// Set default artifact history
+	viper.SetDefault(ArtifactHistoryKeepKey, DefaultArtifactHistoryKeep)
 	// Bind FALCOCTL_DRIVER_HOSTROOT key to HOST_ROOT,
 	// so that we manage Falco HOST_ROOT variable too.
 	_ = viper.BindEnv(DriverHostRootKey, falcoHostRootEnvKey)
@@ -349,6 +499,21 @@ func RegistryCredentialConfPath() string {
 	return viper.GetString(RegistryCredentialConfigKey)
 }
 
+// RegistrySOCKS5Proxy retrieves the SOCKS5 proxy used to reach registries and indexes.
+func RegistrySOCKS5Proxy() string {
+	return viper.GetString(RegistrySOCKS5ProxyKey)
+}
+
+// TLSCABundle retrieves the CA bundle trusted, on top of the system one, by all the outgoing requests.
+func TLSCABundle() string {
+	return viper.GetString(TLSCABundleKey)
+}
+
+// TLSInsecureHosts retrieves the hosts whose certificate is not verified.
+func TLSInsecureHosts() ([]string, error) {
+	return semicolonSeparatedValues(TLSInsecureHostsKey)
+}
+
 // BasicAuths retrieves the basicAuths section of the config file.
 func BasicAuths() ([]BasicAuth, error) {
 	var auths []BasicAuth
@@ -555,6 +720,37 @@ func Installer() (Install, error) {
 	}, nil
 }
 
+// ArtifactVerify retrieves the artifact signature verification section of the config file.
+func ArtifactVerify() (Verify, error) {
+	var verify Verify
+
+	if err := viper.UnmarshalKey(ArtifactVerifyKey, &verify); err != nil {
+		return Verify{}, fmt.Errorf("unable to get the signature verification from configuration: %w", err)
+	}
+
+	return verify, nil
+}
+
+// ArtifactFollowHooks retrieves the hooks run after the follower installs an update.
+func ArtifactFollowHooks() ([]FollowHook, error) {
+	var hooks []FollowHook
+
+	if err := viper.UnmarshalKey(ArtifactFollowHooksKey, &hooks); err != nil {
+		return nil, fmt.Errorf("unable to get the follower hooks from configuration: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// ArtifactHistoryKeep retrieves the number of replaced versions kept per artifact. Zero disables the history.
+func ArtifactHistoryKeep() (int, error) {
+	keep := viper.GetInt(ArtifactHistoryKeepKey)
+	if keep < 0 {
+		return 0, fmt.Errorf("invalid %s %d, it cannot be negative", ArtifactHistoryKeepKey, keep)
+	}
+	return keep, nil
+}
+
 // DriverTypes retrieves the driver types of the config file.
 func DriverTypes() ([]string, error) {
 	// manage driver.Type as ";" separated list.
@@ -570,25 +766,90 @@ func DriverTypes() ([]string, error) {
 
 // DriverRepos retrieves the driver repos of the config file.
 func DriverRepos() ([]string, error) {
-	// manage driver.Repos as ";" separated list.
-	repos := viper.GetStringSlice(DriverReposKey)
-	if len(repos) == 1 { // in this case it might come from the env
-		if !SemicolonSeparatedRegexp.MatchString(repos[0]) {
-			return repos, fmt.Errorf("env variable not correctly set, should match %q, got %q", SemicolonSeparatedRegexp.String(), repos[0])
+	return semicolonSeparatedValues(DriverReposKey)
+}
+
+// DriverPrependRepos retrieves the driver repos of the config file that must be tried before the ones returned by DriverRepos.
+func DriverPrependRepos() ([]string, error) {
+	return semicolonSeparatedValues(DriverPrependReposKey)
+}
+
+// DriverMirrors retrieves the settings of the driver repos of the config file.
+func DriverMirrors() ([]DriverMirror, error) {
+	var mirrors []DriverMirror
+
+	if err := viper.UnmarshalKey(DriverMirrorsKey, &mirrors); err != nil {
+		return nil, fmt.Errorf("unable to get the driver mirrors from configuration: %w", err)
+	}
+	for i, m := range mirrors {
+		if m.URL == "" {
+			return nil, fmt.Errorf("%s[%d]: url is mandatory", DriverMirrorsKey, i)
 		}
-		repos = strings.Split(repos[0], ";")
 	}
-	return repos, nil
+
+	return mirrors, nil
 }
 
-// StoreDriver stores a driver conf in config file.
-func StoreDriver(driverCfg *Driver, configFile string) error {
+// semicolonSeparatedValues retrieves a list of values that, when coming from the env, is ";" separated.
+func semicolonSeparatedValues(key string) ([]string, error) {
+	values := viper.GetStringSlice(key)
+	if len(values) == 1 { // in this case it might come from the env
+		if !SemicolonSeparatedRegexp.MatchString(values[0]) {
+			return values, fmt.Errorf("env variable not correctly set, should match %q, got %q", SemicolonSeparatedRegexp.String(), values[0])
+		}
+		values = strings.Split(values[0], ";")
+	}
+	return values, nil
+}
+
+// ArtifactFollowWebhooks retrieves the URLs of the webhooks notified by the follower.
+func ArtifactFollowWebhooks() ([]string, error) {
+	return semicolonSeparatedValues(ArtifactFollowWebhooksKey)
+}
+
+// RegistryRewrites retrieves the registry rewrite rules of the config file.
+func RegistryRewrites() ([]string, error) {
+	return semicolonSeparatedValues(RegistryRewriteKey)
+}
+
+// StoreDriver stores a driver conf in config file, recording the change in the driver history.
+// Target describes where the driver type was applied, if anywhere.
+func StoreDriver(driverCfg *Driver, target, configFile string) error {
+	prev, err := LoadDriver(configFile)
+	if err != nil {
+		return err
+	}
+	recordDriverChange(prev, driverCfg, target)
+	// The defaults of the command flags are not part of the driver configuration, keep them.
+	driverCfg.Config = prev.Config
+	driverCfg.Install = prev.Install
+	driverCfg.Mirrors = prev.Mirrors
 	if err := UpdateConfigFile(DriverKey, driverCfg, configFile); err != nil {
 		return fmt.Errorf("unable to update driver in the config file %q: %w", configFile, err)
 	}
 	return nil
 }
 
+// LoadDriver reads the driver conf stored in config file, ignoring the env variables and the flags.
+func LoadDriver(configFile string) (*Driver, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	absolutePath, err := filepath.Abs(configFile)
+	if err != nil {
+		return nil, err
+	}
+	v.SetConfigFile(absolutePath)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("config: error reading config file: %w", err)
+	}
+
+	var driverCfg Driver
+	if err := v.UnmarshalKey(DriverKey, &driverCfg); err != nil {
+		return nil, fmt.Errorf("unable to read driver from the config file %q: %w", configFile, err)
+	}
+	return &driverCfg, nil
+}
+
 // ArtifactAllowedTypes retrieves the allowed types section of the config file.
 func ArtifactAllowedTypes() (*oci.ArtifactTypeSlice, error) {
 	allowedTypes := viper.GetStringSlice(ArtifactAllowedTypesKey)
@@ -639,7 +900,12 @@ func UpdateConfigFile(key string, value interface{}, path string) error {
 
 	v.Set(key, value)
 
-	if err := v.WriteConfig(); err != nil {
+	// Marshal the settings as viper does, but replace the file atomically.
+	data, err := yaml.Marshal(v.AllSettings())
+	if err != nil {
+		return fmt.Errorf("unable to set key %q to config file: %w", key, err)
+	}
+	if err := utils.WriteFileAtomic(absolutePath, data, 0o644); err != nil {
 		return fmt.Errorf("unable to set key %q to config file: %w", key, err)
 	}
 
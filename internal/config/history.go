@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"os/user"
+	"time"
+)
+
+// DefaultDriverHistoryLimit is the number of driver configuration changes kept when no limit is configured.
+const DefaultDriverHistoryLimit = 20
+
+// DriverChange is an audit record of a driver configuration change.
+type DriverChange struct {
+	Time     string   `mapstructure:"time" json:"time" yaml:"time"`
+	Previous []string `mapstructure:"previous" json:"previous,omitempty" yaml:"previous,omitempty"`
+	Type     []string `mapstructure:"type" json:"type" yaml:"type"`
+	Target   string   `mapstructure:"target" json:"target,omitempty" yaml:"target,omitempty"`
+	User     string   `mapstructure:"user" json:"user,omitempty" yaml:"user,omitempty"`
+}
+
+// now is the clock used to timestamp the history, swapped in tests.
+var now = time.Now
+
+// recordDriverChange prepends the change from prev to next to the history carried over from prev,
+// truncating it to the configured limit.
+func recordDriverChange(prev, next *Driver, target string) {
+	next.HistoryLimit = prev.HistoryLimit
+	limit := prev.HistoryLimit
+	if limit <= 0 {
+		limit = DefaultDriverHistoryLimit
+	}
+
+	change := DriverChange{
+		Time:     now().UTC().Format(time.RFC3339),
+		Previous: prev.Type,
+		Type:     next.Type,
+		Target:   target,
+		User:     currentUser(),
+	}
+	next.History = append([]DriverChange{change}, prev.History...)
+	if len(next.History) > limit {
+		next.History = next.History[:limit]
+	}
+}
+
+// currentUser returns the name of the user running falcoctl, if known.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
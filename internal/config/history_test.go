@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreDriverHistory(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("driver:\n  type: [kmod]\n  historyLimit: 3\n  config:\n    namespace: falco\n  install:\n    buildCacheDir: /cache\n  mirrors:\n  - url: https://mirror.example.com\n    insecure: true\n"), 0o600))
+
+	clock := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+
+	types := []string{"ebpf", "modern_ebpf", "kmod", "ebpf"}
+	for i, typ := range types {
+		clock = clock.Add(time.Minute)
+		require.NoError(t, StoreDriver(&Driver{Type: []string{typ}, Name: "falco"}, fmt.Sprintf("target%d", i), configFile))
+	}
+
+	driverCfg, err := LoadDriver(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ebpf"}, driverCfg.Type)
+	assert.Equal(t, 3, driverCfg.HistoryLimit)
+	assert.Equal(t, DriverConfigDefaults{Namespace: "falco"}, driverCfg.Config)
+	assert.Equal(t, DriverInstallDefaults{BuildCacheDir: "/cache"}, driverCfg.Install)
+	assert.Equal(t, []DriverMirror{{URL: "https://mirror.example.com", Insecure: true}}, driverCfg.Mirrors)
+	require.Len(t, driverCfg.History, 3)
+
+	// Newest first, the oldest change (kmod -> ebpf) being dropped.
+	assert.Equal(t, "2024-05-01T10:04:00Z", driverCfg.History[0].Time)
+	assert.Equal(t, []string{"kmod"}, driverCfg.History[0].Previous)
+	assert.Equal(t, []string{"ebpf"}, driverCfg.History[0].Type)
+	assert.Equal(t, "target3", driverCfg.History[0].Target)
+	assert.Equal(t, []string{"modern_ebpf"}, driverCfg.History[1].Previous)
+	assert.Equal(t, []string{"kmod"}, driverCfg.History[1].Type)
+	assert.Equal(t, []string{"ebpf"}, driverCfg.History[2].Previous)
+	assert.Equal(t, []string{"modern_ebpf"}, driverCfg.History[2].Type)
+	assert.Equal(t, "2024-05-01T10:02:00Z", driverCfg.History[2].Time)
+	assert.Equal(t, currentUser(), driverCfg.History[0].User)
+}
+
+func TestStoreDriverHistoryDefaultLimit(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("driver:\n  type: [kmod]\n"), 0o600))
+
+	for i := 0; i < DefaultDriverHistoryLimit+5; i++ {
+		require.NoError(t, StoreDriver(&Driver{Type: []string{"ebpf"}}, "", configFile))
+	}
+
+	driverCfg, err := LoadDriver(configFile)
+	require.NoError(t, err)
+	assert.Len(t, driverCfg.History, DefaultDriverHistoryLimit)
+	assert.Equal(t, []string{"ebpf"}, driverCfg.History[DefaultDriverHistoryLimit-1].Previous)
+	assert.Zero(t, driverCfg.HistoryLimit)
+}
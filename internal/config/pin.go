@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+// PinArtifactRefs rewrites the entries of the artifact.install.refs and artifact.follow.refs
+// lists of the config file according to pins, which maps the current refs to the pinned ones.
+// The previous ref is kept as a line comment of the entry for readability.
+// It returns the number of rewritten entries.
+func PinArtifactRefs(path string, pins map[string]string) (int, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return 0, fmt.Errorf("unable to read config file %q: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("unable to parse config file %q: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return 0, nil
+	}
+
+	pinned := 0
+	artifact := MappingValue(doc.Content[0], "artifact")
+	for _, section := range []string{"install", "follow"} {
+		refs := MappingValue(MappingValue(artifact, section), "refs")
+		if refs == nil || refs.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, entry := range refs.Content {
+			if entry.Kind != yaml.ScalarNode {
+				continue
+			}
+			to, ok := pins[entry.Value]
+			if !ok || to == entry.Value {
+				continue
+			}
+			entry.LineComment = "# " + entry.Value
+			entry.Value = to
+			entry.Style = 0
+			pinned++
+		}
+	}
+	if pinned == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return 0, fmt.Errorf("unable to encode config file %q: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return 0, fmt.Errorf("unable to encode config file %q: %w", path, err)
+	}
+
+	if err := utils.WriteFileAtomic(path, buf.Bytes(), 0o600); err != nil {
+		return 0, fmt.Errorf("unable to write config file %q: %w", path, err)
+	}
+	return pinned, nil
+}
+
+// MappingValue returns the value of key in a mapping node, matched case insensitively
+// as viper does, nil if not found.
+func MappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinArtifactRefs(t *testing.T) {
+	const content = `# falcoctl configuration
+artifact:
+  install:
+    refs:
+      - falco-rules:3
+      - k8saudit-rules@sha256:aaaa
+  follow:
+    refs:
+      - falco-rules:3
+`
+	const expected = `# falcoctl configuration
+artifact:
+  install:
+    refs:
+      - falco-rules@sha256:bbbb # falco-rules:3
+      - k8saudit-rules@sha256:aaaa
+  follow:
+    refs:
+      - falco-rules@sha256:bbbb # falco-rules:3
+`
+
+	configFile := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0o600))
+
+	pinned, err := PinArtifactRefs(configFile, map[string]string{"falco-rules:3": "falco-rules@sha256:bbbb"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, pinned)
+
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// RegistriesKey is the Viper key for the registries configuration.
+	RegistriesKey = "registries"
+
+	// RegistryAuthTypeNone does not configure any auth for the registry.
+	RegistryAuthTypeNone = "none"
+	// RegistryAuthTypeBasic configures basic auth for the registry.
+	RegistryAuthTypeBasic = "basic"
+	// RegistryAuthTypeOauth configures OAuth2.0 client credentials auth for the registry.
+	RegistryAuthTypeOauth = "oauth"
+	// RegistryAuthTypeCloud configures the auth provided by a cloud provider for the registry.
+	RegistryAuthTypeCloud = "cloud"
+
+	// CloudProviderGcp is the Google Cloud Platform provider.
+	CloudProviderGcp = "gcp"
+)
+
+// RegistryAuthTypes are the allowed auth types for the entries of the registries section.
+var RegistryAuthTypes = []string{RegistryAuthTypeNone, RegistryAuthTypeBasic, RegistryAuthTypeOauth, RegistryAuthTypeCloud}
+
+// CloudProviders are the allowed providers for the cloud auth type.
+var CloudProviders = []string{CloudProviderGcp}
+
+// Registry represents an entry of the registries section, holding the default auth of a registry.
+type Registry struct {
+	Host  string         `mapstructure:"host"`
+	Auth  string         `mapstructure:"auth"`
+	Basic *RegistryBasic `mapstructure:"basic"`
+	Oauth *RegistryOauth `mapstructure:"oauth"`
+	Cloud *RegistryCloud `mapstructure:"cloud"`
+}
+
+// RegistryBasic holds the settings of the basic auth type. The password can either be set
+// inline or read from the environment variable named by PasswordEnv.
+type RegistryBasic struct {
+	User        string `mapstructure:"user"`
+	Password    string `mapstructure:"password"`
+	PasswordEnv string `mapstructure:"passwordEnv"`
+}
+
+// RegistryOauth holds the settings of the oauth auth type.
+type RegistryOauth struct {
+	ClientID     string `mapstructure:"clientID"`
+	ClientSecret string `mapstructure:"clientSecret"`
+	TokenURL     string `mapstructure:"tokenURL"`
+}
+
+// RegistryCloud holds the settings of the cloud auth type.
+type RegistryCloud struct {
+	Provider string `mapstructure:"provider"`
+}
+
+// Registries retrieves the registries section of the config file.
+func Registries() ([]Registry, error) {
+	var registries []Registry
+
+	if err := viper.UnmarshalKey(RegistriesKey, &registries); err != nil {
+		return nil, fmt.Errorf("unable to get registries: %w", err)
+	}
+
+	return registries, nil
+}
+
+// Validate checks that the registry entry is well-formed.
+func (r *Registry) Validate() error {
+	if r.Host == "" {
+		return errors.New("host is mandatory")
+	}
+
+	switch r.Auth {
+	case RegistryAuthTypeNone:
+	case RegistryAuthTypeBasic:
+		switch {
+		case r.Basic == nil || r.Basic.User == "":
+			return fmt.Errorf("registry %q: basic.user is mandatory for %q auth", r.Host, r.Auth)
+		case r.Basic.Password == "" && r.Basic.PasswordEnv == "":
+			return fmt.Errorf("registry %q: one of basic.password and basic.passwordEnv is mandatory for %q auth", r.Host, r.Auth)
+		case r.Basic.Password != "" && r.Basic.PasswordEnv != "":
+			return fmt.Errorf("registry %q: basic.password and basic.passwordEnv are mutually exclusive", r.Host)
+		}
+	case RegistryAuthTypeOauth:
+		if r.Oauth == nil || r.Oauth.ClientID == "" || r.Oauth.ClientSecret == "" || r.Oauth.TokenURL == "" {
+			return fmt.Errorf("registry %q: oauth.clientID, oauth.clientSecret and oauth.tokenURL are mandatory for %q auth", r.Host, r.Auth)
+		}
+	case RegistryAuthTypeCloud:
+		if r.Cloud == nil || r.Cloud.Provider != CloudProviderGcp {
+			return fmt.Errorf("registry %q: cloud.provider must be one of %s for %q auth", r.Host, strings.Join(CloudProviders, ", "), r.Auth)
+		}
+	default:
+		return fmt.Errorf("registry %q: auth must be one of %s, got %q", r.Host, strings.Join(RegistryAuthTypes, ", "), r.Auth)
+	}
+
+	return nil
+}
+
+// ValidateRegistries checks that all the registries entries are well-formed,
+// and that each host is configured only once.
+func ValidateRegistries(registries []Registry) error {
+	var errs []error
+	hosts := make(map[string]bool, len(registries))
+	for i := range registries {
+		r := &registries[i]
+		if err := r.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("entry %d: %w", i, err))
+			continue
+		}
+		if hosts[r.Host] {
+			errs = append(errs, fmt.Errorf("entry %d: registry %q is configured more than once", i, r.Host))
+		}
+		hosts[r.Host] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// RegistriesAuths converts the entries of the registries section to the corresponding auth entries,
+// so that they are resolved along with the ones of the registry.auth section.
+// Malformed entries are reported as an error.
+func RegistriesAuths() (basics []BasicAuth, oauths []OauthAuth, gcps []GcpAuth, err error) {
+	registries, err := Registries()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := ValidateRegistries(registries); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid %s section: %w", RegistriesKey, err)
+	}
+
+	for i := range registries {
+		r := &registries[i]
+		switch r.Auth {
+		case RegistryAuthTypeBasic:
+			password := r.Basic.Password
+			if r.Basic.PasswordEnv != "" {
+				password = os.Getenv(r.Basic.PasswordEnv)
+			}
+			basics = append(basics, BasicAuth{Registry: r.Host, User: r.Basic.User, Password: password})
+		case RegistryAuthTypeOauth:
+			oauths = append(oauths, OauthAuth{
+				Registry:     r.Host,
+				ClientID:     r.Oauth.ClientID,
+				ClientSecret: r.Oauth.ClientSecret,
+				TokenURL:     r.Oauth.TokenURL,
+			})
+		case RegistryAuthTypeCloud:
+			gcps = append(gcps, GcpAuth{Registry: r.Host})
+		}
+	}
+
+	return basics, oauths, gcps, nil
+}
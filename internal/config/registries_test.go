@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRegistries = `registries:
+- host: ghcr.io
+  auth: basic
+  basic:
+    user: user
+    passwordEnv: TEST_GHCR_PASSWORD
+- host: registry.example.com
+  auth: oauth
+  oauth:
+    clientID: "000000"
+    clientSecret: "999999"
+    tokenURL: https://auth.example.com/token
+- host: europe-docker.pkg.dev
+  auth: cloud
+  cloud:
+    provider: gcp
+- host: docker.io
+  auth: none
+`
+
+func TestRegistryValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		registry    Registry
+		expectedErr string
+	}{
+		{
+			name:     "none",
+			registry: Registry{Host: "ghcr.io", Auth: RegistryAuthTypeNone},
+		},
+		{
+			name:        "missing host",
+			registry:    Registry{Auth: RegistryAuthTypeNone},
+			expectedErr: "host is mandatory",
+		},
+		{
+			name:        "unknown auth",
+			registry:    Registry{Host: "ghcr.io", Auth: "token"},
+			expectedErr: `registry "ghcr.io": auth must be one of none, basic, oauth, cloud, got "token"`,
+		},
+		{
+			name:        "basic without settings",
+			registry:    Registry{Host: "ghcr.io", Auth: RegistryAuthTypeBasic},
+			expectedErr: `registry "ghcr.io": basic.user is mandatory for "basic" auth`,
+		},
+		{
+			name: "basic with both password sources",
+			registry: Registry{Host: "ghcr.io", Auth: RegistryAuthTypeBasic,
+				Basic: &RegistryBasic{User: "user", Password: "pass", PasswordEnv: "PASS"}},
+			expectedErr: `registry "ghcr.io": basic.password and basic.passwordEnv are mutually exclusive`,
+		},
+		{
+			name: "oauth without token url",
+			registry: Registry{Host: "ghcr.io", Auth: RegistryAuthTypeOauth,
+				Oauth: &RegistryOauth{ClientID: "id", ClientSecret: "secret"}},
+			expectedErr: `registry "ghcr.io": oauth.clientID, oauth.clientSecret and oauth.tokenURL are mandatory for "oauth" auth`,
+		},
+		{
+			name:        "cloud with unknown provider",
+			registry:    Registry{Host: "ghcr.io", Auth: RegistryAuthTypeCloud, Cloud: &RegistryCloud{Provider: "aws"}},
+			expectedErr: `registry "ghcr.io": cloud.provider must be one of gcp for "cloud" auth`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.registry.Validate()
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistriesDuplicatedHost(t *testing.T) {
+	err := ValidateRegistries([]Registry{
+		{Host: "ghcr.io", Auth: RegistryAuthTypeNone},
+		{Host: "ghcr.io", Auth: RegistryAuthTypeNone},
+	})
+	assert.EqualError(t, err, `entry 1: registry "ghcr.io" is configured more than once`)
+}
+
+func TestRegistriesAuths(t *testing.T) {
+	t.Setenv("TEST_GHCR_PASSWORD", "secret")
+
+	configFile := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(testRegistries), 0o600))
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile(configFile)
+	require.NoError(t, viper.ReadInConfig())
+
+	basics, oauths, gcps, err := RegistriesAuths()
+	require.NoError(t, err)
+	assert.Equal(t, []BasicAuth{{Registry: "ghcr.io", User: "user", Password: "secret"}}, basics)
+	assert.Equal(t, []OauthAuth{{
+		Registry:     "registry.example.com",
+		ClientID:     "000000",
+		ClientSecret: "999999",
+		TokenURL:     "https://auth.example.com/token",
+	}}, oauths)
+	assert.Equal(t, []GcpAuth{{Registry: "europe-docker.pkg.dev"}}, gcps)
+}
@@ -18,6 +18,7 @@ package follower
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -34,6 +35,7 @@ import (
 	"oras.land/oras-go/v2/registry"
 
 	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/installer"
 	"github.com/falcosecurity/falcoctl/internal/signature"
 	"github.com/falcosecurity/falcoctl/internal/utils"
 	"github.com/falcosecurity/falcoctl/pkg/index/index"
@@ -51,6 +53,8 @@ type Follower struct {
 	tag           string
 	tmpDir        string
 	currentDigest string
+	// lastErr is the error of the last check, returned by Check.
+	lastErr error
 	*ocipuller.Puller
 	*Config
 	logger *pterm.Logger
@@ -84,6 +88,20 @@ type Config struct {
 	AllowedTypes oci.ArtifactTypeSlice
 	// Signature has the data needed for signature checking
 	Signature *index.Signature
+	// VerifyPolicy, if set, requires every pulled artifact to be signed, Signature being the fallback signer.
+	VerifyPolicy *signature.Policy
+	// History, if set, keeps the files replaced by each update so that they can be rolled back.
+	History *installer.History
+	// InstallManifestFile, if set, is the install manifest where the installed artifacts are recorded.
+	InstallManifestFile string
+	// Notifier, if set, is notified of the outcome of each update.
+	Notifier Notifier
+	// Hooks are run, in order, after each successful update.
+	Hooks []Hook
+	// Metrics, if set, records the activity of the follower.
+	Metrics *Metrics
+	// DryRun only reports the files that an update would write, without writing them.
+	DryRun bool
 }
 
 var (
@@ -153,12 +171,23 @@ func (f *Follower) Follow(ctx context.Context) {
 	}
 }
 
+// Check checks the artifact for an update once, as Follow does at start up, and returns the reason
+// the update failed, if it did. The artifact is not followed afterwards.
+func (f *Follower) Check(ctx context.Context) error {
+	defer f.cleanUp()
+	f.lastErr = nil
+	f.follow(ctx)
+	return f.lastErr
+}
+
 func (f *Follower) follow(ctx context.Context) {
 	// First thing get the descriptor from remote repo.
 	f.logger.Debug("Fetching descriptor from remote repository...", f.logger.Args("followerName", f.ref))
 	desc, err := f.Descriptor(ctx, f.ref)
 	if err != nil {
 		f.logger.Debug(fmt.Sprintf("an error occurred while fetching descriptor from remote repository: %v", err))
+		f.Metrics.pullFailed(f.ref)
+		f.lastErr = err
 		return
 	}
 	f.logger.Debug("Descriptor correctly fetched", f.logger.Args("followerName", f.ref))
@@ -167,21 +196,41 @@ func (f *Follower) follow(ctx context.Context) {
 	// TODO(alacuku): check that the file also exists to cover the case when someone has removed the file.
 	if desc.Digest.String() == f.currentDigest {
 		f.logger.Debug("Nothing to do, artifact already up to date.", f.logger.Args("followerName", f.ref))
+		f.Metrics.synced(f.ref)
 		return
 	}
 
 	f.logger.Info("Found new artifact version", f.logger.Args("followerName", f.ref, "tag", f.tag))
 
+	// Record and notify the outcome of the update, whatever it is.
+	n := &Notification{Ref: f.ref, OldDigest: f.currentDigest, NewDigest: desc.Digest.String()}
+	defer func() {
+		switch n.Result {
+		case ResultUpdated:
+			f.Metrics.updated(f.ref)
+		case ResultFailed:
+			f.Metrics.updateFailed(f.ref)
+			f.lastErr = errors.New(n.Error)
+		default:
+			// Dry run, nothing has been updated.
+			return
+		}
+		f.notify(ctx, n)
+	}()
+
 	// Pull config layer to check falco versions
 	artifactConfig, err := f.ArtifactConfig(ctx, f.ref, runtime.GOOS, runtime.GOARCH)
 	if err != nil {
 		f.logger.Error("Unable to pull config layer", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+		n.failed(err)
+		f.Metrics.pullFailed(f.ref)
 		return
 	}
 
 	err = f.checkRequirements(artifactConfig)
 	if err != nil {
 		f.logger.Error("Unmet requirements", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+		n.failed(err)
 		return
 	}
 
@@ -190,65 +239,86 @@ func (f *Follower) follow(ctx context.Context) {
 	filePaths, res, err := f.pull(ctx)
 	if err != nil {
 		f.logger.Error("Unable to pull artifact", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+		n.failed(err)
+		f.Metrics.pullFailed(f.ref)
 		return
 	}
 	f.logger.Debug("Artifact correctly pulled", f.logger.Args("followerName", f.ref))
 
 	dstDir := f.destinationDir(res)
+	n.Directory = dstDir
 
 	// Check if directory exists and is writable.
 	err = utils.ExistsAndIsWritable(dstDir)
 	if err != nil {
 		f.logger.Error("Invalid destination", f.logger.Args("followerName", f.ref, "directory", dstDir, "reason", err.Error()))
+		n.failed(err)
 		return
 	}
 
-	// Install the artifacts if necessary.
+	if f.DryRun {
+		if err = f.printPlan(dstDir, filePaths); err != nil {
+			f.logger.Error("Unable to compare files", f.logger.Args("followerName", f.ref, "directory", dstDir, "reason", err.Error()))
+			n.failed(err)
+		}
+		return
+	}
+
+	// Keep the files about to be replaced, to be able to roll back the update.
+	if err = f.saveReplaced(dstDir, filePaths, res); err != nil {
+		f.logger.Error("Unable to keep the replaced version", f.logger.Args("followerName", f.ref, "directory", dstDir, "reason", err.Error()))
+		n.failed(err)
+		return
+	}
+
+	// Install the files that changed, all of them or none.
+	changed := make(map[string]string)
 	for _, path := range filePaths {
 		baseName := filepath.Base(path)
-		f.logger.Debug("Installing file", f.logger.Args("followerName", f.ref, "fileName", baseName))
 		dstPath := filepath.Join(dstDir, baseName)
 		// Check if the file exists.
 		f.logger.Debug("Checking if file already exists", f.logger.Args("followerName", f.ref, "fileName", baseName, "directory", dstDir))
 		exists, err := utils.FileExists(dstPath)
 		if err != nil {
 			f.logger.Error("Unable to check existence for file", f.logger.Args("followerName", f.ref, "fileName", baseName, "reason", err.Error()))
+			n.failed(err)
 			return
 		}
 
-		if !exists {
-			f.logger.Debug("Moving file", f.logger.Args("followerName", f.ref, "fileName", baseName, "destDirectory", dstDir))
-			if err = utils.Move(path, dstPath); err != nil {
-				f.logger.Error("Unable to move file", f.logger.Args("followerName", f.ref, "fileName", baseName, "destDirectory", dstDir, "reason", err.Error()))
+		if exists {
+			f.logger.Debug(fmt.Sprintf("file %q already exists in %q, checking if it is equal to the existing one", baseName, dstDir),
+				f.logger.Args("followerName", f.ref))
+			// Check if the files are equal.
+			eq, err := equal([]string{path, dstPath})
+			if err != nil {
+				f.logger.Error("Unable to compare files", f.logger.Args("followerName", f.ref, "newFile", path, "existingFile", dstPath, "reason", err.Error()))
+				n.failed(err)
 				return
 			}
-			f.logger.Debug("File correctly installed", f.logger.Args("followerName", f.ref, "path", path))
-			// It's done, move to the next file.
-			continue
-		}
-		f.logger.Debug(fmt.Sprintf("file %q already exists in %q, checking if it is equal to the existing one", baseName, dstDir),
-			f.logger.Args("followerName", f.ref))
-		// Check if the files are equal.
-		eq, err := equal([]string{path, dstPath})
-		if err != nil {
-			f.logger.Error("Unable to compare files", f.logger.Args("followerName", f.ref, "newFile", path, "existingFile", dstPath, "reason", err.Error()))
-			return
-		}
-
-		if !eq {
-			f.logger.Debug(fmt.Sprintf("Overwriting file %q with file %q", dstPath, path), f.logger.Args("followerName", f.ref))
-			if err = utils.Move(path, dstPath); err != nil {
-				f.logger.Error("Unable to overwrite file", f.logger.Args("followerName", f.ref, "existingFile", dstPath, "reason", err.Error()))
-				return
+			if eq {
+				f.logger.Debug("The two file are equal, nothing to be done")
+				continue
 			}
-		} else {
-			f.logger.Debug("The two file are equal, nothing to be done")
 		}
+		changed[baseName] = path
+	}
+
+	f.logger.Debug("Installing files", f.logger.Args("followerName", f.ref, "files", len(changed), "destDirectory", dstDir))
+	if err = installer.Replace(dstDir, changed); err != nil {
+		f.logger.Error("Unable to install files", f.logger.Args("followerName", f.ref, "destDirectory", dstDir, "reason", err.Error()))
+		n.failed(err)
+		return
 	}
 
 	f.logger.Info("Artifact correctly installed",
 		f.logger.Args("followerName", f.ref, "artifactName", f.ref, "type", res.Type, "digest", res.Digest, "directory", dstDir))
 	f.currentDigest = desc.Digest.String()
+	// The files are in place, failing to record them does not fail the update.
+	if err = f.record(dstDir, filePaths, res); err != nil {
+		f.logger.Warn("Unable to record the installed artifact", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+	}
+	n.Result = ResultUpdated
+	f.runHooks(ctx, n)
 }
 
 // pull downloads, extracts, and installs the artifact.
@@ -273,7 +343,19 @@ func (f *Follower) pull(ctx context.Context) (filePaths []string, res *oci.Regis
 	digestRef := fmt.Sprintf("%s@%s", repo, res.RootDigest)
 
 	// Verify the signature if needed
-	if f.Config.Signature != nil {
+	if policy := f.Config.VerifyPolicy; policy != nil {
+		f.logger.Debug("Verifying signature", f.logger.Args("followerName", f.ref, "digest", digestRef))
+		err = policy.Verify(ctx, digestRef, f.Config.Signature)
+		switch {
+		case err == nil:
+			f.logger.Debug("Signature successfully verified")
+		case policy.Mode == signature.ModeWarn:
+			f.logger.Warn("Installing artifact without a valid signature",
+				f.logger.Args("followerName", f.ref, "digest", digestRef, "reason", err.Error()))
+		default:
+			return filePaths, res, fmt.Errorf("could not verify signature for %s: %w", res.RootDigest, err)
+		}
+	} else if f.Config.Signature != nil {
 		f.logger.Debug("Verifying signature", f.logger.Args("followerName", f.ref, "digest", digestRef))
 		err = signature.Verify(ctx, digestRef, f.Config.Signature)
 		if err != nil {
@@ -289,6 +371,9 @@ func (f *Follower) pull(ctx context.Context) (filePaths []string, res *oci.Regis
 	if err != nil {
 		return filePaths, res, fmt.Errorf("unable to open file %q: %w", res.Filename, err)
 	}
+	if info, err := file.Stat(); err == nil {
+		f.Metrics.downloaded(f.ref, info.Size())
+	}
 
 	// Extract artifact and move it to its destination directory
 	filePaths, err = utils.ExtractTarGz(ctx, file, f.tmpDir, 0)
@@ -305,6 +390,96 @@ func (f *Follower) pull(ctx context.Context) (filePaths []string, res *oci.Regis
 	return filePaths, res, err
 }
 
+// saveReplaced saves in the history the files in dstDir that the pulled ones are going to overwrite.
+// Nothing is saved when all of them are already up to date.
+func (f *Follower) saveReplaced(dstDir string, filePaths []string, res *oci.RegistryResult) error {
+	if f.History == nil {
+		return nil
+	}
+
+	var files []installer.File
+	changed := false
+	for _, path := range filePaths {
+		dstPath := filepath.Join(dstDir, filepath.Base(path))
+		exists, err := utils.FileExists(dstPath)
+		if err != nil {
+			return err
+		} else if !exists {
+			continue
+		}
+		files = append(files, installer.File{Path: filepath.Base(path)})
+		if eq, err := equal([]string{path, dstPath}); err != nil {
+			return err
+		} else if !eq {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	repo, err := utils.RepositoryFromRef(f.ref)
+	if err != nil {
+		return err
+	}
+	return f.History.Save(&installer.Artifact{
+		Repository: repo,
+		Ref:        f.ref,
+		Digest:     f.currentDigest,
+		Type:       res.Type.String(),
+		Directory:  dstDir,
+		Files:      files,
+	})
+}
+
+// printPlan prints what installing the pulled files in dstDir would do.
+func (f *Follower) printPlan(dstDir string, filePaths []string) error {
+	for _, path := range filePaths {
+		dstPath := filepath.Join(dstDir, filepath.Base(path))
+		exists, err := utils.FileExists(dstPath)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			f.logger.Info("Would create file", f.logger.Args("followerName", f.ref, "path", dstPath))
+			continue
+		}
+		if eq, err := equal([]string{path, dstPath}); err != nil {
+			return err
+		} else if eq {
+			f.logger.Info("File already up to date", f.logger.Args("followerName", f.ref, "path", dstPath))
+		} else {
+			f.logger.Info("Would overwrite file", f.logger.Args("followerName", f.ref, "path", dstPath))
+		}
+	}
+	return nil
+}
+
+// record records the installed artifact in the install manifest, if configured.
+func (f *Follower) record(dstDir string, filePaths []string, res *oci.RegistryResult) error {
+	if f.InstallManifestFile == "" {
+		return nil
+	}
+	repo, err := utils.RepositoryFromRef(f.ref)
+	if err != nil {
+		return err
+	}
+	files := make([]installer.File, len(filePaths))
+	for i, path := range filePaths {
+		files[i] = installer.File{Path: filepath.Base(path)}
+	}
+	return installer.Record(f.InstallManifestFile, installer.Artifact{
+		Repository:  repo,
+		Ref:         f.ref,
+		Digest:      res.Digest,
+		Type:        res.Type.String(),
+		Version:     res.Config.Version,
+		Directory:   dstDir,
+		Files:       files,
+		InstalledAt: time.Now().UTC(),
+	})
+}
+
 // destinationDir returns the dir where to save the artifact.
 func (f *Follower) destinationDir(res *oci.RegistryResult) string {
 	var dir string
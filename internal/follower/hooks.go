@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+)
+
+// DefaultHookTimeout is the time allowed to each hook when not configured.
+const DefaultHookTimeout = 30 * time.Second
+
+// ErrInvalidHook is returned when a hook is not correctly configured.
+var ErrInvalidHook = errors.New("invalid hook")
+
+// Hook is an action run after the follower installs a new version of its artifact,
+// e.g. to make Falco reload its rules.
+type Hook interface {
+	Run(ctx context.Context, n *Notification) error
+	// String describes the hook in the logs.
+	String() string
+}
+
+// NewHook returns the Hook configured by h.
+func NewHook(h config.FollowHook) (Hook, error) {
+	set := 0
+	for _, ok := range []bool{len(h.Command) > 0, h.PID != 0, h.PIDFile != "", h.URL != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("%w: exactly one of command, pid, pidFile and url must be set", ErrInvalidHook)
+	}
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+
+	switch {
+	case len(h.Command) > 0:
+		return &commandHook{command: h.Command, timeout: timeout}, nil
+	case h.PID != 0, h.PIDFile != "":
+		if h.PID < 0 {
+			return nil, fmt.Errorf("%w: invalid pid %d", ErrInvalidHook, h.PID)
+		}
+		return &signalHook{pid: h.PID, pidFile: h.PIDFile}, nil
+	default:
+		if u, err := url.Parse(h.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: %q is not an http or https URL", ErrInvalidHook, h.URL)
+		}
+		return &httpHook{url: h.URL, webhooks: NewWebhooks([]string{h.URL}, timeout)}, nil
+	}
+}
+
+// commandHook executes a command, passing the update in the FALCOCTL_* environment variables.
+type commandHook struct {
+	command []string
+	timeout time.Duration
+}
+
+func (h *commandHook) Run(ctx context.Context, n *Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.command[0], h.command[1:]...) //nolint:gosec // command is set by the user
+	cmd.Env = append(os.Environ(),
+		"FALCOCTL_REF="+n.Ref,
+		"FALCOCTL_OLD_DIGEST="+n.OldDigest,
+		"FALCOCTL_NEW_DIGEST="+n.NewDigest,
+		"FALCOCTL_DIRECTORY="+n.Directory,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if out = bytes.TrimSpace(out); len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+func (h *commandHook) String() string {
+	return "command " + strings.Join(h.command, " ")
+}
+
+// signalHook sends a SIGHUP to a process.
+type signalHook struct {
+	pid     int
+	pidFile string
+}
+
+func (h *signalHook) Run(_ context.Context, _ *Notification) error {
+	pid := h.pid
+	if h.pidFile != "" {
+		data, err := os.ReadFile(filepath.Clean(h.pidFile))
+		if err != nil {
+			return fmt.Errorf("unable to read pid file: %w", err)
+		}
+		if pid, err = strconv.Atoi(strings.TrimSpace(string(data))); err != nil || pid <= 0 {
+			return fmt.Errorf("invalid pid in %q", h.pidFile)
+		}
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Signal(syscall.SIGHUP)
+}
+
+func (h *signalHook) String() string {
+	if h.pidFile != "" {
+		return "SIGHUP to the pid in " + h.pidFile
+	}
+	return "SIGHUP to pid " + strconv.Itoa(h.pid)
+}
+
+// httpHook posts the notification of the update to an endpoint.
+type httpHook struct {
+	url      string
+	webhooks *Webhooks
+}
+
+func (h *httpHook) Run(ctx context.Context, n *Notification) error {
+	return h.webhooks.Notify(ctx, n)
+}
+
+func (h *httpHook) String() string {
+	return "POST to " + h.url
+}
+
+// runHooks runs the hooks of the follower after n has been installed. Failures are only logged,
+// the update being already in place.
+func (f *Follower) runHooks(ctx context.Context, n *Notification) {
+	n.Time = time.Now().UTC()
+	for _, h := range f.Hooks {
+		f.logger.Debug("Running hook", f.logger.Args("followerName", f.ref, "hook", h.String()))
+		if err := h.Run(ctx, n); err != nil {
+			f.logger.Warn("Hook failed", f.logger.Args("followerName", f.ref, "hook", h.String(), "reason", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+)
+
+func TestNewHookInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		hook config.FollowHook
+	}{
+		{name: "no action", hook: config.FollowHook{Ref: "falco-rules"}},
+		{name: "more actions", hook: config.FollowHook{Command: []string{"true"}, URL: "http://localhost"}},
+		{name: "negative pid", hook: config.FollowHook{PID: -1}},
+		{name: "not an http url", hook: config.FollowHook{URL: "ftp://localhost"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewHook(tc.hook)
+			assert.ErrorIs(t, err, ErrInvalidHook)
+		})
+	}
+}
+
+func testUpdate() *Notification {
+	return &Notification{
+		Ref:       "ghcr.io/falcosecurity/rules/falco-rules:3",
+		OldDigest: "sha256:1",
+		NewDigest: "sha256:2",
+		Result:    ResultUpdated,
+		Directory: "/etc/falco",
+	}
+}
+
+func TestCommandHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	h, err := NewHook(config.FollowHook{
+		Command: []string{"sh", "-c", `echo "$FALCOCTL_REF $FALCOCTL_NEW_DIGEST $FALCOCTL_DIRECTORY" > "$0"`, out},
+	})
+	require.NoError(t, err)
+	require.NoError(t, h.Run(context.Background(), testUpdate()))
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/falcosecurity/rules/falco-rules:3 sha256:2 /etc/falco\n", string(data))
+
+	h, err = NewHook(config.FollowHook{Command: []string{"sh", "-c", "echo reload failed; exit 1"}})
+	require.NoError(t, err)
+	assert.ErrorContains(t, h.Run(context.Background(), testUpdate()), "reload failed")
+
+	h, err = NewHook(config.FollowHook{Command: []string{"sleep", "5"}, Timeout: 10 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Error(t, h.Run(context.Background(), testUpdate()))
+}
+
+func TestSignalHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP is not supported")
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	pidFile := filepath.Join(t.TempDir(), "falco.pid")
+	require.NoError(t, os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o600))
+	h, err := NewHook(config.FollowHook{PIDFile: pidFile})
+	require.NoError(t, err)
+	require.NoError(t, h.Run(context.Background(), testUpdate()))
+
+	select {
+	case <-hup:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGHUP not received")
+	}
+
+	require.NoError(t, os.WriteFile(pidFile, []byte("not a pid"), 0o600))
+	assert.Error(t, h.Run(context.Background(), testUpdate()))
+}
+
+func TestHTTPHook(t *testing.T) {
+	var received Notification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	h, err := NewHook(config.FollowHook{URL: srv.URL})
+	require.NoError(t, err)
+	require.NoError(t, h.Run(context.Background(), testUpdate()))
+	assert.Equal(t, "sha256:2", received.NewDigest)
+	assert.Equal(t, ResultUpdated, received.Result)
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "falcoctl_follower"
+
+// Metrics records the activity of the followers as Prometheus metrics. A nil Metrics records nothing.
+type Metrics struct {
+	registry        *prometheus.Registry
+	updates         *prometheus.CounterVec
+	updateErrors    *prometheus.CounterVec
+	pullErrors      *prometheus.CounterVec
+	lastSync        *prometheus.GaugeVec
+	downloadedBytes *prometheus.CounterVec
+}
+
+// NewMetrics returns the follower metrics, registered along with the Go and process ones.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		updates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "updates_total",
+			Help:      "Number of new versions of the followed artifacts installed.",
+		}, []string{"ref"}),
+		updateErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "update_errors_total",
+			Help:      "Number of new versions of the followed artifacts that could not be installed.",
+		}, []string{"ref"}),
+		pullErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "pull_errors_total",
+			Help:      "Number of failed interactions with the registries of the followed artifacts.",
+		}, []string{"ref"}),
+		lastSync: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_sync_timestamp_seconds",
+			Help:      "Unix time of the last check that found the followed artifact up to date.",
+		}, []string{"ref"}),
+		downloadedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "downloaded_bytes_total",
+			Help:      "Size of the artifacts pulled by the followers.",
+		}, []string{"ref"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.updates, m.updateErrors, m.pullErrors, m.lastSync, m.downloadedBytes,
+	)
+	return m
+}
+
+// Handler returns the HTTP handler serving the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) updated(ref string) {
+	if m != nil {
+		m.updates.WithLabelValues(ref).Inc()
+		m.synced(ref)
+	}
+}
+
+func (m *Metrics) updateFailed(ref string) {
+	if m != nil {
+		m.updateErrors.WithLabelValues(ref).Inc()
+	}
+}
+
+func (m *Metrics) pullFailed(ref string) {
+	if m != nil {
+		m.pullErrors.WithLabelValues(ref).Inc()
+	}
+}
+
+func (m *Metrics) synced(ref string) {
+	if m != nil {
+		m.lastSync.WithLabelValues(ref).Set(float64(time.Now().Unix()))
+	}
+}
+
+func (m *Metrics) downloaded(ref string, size int64) {
+	if m != nil {
+		m.downloadedBytes.WithLabelValues(ref).Add(float64(size))
+	}
+}
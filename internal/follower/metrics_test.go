@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	const ref = "ghcr.io/falcosecurity/rules/falco-rules:3"
+	m := NewMetrics()
+
+	m.downloaded(ref, 1024)
+	m.downloaded(ref, 512)
+	m.updated(ref)
+	m.pullFailed(ref)
+	m.pullFailed(ref)
+	m.updateFailed(ref)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `falcoctl_follower_updates_total{ref="`+ref+`"} 1`)
+	assert.Contains(t, body, `falcoctl_follower_update_errors_total{ref="`+ref+`"} 1`)
+	assert.Contains(t, body, `falcoctl_follower_pull_errors_total{ref="`+ref+`"} 2`)
+	assert.Contains(t, body, `falcoctl_follower_downloaded_bytes_total{ref="`+ref+`"} 1536`)
+	assert.Contains(t, body, `falcoctl_follower_last_sync_timestamp_seconds{ref="`+ref+`"}`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestMetricsNil(t *testing.T) {
+	var m *Metrics
+	assert.NotPanics(t, func() {
+		m.updated("ref")
+		m.updateFailed("ref")
+		m.pullFailed("ref")
+		m.synced("ref")
+		m.downloaded("ref", 1)
+	})
+}
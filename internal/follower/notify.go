@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+const (
+	// ResultUpdated is the result of a successful update.
+	ResultUpdated = "updated"
+	// ResultFailed is the result of an update that could not be installed.
+	ResultFailed = "failed"
+
+	// DefaultWebhookTimeout is the time allowed to each webhook to answer.
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// Notification describes the outcome of an update attempt of a follower.
+type Notification struct {
+	// Ref is the followed reference.
+	Ref string `json:"ref"`
+	// OldDigest is the digest installed before the update, empty when unknown.
+	OldDigest string `json:"oldDigest,omitempty"`
+	// NewDigest is the digest of the new version.
+	NewDigest string `json:"newDigest"`
+	// Result is either ResultUpdated or ResultFailed.
+	Result string `json:"result"`
+	// Error is the reason of the failure, if any.
+	Error string `json:"error,omitempty"`
+	// Directory is where the artifact has been installed, if known.
+	Directory string `json:"directory,omitempty"`
+	// Time is when the update attempt completed.
+	Time time.Time `json:"time"`
+}
+
+// Notifier delivers the notifications of the followers.
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// Webhooks is a Notifier posting the notifications as JSON to each of its URLs.
+type Webhooks struct {
+	urls   []string
+	client *http.Client
+}
+
+// NewWebhooks returns a Notifier posting to urls, waiting up to timeout for each of them.
+func NewWebhooks(urls []string, timeout time.Duration) *Webhooks {
+	return &Webhooks{
+		urls:   urls,
+		client: &http.Client{Transport: utils.Transport(), Timeout: timeout},
+	}
+}
+
+// Notify posts n to all the webhooks, returning the errors of the ones that could not be notified.
+func (w *Webhooks) Notify(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, u := range w.urls {
+		if err := w.post(ctx, u, body); err != nil {
+			errs = append(errs, fmt.Errorf("unable to notify webhook %q: %w", u, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *Webhooks) post(ctx context.Context, u string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return nil
+}
+
+// failed records err as the reason of the failure of the update.
+func (n *Notification) failed(err error) {
+	n.Result = ResultFailed
+	n.Error = err.Error()
+}
+
+// notify delivers n, if a Notifier is configured. Delivery errors are only logged.
+func (f *Follower) notify(ctx context.Context, n *Notification) {
+	if f.Notifier == nil {
+		return
+	}
+	n.Time = time.Now().UTC()
+	if err := f.Notifier.Notify(ctx, n); err != nil {
+		f.logger.Warn("Unable to deliver notification", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+	}
+}
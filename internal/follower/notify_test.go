@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooksNotify(t *testing.T) {
+	var received []Notification
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var n Notification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		received = append(received, n)
+	}))
+	defer ok.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	n := &Notification{
+		Ref:       "ghcr.io/falcosecurity/rules/falco-rules:3",
+		OldDigest: "sha256:1",
+		NewDigest: "sha256:2",
+		Time:      time.Now().UTC(),
+	}
+	n.failed(errors.New("unmet requirements"))
+
+	err := NewWebhooks([]string{ok.URL, broken.URL, ok.URL}, time.Second).Notify(context.Background(), n)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, broken.URL)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, n.Ref, received[0].Ref)
+	assert.Equal(t, "sha256:1", received[0].OldDigest)
+	assert.Equal(t, "sha256:2", received[0].NewDigest)
+	assert.Equal(t, ResultFailed, received[0].Result)
+	assert.Equal(t, "unmet requirements", received[0].Error)
+}
+
+func TestWebhooksNotifySucceeded(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n := &Notification{Ref: "falco-rules", NewDigest: "sha256:2", Result: ResultUpdated, Directory: "/etc/falco"}
+	require.NoError(t, NewWebhooks([]string{srv.URL}, time.Second).Notify(context.Background(), n))
+
+	assert.Equal(t, ResultUpdated, body["result"])
+	assert.Equal(t, "/etc/falco", body["directory"])
+	assert.NotContains(t, body, "oldDigest")
+	assert.NotContains(t, body, "error")
+}
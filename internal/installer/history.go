@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+const (
+	versionFile     = "version.yaml"
+	versionFilesDir = "files"
+	versionDirTime  = "20060102T150405.000000000"
+)
+
+// ErrNoHistory is returned when rolling back an artifact without previous versions.
+var ErrNoHistory = errors.New("no previous version kept")
+
+// repositoryEscaper turns a repository into a directory name.
+var repositoryEscaper = strings.NewReplacer("/", "_", ":", "_")
+
+// History keeps a copy of the files of the last replaced versions of each artifact, so that they can be rolled back.
+// Each version lives in its own directory under <dir>/<repository>, holding the files and a version.yaml describing them.
+type History struct {
+	dir  string
+	keep int
+}
+
+// Version is a replaced version of an artifact, kept in the history.
+type Version struct {
+	Artifact `yaml:",inline"`
+	// ReplacedAt is the time the version has been replaced by another one.
+	ReplacedAt time.Time `yaml:"replacedAt"`
+	// path is the directory of the version in the history.
+	path string
+}
+
+// NewHistory returns a History storing in dir up to keep versions per artifact. A non positive keep disables it.
+func NewHistory(dir string, keep int) *History {
+	return &History{dir: dir, keep: keep}
+}
+
+// WithHistory keeps the versions replaced by the installs in h, making them available to Rollback.
+func WithHistory(h *History) Option {
+	return func(i *Installer) {
+		i.history = h
+	}
+}
+
+// Save copies the files of a into the history, as the most recent version of its repository,
+// then removes the older versions in excess. The files missing on disk are skipped.
+func (h *History) Save(a *Artifact) error {
+	if h == nil || h.keep <= 0 {
+		return nil
+	}
+
+	repoDir := filepath.Join(h.dir, repositoryEscaper.Replace(a.Repository))
+	if err := os.MkdirAll(repoDir, 0o700); err != nil {
+		return fmt.Errorf("cannot create history directory: %w", err)
+	}
+	now := time.Now().UTC()
+	versionDir, err := os.MkdirTemp(repoDir, now.Format(versionDirTime)+"-")
+	if err != nil {
+		return fmt.Errorf("cannot create history directory: %w", err)
+	}
+
+	v := Version{Artifact: *a, ReplacedAt: now}
+	v.Files = nil
+	for _, f := range a.Files {
+		src := filepath.Join(a.Directory, f.Path)
+		dst := filepath.Join(versionDir, versionFilesDir, f.Path)
+		copied, err := copyFile(src, dst)
+		if err != nil {
+			_ = os.RemoveAll(versionDir)
+			return fmt.Errorf("cannot save %q in history: %w", src, err)
+		} else if !copied {
+			continue
+		}
+		if f.Digest, err = fileDigest(dst); err != nil {
+			_ = os.RemoveAll(versionDir)
+			return err
+		}
+		f.Staged = ""
+		v.Files = append(v.Files, f)
+	}
+	if len(v.Files) == 0 {
+		return os.RemoveAll(versionDir)
+	}
+
+	// The version file is written last, a directory without it is an incomplete version.
+	data, err := yaml.Marshal(&v)
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(filepath.Join(versionDir, versionFile), data, 0o600); err != nil {
+		_ = os.RemoveAll(versionDir)
+		return fmt.Errorf("cannot save version in history: %w", err)
+	}
+
+	return h.prune(repoDir)
+}
+
+// Versions returns the versions kept for repository, the most recent first.
+func (h *History) Versions(repository string) ([]Version, error) {
+	versions, err := h.versions(filepath.Join(h.dir, repositoryEscaper.Replace(repository)))
+	if err != nil {
+		return nil, err
+	}
+	kept := versions[:0]
+	for _, v := range versions {
+		if v.Repository == repository {
+			kept = append(kept, v)
+		}
+	}
+	return kept, nil
+}
+
+// Resolve returns the repository with kept versions matching name,
+// which is either the repository itself or its last component.
+func (h *History) Resolve(name string) (string, error) {
+	entries, err := os.ReadDir(h.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w for %q", ErrNoHistory, name)
+	} else if err != nil {
+		return "", fmt.Errorf("unable to read history directory %q: %w", h.dir, err)
+	}
+
+	var matches []string
+	for _, e := range entries {
+		versions, err := h.versions(filepath.Join(h.dir, e.Name()))
+		if err != nil {
+			return "", err
+		}
+		for _, v := range versions {
+			if (v.Repository == name || path.Base(v.Repository) == name) && !slices.Contains(matches, v.Repository) {
+				matches = append(matches, v.Repository)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w for %q", ErrNoHistory, name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%q matches several artifacts, use one of %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// versions reads the complete versions found in repoDir, the most recent first.
+func (h *History) versions(repoDir string) ([]Version, error) {
+	entries, err := os.ReadDir(repoDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read history directory %q: %w", repoDir, err)
+	}
+
+	var versions []Version
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		versionDir := filepath.Join(repoDir, e.Name())
+		data, err := os.ReadFile(filepath.Clean(filepath.Join(versionDir, versionFile)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to read version in history: %w", err)
+		}
+		v := Version{path: versionDir}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("unable to parse version %q in history: %w", versionDir, err)
+		}
+		versions = append(versions, v)
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return filepath.Base(versions[i].path) > filepath.Base(versions[j].path)
+	})
+	return versions, nil
+}
+
+// prune removes the versions in repoDir beyond the ones to keep, and the incomplete ones.
+func (h *History) prune(repoDir string) error {
+	versions, err := h.versions(repoDir)
+	if err != nil {
+		return err
+	}
+	kept := make(map[string]bool, h.keep)
+	for k := 0; k < len(versions) && k < h.keep; k++ {
+		kept[filepath.Base(versions[k].path)] = true
+	}
+
+	entries, err := os.ReadDir(repoDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !kept[e.Name()] {
+			if err := os.RemoveAll(filepath.Join(repoDir, e.Name())); err != nil {
+				return fmt.Errorf("unable to prune history: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// drop removes v from the history.
+func (h *History) drop(v *Version) error {
+	if err := os.RemoveAll(v.path); err != nil {
+		return fmt.Errorf("unable to remove version from history: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies the regular file src to dst, creating its parent directories.
+// It returns false if src does not exist.
+func copyFile(src, dst string) (bool, error) {
+	in, err := os.Open(filepath.Clean(src))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return false, err
+	}
+	out, err := os.OpenFile(filepath.Clean(dst), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return false, err
+	}
+	return true, out.Close()
+}
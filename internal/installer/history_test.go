@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHistoryInstaller(t *testing.T, keep int) (inst *Installer, destDir string) {
+	inst, destDir = newTestInstaller(t)
+	WithHistory(NewHistory(filepath.Join(t.TempDir(), "history"), keep))(inst)
+	return inst, destDir
+}
+
+func TestHistorySave(t *testing.T) {
+	destDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "rules.yaml"), []byte("v1"), 0o600))
+	h := NewHistory(t.TempDir(), 2)
+
+	a := testArtifact(destDir, "sha256:1")
+	a.Files = []File{{Path: "rules.yaml"}, {Path: "missing.yaml"}}
+	for _, digest := range []string{"sha256:1", "sha256:2", "sha256:3"} {
+		a.Digest = digest
+		require.NoError(t, h.Save(&a))
+	}
+
+	versions, err := h.Versions(a.Repository)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "sha256:3", versions[0].Digest)
+	assert.Equal(t, "sha256:2", versions[1].Digest)
+	require.Len(t, versions[0].Files, 1)
+	assert.Equal(t, "rules.yaml", versions[0].Files[0].Path)
+	assert.Equal(t, "v1", readFile(t, filepath.Join(versions[0].path, versionFilesDir, "rules.yaml")))
+
+	entries, err := os.ReadDir(filepath.Dir(versions[0].path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	destDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "rules.yaml"), []byte("v1"), 0o600))
+	dir := filepath.Join(t.TempDir(), "history")
+	h := NewHistory(dir, 0)
+
+	a := testArtifact(destDir, "sha256:1")
+	a.Files = []File{{Path: "rules.yaml"}}
+	require.NoError(t, h.Save(&a))
+	assert.NoDirExists(t, dir)
+}
+
+func TestHistoryResolve(t *testing.T) {
+	destDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "rules.yaml"), []byte("v1"), 0o600))
+	h := NewHistory(t.TempDir(), 1)
+
+	for _, repository := range []string{
+		"ghcr.io/falcosecurity/rules/falco-rules",
+		"ghcr.io/falcosecurity/plugins/ruleset/k8saudit-rules",
+		"example.com/mirror/k8saudit-rules",
+	} {
+		a := testArtifact(destDir, "sha256:1")
+		a.Repository = repository
+		a.Files = []File{{Path: "rules.yaml"}}
+		require.NoError(t, h.Save(&a))
+	}
+
+	repository, err := h.Resolve("falco-rules")
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/falcosecurity/rules/falco-rules", repository)
+
+	repository, err = h.Resolve("example.com/mirror/k8saudit-rules")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/mirror/k8saudit-rules", repository)
+
+	_, err = h.Resolve("k8saudit-rules")
+	assert.ErrorContains(t, err, "matches several artifacts")
+
+	_, err = h.Resolve("unknown")
+	assert.ErrorIs(t, err, ErrNoHistory)
+}
+
+func TestRollback(t *testing.T) {
+	inst, destDir := newTestHistoryInstaller(t, 3)
+	ctx := context.Background()
+
+	_, err := inst.Install(ctx, testArtifact(destDir, "sha256:1"), tarball(t, map[string]string{"rules.yaml": "v1"}))
+	require.NoError(t, err)
+	_, err = inst.Install(ctx, testArtifact(destDir, "sha256:2"), tarball(t, map[string]string{"rules.yaml": "v2"}))
+	require.NoError(t, err)
+	_, err = inst.Install(ctx, testArtifact(destDir, "sha256:3"),
+		tarball(t, map[string]string{"rules.yaml": "v3", "extra.yaml": "extra"}))
+	require.NoError(t, err)
+
+	// Reinstalling the same digest does not keep a version.
+	_, err = inst.Install(ctx, testArtifact(destDir, "sha256:3"),
+		tarball(t, map[string]string{"rules.yaml": "v3", "extra.yaml": "extra"}))
+	require.NoError(t, err)
+
+	v, err := inst.Rollback("falco-rules")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:2", v.Digest)
+	assert.Equal(t, "v2", readFile(t, filepath.Join(destDir, "rules.yaml")))
+	assert.NoFileExists(t, filepath.Join(destDir, "extra.yaml"))
+	assertNoStagingDir(t, destDir)
+	assert.NoFileExists(t, inst.journalFile)
+
+	m, err := LoadManifest(inst.manifestFile)
+	require.NoError(t, err)
+	recorded, ok := m.Get(v.Repository)
+	require.True(t, ok)
+	assert.Equal(t, "sha256:2", recorded.Digest)
+	assert.Len(t, recorded.Files, 1)
+
+	// Rolling back again restores the version before.
+	v, err = inst.Rollback(v.Repository)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:1", v.Digest)
+	assert.Equal(t, "v1", readFile(t, filepath.Join(destDir, "rules.yaml")))
+
+	_, err = inst.Rollback("falco-rules")
+	assert.ErrorIs(t, err, ErrNoHistory)
+}
+
+func TestRollbackUntracked(t *testing.T) {
+	inst, destDir := newTestHistoryInstaller(t, 3)
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "rules.yaml"), []byte("v1"), 0o600))
+
+	// Versions saved by the follower are not recorded in the install manifest.
+	a := testArtifact(destDir, "")
+	a.Files = []File{{Path: "rules.yaml"}}
+	require.NoError(t, inst.history.Save(&a))
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "rules.yaml"), []byte("v2"), 0o600))
+
+	_, err := inst.Rollback("falco-rules")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", readFile(t, filepath.Join(destDir, "rules.yaml")))
+
+	m, err := LoadManifest(inst.manifestFile)
+	require.NoError(t, err)
+	assert.Empty(t, m.Artifacts)
+}
+
+func TestRollbackPendingInstall(t *testing.T) {
+	inst, destDir := newTestHistoryInstaller(t, 3)
+	inst.interrupt = func(step string) error {
+		return errCrash
+	}
+	_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"), tarball(t, map[string]string{"rules.yaml": "v1"}))
+	require.ErrorIs(t, err, errCrash)
+
+	_, err = inst.Rollback("falco-rules")
+	assert.ErrorIs(t, err, ErrPendingInstall)
+}
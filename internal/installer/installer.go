@@ -0,0 +1,477 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+const (
+	// phaseStaged means the artifact is being extracted in the staging directory. Recovering rolls it back.
+	phaseStaged = "staged"
+	// phaseSwapping means the files are being moved into place. Recovering completes it.
+	phaseSwapping = "swapping"
+
+	stagingDirPrefix = ".falcoctl-staging-"
+	// backupDir is where, inside the staging directory, the files replaced by the install are kept
+	// until it is committed.
+	backupDir = ".falcoctl-backup"
+)
+
+var (
+	// ErrPendingInstall is returned when an interrupted install has not been recovered yet.
+	ErrPendingInstall = errors.New("an interrupted install is pending")
+	// ErrDigestMismatch is returned when a file moved into place does not match the extracted one.
+	ErrDigestMismatch = errors.New("digest mismatch")
+
+	// errInterrupted wraps the errors returned by the interrupt hook.
+	errInterrupted = errors.New("install interrupted")
+)
+
+// journal is the write-ahead log of the install in progress.
+type journal struct {
+	Phase string `yaml:"phase"`
+	entry `yaml:",inline"`
+	// Next are the artifacts installed in the same transaction after Artifact, in order.
+	Next []entry `yaml:"next,omitempty"`
+}
+
+// entry is an artifact of the install in progress.
+type entry struct {
+	StagingDir string   `yaml:"stagingDir"`
+	Artifact   Artifact `yaml:"artifact"`
+	// Untracked is set when the artifact is not to be recorded in the install manifest.
+	Untracked bool `yaml:"untracked,omitempty"`
+}
+
+// entries returns the artifacts of the install in progress, in order.
+func (j *journal) entries() []*entry {
+	if j.StagingDir == "" {
+		return nil
+	}
+	entries := []*entry{&j.entry}
+	for k := range j.Next {
+		entries = append(entries, &j.Next[k])
+	}
+	return entries
+}
+
+// add appends e to the artifacts of the install in progress and returns it.
+func (j *journal) add(e entry) *entry {
+	if j.StagingDir == "" {
+		j.entry = e
+		return &j.entry
+	}
+	j.Next = append(j.Next, e)
+	return &j.Next[len(j.Next)-1]
+}
+
+// Pending is an artifact to be installed from the gzip compressed tarball of its files.
+type Pending struct {
+	Artifact Artifact
+	Tarball  io.Reader
+}
+
+// Installer installs artifacts transactionally: the artifact is extracted in a staging
+// directory created inside the destination one, then its files are renamed into place.
+// Each step is recorded in a journal, so that an interrupted install is either rolled back
+// or completed by Recover. If moving a file into place fails, the files already replaced
+// are restored. The install manifest is only updated after the files are in place.
+type Installer struct {
+	manifestFile  string
+	journalFile   string
+	mergeStrategy MergeStrategy
+	history       *History
+	// interrupt, when set, is invoked after each install step. A non nil error stops
+	// the install right away, leaving things as a crash would. Used by tests.
+	interrupt func(step string) error
+}
+
+// Recovered describes an interrupted install handled by Recover.
+type Recovered struct {
+	Artifact   Artifact
+	RolledBack bool
+}
+
+// New returns a new Installer that records the installed artifacts in manifestFile
+// and the install in progress in journalFile.
+func New(manifestFile, journalFile string, opts ...Option) *Installer {
+	i := &Installer{
+		manifestFile:  manifestFile,
+		journalFile:   journalFile,
+		mergeStrategy: MergeFail,
+	}
+	for _, o := range opts {
+		o(i)
+	}
+	return i
+}
+
+// Recover rolls back or completes an install that was interrupted before being committed.
+// It returns nil if there was nothing to recover.
+func (i *Installer) Recover() (*Recovered, error) {
+	j, err := i.readJournal()
+	if err != nil || j == nil {
+		return nil, err
+	}
+
+	switch j.Phase {
+	case phaseStaged:
+		if err := i.rollback(j); err != nil {
+			return nil, err
+		}
+		return &Recovered{Artifact: j.Artifact, RolledBack: true}, nil
+	case phaseSwapping:
+		if err := i.swap(j); err != nil {
+			return nil, err
+		}
+		installed, err := i.commit(j)
+		if err != nil {
+			return nil, err
+		}
+		return &Recovered{Artifact: *installed[0]}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized phase %q in install journal %q", j.Phase, i.journalFile)
+	}
+}
+
+// Install extracts the gzip compressed tarball of the artifact in a.Directory.
+// It returns the artifact as recorded in the install manifest.
+func (i *Installer) Install(ctx context.Context, a Artifact, tarball io.Reader) (*Artifact, error) {
+	installed, err := i.InstallAll(ctx, []Pending{{Artifact: a, Tarball: tarball}})
+	if err != nil {
+		return nil, err
+	}
+	return installed[0], nil
+}
+
+// InstallAll installs the pending artifacts in a single transaction: all of them are extracted
+// in their staging directory before any file is moved into place, so that either all of them
+// or none are installed. Files shipped by more than one of them follow the merge strategy, as
+// if they were installed one after the other. It returns the artifacts as recorded in the
+// install manifest, in order.
+func (i *Installer) InstallAll(ctx context.Context, pending []Pending) ([]*Artifact, error) {
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	if j, err := i.readJournal(); err != nil {
+		return nil, err
+	} else if j != nil {
+		return nil, fmt.Errorf("%w for %q", ErrPendingInstall, j.Artifact.Ref)
+	}
+
+	// The manifest as it will be once the artifacts staged so far are installed.
+	m, err := LoadManifest(i.manifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &journal{Phase: phaseStaged}
+	for _, p := range pending {
+		stagingDir, err := os.MkdirTemp(p.Artifact.Directory, stagingDirPrefix)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("cannot create staging directory: %w", err), i.rollback(j))
+		}
+		e := j.add(entry{StagingDir: stagingDir, Artifact: p.Artifact})
+		if err := i.writeJournal(j); err != nil {
+			return nil, errors.Join(err, i.rollback(j))
+		}
+
+		if _, err := utils.ExtractTarGz(ctx, p.Tarball, stagingDir, 0); err != nil {
+			return nil, errors.Join(fmt.Errorf("cannot extract artifact to %q: %w", stagingDir, err), i.rollback(j))
+		}
+		if e.Artifact.Files, err = stagedFiles(stagingDir); err != nil {
+			return nil, errors.Join(err, i.rollback(j))
+		}
+		if err := i.resolveCollisions(m, &e.Artifact); err != nil {
+			return nil, errors.Join(err, i.rollback(j))
+		}
+		if err := i.saveReplaced(&e.Artifact); err != nil {
+			return nil, errors.Join(err, i.rollback(j))
+		}
+		disown(m, &e.Artifact)
+		m.Upsert(e.Artifact)
+	}
+	if err := i.step(phaseStaged); err != nil {
+		return nil, err
+	}
+
+	j.Phase = phaseSwapping
+	if err := i.writeJournal(j); err != nil {
+		return nil, errors.Join(err, i.rollback(j))
+	}
+	if err := i.swap(j); err != nil {
+		return nil, i.abort(j, err)
+	}
+	return i.commit(j)
+}
+
+func (i *Installer) step(name string) error {
+	if i.interrupt == nil {
+		return nil
+	}
+	if err := i.interrupt(name); err != nil {
+		return fmt.Errorf("%w: %w", errInterrupted, err)
+	}
+	return nil
+}
+
+// abort handles the failure of swap: the replaced files are restored and the install is rolled back.
+// An interrupted install is left as is instead, the next run will complete it.
+func (i *Installer) abort(j *journal, err error) error {
+	if errors.Is(err, errInterrupted) {
+		return err
+	}
+	if rerr := i.restore(j); rerr != nil {
+		// Leave the journal in place, the next run will complete the install.
+		return errors.Join(err, rerr)
+	}
+	return errors.Join(err, i.rollback(j))
+}
+
+// paths returns where f is staged, installed and backed up.
+func (e *entry) paths(f File) (src, dst, backup string) {
+	staged := f.Path
+	if f.Staged != "" {
+		staged = f.Staged
+	}
+	return filepath.Join(e.StagingDir, staged),
+		filepath.Join(e.Artifact.Directory, f.Path),
+		filepath.Join(e.StagingDir, backupDir, f.Path)
+}
+
+// swap renames the staged files into the destination directories, moving the files they replace
+// to the backup directory. It can be safely re-run: files no longer in the staging directories
+// have already been moved.
+func (i *Installer) swap(j *journal) error {
+	for _, e := range j.entries() {
+		for _, f := range e.Artifact.Files {
+			src, dst, backup := e.paths(f)
+			if _, err := os.Lstat(src); errors.Is(err, os.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if err := replaceFile(src, dst, backup, f.Digest); err != nil {
+				return err
+			}
+			if err := i.step("swapped " + f.Path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restore undoes swap, in reverse order: the files moved into place are taken back to the
+// staging directories and the files they replaced are moved back from the backup directory.
+func (i *Installer) restore(j *journal) error {
+	entries := j.entries()
+	for k := len(entries) - 1; k >= 0; k-- {
+		e := entries[k]
+		for n := len(e.Artifact.Files) - 1; n >= 0; n-- {
+			src, dst, backup := e.paths(e.Artifact.Files[n])
+			if err := restoreFile(src, dst, backup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replaceFile renames src to dst, first moving the existing dst, if any, to backup.
+// If digest is set, the file moved into place is checked against it.
+func replaceFile(src, dst, backup, digest string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		if err := os.MkdirAll(filepath.Dir(backup), 0o700); err != nil {
+			return err
+		}
+		if err := os.Rename(dst, backup); err != nil {
+			return fmt.Errorf("cannot back up %q: %w", dst, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("cannot move %q to %q: %w", src, dst, err)
+	}
+	if digest == "" {
+		return nil
+	}
+	if d, err := fileDigest(dst); err != nil {
+		return err
+	} else if d != digest {
+		return fmt.Errorf("%w for %q: expected %s, got %s", ErrDigestMismatch, dst, digest, d)
+	}
+	return nil
+}
+
+// restoreFile undoes replaceFile, whatever step it stopped at.
+func restoreFile(src, dst, backup string) error {
+	if _, err := os.Lstat(src); errors.Is(err, os.ErrNotExist) {
+		// The file has been moved into place, take it back.
+		if err := os.Rename(dst, src); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot restore %q: %w", dst, err)
+		}
+	} else if err != nil {
+		return err
+	}
+	if err := os.Rename(backup, dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot restore %q: %w", dst, err)
+	}
+	return nil
+}
+
+// commit records the artifacts in the install manifest and removes the journal.
+func (i *Installer) commit(j *journal) ([]*Artifact, error) {
+	m, err := LoadManifest(i.manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		installed []*Artifact
+		// stale are the files of the previous versions no longer shipped by the installed ones.
+		stale []string
+	)
+	tracked := false
+	for _, e := range j.entries() {
+		a := e.Artifact
+		a.InstalledAt = time.Now().UTC()
+		a.Files = make([]File, len(e.Artifact.Files))
+		for k, f := range e.Artifact.Files {
+			f.Staged = ""
+			a.Files[k] = f
+		}
+		if !e.Untracked {
+			if prev, ok := m.Get(a.Repository); ok {
+				for _, f := range staleFiles(prev, &a) {
+					stale = append(stale, filepath.Join(prev.Directory, f.Path))
+				}
+			}
+			disown(m, &a)
+			m.Upsert(a)
+			tracked = true
+		}
+		installed = append(installed, &a)
+	}
+	if tracked {
+		if err := m.Write(i.manifestFile); err != nil {
+			return nil, fmt.Errorf("unable to write install manifest %q: %w", i.manifestFile, err)
+		}
+	}
+	owned := make(map[string]bool)
+	for _, a := range m.Artifacts {
+		for _, f := range a.Files {
+			owned[filepath.Join(a.Directory, f.Path)] = true
+		}
+	}
+	for _, p := range stale {
+		if owned[p] {
+			// Installed by another artifact in the meantime.
+			continue
+		}
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("cannot remove %q: %w", p, err)
+		}
+	}
+	if err := os.Remove(i.journalFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range j.entries() {
+		if err := os.RemoveAll(e.StagingDir); err != nil {
+			return nil, err
+		}
+	}
+	return installed, nil
+}
+
+// rollback removes the staging directories and the journal, leaving the destinations untouched.
+func (i *Installer) rollback(j *journal) error {
+	for _, e := range j.entries() {
+		if err := os.RemoveAll(e.StagingDir); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(i.journalFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (i *Installer) readJournal() (*journal, error) {
+	data, err := os.ReadFile(filepath.Clean(i.journalFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read install journal %q: %w", i.journalFile, err)
+	}
+	j := &journal{}
+	if err := yaml.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("unable to parse install journal %q: %w", i.journalFile, err)
+	}
+	return j, nil
+}
+
+func (i *Installer) writeJournal(j *journal) error {
+	data, err := yaml.Marshal(j)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(i.journalFile), 0o700); err != nil {
+		return fmt.Errorf("unable to write install journal %q: %w", i.journalFile, err)
+	}
+	if err := utils.WriteFileAtomic(i.journalFile, data, 0o600); err != nil {
+		return fmt.Errorf("unable to write install journal %q: %w", i.journalFile, err)
+	}
+	return nil
+}
+
+// stagedFiles lists the non directory entries extracted in the staging directory.
+func stagedFiles(stagingDir string) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(stagingDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(stagingDir, path)
+		if err != nil {
+			return err
+		}
+		f := File{Path: rel}
+		if d.Type().IsRegular() {
+			if f.Digest, err = fileDigest(path); err != nil {
+				return err
+			}
+		}
+		files = append(files, f)
+		return nil
+	})
+	return files, err
+}
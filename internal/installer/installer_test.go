@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errCrash = errors.New("crash")
+
+// tarball returns a gzip compressed tarball containing the given files.
+func tarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for name := range files {
+		if dir := filepath.Dir(name); dir != "." {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: dir + "/", Mode: 0o755, Typeflag: tar.TypeDir}))
+		}
+	}
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf
+}
+
+func newTestInstaller(t *testing.T) (inst *Installer, destDir string) {
+	stateDir := t.TempDir()
+	destDir = t.TempDir()
+	inst = New(filepath.Join(stateDir, "installed.yaml"), filepath.Join(stateDir, "install.journal"))
+	return inst, destDir
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func assertNoStagingDir(t *testing.T, destDir string) {
+	entries, err := os.ReadDir(destDir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.False(t, strings.HasPrefix(e.Name(), stagingDirPrefix), "staging directory %q left behind", e.Name())
+	}
+}
+
+func testArtifact(destDir, digest string) Artifact {
+	return Artifact{
+		Repository: "ghcr.io/falcosecurity/rules/falco-rules",
+		Ref:        "ghcr.io/falcosecurity/rules/falco-rules:latest",
+		Digest:     digest,
+		Type:       "rulesfile",
+		Directory:  destDir,
+	}
+}
+
+func TestInstall(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "falco.yaml"), []byte("untouched"), 0o600))
+
+	a, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"),
+		tarball(t, map[string]string{"rules.yaml": "new", "sub/more.yaml": "more"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "new", readFile(t, filepath.Join(destDir, "rules.yaml")))
+	assert.Equal(t, "more", readFile(t, filepath.Join(destDir, "sub", "more.yaml")))
+	assert.Equal(t, "untouched", readFile(t, filepath.Join(destDir, "falco.yaml")))
+	assertNoStagingDir(t, destDir)
+	assert.NoFileExists(t, inst.journalFile)
+
+	m, err := LoadManifest(inst.manifestFile)
+	require.NoError(t, err)
+	recorded, ok := m.Get(a.Repository)
+	require.True(t, ok)
+	assert.Equal(t, "sha256:1", recorded.Digest)
+	assert.Len(t, recorded.Files, 2)
+	for _, f := range recorded.Files {
+		assert.True(t, strings.HasPrefix(f.Digest, "sha256:"))
+	}
+}
+
+func TestInstallInterrupted(t *testing.T) {
+	testCases := []struct {
+		name             string
+		crashAt          string
+		expectRolledBack bool
+		expectedContent  string
+	}{
+		{
+			name:             "during staging is rolled back",
+			crashAt:          phaseStaged,
+			expectRolledBack: true,
+			expectedContent:  "old",
+		},
+		{
+			name:            "during swap is completed",
+			crashAt:         "swapped a.yaml",
+			expectedContent: "new",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inst, destDir := newTestInstaller(t)
+
+			// First install, that will be updated.
+			_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:old"),
+				tarball(t, map[string]string{"a.yaml": "old", "b.yaml": "old"}))
+			require.NoError(t, err)
+
+			inst.interrupt = func(step string) error {
+				if step == tc.crashAt {
+					return errCrash
+				}
+				return nil
+			}
+			_, err = inst.Install(context.Background(), testArtifact(destDir, "sha256:new"),
+				tarball(t, map[string]string{"a.yaml": "new", "b.yaml": "new"}))
+			require.ErrorIs(t, err, errCrash)
+			assert.FileExists(t, inst.journalFile)
+
+			// A new install is refused until the interrupted one is recovered.
+			_, err = inst.Install(context.Background(), testArtifact(destDir, "sha256:new"), tarball(t, nil))
+			require.ErrorIs(t, err, ErrPendingInstall)
+
+			// Re-run, as a new process would do.
+			inst = New(inst.manifestFile, inst.journalFile)
+			recovered, err := inst.Recover()
+			require.NoError(t, err)
+			require.NotNil(t, recovered)
+			assert.Equal(t, tc.expectRolledBack, recovered.RolledBack)
+			assert.Equal(t, "sha256:new", recovered.Artifact.Digest)
+
+			// Both files come from the same install, never a mix of the two.
+			assert.Equal(t, tc.expectedContent, readFile(t, filepath.Join(destDir, "a.yaml")))
+			assert.Equal(t, tc.expectedContent, readFile(t, filepath.Join(destDir, "b.yaml")))
+			assertNoStagingDir(t, destDir)
+			assert.NoFileExists(t, inst.journalFile)
+
+			m, err := LoadManifest(inst.manifestFile)
+			require.NoError(t, err)
+			recorded, ok := m.Get(recovered.Artifact.Repository)
+			require.True(t, ok)
+			assert.Equal(t, "sha256:"+tc.expectedContent, recorded.Digest)
+
+			// Nothing left to recover.
+			recovered, err = inst.Recover()
+			require.NoError(t, err)
+			assert.Nil(t, recovered)
+		})
+	}
+}
+
+func TestInstallExtractionFailure(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+
+	_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"), bytes.NewBufferString("not a tarball"))
+	require.Error(t, err)
+	assertNoStagingDir(t, destDir)
+	assert.NoFileExists(t, inst.journalFile)
+	assert.NoFileExists(t, inst.manifestFile)
+}
+
+func TestInstallFailureRestoresFiles(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+	_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"),
+		tarball(t, map[string]string{"a.yaml": "a1", "b.yaml": "b1"}))
+	require.NoError(t, err)
+
+	// Tamper with a staged file, so that it fails the verification once in place.
+	inst.interrupt = func(step string) error {
+		if step == phaseStaged {
+			staged, err := filepath.Glob(filepath.Join(destDir, stagingDirPrefix+"*", "b.yaml"))
+			require.NoError(t, err)
+			require.Len(t, staged, 1)
+			require.NoError(t, os.WriteFile(staged[0], []byte("tampered"), 0o600))
+		}
+		return nil
+	}
+	_, err = inst.Install(context.Background(), testArtifact(destDir, "sha256:2"),
+		tarball(t, map[string]string{"a.yaml": "a2", "b.yaml": "b2", "c.yaml": "c2"}))
+	require.ErrorIs(t, err, ErrDigestMismatch)
+
+	// The files replaced before the failure are restored, the new ones removed.
+	assert.Equal(t, "a1", readFile(t, filepath.Join(destDir, "a.yaml")))
+	assert.Equal(t, "b1", readFile(t, filepath.Join(destDir, "b.yaml")))
+	assert.NoFileExists(t, filepath.Join(destDir, "c.yaml"))
+	assertNoStagingDir(t, destDir)
+	assert.NoFileExists(t, inst.journalFile)
+
+	m, err := LoadManifest(inst.manifestFile)
+	require.NoError(t, err)
+	recorded, ok := m.Get("ghcr.io/falcosecurity/rules/falco-rules")
+	require.True(t, ok)
+	assert.Equal(t, "sha256:1", recorded.Digest)
+}
+
+func testDependency(destDir, digest string) Artifact {
+	return Artifact{
+		Repository: "ghcr.io/falcosecurity/plugins/plugin/json",
+		Ref:        "ghcr.io/falcosecurity/plugins/plugin/json:0.7.0",
+		Digest:     digest,
+		Type:       "plugin",
+		Directory:  destDir,
+	}
+}
+
+func TestInstallAll(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+
+	installed, err := inst.InstallAll(context.Background(), []Pending{
+		{Artifact: testDependency(destDir, "sha256:1"), Tarball: tarball(t, map[string]string{"libjson.so": "json"})},
+		{Artifact: testArtifact(destDir, "sha256:2"), Tarball: tarball(t, map[string]string{"rules.yaml": "rules"})},
+	})
+	require.NoError(t, err)
+	require.Len(t, installed, 2)
+	assert.Equal(t, "ghcr.io/falcosecurity/plugins/plugin/json", installed[0].Repository)
+	assert.Equal(t, "json", readFile(t, filepath.Join(destDir, "libjson.so")))
+	assert.Equal(t, "rules", readFile(t, filepath.Join(destDir, "rules.yaml")))
+	assertNoStagingDir(t, destDir)
+	assert.NoFileExists(t, inst.journalFile)
+
+	m, err := LoadManifest(inst.manifestFile)
+	require.NoError(t, err)
+	assert.Len(t, m.Artifacts, 2)
+}
+
+func TestInstallAllFailure(t *testing.T) {
+	testCases := []struct {
+		name     string
+		second   *bytes.Buffer
+		expected error
+	}{
+		{
+			name:   "extraction of the second artifact",
+			second: bytes.NewBufferString("not a tarball"),
+		},
+		{
+			name:     "collision between the artifacts",
+			second:   tarball(t, map[string]string{"libjson.so": "other"}),
+			expected: ErrFileCollision,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inst, destDir := newTestInstaller(t)
+
+			_, err := inst.InstallAll(context.Background(), []Pending{
+				{Artifact: testDependency(destDir, "sha256:1"), Tarball: tarball(t, map[string]string{"libjson.so": "json"})},
+				{Artifact: testArtifact(destDir, "sha256:2"), Tarball: tc.second},
+			})
+			require.Error(t, err)
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, tc.expected)
+			}
+			// Nothing is installed, not even the first artifact.
+			assert.NoFileExists(t, filepath.Join(destDir, "libjson.so"))
+			assertNoStagingDir(t, destDir)
+			assert.NoFileExists(t, inst.journalFile)
+			assert.NoFileExists(t, inst.manifestFile)
+		})
+	}
+}
+
+func TestInstallAllInterrupted(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+	inst.interrupt = func(step string) error {
+		if step == "swapped libjson.so" {
+			return errCrash
+		}
+		return nil
+	}
+
+	_, err := inst.InstallAll(context.Background(), []Pending{
+		{Artifact: testDependency(destDir, "sha256:1"), Tarball: tarball(t, map[string]string{"libjson.so": "json"})},
+		{Artifact: testArtifact(destDir, "sha256:2"), Tarball: tarball(t, map[string]string{"rules.yaml": "rules"})},
+	})
+	require.ErrorIs(t, err, errCrash)
+	assert.NoFileExists(t, filepath.Join(destDir, "rules.yaml"))
+
+	inst.interrupt = nil
+	recovered, err := inst.Recover()
+	require.NoError(t, err)
+	require.NotNil(t, recovered)
+	assert.False(t, recovered.RolledBack)
+	assert.Equal(t, "rules", readFile(t, filepath.Join(destDir, "rules.yaml")))
+	assertNoStagingDir(t, destDir)
+
+	m, err := LoadManifest(inst.manifestFile)
+	require.NoError(t, err)
+	assert.Len(t, m.Artifacts, 2)
+}
+
+func TestRecord(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+	_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"),
+		tarball(t, map[string]string{"rules.yaml": "rules", "shared.yaml": "shared"}))
+	require.NoError(t, err)
+
+	// An artifact updated outside of the installer takes the ownership of its files.
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "shared.yaml"), []byte("updated"), 0o600))
+	a := testDependency(destDir, "sha256:2")
+	a.Version = "0.7.0"
+	a.Files = []File{{Path: "shared.yaml"}}
+	require.NoError(t, Record(inst.manifestFile, a))
+
+	m, err := LoadManifest(inst.manifestFile)
+	require.NoError(t, err)
+	recorded, ok := m.Get(a.Repository)
+	require.True(t, ok)
+	assert.Equal(t, "0.7.0", recorded.Version)
+	require.Len(t, recorded.Files, 1)
+	digest, err := fileDigest(filepath.Join(destDir, "shared.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, digest, recorded.Files[0].Digest)
+	assert.Equal(t, []string{"rules.yaml"}, recordedFiles(t, m, testArtifact(destDir, "").Repository))
+}
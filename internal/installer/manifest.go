@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+// ErrNotInstalled is returned when an artifact is not recorded in the install manifest.
+var ErrNotInstalled = errors.New("artifact not installed")
+
+// Manifest records the artifacts installed on the local filesystem.
+type Manifest struct {
+	Artifacts []Artifact `yaml:"artifacts"`
+}
+
+// Artifact is an artifact recorded in the install manifest.
+type Artifact struct {
+	// Repository is the registry and repository of the artifact, used to identify it.
+	Repository string `yaml:"repository"`
+	// Ref is the reference the artifact has been installed from.
+	Ref string `yaml:"ref"`
+	// Digest is the digest of the installed artifact.
+	Digest string `yaml:"digest"`
+	// Type is the artifact type.
+	Type string `yaml:"type"`
+	// Version is the version of the artifact, as set in its config layer.
+	Version string `yaml:"version,omitempty"`
+	// Directory is where the artifact files have been installed.
+	Directory string `yaml:"directory"`
+	// Files are the installed files, relative to Directory.
+	Files []File `yaml:"files"`
+	// InstalledAt is the time the install has been committed.
+	InstalledAt time.Time `yaml:"installedAt"`
+}
+
+// File is a file installed by an artifact.
+type File struct {
+	// Path is the on-disk path of the file, relative to the artifact Directory.
+	Path   string `yaml:"path"`
+	Digest string `yaml:"digest,omitempty"`
+	// Staged is the path of the file in the staging directory, when it differs from Path.
+	// It is only set while the install is in progress.
+	Staged string `yaml:"staged,omitempty"`
+}
+
+// LoadManifest reads the install manifest from path. A missing file is an empty manifest.
+func LoadManifest(path string) (*Manifest, error) {
+	m := &Manifest{}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read install manifest %q: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("unable to parse install manifest %q: %w", path, err)
+	}
+	return m, nil
+}
+
+// Get returns the artifact recorded for the given repository, if any.
+func (m *Manifest) Get(repository string) (*Artifact, bool) {
+	for i := range m.Artifacts {
+		if m.Artifacts[i].Repository == repository {
+			return &m.Artifacts[i], true
+		}
+	}
+	return nil, false
+}
+
+// Resolve returns the artifact recorded for ref, which is either its repository, possibly with a tag
+// or digest, or the last component of it.
+func (m *Manifest) Resolve(ref string) (*Artifact, error) {
+	name, err := utils.RepositoryFromRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*Artifact
+	for i := range m.Artifacts {
+		a := &m.Artifacts[i]
+		if a.Repository == name || path.Base(a.Repository) == name {
+			matches = append(matches, a)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %q", ErrNotInstalled, ref)
+	case 1:
+		return matches[0], nil
+	default:
+		repositories := make([]string, len(matches))
+		for i, a := range matches {
+			repositories[i] = a.Repository
+		}
+		return nil, fmt.Errorf("%q matches several artifacts, use one of %s", ref, strings.Join(repositories, ", "))
+	}
+}
+
+// Remove removes the artifact recorded for the given repository, if any.
+func (m *Manifest) Remove(repository string) {
+	m.Artifacts = slices.DeleteFunc(m.Artifacts, func(a Artifact) bool {
+		return a.Repository == repository
+	})
+}
+
+// Upsert records the artifact, replacing the one previously installed from the same repository.
+func (m *Manifest) Upsert(a Artifact) {
+	for i := range m.Artifacts {
+		if m.Artifacts[i].Repository == a.Repository {
+			m.Artifacts[i] = a
+			return
+		}
+	}
+	m.Artifacts = append(m.Artifacts, a)
+}
+
+// manifestMu serializes Record, used by the followers running in the same process.
+var manifestMu sync.Mutex
+
+// Record records the artifact in the install manifest at path, installed outside of an Installer,
+// replacing the one previously installed from the same repository.
+func Record(path string, a Artifact) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	m, err := LoadManifest(path)
+	if err != nil {
+		return err
+	}
+	for k := range a.Files {
+		f := &a.Files[k]
+		if f.Digest != "" {
+			continue
+		}
+		if f.Digest, err = fileDigest(filepath.Join(a.Directory, f.Path)); err != nil {
+			return err
+		}
+	}
+	disown(m, &a)
+	m.Upsert(a)
+	if err := m.Write(path); err != nil {
+		return fmt.Errorf("unable to write install manifest %q: %w", path, err)
+	}
+	return nil
+}
+
+// Write atomically writes the install manifest to path.
+func (m *Manifest) Write(path string) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, data, 0o600)
+}
+
+// fileDigest returns the sha256 digest of a regular file, in the "sha256:<hex>" format of the manifests.
+func fileDigest(path string) (string, error) {
+	digest, err := utils.FileDigest(path)
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + digest, nil
+}
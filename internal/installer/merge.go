@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// MergeStrategy controls what happens when an artifact ships a file already installed by another artifact.
+type MergeStrategy string
+
+const (
+	// MergeFail aborts the install.
+	MergeFail MergeStrategy = "fail"
+	// MergeOverwrite replaces the file, which is then owned by the artifact being installed.
+	MergeOverwrite MergeStrategy = "overwrite"
+	// MergeRename installs the file suffixing its name with the artifact name.
+	MergeRename MergeStrategy = "rename"
+	// MergeNamespace installs all the files of the artifact in a subdirectory named after it.
+	MergeNamespace MergeStrategy = "namespace"
+)
+
+// MergeStrategies are the supported merge strategies.
+var MergeStrategies = []string{string(MergeFail), string(MergeOverwrite), string(MergeRename), string(MergeNamespace)}
+
+// ErrFileCollision is returned by the fail merge strategy when a file is already installed by another artifact.
+var ErrFileCollision = errors.New("file already installed by another artifact")
+
+// Option customizes an Installer.
+type Option func(i *Installer)
+
+// WithMergeStrategy sets the strategy used on file collisions. Defaults to MergeFail.
+func WithMergeStrategy(strategy MergeStrategy) Option {
+	return func(i *Installer) {
+		i.mergeStrategy = strategy
+	}
+}
+
+// artifactName returns the last component of the artifact repository.
+func artifactName(a *Artifact) string {
+	return path.Base(a.Repository)
+}
+
+// owners maps the files installed in a.Directory by artifacts other than a to their repository.
+func owners(m *Manifest, a *Artifact) map[string]string {
+	o := make(map[string]string)
+	for i := range m.Artifacts {
+		other := &m.Artifacts[i]
+		if other.Repository == a.Repository || filepath.Clean(other.Directory) != filepath.Clean(a.Directory) {
+			continue
+		}
+		for _, f := range other.Files {
+			o[f.Path] = other.Repository
+		}
+	}
+	return o
+}
+
+// resolveCollisions sets the on-disk path of the staged files according to the merge strategy,
+// given the artifacts recorded in m.
+func (i *Installer) resolveCollisions(m *Manifest, a *Artifact) error {
+	taken := owners(m, a)
+	name := artifactName(a)
+
+	for k := range a.Files {
+		f := &a.Files[k]
+		staged := f.Path
+		switch i.mergeStrategy {
+		case MergeNamespace:
+			f.Path = filepath.Join(name, staged)
+		case MergeRename:
+			if _, ok := taken[staged]; ok {
+				ext := filepath.Ext(staged)
+				f.Path = strings.TrimSuffix(staged, ext) + "-" + name + ext
+			}
+		case MergeOverwrite:
+			continue
+		case MergeFail, "":
+		default:
+			return fmt.Errorf("unsupported merge strategy %q", i.mergeStrategy)
+		}
+		if owner, ok := taken[f.Path]; ok {
+			return fmt.Errorf("%w: %q in %q is owned by %q", ErrFileCollision, f.Path, a.Directory, owner)
+		}
+		if f.Path != staged {
+			f.Staged = staged
+		}
+	}
+	return nil
+}
+
+// disown removes the files of a from the other artifacts installed in the same directory.
+func disown(m *Manifest, a *Artifact) {
+	installed := make(map[string]bool, len(a.Files))
+	for _, f := range a.Files {
+		installed[f.Path] = true
+	}
+	for i := range m.Artifacts {
+		other := &m.Artifacts[i]
+		if other.Repository == a.Repository || filepath.Clean(other.Directory) != filepath.Clean(a.Directory) {
+			continue
+		}
+		files := other.Files[:0]
+		for _, f := range other.Files {
+			if !installed[f.Path] {
+				files = append(files, f)
+			}
+		}
+		other.Files = files
+	}
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallMergeStrategies(t *testing.T) {
+	testCases := []struct {
+		strategy MergeStrategy
+		// expectedErr, if set, is the error returned when installing the second artifact.
+		expectedErr error
+		// expected maps the on-disk files to their content after both installs.
+		expected map[string]string
+		// expectedFirst and expectedSecond are the files recorded in the manifest for each artifact.
+		expectedFirst  []string
+		expectedSecond []string
+	}{
+		{
+			strategy:       MergeFail,
+			expectedErr:    ErrFileCollision,
+			expected:       map[string]string{"custom_rules.yaml": "first"},
+			expectedFirst:  []string{"custom_rules.yaml"},
+			expectedSecond: nil,
+		},
+		{
+			strategy:       MergeOverwrite,
+			expected:       map[string]string{"custom_rules.yaml": "second", "other.yaml": "other"},
+			expectedFirst:  []string{},
+			expectedSecond: []string{"custom_rules.yaml", "other.yaml"},
+		},
+		{
+			strategy:       MergeRename,
+			expected:       map[string]string{"custom_rules.yaml": "first", "custom_rules-k8saudit-rules.yaml": "second", "other.yaml": "other"},
+			expectedFirst:  []string{"custom_rules.yaml"},
+			expectedSecond: []string{"custom_rules-k8saudit-rules.yaml", "other.yaml"},
+		},
+		{
+			strategy: MergeNamespace,
+			expected: map[string]string{
+				"custom_rules.yaml":                "first",
+				"k8saudit-rules/custom_rules.yaml": "second",
+				"k8saudit-rules/other.yaml":        "other",
+			},
+			expectedFirst:  []string{"custom_rules.yaml"},
+			expectedSecond: []string{"k8saudit-rules/custom_rules.yaml", "k8saudit-rules/other.yaml"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			stateDir := t.TempDir()
+			destDir := t.TempDir()
+			manifestFile := filepath.Join(stateDir, "installed.yaml")
+			journalFile := filepath.Join(stateDir, "install.journal")
+
+			first := testArtifact(destDir, "sha256:1")
+			_, err := New(manifestFile, journalFile).Install(context.Background(), first,
+				tarball(t, map[string]string{"custom_rules.yaml": "first"}))
+			require.NoError(t, err)
+
+			second := testArtifact(destDir, "sha256:2")
+			second.Repository = "ghcr.io/falcosecurity/rules/k8saudit-rules"
+			_, err = New(manifestFile, journalFile, WithMergeStrategy(tc.strategy)).Install(context.Background(), second,
+				tarball(t, map[string]string{"custom_rules.yaml": "second", "other.yaml": "other"}))
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.NoFileExists(t, journalFile)
+				assertNoStagingDir(t, destDir)
+			} else {
+				require.NoError(t, err)
+			}
+
+			for name, content := range tc.expected {
+				assert.Equal(t, content, readFile(t, filepath.Join(destDir, name)))
+			}
+
+			m, err := LoadManifest(manifestFile)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedFirst, recordedFiles(t, m, first.Repository))
+			if tc.expectedSecond == nil {
+				_, ok := m.Get(second.Repository)
+				assert.False(t, ok)
+			} else {
+				assert.ElementsMatch(t, tc.expectedSecond, recordedFiles(t, m, second.Repository))
+			}
+		})
+	}
+}
+
+func recordedFiles(t *testing.T, m *Manifest, repository string) []string {
+	a, ok := m.Get(repository)
+	require.True(t, ok)
+	files := make([]string, 0, len(a.Files))
+	for _, f := range a.Files {
+		assert.Empty(t, f.Staged)
+		files = append(files, f.Path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestInstallSameArtifactOverwritesItsFiles(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+	a := testArtifact(destDir, "sha256:1")
+	_, err := inst.Install(context.Background(), a, tarball(t, map[string]string{"custom_rules.yaml": "v1"}))
+	require.NoError(t, err)
+	_, err = inst.Install(context.Background(), a, tarball(t, map[string]string{"custom_rules.yaml": "v2"}))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", readFile(t, filepath.Join(destDir, "custom_rules.yaml")))
+}
+
+func TestInstallUnownedFile(t *testing.T) {
+	for _, strategy := range []MergeStrategy{MergeFail, MergeOverwrite, MergeRename} {
+		t.Run(string(strategy), func(t *testing.T) {
+			stateDir := t.TempDir()
+			destDir := t.TempDir()
+			// The file is on disk, but not installed by an artifact, e.g. shipped by the Falco package.
+			require.NoError(t, os.WriteFile(filepath.Join(destDir, "falco_rules.yaml"), []byte("packaged"), 0o600))
+
+			_, err := New(filepath.Join(stateDir, "installed.yaml"), filepath.Join(stateDir, "install.journal"),
+				WithMergeStrategy(strategy)).Install(context.Background(), testArtifact(destDir, "sha256:1"),
+				tarball(t, map[string]string{"falco_rules.yaml": "artifact"}))
+			require.NoError(t, err)
+			assert.Equal(t, "artifact", readFile(t, filepath.Join(destDir, "falco_rules.yaml")))
+		})
+	}
+}
+
+func TestInstallUpgradeDowngrade(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+	v1 := map[string]string{"a.yaml": "a1", "b.yaml": "b1"}
+
+	_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"), tarball(t, v1))
+	require.NoError(t, err)
+	// The new version no longer ships b.yaml, which is removed.
+	_, err = inst.Install(context.Background(), testArtifact(destDir, "sha256:2"), tarball(t, map[string]string{"a.yaml": "a2"}))
+	require.NoError(t, err)
+	assert.Equal(t, "a2", readFile(t, filepath.Join(destDir, "a.yaml")))
+	assert.NoFileExists(t, filepath.Join(destDir, "b.yaml"))
+
+	_, err = inst.Install(context.Background(), testArtifact(destDir, "sha256:1"), tarball(t, v1))
+	require.NoError(t, err)
+	assert.Equal(t, "a1", readFile(t, filepath.Join(destDir, "a.yaml")))
+	assert.Equal(t, "b1", readFile(t, filepath.Join(destDir, "b.yaml")))
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+// Action is what installing an artifact would do to one of its files.
+type Action string
+
+const (
+	// ActionCreate means the file does not exist yet.
+	ActionCreate Action = "create"
+	// ActionOverwrite means the file exists with a different content.
+	ActionOverwrite Action = "overwrite"
+	// ActionUnchanged means the file exists with the same content.
+	ActionUnchanged Action = "unchanged"
+)
+
+// PlannedFile is a file that installing an artifact would write.
+type PlannedFile struct {
+	// Path is the on-disk path of the file.
+	Path   string
+	Action Action
+	// Owner is the repository of the other artifact the file is recorded for, if any.
+	Owner string
+}
+
+// Plan is what installing an artifact would do.
+type Plan struct {
+	Artifact Artifact
+	Files    []PlannedFile
+}
+
+// Plan returns what InstallAll would do with the pending artifacts, without touching their destination
+// directories nor the install state: the tarballs are only extracted in a temporary directory. It fails
+// as InstallAll would when the files collide according to the merge strategy.
+func (i *Installer) Plan(ctx context.Context, pending []Pending) ([]Plan, error) {
+	m, err := LoadManifest(i.manifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]Plan, 0, len(pending))
+	for _, p := range pending {
+		a := p.Artifact
+		if a.Files, err = planFiles(ctx, p.Tarball); err != nil {
+			return nil, err
+		}
+		if err := i.resolveCollisions(m, &a); err != nil {
+			return nil, err
+		}
+
+		plan := Plan{Artifact: a}
+		taken := owners(m, &a)
+		for _, f := range a.Files {
+			dst := filepath.Join(a.Directory, f.Path)
+			action := ActionCreate
+			if digest, err := fileDigest(dst); err == nil {
+				action = ActionOverwrite
+				if digest == f.Digest {
+					action = ActionUnchanged
+				}
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			plan.Files = append(plan.Files, PlannedFile{Path: dst, Action: action, Owner: taken[f.Path]})
+		}
+		plans = append(plans, plan)
+
+		// The next artifacts are planned as if this one was installed.
+		disown(m, &a)
+		m.Upsert(a)
+	}
+	return plans, nil
+}
+
+// planFiles lists the files of the gzip compressed tarball, extracting it in a temporary directory.
+func planFiles(ctx context.Context, tarball io.Reader) ([]File, error) {
+	dir, err := os.MkdirTemp("", "falcoctl-plan-")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := utils.ExtractTarGz(ctx, tarball, dir, 0); err != nil {
+		return nil, fmt.Errorf("cannot extract artifact: %w", err)
+	}
+	return stagedFiles(dir)
+}
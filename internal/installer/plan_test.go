@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+	_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"),
+		tarball(t, map[string]string{"rules.yaml": "rules", "other.yaml": "other"}))
+	require.NoError(t, err)
+	manifest := readFile(t, inst.manifestFile)
+
+	WithMergeStrategy(MergeOverwrite)(inst)
+	plans, err := inst.Plan(context.Background(), []Pending{
+		{Artifact: testArtifact(destDir, "sha256:2"), Tarball: tarball(t, map[string]string{"rules.yaml": "new", "other.yaml": "other"})},
+		{Artifact: testDependency(destDir, "sha256:3"), Tarball: tarball(t, map[string]string{"rules.yaml": "json", "libjson.so": "json"})},
+	})
+	require.NoError(t, err)
+	require.Len(t, plans, 2)
+
+	actions := func(p Plan) map[string]PlannedFile {
+		files := make(map[string]PlannedFile)
+		for _, f := range p.Files {
+			files[filepath.Base(f.Path)] = f
+		}
+		return files
+	}
+	first := actions(plans[0])
+	assert.Equal(t, ActionOverwrite, first["rules.yaml"].Action)
+	assert.Equal(t, ActionUnchanged, first["other.yaml"].Action)
+	assert.Equal(t, filepath.Join(destDir, "rules.yaml"), first["rules.yaml"].Path)
+	second := actions(plans[1])
+	assert.Equal(t, ActionCreate, second["libjson.so"].Action)
+	assert.Equal(t, ActionOverwrite, second["rules.yaml"].Action)
+	assert.Equal(t, testArtifact(destDir, "").Repository, second["rules.yaml"].Owner)
+
+	// Nothing has been touched.
+	assert.Equal(t, "rules", readFile(t, filepath.Join(destDir, "rules.yaml")))
+	assert.NoFileExists(t, filepath.Join(destDir, "libjson.so"))
+	assert.Equal(t, manifest, readFile(t, inst.manifestFile))
+	assert.NoFileExists(t, inst.journalFile)
+	assertNoStagingDir(t, destDir)
+}
+
+func TestPlanCollision(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+
+	_, err := inst.Plan(context.Background(), []Pending{
+		{Artifact: testArtifact(destDir, "sha256:1"), Tarball: tarball(t, map[string]string{"rules.yaml": "rules"})},
+		{Artifact: testDependency(destDir, "sha256:2"), Tarball: tarball(t, map[string]string{"rules.yaml": "json"})},
+	})
+	assert.ErrorIs(t, err, ErrFileCollision)
+}
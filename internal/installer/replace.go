@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Replace moves the files into dir, as a whole: files maps their path relative to dir to the
+// path of their new version. The new versions are first copied to a staging directory inside
+// dir, then renamed into place one by one. If any of them fails, the files already replaced
+// are restored, so that dir is left as it was.
+func Replace(dir string, files map[string]string) error {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	stagingDir, err := os.MkdirTemp(dir, stagingDirPrefix)
+	if err != nil {
+		return fmt.Errorf("cannot create staging directory: %w", err)
+	}
+
+	e := &entry{StagingDir: stagingDir, Artifact: Artifact{Directory: dir}}
+	if err := stageFiles(e, paths, files); err != nil {
+		return errors.Join(err, os.RemoveAll(stagingDir))
+	}
+
+	for k, f := range e.Artifact.Files {
+		src, dst, backup := e.paths(f)
+		if err := replaceFile(src, dst, backup, f.Digest); err != nil {
+			for n := k; n >= 0; n-- {
+				src, dst, backup := e.paths(e.Artifact.Files[n])
+				if rerr := restoreFile(src, dst, backup); rerr != nil {
+					// Keep the staging directory, it holds the replaced files still to be restored.
+					return errors.Join(err, fmt.Errorf("replaced files are kept in %q: %w", stagingDir, rerr))
+				}
+			}
+			return errors.Join(err, os.RemoveAll(stagingDir))
+		}
+	}
+	return os.RemoveAll(stagingDir)
+}
+
+// stageFiles copies the files at paths to the staging directory of e, recording their digest.
+func stageFiles(e *entry, paths []string, files map[string]string) error {
+	for _, p := range paths {
+		f := File{Path: p}
+		src, _, _ := e.paths(f)
+		copied, err := copyFile(files[p], src)
+		if err != nil {
+			return fmt.Errorf("cannot stage %q: %w", files[p], err)
+		} else if !copied {
+			return fmt.Errorf("cannot stage %q: not a regular file", files[p])
+		}
+		if f.Digest, err = fileDigest(src); err != nil {
+			return err
+		}
+		e.Artifact.Files = append(e.Artifact.Files, f)
+	}
+	return nil
+}
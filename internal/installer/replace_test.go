@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplace(t *testing.T) {
+	srcDir, destDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "a.yaml"), []byte("old"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "a.yaml"), []byte("new"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "b.yaml"), []byte("b"), 0o600))
+
+	err := Replace(destDir, map[string]string{
+		"a.yaml": filepath.Join(srcDir, "a.yaml"),
+		"b.yaml": filepath.Join(srcDir, "b.yaml"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new", readFile(t, filepath.Join(destDir, "a.yaml")))
+	assert.Equal(t, "b", readFile(t, filepath.Join(destDir, "b.yaml")))
+	assertNoStagingDir(t, destDir)
+}
+
+func TestReplaceFailure(t *testing.T) {
+	srcDir, destDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "a.yaml"), []byte("old"), 0o600))
+	// A file where a directory is expected makes the second file fail.
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "sub"), []byte("file"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "a.yaml"), []byte("new"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "c.yaml"), []byte("c"), 0o600))
+
+	err := Replace(destDir, map[string]string{
+		"a.yaml":     filepath.Join(srcDir, "a.yaml"),
+		"sub/c.yaml": filepath.Join(srcDir, "c.yaml"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, "old", readFile(t, filepath.Join(destDir, "a.yaml")))
+	assert.Equal(t, "file", readFile(t, filepath.Join(destDir, "sub")))
+	assertNoStagingDir(t, destDir)
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Rollback restores the most recent version kept in the history for the artifact name, which is either
+// its repository or the last component of it. The files are restored through the same staging directory
+// and journal as Install. The restored version is then removed from the history, so that rolling back
+// again restores the version before it.
+func (i *Installer) Rollback(name string) (*Version, error) {
+	if i.history == nil {
+		return nil, fmt.Errorf("%w for %q", ErrNoHistory, name)
+	}
+	if j, err := i.readJournal(); err != nil {
+		return nil, err
+	} else if j != nil {
+		return nil, fmt.Errorf("%w for %q", ErrPendingInstall, j.Artifact.Ref)
+	}
+
+	repository, err := i.history.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := i.history.Versions(repository)
+	if err != nil {
+		return nil, err
+	} else if len(versions) == 0 {
+		return nil, fmt.Errorf("%w for %q", ErrNoHistory, name)
+	}
+	v := &versions[0]
+
+	m, err := LoadManifest(i.manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	_, tracked := m.Get(repository)
+
+	stagingDir, err := os.MkdirTemp(v.Directory, stagingDirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create staging directory: %w", err)
+	}
+	j := &journal{Phase: phaseStaged, entry: entry{StagingDir: stagingDir, Artifact: v.Artifact, Untracked: !tracked}}
+	if err := i.writeJournal(j); err != nil {
+		_ = os.RemoveAll(stagingDir)
+		return nil, err
+	}
+	for _, f := range v.Files {
+		src := filepath.Join(v.path, versionFilesDir, f.Path)
+		if copied, err := copyFile(src, filepath.Join(stagingDir, f.Path)); err != nil {
+			return nil, errors.Join(fmt.Errorf("cannot restore %q: %w", src, err), i.rollback(j))
+		} else if !copied {
+			return nil, errors.Join(fmt.Errorf("cannot restore %q: missing from history", src), i.rollback(j))
+		}
+	}
+	if err := i.step(phaseStaged); err != nil {
+		return nil, err
+	}
+
+	j.Phase = phaseSwapping
+	if err := i.writeJournal(j); err != nil {
+		return nil, errors.Join(err, i.rollback(j))
+	}
+	if err := i.swap(j); err != nil {
+		return nil, i.abort(j, err)
+	}
+	// The files of the current version not part of the restored one are removed on commit.
+	if _, err := i.commit(j); err != nil {
+		return nil, err
+	}
+	return v, i.history.drop(v)
+}
+
+// saveReplaced keeps in the history the installed version of the artifact a is replacing, if any.
+func (i *Installer) saveReplaced(a *Artifact) error {
+	if i.history == nil {
+		return nil
+	}
+	m, err := LoadManifest(i.manifestFile)
+	if err != nil {
+		return err
+	}
+	prev, ok := m.Get(a.Repository)
+	if !ok || prev.Digest == a.Digest {
+		return nil
+	}
+	return i.history.Save(prev)
+}
+
+// staleFiles returns the files of current that are not part of the restored artifact.
+func staleFiles(current, restored *Artifact) []File {
+	if filepath.Clean(current.Directory) != filepath.Clean(restored.Directory) {
+		return current.Files
+	}
+	keep := make(map[string]bool, len(restored.Files))
+	for _, f := range restored.Files {
+		keep[f.Path] = true
+	}
+	var stale []File
+	for _, f := range current.Files {
+		if !keep[f.Path] {
+			stale = append(stale, f)
+		}
+	}
+	return stale
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrModified is returned when uninstalling an artifact whose files have been modified since they were installed.
+var ErrModified = errors.New("files modified since the install")
+
+// Uninstall removes the files installed by the artifact ref, resolved as by Manifest.Resolve, and removes it
+// from the install manifest. Files whose digest differs from the recorded one are only removed if force is set;
+// files already missing are skipped. The removed version is kept in the history, so that Rollback can restore its files.
+func (i *Installer) Uninstall(ref string, force bool) (*Artifact, error) {
+	if j, err := i.readJournal(); err != nil {
+		return nil, err
+	} else if j != nil {
+		return nil, fmt.Errorf("%w for %q", ErrPendingInstall, j.Artifact.Ref)
+	}
+
+	m, err := LoadManifest(i.manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	found, err := m.Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	a := *found
+
+	if !force {
+		var modified []string
+		for _, f := range a.Files {
+			if f.Digest == "" {
+				continue
+			}
+			digest, err := fileDigest(filepath.Join(a.Directory, f.Path))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			if digest != f.Digest {
+				modified = append(modified, f.Path)
+			}
+		}
+		if len(modified) > 0 {
+			return nil, fmt.Errorf("%w in %q: %s", ErrModified, a.Directory, strings.Join(modified, ", "))
+		}
+	}
+
+	if err := i.history.Save(&a); err != nil {
+		return nil, err
+	}
+
+	for _, f := range a.Files {
+		p := filepath.Join(a.Directory, f.Path)
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("cannot remove %q: %w", p, err)
+		}
+		removeEmptyDirs(a.Directory, filepath.Dir(p))
+	}
+
+	m.Remove(a.Repository)
+	if err := m.Write(i.manifestFile); err != nil {
+		return nil, fmt.Errorf("unable to write install manifest %q: %w", i.manifestFile, err)
+	}
+	return &a, nil
+}
+
+// removeEmptyDirs removes dir and its parents while they are empty, stopping at root.
+func removeEmptyDirs(root, dir string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
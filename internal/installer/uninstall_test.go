@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUninstall(t *testing.T) {
+	inst, destDir := newTestHistoryInstaller(t, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "falco.yaml"), []byte("untouched"), 0o600))
+	_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"),
+		tarball(t, map[string]string{"rules.yaml": "rules", "sub/more.yaml": "more"}))
+	require.NoError(t, err)
+
+	a, err := inst.Uninstall("falco-rules:latest", false)
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/falcosecurity/rules/falco-rules", a.Repository)
+	assert.NoFileExists(t, filepath.Join(destDir, "rules.yaml"))
+	assert.NoDirExists(t, filepath.Join(destDir, "sub"))
+	assert.Equal(t, "untouched", readFile(t, filepath.Join(destDir, "falco.yaml")))
+
+	m, err := LoadManifest(inst.manifestFile)
+	require.NoError(t, err)
+	assert.Empty(t, m.Artifacts)
+
+	_, err = inst.Uninstall("falco-rules", false)
+	assert.ErrorIs(t, err, ErrNotInstalled)
+
+	// The removed version can be restored.
+	_, err = inst.Rollback("falco-rules")
+	require.NoError(t, err)
+	assert.Equal(t, "rules", readFile(t, filepath.Join(destDir, "rules.yaml")))
+}
+
+func TestUninstallModified(t *testing.T) {
+	inst, destDir := newTestInstaller(t)
+	_, err := inst.Install(context.Background(), testArtifact(destDir, "sha256:1"),
+		tarball(t, map[string]string{"rules.yaml": "rules", "other.yaml": "other"}))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "rules.yaml"), []byte("edited"), 0o600))
+	require.NoError(t, os.Remove(filepath.Join(destDir, "other.yaml")))
+
+	_, err = inst.Uninstall(testArtifact(destDir, "").Repository, false)
+	assert.ErrorIs(t, err, ErrModified)
+	assert.ErrorContains(t, err, "rules.yaml")
+	assert.Equal(t, "edited", readFile(t, filepath.Join(destDir, "rules.yaml")))
+
+	_, err = inst.Uninstall(testArtifact(destDir, "").Repository, true)
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(destDir, "rules.yaml"))
+}
+
+func TestManifestResolve(t *testing.T) {
+	m := &Manifest{Artifacts: []Artifact{
+		{Repository: "ghcr.io/falcosecurity/rules/falco-rules"},
+		{Repository: "ghcr.io/falcosecurity/plugins/ruleset/k8saudit"},
+		{Repository: "ghcr.io/falcosecurity/plugins/plugin/k8saudit"},
+	}}
+
+	a, err := m.Resolve("falco-rules")
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/falcosecurity/rules/falco-rules", a.Repository)
+
+	a, err = m.Resolve("ghcr.io/falcosecurity/plugins/plugin/k8saudit@sha256:1")
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/falcosecurity/plugins/plugin/k8saudit", a.Repository)
+
+	_, err = m.Resolve("k8saudit")
+	assert.ErrorContains(t, err, "matches several artifacts")
+
+	_, err = m.Resolve("json")
+	assert.ErrorIs(t, err, ErrNotInstalled)
+}
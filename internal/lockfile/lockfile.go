@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+const (
+	// DefaultFile is the lock file used when not specified.
+	DefaultFile = "falcoctl.lock"
+	// Version is the version of the lock file format.
+	Version = 1
+)
+
+// ErrMismatch is returned when the lock file has been written for other artifacts than the requested ones.
+var ErrMismatch = errors.New("lock file does not match the requested artifacts")
+
+// Lock records the digests that the requested artifacts, and their dependencies, resolved to.
+type Lock struct {
+	Version int `yaml:"version"`
+	// Refs are the artifacts the lock file has been written for, as requested.
+	Refs []string `yaml:"refs"`
+	// Artifacts are the artifacts installed for Refs, dependencies included, in install order.
+	Artifacts []Artifact `yaml:"artifacts"`
+}
+
+// Artifact is a locked artifact.
+type Artifact struct {
+	// Ref is the reference the artifact has been resolved from.
+	Ref string `yaml:"ref"`
+	// Digest is the digest Ref resolved to.
+	Digest string `yaml:"digest"`
+	// Type is the artifact type.
+	Type string `yaml:"type,omitempty"`
+}
+
+// New returns the lock for refs.
+func New(refs []string) *Lock {
+	return &Lock{Version: Version, Refs: refs}
+}
+
+// Load reads the lock file at path.
+func Load(path string) (*Lock, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read lock file %q: %w", path, err)
+	}
+	l := &Lock{}
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("unable to parse lock file %q: %w", path, err)
+	}
+	if l.Version != Version {
+		return nil, fmt.Errorf("unsupported version %d of lock file %q", l.Version, path)
+	}
+	for _, a := range l.Artifacts {
+		if a.Ref == "" || a.Digest == "" {
+			return nil, fmt.Errorf("invalid lock file %q: each artifact needs a ref and a digest", path)
+		}
+	}
+	return l, nil
+}
+
+// Write writes the lock file to path.
+func (l *Lock) Write(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("unable to write lock file %q: %w", path, err)
+	}
+	return nil
+}
+
+// Add records that ref resolved to digest.
+func (l *Lock) Add(ref, digest, artifactType string) {
+	l.Artifacts = append(l.Artifacts, Artifact{Ref: ref, Digest: digest, Type: artifactType})
+}
+
+// Check returns ErrMismatch if the lock file has not been written for refs, in any order.
+func (l *Lock) Check(refs []string) error {
+	locked, requested := slices.Clone(l.Refs), slices.Clone(refs)
+	slices.Sort(locked)
+	slices.Sort(requested)
+	if !slices.Equal(slices.Compact(locked), slices.Compact(requested)) {
+		return fmt.Errorf("%w: written for %v, got %v", ErrMismatch, l.Refs, refs)
+	}
+	return nil
+}
+
+// PinnedRef returns the reference of the locked digest of a.
+func (a *Artifact) PinnedRef() (string, error) {
+	repo, err := utils.RepositoryFromRef(a.Ref)
+	if err != nil {
+		return "", err
+	}
+	return repo + "@" + a.Digest, nil
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockfile
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+
+	l := New([]string{"ghcr.io/falcosecurity/plugins/plugin/k8saudit:0.7.0"})
+	l.Add("ghcr.io/falcosecurity/plugins/plugin/k8saudit:0.7.0", "sha256:aaaa", "plugin")
+	l.Add("ghcr.io/falcosecurity/plugins/plugin/json:0.7.0", "sha256:bbbb", "plugin")
+	require.NoError(t, l.Write(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, l, loaded)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Load(filepath.Join(dir, "missing.lock"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "version.lock")
+	l := New(nil)
+	l.Version = Version + 1
+	require.NoError(t, l.Write(path))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "unsupported version")
+
+	path = filepath.Join(dir, "digest.lock")
+	l = New([]string{"ghcr.io/falcosecurity/rules/falco-rules:3"})
+	l.Add("ghcr.io/falcosecurity/rules/falco-rules:3", "", "rulesfile")
+	require.NoError(t, l.Write(path))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "needs a ref and a digest")
+}
+
+func TestCheck(t *testing.T) {
+	l := New([]string{"registry.io/repo/a:1", "registry.io/repo/b:1"})
+
+	assert.NoError(t, l.Check([]string{"registry.io/repo/b:1", "registry.io/repo/a:1"}))
+	assert.NoError(t, l.Check([]string{"registry.io/repo/a:1", "registry.io/repo/b:1", "registry.io/repo/a:1"}))
+
+	err := l.Check([]string{"registry.io/repo/a:1"})
+	assert.True(t, errors.Is(err, ErrMismatch))
+	err = l.Check([]string{"registry.io/repo/a:2", "registry.io/repo/b:1"})
+	assert.True(t, errors.Is(err, ErrMismatch))
+}
+
+func TestPinnedRef(t *testing.T) {
+	a := Artifact{Ref: "ghcr.io/falcosecurity/rules/falco-rules:3", Digest: "sha256:aaaa"}
+	ref, err := a.PinnedRef()
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/falcosecurity/rules/falco-rules@sha256:aaaa", ref)
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package login
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2/clientcredentials"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/login/gcp"
+	"github.com/falcosecurity/falcoctl/pkg/oci/registry"
+)
+
+// CheckRegistryAuth checks that the default auth of a registries entry allows to connect
+// to the registry. Differently from the login functions, no credential is stored.
+func CheckRegistryAuth(ctx context.Context, r *config.Registry) error {
+	client := &auth.Client{
+		Client: http.DefaultClient,
+		Credential: func(context.Context, string) (auth.Credential, error) {
+			return auth.EmptyCredential, nil
+		},
+	}
+
+	switch r.Auth {
+	case config.RegistryAuthTypeBasic:
+		password := r.Basic.Password
+		if r.Basic.PasswordEnv != "" {
+			password = os.Getenv(r.Basic.PasswordEnv)
+		}
+		client.Credential = auth.StaticCredential(r.Host, auth.Credential{
+			Username: r.Basic.User,
+			Password: password,
+		})
+	case config.RegistryAuthTypeOauth:
+		creds := &clientcredentials.Config{
+			ClientID:     r.Oauth.ClientID,
+			ClientSecret: r.Oauth.ClientSecret,
+			TokenURL:     r.Oauth.TokenURL,
+		}
+		token, err := creds.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("wrong client credentials, unable to retrieve token: %w", err)
+		}
+		client.Credential = auth.StaticCredential(r.Host, auth.Credential{
+			AccessToken: token.AccessToken,
+		})
+	case config.RegistryAuthTypeCloud:
+		return gcp.Login(ctx, r.Host)
+	}
+
+	reg, err := registry.NewRegistry(r.Host, registry.WithClient(client))
+	if err != nil {
+		return err
+	}
+
+	if err := reg.CheckConnection(ctx); err != nil {
+		return fmt.Errorf("unable to connect to registry %q: %w", r.Host, err)
+	}
+
+	return nil
+}
@@ -40,11 +40,19 @@ func PerformAuthsFromConfig(ctx context.Context, client *auth.Client, credStore
 
 // PerformAuthsFromConfigWithMap logins to the specified registry set and stores credentials in local stores.
 func PerformAuthsFromConfigWithMap(ctx context.Context, client *auth.Client, credStore credentials.Store, registrySet map[string]bool) error {
+	// Collect the auths from the registries section first, so that a malformed entry
+	// is reported before logging in to any registry.
+	regBasicAuths, regOauthAuths, regGcpAuths, err := config.RegistriesAuths()
+	if err != nil {
+		return err
+	}
+
 	// Perform authentications using basic auth.
 	basicAuths, err := config.BasicAuths()
 	if err != nil {
 		return err
 	}
+	basicAuths = append(basicAuths, regBasicAuths...)
 
 	// skip basic auth login if we do not have a credentials.Store
 	if credStore != nil {
@@ -58,6 +66,7 @@ func PerformAuthsFromConfigWithMap(ctx context.Context, client *auth.Client, cre
 	if err != nil {
 		return err
 	}
+	gcpAuths = append(gcpAuths, regGcpAuths...)
 
 	if err := PerformGcpAuthsLogin(ctx, gcpAuths, registrySet); err != nil {
 		return err
@@ -68,6 +77,7 @@ func PerformAuthsFromConfigWithMap(ctx context.Context, client *auth.Client, cre
 	if err != nil {
 		return err
 	}
+	oauthAuths = append(oauthAuths, regOauthAuths...)
 
 	return PerformOauthAuths(ctx, oauthAuths, registrySet)
 }
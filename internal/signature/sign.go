@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"time"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign"
+)
+
+// SignOptions selects how the artifacts are signed with cosign.
+type SignOptions struct {
+	// KeyRef is the private key file, or the KMS URI (e.g. "awskms:///alias/falco"), signing the artifacts.
+	// When empty, the artifacts are signed keyless, with a Fulcio certificate issued for the OIDC identity.
+	// The password of a key file is read from the COSIGN_PASSWORD env variable.
+	KeyRef string
+	// IdentityToken is the OIDC token used for keyless signing, instead of the ambient credentials
+	// (e.g. the ones of a GitHub Actions workflow).
+	IdentityToken string
+	// TlogUpload uploads the signatures to the Rekor transparency log. It is required for keyless signing.
+	TlogUpload bool
+	// PlainHTTP allows interacting with the registry via plain http requests.
+	PlainHTTP bool
+	// Keychain authenticates the upload of the signatures, instead of the default docker keychain.
+	Keychain options.Keychain
+}
+
+// Sign signs a fully qualified reference, attaching the signature to the registry as cosign sign does.
+// The reference should be a digest, so that exactly the pushed artifact is signed.
+func Sign(ctx context.Context, ref string, opts SignOptions) error {
+	timeout := options.DefaultTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	ko := options.KeyOpts{
+		KeyRef:           opts.KeyRef,
+		PassFunc:         generate.GetPass,
+		FulcioURL:        options.DefaultFulcioURL,
+		RekorURL:         options.DefaultRekorURL,
+		OIDCIssuer:       options.DefaultOIDCIssuerURL,
+		OIDCClientID:     "sigstore",
+		IDToken:          opts.IdentityToken,
+		SkipConfirmation: true,
+	}
+	signOpts := options.SignOptions{
+		Key:              opts.KeyRef,
+		Upload:           true,
+		SkipConfirmation: true,
+		TlogUpload:       opts.TlogUpload,
+		Registry: options.RegistryOptions{
+			AllowHTTPRegistry: opts.PlainHTTP,
+			Keychain:          opts.Keychain,
+		},
+	}
+	return sign.SignCmd(&options.RootOptions{Timeout: timeout}, ko, signOpts, []string{ref})
+}
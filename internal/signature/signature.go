@@ -17,13 +17,85 @@ package signature
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 
+	"github.com/falcosecurity/falcoctl/internal/config"
 	"github.com/falcosecurity/falcoctl/internal/cosign"
 	"github.com/falcosecurity/falcoctl/pkg/index/index"
 )
 
+// Mode is what to do when the signature of an artifact is missing or does not match the expected signer.
+type Mode string
+
+const (
+	// ModeEnforce fails the installation of the artifact.
+	ModeEnforce Mode = "enforce"
+	// ModeWarn installs the artifact anyway, the failure being only reported.
+	ModeWarn Mode = "warn"
+)
+
+// Modes are the supported verification modes.
+var Modes = []string{string(ModeEnforce), string(ModeWarn)}
+
+// ErrNoSigner is returned when the verification is required, but no signer is known for the artifact.
+var ErrNoSigner = errors.New("no expected signer for the artifact, neither in the indexes nor in the artifact.verify.cosign configuration")
+
+// Policy requires all the artifacts to be signed, by the configured signer or by the one of their index.
+type Policy struct {
+	// Cosign is the expected signer, used instead of the one of the indexes when set.
+	Cosign *index.CosignSignature
+	Mode   Mode
+}
+
+// NewPolicy returns the verification policy of the given configuration, the mode defaulting to ModeEnforce.
+func NewPolicy(cfg config.Verify) (*Policy, error) {
+	p := &Policy{Mode: Mode(cfg.Mode)}
+	switch p.Mode {
+	case "":
+		p.Mode = ModeEnforce
+	case ModeEnforce, ModeWarn:
+	default:
+		return nil, fmt.Errorf("unsupported signature verification mode %q, must be one of %v", cfg.Mode, Modes)
+	}
+	if cfg.Cosign != (config.VerifyCosign{}) {
+		p.Cosign = &index.CosignSignature{
+			CertificateOidcIssuer:       cfg.Cosign.CertificateOidcIssuer,
+			CertificateOidcIssuerRegexp: cfg.Cosign.CertificateOidcIssuerRegexp,
+			CertificateIdentity:         cfg.Cosign.CertificateIdentity,
+			CertificateIdentityRegexp:   cfg.Cosign.CertificateIdentityRegexp,
+			KeyRef:                      cfg.Cosign.KeyRef,
+			IgnoreTlog:                  cfg.Cosign.IgnoreTlog,
+		}
+	}
+	return p, nil
+}
+
+// ConfiguredPolicy returns the verification policy of the config file, with the given mode.
+func ConfiguredPolicy(mode string) (*Policy, error) {
+	cfg, err := config.ArtifactVerify()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Mode = mode
+	return NewPolicy(cfg)
+}
+
+// Verify checks that a fully qualified reference is signed by the expected signer: the configured one,
+// or the one of the index, if any. Unlike the package Verify function, a missing signer is an error.
+func (p *Policy) Verify(ctx context.Context, ref string, fromIndex *index.Signature) error {
+	signature := fromIndex
+	if p.Cosign != nil {
+		signature = &index.Signature{Cosign: p.Cosign}
+	}
+	if signature == nil || signature.Cosign == nil {
+		return ErrNoSigner
+	}
+	return Verify(ctx, ref, signature)
+}
+
 // Verify checks that a fully qualified reference is signed according to the parameters.
 func Verify(ctx context.Context, ref string, signature *index.Signature) error {
 	if signature == nil {
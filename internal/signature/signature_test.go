@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
+)
+
+const testVerifyConfig = `artifact:
+  verify:
+    enabled: true
+    mode: warn
+    cosign:
+      certificateIdentityRegexp: https://github.com/falcosecurity/rules/
+      certificateOidcIssuer: https://token.actions.githubusercontent.com
+`
+
+func TestNewPolicy(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         config.Verify
+		expected    *Policy
+		expectedErr string
+	}{
+		{
+			name:     "defaults",
+			cfg:      config.Verify{Enabled: true},
+			expected: &Policy{Mode: ModeEnforce},
+		},
+		{
+			name: "signer",
+			cfg: config.Verify{Mode: "warn", Cosign: config.VerifyCosign{
+				CertificateIdentity:   "someone@example.com",
+				CertificateOidcIssuer: "https://accounts.example.com",
+			}},
+			expected: &Policy{Mode: ModeWarn, Cosign: &index.CosignSignature{
+				CertificateIdentity:   "someone@example.com",
+				CertificateOidcIssuer: "https://accounts.example.com",
+			}},
+		},
+		{
+			name:        "unsupported mode",
+			cfg:         config.Verify{Mode: "audit"},
+			expectedErr: `unsupported signature verification mode "audit", must be one of [enforce warn]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := NewPolicy(tc.cfg)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, policy)
+		})
+	}
+}
+
+func TestConfiguredPolicy(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(testVerifyConfig), 0o600))
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile(configFile)
+	require.NoError(t, viper.ReadInConfig())
+
+	// The mode comes from the flags, already defaulted to the config file.
+	policy, err := ConfiguredPolicy(string(ModeEnforce))
+	require.NoError(t, err)
+	assert.Equal(t, &Policy{Mode: ModeEnforce, Cosign: &index.CosignSignature{
+		CertificateIdentityRegexp: "https://github.com/falcosecurity/rules/",
+		CertificateOidcIssuer:     "https://token.actions.githubusercontent.com",
+	}}, policy)
+}
+
+func TestPolicyVerifyNoSigner(t *testing.T) {
+	policy := &Policy{Mode: ModeEnforce}
+	ref := "ghcr.io/falcosecurity/rules/falco-rules@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	assert.ErrorIs(t, policy.Verify(context.Background(), ref, nil), ErrNoSigner)
+	assert.ErrorIs(t, policy.Verify(context.Background(), ref, &index.Signature{}), ErrNoSigner)
+	// Without a policy, artifacts without signer are not verified at all.
+	assert.NoError(t, Verify(context.Background(), ref, nil))
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+)
+
+// secretKeys are the keys of the registry auth, index http and driver mirror entries holding secrets.
+var secretKeys = map[string]bool{
+	"password":     true,
+	"clientsecret": true,
+	"token":        true,
+	"clientkey":    true,
+}
+
+// redactSecrets blanks the secrets stored in the registry.auth, registries, indexes and driver.mirrors sections of a falcoctl config
+// file.
+func redactSecrets(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+
+	if auth := config.MappingValue(config.MappingValue(doc.Content[0], "registry"), "auth"); auth != nil && auth.Kind == yaml.MappingNode {
+		for i := 1; i < len(auth.Content); i += 2 {
+			if entries := auth.Content[i]; entries.Kind == yaml.SequenceNode {
+				for _, entry := range entries.Content {
+					blankSecrets(entry)
+				}
+			}
+		}
+	}
+	if registries := config.MappingValue(doc.Content[0], "registries"); registries != nil && registries.Kind == yaml.SequenceNode {
+		for _, entry := range registries.Content {
+			blankSecrets(config.MappingValue(entry, "basic"))
+			blankSecrets(config.MappingValue(entry, "oauth"))
+		}
+	}
+	if indexes := config.MappingValue(doc.Content[0], "indexes"); indexes != nil && indexes.Kind == yaml.SequenceNode {
+		for _, entry := range indexes.Content {
+			http := config.MappingValue(entry, "http")
+			blankSecrets(http)
+			// The headers may carry credentials, e.g. API keys.
+			blankValues(config.MappingValue(http, "headers"))
+		}
+	}
+	if mirrors := config.MappingValue(config.MappingValue(doc.Content[0], "driver"), "mirrors"); mirrors != nil && mirrors.Kind == yaml.SequenceNode {
+		for _, entry := range mirrors.Content {
+			blankSecrets(entry)
+			blankValues(config.MappingValue(entry, "headers"))
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blankSecrets blanks the values of the secret keys of a mapping node.
+func blankSecrets(entry *yaml.Node) {
+	if entry == nil || entry.Kind != yaml.MappingNode {
+		return
+	}
+	for j := 0; j+1 < len(entry.Content); j += 2 {
+		if secretKeys[strings.ToLower(entry.Content[j].Value)] {
+			blank(entry.Content[j+1])
+		}
+	}
+}
+
+// blankValues blanks all the values of a mapping node.
+func blankValues(node *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for j := 1; j < len(node.Content); j += 2 {
+		blank(node.Content[j])
+	}
+}
+
+func blank(value *yaml.Node) {
+	value.Value = ""
+	value.Tag = "!!str"
+	value.Style = yaml.DoubleQuotedStyle
+}
@@ -0,0 +1,382 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/installer"
+)
+
+const (
+	// metadataFile is the bundle member describing its content. It is always the first member.
+	metadataFile = "state.yaml"
+	// bundleVersion is the version of the bundle layout.
+	bundleVersion = 1
+
+	entryConfig              = "config"
+	entryIndexes             = "indexes"
+	entryIndexesCache        = "indexes-cache"
+	entryInstallManifest     = "install-manifest"
+	entryClientCredentials   = "client-credentials"
+	entryRegistryCredentials = "registry-credentials"
+	entryArtifactsPrefix     = "artifacts/"
+)
+
+// ErrUnsupportedBundle is returned when importing a bundle that was not produced by a compatible falcoctl.
+var ErrUnsupportedBundle = errors.New("unsupported state bundle")
+
+// Paths are the local paths making up the falcoctl state.
+type Paths struct {
+	ConfigFile              string
+	IndexesFile             string
+	IndexesDir              string
+	InstallManifestFile     string
+	ClientCredentialsFile   string
+	RegistryCredentialsFile string
+	// ArtifactDirs are the directories where the installed artifacts files can be restored.
+	ArtifactDirs []string
+}
+
+// DefaultPaths returns the local state paths for the given config file, as set up by the config package.
+func DefaultPaths(configFile string) *Paths {
+	return &Paths{
+		ConfigFile:              configFile,
+		IndexesFile:             config.IndexesFile,
+		IndexesDir:              config.IndexesDir,
+		InstallManifestFile:     config.InstallManifestFile,
+		ClientCredentialsFile:   config.ClientCredentialsFile,
+		RegistryCredentialsFile: config.RegistryCredentialConfPath(),
+		ArtifactDirs:            artifactDirs(),
+	}
+}
+
+// artifactDirs returns the default and the configured directories of the installed artifacts.
+func artifactDirs() []string {
+	dirs := []string{config.RulesfilesDir, config.PluginsDir, config.AssetsDir}
+	for _, key := range []string{
+		config.ArtifactInstallRulesfilesDirKey, config.ArtifactInstallPluginsDirKey, config.ArtifactInstallAssetsDirKey,
+		config.ArtifactFollowRulesfilesDirKey, config.ArtifactFollowPluginsDirKey, config.ArtifactFollowAssetsDirKey,
+	} {
+		if dir := viper.GetString(key); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Options customize the export.
+type Options struct {
+	// IncludeBlobs also packages the files of the installed artifacts, as recorded in the install manifest.
+	IncludeBlobs bool
+	// IncludeSecrets also packages the credential stores, and keeps the secrets in the config file.
+	IncludeSecrets bool
+}
+
+// Metadata describes the content of a bundle.
+type Metadata struct {
+	Version   int       `yaml:"version"`
+	CreatedAt time.Time `yaml:"createdAt"`
+	Entries   []Entry   `yaml:"entries"`
+}
+
+// Entry is a file or directory stored in a bundle.
+type Entry struct {
+	// Name is the path of the entry inside the bundle.
+	Name string `yaml:"name"`
+	// Path is where the entry was found on the exporting machine.
+	Path string `yaml:"path"`
+	// Dir is true if the entry is a directory.
+	Dir bool `yaml:"dir,omitempty"`
+	// Files restricts a directory entry to the given files, relative to Path.
+	Files []string `yaml:"files,omitempty"`
+}
+
+// Export writes the falcoctl state found at paths to w, as a tar.gz bundle.
+// Missing files are skipped.
+func Export(ctx context.Context, w io.Writer, paths *Paths, opts Options) (*Metadata, error) {
+	meta := &Metadata{Version: bundleVersion, CreatedAt: time.Now().UTC()}
+
+	candidates := []Entry{
+		{Name: entryConfig, Path: paths.ConfigFile},
+		{Name: entryIndexes, Path: paths.IndexesFile},
+		{Name: entryIndexesCache, Path: paths.IndexesDir, Dir: true},
+		{Name: entryInstallManifest, Path: paths.InstallManifestFile},
+	}
+	if opts.IncludeSecrets {
+		candidates = append(candidates,
+			Entry{Name: entryClientCredentials, Path: paths.ClientCredentialsFile},
+			Entry{Name: entryRegistryCredentials, Path: paths.RegistryCredentialsFile})
+	}
+	if opts.IncludeBlobs {
+		manifest, err := installer.LoadManifest(paths.InstallManifestFile)
+		if err != nil {
+			return nil, err
+		}
+		for i := range manifest.Artifacts {
+			a := &manifest.Artifacts[i]
+			files := make([]string, 0, len(a.Files))
+			for _, f := range a.Files {
+				files = append(files, f.Path)
+			}
+			candidates = append(candidates, Entry{Name: fmt.Sprintf("%s%d", entryArtifactsPrefix, i), Path: a.Directory, Dir: true, Files: files})
+		}
+	}
+
+	for _, e := range candidates {
+		if e.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(e.Path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		meta.Entries = append(meta.Entries, e)
+	}
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	data, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeMember(tw, metadataFile, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	for _, e := range meta.Entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := exportEntry(tw, &e, opts); err != nil {
+			return nil, fmt.Errorf("unable to export %q: %w", e.Path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+func exportEntry(tw *tar.Writer, e *Entry, opts Options) error {
+	if !e.Dir {
+		data, info, err := readFile(e.Path)
+		if err != nil {
+			return err
+		}
+		if e.Name == entryConfig && !opts.IncludeSecrets {
+			if data, err = redactSecrets(data); err != nil {
+				return err
+			}
+		}
+		return writeMember(tw, e.Name, data, info.Mode().Perm())
+	}
+
+	if len(e.Files) > 0 {
+		for _, f := range e.Files {
+			data, info, err := readFile(filepath.Join(e.Path, f))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if err := writeMember(tw, e.Name+"/"+filepath.ToSlash(f), data, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return filepath.WalkDir(e.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(e.Path, path)
+		if err != nil {
+			return err
+		}
+		data, info, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		return writeMember(tw, e.Name+"/"+filepath.ToSlash(rel), data, info.Mode().Perm())
+	})
+}
+
+// Import restores the bundle read from r. The well known entries are restored to paths,
+// while the installed artifacts files are restored to the directories they were exported from,
+// provided that they are within paths.ArtifactDirs.
+func Import(ctx context.Context, r io.Reader, paths *Paths) (*Metadata, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedBundle, err)
+	}
+	tr := tar.NewReader(gzr)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != metadataFile {
+		return nil, fmt.Errorf("%w: missing %s", ErrUnsupportedBundle, metadataFile)
+	}
+	meta := &Metadata{}
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, meta); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedBundle, err)
+	}
+	if meta.Version != bundleVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedBundle, meta.Version)
+	}
+
+	destinations := map[string]string{
+		entryConfig:              paths.ConfigFile,
+		entryIndexes:             paths.IndexesFile,
+		entryIndexesCache:        paths.IndexesDir,
+		entryInstallManifest:     paths.InstallManifestFile,
+		entryClientCredentials:   paths.ClientCredentialsFile,
+		entryRegistryCredentials: paths.RegistryCredentialsFile,
+	}
+	for _, e := range meta.Entries {
+		if strings.HasPrefix(e.Name, entryArtifactsPrefix) {
+			if !withinDirs(e.Path, paths.ArtifactDirs) {
+				return nil, fmt.Errorf("%w: %q is outside the artifacts directories", ErrUnsupportedBundle, e.Path)
+			}
+			destinations[e.Name] = e.Path
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		dest, err := destination(destinations, header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := restoreFile(dest, tr, fs.FileMode(header.Mode).Perm()); err != nil {
+			return nil, fmt.Errorf("unable to restore %q: %w", dest, err)
+		}
+	}
+	return meta, nil
+}
+
+// destination maps a bundle member to the local path it must be restored to.
+func destination(destinations map[string]string, member string) (string, error) {
+	if strings.Contains(member, "..") {
+		return "", fmt.Errorf("%w: not allowed relative path %q", ErrUnsupportedBundle, member)
+	}
+	if dest, ok := destinations[member]; ok && dest != "" {
+		return dest, nil
+	}
+	for name, dest := range destinations {
+		if rel, ok := strings.CutPrefix(member, name+"/"); ok && dest != "" {
+			return filepath.Join(dest, filepath.FromSlash(rel)), nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown member %q", ErrUnsupportedBundle, member)
+}
+
+// withinDirs returns true if path is one of dirs, or is below one of them.
+func withinDirs(path string, dirs []string) bool {
+	if !filepath.IsAbs(path) {
+		return false
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+		if err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func restoreFile(dest string, r io.Reader, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Clean(dest), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { //nolint:gosec // the size of the bundle members is bound by the bundle itself.
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readFile(path string) ([]byte, fs.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, info, nil
+}
+
+func writeMember(tw *tar.Writer, name string, data []byte, perm fs.FileMode) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Size:     int64(len(data)),
+		Mode:     int64(perm),
+		Typeflag: tar.TypeReg,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/installer"
+)
+
+const testConfig = `indexes:
+- name: falcosecurity
+  url: https://falcosecurity.github.io/falcoctl/index.yaml
+- name: private
+  url: https://index.example.com/index.yaml
+  http:
+    username: index-user
+    password: index-password
+    token: index-token
+    headers:
+      X-Api-Key: index-api-key
+driver:
+  mirrors:
+  - url: https://mirror.example.com/driver
+    headers:
+      Authorization: Bearer mirror-token
+    clientCert: /etc/falcoctl/mirror.crt
+    clientKey: mirror-client-key
+registry:
+  auth:
+    basic:
+    - password: password
+      registry: myregistry.example.com:5000
+      user: user
+    oauth:
+    - registry: myregistry.example.com:5001
+      clientSecret: "999999"
+      clientID: "000000"
+registries:
+- host: ghcr.io
+  auth: basic
+  basic:
+    user: user
+    password: ghcr-secret
+`
+
+func newTestPaths(root string) *Paths {
+	return &Paths{
+		ConfigFile:              filepath.Join(root, "etc", "falcoctl.yaml"),
+		IndexesFile:             filepath.Join(root, "falcoctl", "indexes.yaml"),
+		IndexesDir:              filepath.Join(root, "falcoctl", "indexes"),
+		InstallManifestFile:     filepath.Join(root, "falcoctl", "installed.yaml"),
+		ClientCredentialsFile:   filepath.Join(root, "falcoctl", "clientcredentials.json"),
+		RegistryCredentialsFile: filepath.Join(root, "docker", "config.json"),
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func setupTestState(t *testing.T) (*Paths, string) {
+	root := t.TempDir()
+	paths := newTestPaths(root)
+	writeTestFile(t, paths.ConfigFile, testConfig)
+	writeTestFile(t, paths.IndexesFile, "- name: falcosecurity\n")
+	writeTestFile(t, filepath.Join(paths.IndexesDir, "falcosecurity.yaml"), "- name: k8saudit\n")
+	writeTestFile(t, paths.ClientCredentialsFile, `{"secret": true}`)
+	writeTestFile(t, paths.RegistryCredentialsFile, `{"auths": {}}`)
+
+	rulesDir := filepath.Join(root, "rules")
+	writeTestFile(t, filepath.Join(rulesDir, "k8saudit_rules.yaml"), "- rule: audit\n")
+	writeTestFile(t, filepath.Join(rulesDir, "unmanaged.yaml"), "- rule: local\n")
+	manifest := installer.Manifest{Artifacts: []installer.Artifact{{
+		Repository: "ghcr.io/falcosecurity/rules/k8saudit-rules",
+		Directory:  rulesDir,
+		Files:      []installer.File{{Path: "k8saudit_rules.yaml"}},
+	}}}
+	data, err := yaml.Marshal(manifest)
+	require.NoError(t, err)
+	writeTestFile(t, paths.InstallManifestFile, string(data))
+	return paths, rulesDir
+}
+
+func TestExportImport(t *testing.T) {
+	paths, _ := setupTestState(t)
+
+	var bundle bytes.Buffer
+	meta, err := Export(context.Background(), &bundle, paths, Options{})
+	require.NoError(t, err)
+	names := make([]string, 0, len(meta.Entries))
+	for _, e := range meta.Entries {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{entryConfig, entryIndexes, entryIndexesCache, entryInstallManifest}, names)
+
+	target := newTestPaths(t.TempDir())
+	_, err = Import(context.Background(), &bundle, target)
+	require.NoError(t, err)
+
+	config, err := os.ReadFile(target.ConfigFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(config), "password: password")
+	assert.NotContains(t, string(config), "999999")
+	assert.NotContains(t, string(config), "ghcr-secret")
+	assert.NotContains(t, string(config), "index-password")
+	assert.NotContains(t, string(config), "index-token")
+	assert.NotContains(t, string(config), "index-api-key")
+	assert.NotContains(t, string(config), "mirror-token")
+	assert.NotContains(t, string(config), "mirror-client-key")
+	assert.Contains(t, string(config), "user: user")
+	assert.Contains(t, string(config), "username: index-user")
+
+	cached, err := os.ReadFile(filepath.Join(target.IndexesDir, "falcosecurity.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "- name: k8saudit\n", string(cached))
+	assert.FileExists(t, target.InstallManifestFile)
+	assert.NoFileExists(t, target.ClientCredentialsFile)
+	assert.NoFileExists(t, target.RegistryCredentialsFile)
+}
+
+func TestExportImportWithSecretsAndBlobs(t *testing.T) {
+	paths, rulesDir := setupTestState(t)
+
+	var bundle bytes.Buffer
+	_, err := Export(context.Background(), &bundle, paths, Options{IncludeBlobs: true, IncludeSecrets: true})
+	require.NoError(t, err)
+
+	// Installed artifacts are restored to their original directory.
+	require.NoError(t, os.RemoveAll(rulesDir))
+
+	target := newTestPaths(t.TempDir())
+	target.ArtifactDirs = []string{filepath.Dir(rulesDir)}
+	_, err = Import(context.Background(), &bundle, target)
+	require.NoError(t, err)
+
+	config, err := os.ReadFile(target.ConfigFile)
+	require.NoError(t, err)
+	assert.Equal(t, testConfig, string(config))
+	assert.FileExists(t, target.ClientCredentialsFile)
+	assert.FileExists(t, target.RegistryCredentialsFile)
+	assert.FileExists(t, filepath.Join(rulesDir, "k8saudit_rules.yaml"))
+	assert.NoFileExists(t, filepath.Join(rulesDir, "unmanaged.yaml"))
+}
+
+func TestImportArtifactsOutsideDirs(t *testing.T) {
+	paths, rulesDir := setupTestState(t)
+
+	var bundle bytes.Buffer
+	_, err := Export(context.Background(), &bundle, paths, Options{IncludeBlobs: true})
+	require.NoError(t, err)
+	require.NoError(t, os.RemoveAll(rulesDir))
+
+	target := newTestPaths(t.TempDir())
+	target.ArtifactDirs = []string{"/etc/falco", rulesDir + "-other"}
+	_, err = Import(context.Background(), &bundle, target)
+	assert.ErrorIs(t, err, ErrUnsupportedBundle)
+	assert.ErrorContains(t, err, "is outside the artifacts directories")
+	assert.NoFileExists(t, filepath.Join(rulesDir, "k8saudit_rules.yaml"))
+}
+
+func TestImportInvalidBundle(t *testing.T) {
+	_, err := Import(context.Background(), bytes.NewBufferString("not a bundle"), newTestPaths(t.TempDir()))
+	assert.ErrorIs(t, err, ErrUnsupportedBundle)
+}
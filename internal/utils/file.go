@@ -16,6 +16,10 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -70,7 +74,73 @@ func replaceInFile(filePath, searchFor string, n int, replacementCB func(string)
 		}
 	}
 	newContent := strings.Join(lines, "\n")
-	return os.WriteFile(filePath, []byte(newContent), stat.Mode())
+	return WriteFileAtomic(filePath, []byte(newContent), stat.Mode())
+}
+
+// createTemp creates the temporary files of WriteFileAtomic. Replaced in tests.
+var createTemp = os.CreateTemp
+
+// WriteFileAtomic writes data to the named file, replacing it atomically so that it is never left partially written:
+// the data is written and synced to a temporary file in the same directory, which is then renamed to the file.
+// The mode and, where supported, the ownership of an existing file are preserved; perm is used for new files.
+// Symlinks are followed, so that the file they point to is replaced rather than the link itself.
+func WriteFileAtomic(name string, data []byte, perm os.FileMode) (err error) {
+	if resolved, err := filepath.EvalSymlinks(name); err == nil {
+		name = resolved
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	mode := perm
+	stat, err := os.Stat(name)
+	switch {
+	case err == nil:
+		mode = stat.Mode().Perm()
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	tmp, err := createTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if stat != nil {
+		if err = chownLike(tmp, stat); err != nil {
+			return err
+		}
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// FileDigest returns the hex encoded sha256 digest of the file at path.
+func FileDigest(path string) (string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // FileExists checks if a file exists on disk.
@@ -86,3 +156,20 @@ func FileExists(filename string) (bool, error) {
 
 	return !info.IsDir(), nil
 }
+
+// ResolveHostPath returns the path of the given host file under hostRoot, where the host filesystem is mounted
+// (e.g. /host when running in a container). An empty hostRoot means the root directory.
+// The parts are always relative to hostRoot, even if absolute, and must not contain ".." elements.
+func ResolveHostPath(hostRoot string, parts ...string) (string, error) {
+	for _, part := range parts {
+		for _, elem := range strings.Split(filepath.ToSlash(part), "/") {
+			if elem == ".." {
+				return "", fmt.Errorf("path %q escapes the host root", part)
+			}
+		}
+	}
+	if hostRoot == "" {
+		hostRoot = string(os.PathSeparator)
+	}
+	return filepath.Join(append([]string{hostRoot}, parts...)...), nil
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// chownLike gives the file the ownership described by info.
+func chownLike(f *os.File, info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	err := f.Chown(int(st.Uid), int(st.Gid))
+	// Unprivileged users may not be allowed to chown, e.g. on NFS: the failure only matters
+	// if the ownership differs.
+	if errors.Is(err, syscall.EPERM) && sameOwner(f, st) {
+		return nil
+	}
+	return err
+}
+
+// sameOwner returns whether the file is already owned by the uid and gid of st.
+func sameOwner(f *os.File, st *syscall.Stat_t) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	cur, ok := info.Sys().(*syscall.Stat_t)
+	return ok && cur.Uid == st.Uid && cur.Gid == st.Gid
+}
@@ -17,6 +17,7 @@ package utils
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -114,13 +115,8 @@ foo:
 	})
 
 	for _, test := range tests {
-		// Truncate and seek at beginning
-		err = file.Truncate(0)
-		require.NoError(t, err)
-		_, err = file.Seek(0, 0)
-		require.NoError(t, err)
-
-		_, err = file.WriteString(test.fileContent)
+		// The file is replaced on each update, write it by name.
+		err = os.WriteFile(file.Name(), []byte(test.fileContent), 0o600)
 		require.NoError(t, err)
 
 		err = ReplaceTextInFile(file.Name(), test.searchFor, test.replacementText, test.n)
@@ -224,13 +220,8 @@ testFoo
 	})
 
 	for _, test := range tests {
-		// Truncate and seek at beginning
-		err = file.Truncate(0)
-		require.NoError(t, err)
-		_, err = file.Seek(0, 0)
-		require.NoError(t, err)
-
-		_, err = file.WriteString(test.fileContent)
+		// The file is replaced on each update, write it by name.
+		err = os.WriteFile(file.Name(), []byte(test.fileContent), 0o600)
 		require.NoError(t, err)
 
 		err = ReplaceLineInFile(file.Name(), test.searchFor, test.replacementLine, test.n)
@@ -242,3 +233,120 @@ testFoo
 		assert.Equal(t, test.expectedFileContent, string(content))
 	}
 }
+
+func TestResolveHostPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		hostRoot    string
+		parts       []string
+		expected    string
+		expectedErr string
+	}{
+		{name: "empty host root", parts: []string{"/etc/falco/falco.yaml"}, expected: "/etc/falco/falco.yaml"},
+		{name: "root host root", hostRoot: "/", parts: []string{"etc", "falco", "falco.yaml"}, expected: "/etc/falco/falco.yaml"},
+		{name: "mounted host root", hostRoot: "/host", parts: []string{"/etc/falco/falco.yaml"}, expected: "/host/etc/falco/falco.yaml"},
+		{name: "mounted host root parts", hostRoot: "/host/", parts: []string{"/etc", "falco/", "falco.yaml"}, expected: "/host/etc/falco/falco.yaml"},
+		{name: "no parts", hostRoot: "/host", expected: "/host"},
+		{name: "traversal", hostRoot: "/host", parts: []string{"/etc/../../root/.ssh"}, expectedErr: `path "/etc/../../root/.ssh" escapes the host root`},
+		{name: "traversal part", parts: []string{"etc", ".."}, expectedErr: `path ".." escapes the host root`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ResolveHostPath(tt.hostRoot, tt.parts...)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, path)
+		})
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "falcoctl.yaml")
+
+	// New files get the given permissions.
+	require.NoError(t, WriteFileAtomic(name, []byte("driver: {}\n"), 0o600))
+	stat, err := os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+
+	// Existing files keep their permissions.
+	require.NoError(t, os.Chmod(name, 0o640))
+	require.NoError(t, WriteFileAtomic(name, []byte("driver:\n  type: [kmod]\n"), 0o600))
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "driver:\n  type: [kmod]\n", string(data))
+	stat, err = os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), stat.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "falco.yaml.d", "falco.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o750))
+	require.NoError(t, os.WriteFile(target, []byte("engine:\n  kind: kmod\n"), 0o640))
+	link := filepath.Join(dir, "falco.yaml")
+	require.NoError(t, os.Symlink(target, link))
+
+	require.NoError(t, WriteFileAtomic(link, []byte("engine:\n  kind: modern_ebpf\n"), 0o600))
+
+	// The link is kept, pointing to the updated file.
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink)
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "engine:\n  kind: modern_ebpf\n", string(data))
+	stat, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), stat.Mode().Perm())
+}
+
+func TestWriteFileAtomicWriteError(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "falco.yaml")
+	require.NoError(t, os.WriteFile(name, []byte("engine:\n  kind: kmod\n"), 0o600))
+
+	// Simulate a failing write, e.g. a full disk, returning a temporary file that cannot be written.
+	orig := createTemp
+	createTemp = func(dir, pattern string) (*os.File, error) {
+		f, err := orig(dir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		require.NoError(t, f.Close())
+		return os.Open(f.Name())
+	}
+	t.Cleanup(func() { createTemp = orig })
+
+	assert.Error(t, WriteFileAtomic(name, []byte("engine:\n  kind: modern_ebpf\n"), 0o600))
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "engine:\n  kind: kmod\n", string(data))
+
+	// The temporary file is cleaned up.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileDigest(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "falco_rules.yaml")
+	require.NoError(t, os.WriteFile(name, []byte("falco"), 0o600))
+
+	digest, err := FileDigest(name)
+	require.NoError(t, err)
+	assert.Equal(t, "f5bed22b9f4bed888f77f06c03a5d6aaef691682aa2820f6158919427f905194", digest)
+
+	_, err = FileDigest(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
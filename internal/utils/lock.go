@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLockTimeout is returned when a file lock is not acquired within the given timeout.
+var ErrLockTimeout = errors.New("timed out waiting for the file lock")
+
+// lockRetryInterval is the interval between two attempts at acquiring a held lock.
+var lockRetryInterval = 50 * time.Millisecond
+
+// LockFile acquires an exclusive advisory lock on the given lock file, creating it if needed.
+// It waits until the lock is released by its holder for at most timeout, zero meaning a single attempt.
+// The returned function releases the lock.
+func LockFile(name string, timeout time.Duration) (unlock func() error, err error) {
+	f, err := os.OpenFile(filepath.Clean(name), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if locked {
+			return func() error {
+				// Closing the file releases the lock.
+				return f.Close()
+			}, nil
+		}
+		if !time.Now().Before(deadline) {
+			_ = f.Close()
+			return nil, fmt.Errorf("%w %q after %s", ErrLockTimeout, name, timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	dir := t.TempDir()
+	lock := filepath.Join(dir, "counter.lock")
+	counter := filepath.Join(dir, "counter")
+	require.NoError(t, os.WriteFile(counter, []byte("0"), 0o600))
+
+	const runs = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := LockFile(lock, 10*time.Second)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer func() { _ = unlock() }()
+
+			data, err := os.ReadFile(counter)
+			if err == nil {
+				var n int
+				if n, err = strconv.Atoi(string(data)); err == nil {
+					time.Sleep(time.Millisecond)
+					err = WriteFileAtomic(counter, []byte(strconv.Itoa(n+1)), 0o600)
+				}
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(counter)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(runs), string(data))
+}
+
+func TestLockFileTimeout(t *testing.T) {
+	lock := filepath.Join(t.TempDir(), "falco.yaml.lock")
+	unlock, err := LockFile(lock, 0)
+	require.NoError(t, err)
+
+	_, err = LockFile(lock, 100*time.Millisecond)
+	assert.ErrorIs(t, err, ErrLockTimeout)
+
+	require.NoError(t, unlock())
+	unlock, err = LockFile(lock, 0)
+	require.NoError(t, err)
+	assert.NoError(t, unlock())
+}
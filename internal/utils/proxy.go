@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	allProxyEnv      = "ALL_PROXY"
+	allProxyEnvLower = "all_proxy"
+	noProxyEnv       = "NO_PROXY"
+	noProxyEnvLower  = "no_proxy"
+)
+
+var (
+	proxyMu     sync.RWMutex
+	socks5Proxy *url.URL
+)
+
+// ParseSOCKS5Proxy parses a SOCKS5 proxy address. The address can be given as "host:port",
+// "user:password@host:port" or as a full "socks5://" or "socks5h://" URL.
+func ParseSOCKS5Proxy(addr string) (*url.URL, error) {
+	if !strings.Contains(addr, "://") {
+		addr = "socks5://" + addr
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q: %w", addr, err)
+	}
+
+	if u.Scheme != "socks5" && u.Scheme != "socks5h" {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q: unsupported scheme %q", addr, u.Scheme)
+	}
+
+	if _, port, err := net.SplitHostPort(u.Host); err != nil || u.Hostname() == "" || port == "" {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q: expected host:port", addr)
+	}
+
+	return u, nil
+}
+
+// SetSOCKS5Proxy sets the SOCKS5 proxy used for all the outgoing requests towards
+// registries and indexes. A nil url restores the proxy configured in the environment.
+func SetSOCKS5Proxy(u *url.URL) {
+	proxyMu.Lock()
+	defer proxyMu.Unlock()
+
+	socks5Proxy = u
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.Proxy = Proxy
+	}
+}
+
+// Proxy is a drop-in replacement of http.ProxyFromEnvironment. It returns the SOCKS5 proxy set
+// through SetSOCKS5Proxy, if any. Otherwise, it falls back to the HTTP_PROXY and HTTPS_PROXY
+// environment variables and then to ALL_PROXY, while honoring NO_PROXY.
+func Proxy(req *http.Request) (*url.URL, error) {
+	proxyMu.RLock()
+	u := socks5Proxy
+	proxyMu.RUnlock()
+
+	if u != nil {
+		return u, nil
+	}
+
+	if u, err := http.ProxyFromEnvironment(req); u != nil || err != nil {
+		return u, err
+	}
+
+	all := getEnvAny(allProxyEnv, allProxyEnvLower)
+	if all == "" {
+		return nil, nil
+	}
+
+	cfg := &httpproxy.Config{
+		HTTPProxy:  all,
+		HTTPSProxy: all,
+		NoProxy:    getEnvAny(noProxyEnv, noProxyEnvLower),
+	}
+	return cfg.ProxyFunc()(req.URL)
+}
+
+func getEnvAny(names ...string) string {
+	for _, n := range names {
+		if val := os.Getenv(n); val != "" {
+			return val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	tlsMu         sync.RWMutex
+	insecureHosts []string
+)
+
+// SetTLS sets the TLS settings of all the outgoing requests. The CA certificates of caBundle, e.g. the one of a
+// TLS-intercepting proxy, are trusted on top of the system ones. The certificate of the hosts, with an optional
+// port, in hosts is not verified.
+// The settings are applied to http.DefaultTransport, and http.DefaultClient is set to use Transport.
+func SetTLS(caBundle string, hosts []string) error {
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unable to configure TLS on a non-standard http transport")
+	}
+
+	if caBundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		caPEM, err := os.ReadFile(caBundle)
+		if err != nil {
+			return fmt.Errorf("unable to read CA bundle %q: %w", caBundle, err)
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("no valid certificates found in CA bundle %q", caBundle)
+		}
+
+		var cfg *tls.Config
+		if t.TLSClientConfig != nil {
+			cfg = t.TLSClientConfig.Clone()
+		} else {
+			cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		cfg.RootCAs = pool
+		t.TLSClientConfig = cfg
+	}
+
+	tlsMu.Lock()
+	insecureHosts = hosts
+	tlsMu.Unlock()
+
+	http.DefaultClient.Transport = Transport()
+	return nil
+}
+
+// TLSConfig returns a copy of the TLS configuration of http.DefaultTransport, to be used by the
+// transports not derived from it.
+func TLSConfig() *tls.Config {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok && t.TLSClientConfig != nil {
+		return t.TLSClientConfig.Clone()
+	}
+	return nil
+}
+
+// Transport returns the round tripper used for all the outgoing requests: http.DefaultTransport,
+// not verifying the certificate of the insecure hosts set through SetTLS.
+func Transport() http.RoundTripper {
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return http.DefaultTransport
+	}
+	return InsecureHostsTransport(t)
+}
+
+// InsecureHostsTransport returns a round tripper sending the requests towards the insecure hosts
+// set through SetTLS to a copy of base not verifying the server certificate, and the others to base.
+func InsecureHostsTransport(base *http.Transport) http.RoundTripper {
+	return &insecureHostsTransport{base: base}
+}
+
+type insecureHostsTransport struct {
+	base     *http.Transport
+	once     sync.Once
+	insecure *http.Transport
+}
+
+func (t *insecureHostsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !IsInsecureHost(req.URL.Host) {
+		return t.base.RoundTrip(req)
+	}
+
+	t.once.Do(func() {
+		t.insecure = t.base.Clone()
+		if t.insecure.TLSClientConfig == nil {
+			t.insecure.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		t.insecure.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec // explicitly requested by the user for this host.
+	})
+	return t.insecure.RoundTrip(req)
+}
+
+// IsInsecureHost returns true if the certificate of the given host, with an optional port, is not verified.
+func IsInsecureHost(host string) bool {
+	tlsMu.RLock()
+	defer tlsMu.RUnlock()
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	for _, h := range insecureHosts {
+		if strings.EqualFold(h, host) || strings.EqualFold(h, hostname) {
+			return true
+		}
+	}
+	return false
+}
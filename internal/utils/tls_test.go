@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetTLS(t *testing.T) {
+	tr := http.DefaultTransport.(*http.Transport)
+	prevCfg, prevTransport := tr.TLSClientConfig, http.DefaultClient.Transport
+	t.Cleanup(func() {
+		tr.TLSClientConfig, http.DefaultClient.Transport = prevCfg, prevTransport
+		insecureHosts = nil
+	})
+
+	trusted := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer trusted.Close()
+	insecure := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer insecure.Close()
+	insecureURL, err := url.Parse(insecure.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(u string) error {
+		resp, err := http.Get(u)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+	if err := get(trusted.URL); err == nil {
+		t.Fatal("expected an error with an unknown CA")
+	}
+
+	// The certificate of the insecure hosts only is not verified.
+	if err := SetTLS("", []string{insecureURL.Host}); err != nil {
+		t.Fatal(err)
+	}
+	if err := get(insecure.URL); err != nil {
+		t.Errorf("expected the certificate of the insecure host not to be verified: %v", err)
+	}
+	if err := get(trusted.URL); err == nil {
+		t.Error("expected an error with an unknown CA")
+	}
+	if !IsInsecureHost(insecureURL.Host) || IsInsecureHost("example.com:443") {
+		t.Error("unexpected insecure hosts matching")
+	}
+
+	// httptest servers share the same certificate.
+	bundle := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: trusted.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetTLS(bundle, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := get(trusted.URL); err != nil {
+		t.Errorf("expected the CA bundle to be trusted: %v", err)
+	}
+
+	if err := SetTLS(filepath.Join(t.TempDir(), "missing.crt"), nil); err == nil {
+		t.Error("expected an error with a missing CA bundle")
+	}
+}
@@ -18,11 +18,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/falcosecurity/falcoctl/cmd"
+	driverconfig "github.com/falcosecurity/falcoctl/cmd/driver/config"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 )
 
@@ -52,6 +54,10 @@ func main() {
 
 	// Execute the command.
 	if err := cmd.Execute(rootCmd, opt); err != nil {
+		// A distinct exit code lets scripts tell a skipped driver config update from a failure.
+		if errors.Is(err, driverconfig.ErrEngineNotDriverDriven) {
+			os.Exit(2)
+		}
 		os.Exit(1)
 	}
 	os.Exit(0)
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverdistro
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+// digestSuffix is the suffix of the file holding the sha256 of a cached driver, next to it.
+const digestSuffix = ".sha256"
+
+// BuildCache keeps the drivers successfully built by Build, so that an identical build is not
+// run again, e.g. when a node reboots or its init container runs again. The drivers are stored as
+// <dir>/<driver version>/<arch>/<kernel release>/<kernel config sha256>/<file name>, along with their
+// own sha256, checked before reusing them.
+type BuildCache struct {
+	Dir string
+}
+
+// NewBuildCache returns the build cache in dir.
+func NewBuildCache(dir string) *BuildCache {
+	return &BuildCache{Dir: dir}
+}
+
+// driverPath returns where the driver file, built for the kernel release, is cached, keyed by the
+// digest of the kernel config.
+func (c *BuildCache) driverPath(kr *kernelrelease.KernelRelease, driverVer, fileName string) (string, error) {
+	configPath, ok := findKernelConfig(kr)
+	if !ok {
+		return "", fmt.Errorf("cannot find kernel config")
+	}
+	configDigest, err := utils.FileDigest(configPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c.Dir, driverVer, kr.Architecture.ToNonDeb(), kr.String(), configDigest, fileName), nil
+}
+
+// restore copies the driver cached at path to dest. It returns false if it is not cached, or if it has
+// been modified since it was stored.
+func (c *BuildCache) restore(path, dest string) (bool, error) {
+	want, err := os.ReadFile(filepath.Clean(path + digestSuffix))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if got, err := utils.FileDigest(path); err != nil || got != strings.TrimSpace(string(want)) {
+		return false, err
+	}
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return false, err
+	}
+	return true, copyDataToLocalPath(dest, f)
+}
+
+// store copies the driver built at src to path, in the cache.
+func (c *BuildCache) store(src, path string) error {
+	digest, err := utils.FileDigest(src)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return err
+	}
+	if err := copyDataToLocalPath(path, f); err != nil {
+		return err
+	}
+	// The digest is written last, so that a driver partially stored is never reused.
+	return os.WriteFile(path+digestSuffix, []byte(digest+"\n"), 0o600)
+}
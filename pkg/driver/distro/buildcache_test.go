@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverdistro
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewBuildCache(filepath.Join(dir, "cache"))
+	cached := filepath.Join(cache.Dir, "7.0.0+driver", "x86_64", "6.1.0-12-amd64", "abcd", "falco_debian_6.1.0-12-amd64_1.ko")
+	dest := filepath.Join(dir, "dest", "falco.ko")
+
+	found, err := cache.restore(cached, dest)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	built := filepath.Join(dir, "built.ko")
+	require.NoError(t, os.WriteFile(built, []byte("kmod"), 0o600))
+	require.NoError(t, cache.store(built, cached))
+
+	found, err = cache.restore(cached, dest)
+	require.NoError(t, err)
+	assert.True(t, found)
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "kmod", string(data))
+
+	// A driver modified since it was stored is not reused.
+	require.NoError(t, os.WriteFile(cached, []byte("corrupted"), 0o600))
+	require.NoError(t, os.Remove(dest))
+	found, err = cache.restore(cached, dest)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.NoFileExists(t, dest)
+}
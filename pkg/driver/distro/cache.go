@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverdistro
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+)
+
+// CachedDriver is a driver artifact found in the local drivers directory.
+type CachedDriver struct {
+	Path          string
+	Name          string
+	Type          string
+	Version       string
+	Arch          string
+	Distro        string
+	KernelRelease string
+	KernelVersion string
+	ModTime       time.Time
+}
+
+// ListCached returns the driver artifacts stored in the local drivers directory,
+// as laid out by Download and Build: <dir>/<driver version>/<arch>/<name>_<distro>_<kernel release>_<kernel version><ext>.
+// Files not following that layout are ignored. Artifacts are sorted by name, newest first.
+func ListCached() ([]CachedDriver, error) {
+	root := localDriversDir()
+	cached := make([]CachedDriver, 0)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == root {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(rel, string(os.PathSeparator))
+		if len(parts) != 3 {
+			return nil
+		}
+		c, ok := parseCachedDriver(parts[2])
+		if !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		c.Path = path
+		c.Version = parts[0]
+		c.Arch = parts[1]
+		c.ModTime = info.ModTime()
+		cached = append(cached, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(cached, func(i, j int) bool {
+		if cached[i].Name != cached[j].Name {
+			return cached[i].Name < cached[j].Name
+		}
+		return cached[i].ModTime.After(cached[j].ModTime)
+	})
+	return cached, nil
+}
+
+// parseCachedDriver parses a driver file name, splitting it from the right
+// since the driver name is the only component that may contain underscores.
+func parseCachedDriver(fileName string) (CachedDriver, bool) {
+	var c CachedDriver
+	for _, t := range drivertype.GetTypes() {
+		dType, err := drivertype.Parse(t)
+		if err != nil || !dType.HasArtifacts() {
+			continue
+		}
+		if strings.HasSuffix(fileName, dType.Extension()) {
+			c.Type = dType.String()
+			fileName = strings.TrimSuffix(fileName, dType.Extension())
+			break
+		}
+	}
+	if c.Type == "" {
+		return c, false
+	}
+
+	parts := strings.Split(fileName, "_")
+	if len(parts) < 4 {
+		return c, false
+	}
+	n := len(parts)
+	c.KernelVersion = parts[n-1]
+	c.KernelRelease = parts[n-2]
+	c.Distro = parts[n-3]
+	c.Name = strings.Join(parts[:n-3], "_")
+	return c, true
+}
+
+// IsRunning returns true if the cached driver is the one that would be used for the given distro and kernel.
+//
+//nolint:gocritic // the method shall not be able to modify kr
+func (c *CachedDriver) IsRunning(d Distro, kr kernelrelease.KernelRelease) bool {
+	dType, err := drivertype.Parse(c.Type)
+	if err != nil {
+		return false
+	}
+	return filepath.Base(c.Path) == toFilename(d, &kr, c.Name, dType)
+}
+
+// ToPrune returns the cached drivers to be removed, keeping the keep newest ones for each driver name.
+// Cached must be sorted as returned by ListCached. Drivers for which protected returns true are never pruned,
+// and are not accounted in the kept ones.
+func ToPrune(cached []CachedDriver, keep int, protected func(c *CachedDriver) bool) []CachedDriver {
+	prune := make([]CachedDriver, 0)
+	kept := make(map[string]int)
+	for i := range cached {
+		c := &cached[i]
+		if protected != nil && protected(c) {
+			continue
+		}
+		if kept[c.Name] < keep {
+			kept[c.Name]++
+			continue
+		}
+		prune = append(prune, *c)
+	}
+	return prune
+}
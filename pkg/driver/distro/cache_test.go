@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverdistro
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCachedAndPrune(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	now := time.Now()
+	files := []struct {
+		path string
+		age  time.Duration
+	}{
+		{"7.0.0+driver/x86_64/falco_debian_6.1.0-9-amd64_1.ko", 4 * time.Hour},
+		{"7.0.0+driver/x86_64/falco_debian_6.1.0-10-amd64_1.ko", 3 * time.Hour},
+		{"7.0.0+driver/x86_64/falco_debian_6.1.0-11-amd64_1.ko", 2 * time.Hour},
+		{"7.0.0+driver/x86_64/falco_debian_6.1.0-12-amd64_1.o", time.Hour},
+		{"7.0.0+driver/x86_64/my_driver_debian_6.1.0-12-amd64_1.ko", time.Hour},
+		{"7.0.0+driver/x86_64/README.md", time.Hour},
+		{"stray_debian_6.1.0-12-amd64_1.ko", time.Hour},
+	}
+	for _, f := range files {
+		p := filepath.Join(home, ".falco", f.path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, nil, 0o600))
+		require.NoError(t, os.Chtimes(p, now.Add(-f.age), now.Add(-f.age)))
+	}
+
+	cached, err := ListCached()
+	require.NoError(t, err)
+	require.Len(t, cached, 5)
+	assert.Equal(t, "falco", cached[0].Name)
+	assert.Equal(t, "ebpf", cached[0].Type)
+	assert.Equal(t, "6.1.0-12-amd64", cached[0].KernelRelease)
+	assert.Equal(t, "7.0.0+driver", cached[0].Version)
+	assert.Equal(t, "x86_64", cached[0].Arch)
+	assert.Equal(t, "debian", cached[0].Distro)
+	assert.Equal(t, "my_driver", cached[4].Name)
+	assert.Equal(t, "kmod", cached[4].Type)
+
+	d := &generic{targetID: "debian"}
+	kr := kernelrelease.FromString("6.1.0-9-amd64")
+	kr.KernelVersion = "#1 SMP PREEMPT_DYNAMIC Debian 6.1.27-1 (2023-05-08)"
+	require.True(t, cached[3].IsRunning(d, kr))
+
+	prune := ToPrune(cached, 1, func(c *CachedDriver) bool {
+		return c.IsRunning(d, kr)
+	})
+	pruned := make([]string, 0, len(prune))
+	for _, c := range prune {
+		pruned = append(pruned, filepath.Base(c.Path))
+	}
+	assert.Equal(t, []string{"falco_debian_6.1.0-11-amd64_1.ko", "falco_debian_6.1.0-10-amd64_1.ko"}, pruned)
+
+	assert.Empty(t, ToPrune(cached, 5, nil))
+}
+
+func TestListCachedMissingDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cached, err := ListCached()
+	require.NoError(t, err)
+	assert.Empty(t, cached)
+}
@@ -102,7 +102,11 @@ func Discover(kr kernelrelease.KernelRelease, hostroot string) (Distro, error) {
 }
 
 func getOSReleaseDistro(kr *kernelrelease.KernelRelease) (Distro, error) {
-	cfg, err := ini.Load(hostRoot + "/etc/os-release")
+	osRelease, err := utils.ResolveHostPath(hostRoot, "/etc/os-release")
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ini.Load(osRelease)
 	if err != nil {
 		return nil, err
 	}
@@ -133,8 +137,20 @@ func toURL(repo, driverVer, fileName, arch string) string {
 	return fmt.Sprintf("%s/%s/%s/%s", repo, url.QueryEscape(driverVer), arch, fileName)
 }
 
+func localDriversDir() string {
+	return filepath.Join(homedir.Get(), ".falco")
+}
+
+// LocalPath returns the path where the driver of the given type, for the distro and kernel release,
+// is stored once downloaded or built.
+//
+//nolint:gocritic // the method shall not be able to modify kr
+func LocalPath(d Distro, kr kernelrelease.KernelRelease, driverName string, driverType drivertype.DriverType, driverVer string) string {
+	return toLocalPath(driverVer, toFilename(d, &kr, driverName, driverType), kr.Architecture.ToNonDeb())
+}
+
 func toLocalPath(driverVer, fileName, arch string) string {
-	return fmt.Sprintf("%s/.falco/%s/%s/%s", homedir.Get(), driverVer, arch, fileName)
+	return fmt.Sprintf("%s/%s/%s/%s", localDriversDir(), driverVer, arch, fileName)
 }
 
 func toFilename(d Distro, kr *kernelrelease.KernelRelease, driverName string, driverType drivertype.DriverType) string {
@@ -160,6 +176,7 @@ func copyDataToLocalPath(destination string, src io.ReadCloser) error {
 }
 
 // Build will try to build the desired driver for the specified distro and kernel release.
+// The driver is first looked up in the build cache, if not nil, and stored in it once built.
 //
 //nolint:gocritic // the method shall not be able to modify kr
 func Build(ctx context.Context,
@@ -169,6 +186,7 @@ func Build(ctx context.Context,
 	driverName string,
 	driverType drivertype.DriverType,
 	driverVer string,
+	cache *BuildCache,
 ) (string, error) {
 	printer.Logger.Info("Trying to compile the requested driver")
 	driverFileName := toFilename(d, &kr, driverName, driverType)
@@ -177,6 +195,20 @@ func Build(ctx context.Context,
 		return destPath, ErrAlreadyPresent
 	}
 
+	// The cache is only an optimization: failing to use it never fails the build.
+	var cachedPath string
+	if cache != nil {
+		var err error
+		if cachedPath, err = cache.driverPath(&kr, driverVer, driverFileName); err != nil {
+			printer.Logger.Warn("Unable to use the driver build cache.", printer.Logger.Args("err", err))
+		} else if found, err := cache.restore(cachedPath, destPath); err != nil {
+			printer.Logger.Warn("Unable to restore the driver from the build cache.", printer.Logger.Args("err", err))
+		} else if found {
+			printer.Logger.Info("Driver found in the build cache.", printer.Logger.Args("path", cachedPath))
+			return destPath, nil
+		}
+	}
+
 	env, err := d.customizeBuild(ctx, printer, driverType, kr)
 	if err != nil {
 		return "", err
@@ -196,6 +228,13 @@ func Build(ctx context.Context,
 	}
 	srcPath := fmt.Sprintf("/usr/src/%s-%s", driverName, driverVer)
 	err = driverbuilder.NewLocalBuildProcessor(true, downloadHeaders, true, srcPath, env, 1000).Start(ro.ToBuild(printer))
+	if err == nil && cachedPath != "" {
+		if storeErr := cache.store(destPath, cachedPath); storeErr != nil {
+			printer.Logger.Warn("Unable to store the driver in the build cache.", printer.Logger.Args("err", storeErr))
+		} else {
+			printer.Logger.Info("Driver stored in the build cache.", printer.Logger.Args("path", cachedPath))
+		}
+	}
 	return destPath, err
 }
 
@@ -233,7 +272,11 @@ func getDKRootOptions(d Distro,
 	return ro, nil
 }
 
-// Download will try to download drivers for a distro trying specified repos.
+// Download will try to download drivers for a distro trying specified repos,
+// using the given http client (http.DefaultClient if nil). The repos prefixed by OCIRepoPrefix
+// are OCI repositories, where the drivers are published by Publish; they are skipped if ociOpts is nil.
+// The repos having an entry in mirrors are downloaded from using its headers and client.
+// On failure, the returned error lists the outcome of every tried repo.
 //
 //nolint:gocritic // the method shall not be able to modify kr
 func Download(ctx context.Context,
@@ -244,7 +287,13 @@ func Download(ctx context.Context,
 	driverType drivertype.DriverType,
 	driverVer string, repos []string,
 	httpHeaders string,
+	client HTTPClient,
+	ociOpts *OCIOptions,
+	mirrors []Mirror,
 ) (string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
 	driverFileName := toFilename(d, &kr, driverName, driverType)
 	// Skip if existent
 	destination := toLocalPath(driverVer, driverFileName, kr.Architecture.ToNonDeb())
@@ -254,13 +303,32 @@ func Download(ctx context.Context,
 
 	// Try to download from any specified repository,
 	// stopping at first successful http GET.
+	var (
+		rejectedErr error
+		failures    []string
+	)
 	for _, repo := range repos {
+		if strings.HasPrefix(repo, OCIRepoPrefix) {
+			if ociOpts == nil {
+				continue
+			}
+			ref := ociRef(repo, driverVer, kr.Architecture.ToNonDeb(), driverFileName)
+			printer.Logger.Info("Trying to download a driver.", printer.Logger.Args("ref", ref))
+			if err := pullFromOCI(ctx, ociOpts, ref, destination); err != nil {
+				printer.Logger.Warn("Error pulling ref.", printer.Logger.Args("ref", ref, "err", err))
+				failures = append(failures, fmt.Sprintf("%s: %v", ref, err))
+				continue
+			}
+			printer.Logger.Info("Driver served by repo.", printer.Logger.Args("ref", ref))
+			return destination, nil
+		}
 		driverURL := toURL(repo, driverVer, driverFileName, kr.Architecture.ToNonDeb())
 		printer.Logger.Info("Trying to download a driver.", printer.Logger.Args("url", driverURL))
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, driverURL, nil)
 		if err != nil {
 			printer.Logger.Warn("Error creating http request.", printer.Logger.Args("err", err))
+			failures = append(failures, fmt.Sprintf("%s: %v", driverURL, err))
 			continue
 		}
 		if httpHeaders != "" {
@@ -274,19 +342,85 @@ func Download(ctx context.Context,
 			}
 			req.Header = header
 		}
-		resp, err := http.DefaultClient.Do(req)
+		repoClient := client
+		if mirror := mirrorFor(mirrors, repo); mirror != nil {
+			for key, value := range mirror.Headers {
+				req.Header.Set(key, value)
+			}
+			if mirror.Client != nil {
+				repoClient = mirror.Client
+			}
+		}
+		resp, err := repoClient.Do(req)
 		if err != nil || resp.StatusCode != 200 {
 			if err == nil {
 				_ = resp.Body.Close()
-				printer.Logger.Warn("Non-200 response from url.", printer.Logger.Args("code", resp.StatusCode))
+				printer.Logger.Warn("Non-200 response from url.", printer.Logger.Args("url", driverURL, "code", resp.StatusCode))
+				failures = append(failures, fmt.Sprintf("%s: %s", driverURL, resp.Status))
+			} else if errors.Is(err, ErrClientCertRejected) {
+				rejectedErr = err
+				printer.Logger.Warn("Client certificate rejected by the repository, check --repo-client-cert and --repo-client-key.",
+					printer.Logger.Args("err", err))
+				failures = append(failures, fmt.Sprintf("%s: client certificate rejected", driverURL))
 			} else {
-				printer.Logger.Warn("Error GETting url.", printer.Logger.Args("err", err))
+				printer.Logger.Warn("Error GETting url.", printer.Logger.Args("url", driverURL, "err", err))
+				failures = append(failures, fmt.Sprintf("%s: %v", driverURL, err))
 			}
 			continue
 		}
+		printer.Logger.Info("Driver served by repo.", printer.Logger.Args("url", driverURL))
 		return destination, copyDataToLocalPath(destination, resp.Body)
 	}
-	return destination, fmt.Errorf("unable to find a prebuilt driver")
+	tried := ""
+	if len(failures) > 0 {
+		tried = fmt.Sprintf(" (tried %s)", strings.Join(failures, "; "))
+	}
+	if rejectedErr != nil {
+		return destination, fmt.Errorf("unable to find a prebuilt driver%s: %w", tried, rejectedErr)
+	}
+	return destination, fmt.Errorf("unable to find a prebuilt driver%s", tried)
+}
+
+// CopyFromDir will try to copy the driver for a distro from dir, laid out like a download repo,
+// i.e. <dir>/<driver version>/<arch>/<driver file>, without reaching the network.
+//
+//nolint:gocritic // the method shall not be able to modify kr
+func CopyFromDir(d Distro,
+	printer *output.Printer,
+	kr kernelrelease.KernelRelease,
+	driverName string,
+	driverType drivertype.DriverType,
+	driverVer, dir string,
+) (string, error) {
+	driverFileName := toFilename(d, &kr, driverName, driverType)
+	// Skip if existent
+	destination := toLocalPath(driverVer, driverFileName, kr.Architecture.ToNonDeb())
+	if exist, _ := utils.FileExists(destination); exist {
+		return destination, ErrAlreadyPresent
+	}
+
+	// Both the plain and the escaped driver version are accepted, the latter being
+	// the name of the directory when it is mirrored from an http repo.
+	versions := []string{driverVer}
+	if escaped := url.QueryEscape(driverVer); escaped != driverVer {
+		versions = append(versions, escaped)
+	}
+	for _, ver := range versions {
+		src := filepath.Join(dir, ver, kr.Architecture.ToNonDeb(), driverFileName)
+		printer.Logger.Info("Trying to copy a driver.", printer.Logger.Args("path", src))
+		f, err := os.Open(filepath.Clean(src))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return destination, fmt.Errorf("unable to open driver %q: %w", src, err)
+		}
+		if err = copyDataToLocalPath(destination, f); err != nil {
+			return destination, fmt.Errorf("unable to copy driver %q: %w", src, err)
+		}
+		return destination, nil
+	}
+	return destination, fmt.Errorf("unable to find a prebuilt driver in %q", dir)
 }
 
 func customizeDownloadKernelSrcBuild(printer *output.Printer, kr *kernelrelease.KernelRelease) error {
@@ -305,28 +439,39 @@ func customizeDownloadKernelSrcBuild(printer *output.Printer, kr *kernelrelease.
 }
 
 func getKernelConfig(printer *output.Printer, kr *kernelrelease.KernelRelease) (string, error) {
+	path, ok := findKernelConfig(kr)
+	if !ok {
+		return "", fmt.Errorf("cannot find kernel config")
+	}
+	printer.Logger.Info("Found kernel config.", printer.Logger.Args("path", path))
+	return path, nil
+}
+
+// findKernelConfig returns the path of the config of the kernel release, if found.
+func findKernelConfig(kr *kernelrelease.KernelRelease) (string, bool) {
 	bootConfig := fmt.Sprintf("/boot/config-%s", kr.String())
-	hrBootConfig := fmt.Sprintf("%s%s", hostRoot, bootConfig)
 	ostreeConfig := fmt.Sprintf("/usr/lib/ostree-boot/config-%s", kr.String())
-	hrostreeConfig := fmt.Sprintf("%s%s", hostRoot, ostreeConfig)
 	libModulesConfig := fmt.Sprintf("/lib/modules/%s/config", kr.String())
 
 	toBeChecked := []string{
 		"/proc/config.gz",
 		bootConfig,
-		hrBootConfig,
-		ostreeConfig,
-		hrostreeConfig,
-		libModulesConfig,
 	}
+	if hrBootConfig, err := utils.ResolveHostPath(hostRoot, bootConfig); err == nil {
+		toBeChecked = append(toBeChecked, hrBootConfig)
+	}
+	toBeChecked = append(toBeChecked, ostreeConfig)
+	if hrostreeConfig, err := utils.ResolveHostPath(hostRoot, ostreeConfig); err == nil {
+		toBeChecked = append(toBeChecked, hrostreeConfig)
+	}
+	toBeChecked = append(toBeChecked, libModulesConfig)
 
 	for _, path := range toBeChecked {
 		if exist, _ := utils.FileExists(path); exist {
-			printer.Logger.Info("Found kernel config.", printer.Logger.Args("path", path))
-			return path, nil
+			return path, true
 		}
 	}
-	return "", fmt.Errorf("cannot find kernel config")
+	return "", false
 }
 
 func downloadKernelSrc(ctx context.Context,
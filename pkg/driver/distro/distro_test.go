@@ -16,14 +16,19 @@
 package driverdistro
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/pterm/pterm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/ini.v1"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/output"
 )
 
 func TestDiscoverDistro(t *testing.T) {
@@ -211,3 +216,34 @@ func TestDiscoverDistro(t *testing.T) {
 		tCase.postFn()
 	}
 }
+
+func TestCopyFromDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	d := &generic{targetID: "debian"}
+	kr := kernelrelease.FromString("6.1.0-9-amd64")
+	kr.KernelVersion = "#1 SMP PREEMPT_DYNAMIC Debian 6.1.27-1 (2023-05-08)"
+	kr.Architecture = "amd64"
+	driverType, err := drivertype.Parse(drivertype.TypeKmod)
+	require.NoError(t, err)
+	printer := output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, io.Discard)
+
+	_, err = CopyFromDir(d, printer, kr, "falco", driverType, "7.0.0+driver", dir)
+	assert.ErrorContains(t, err, "unable to find a prebuilt driver")
+
+	// The directory can be mirrored from an http repo, having the driver version escaped.
+	src := filepath.Join(dir, "7.0.0%2Bdriver", "x86_64", toFilename(d, &kr, "falco", driverType))
+	require.NoError(t, os.MkdirAll(filepath.Dir(src), 0o750))
+	require.NoError(t, os.WriteFile(src, []byte("kmod"), 0o600))
+
+	dest, err := CopyFromDir(d, printer, kr, "falco", driverType, "7.0.0+driver", dir)
+	require.NoError(t, err)
+	assert.Equal(t, LocalPath(d, kr, "falco", driverType, "7.0.0+driver"), dest)
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "kmod", string(data))
+
+	_, err = CopyFromDir(d, printer, kr, "falco", driverType, "7.0.0+driver", dir)
+	assert.ErrorIs(t, err, ErrAlreadyPresent)
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverdistro
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+// HTTPClient is the interface used to perform http requests when downloading drivers and their companion files.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ErrClientCertRejected is the error returned when a repository rejects the configured client certificate.
+var ErrClientCertRejected = errors.New("client certificate rejected by the repository")
+
+// MTLSOptions holds the client certificate, key and CA bundle used to authenticate against driver repositories
+// requiring mutual TLS.
+type MTLSOptions struct {
+	// ClientCert is the path to the PEM encoded client certificate.
+	ClientCert string
+	// ClientKey is the path to the PEM encoded client key.
+	ClientKey string
+	// CACert is the path to the PEM encoded CA bundle used to verify the repository.
+	CACert string
+	// Hosts restricts the mTLS configuration to the given hosts (with an optional port).
+	// When empty, it is applied to every host.
+	Hosts []string
+}
+
+// Enabled returns true if any of the mTLS settings is set.
+func (o *MTLSOptions) Enabled() bool {
+	return o != nil && (o.ClientCert != "" || o.ClientKey != "" || o.CACert != "")
+}
+
+func (o *MTLSOptions) matches(host string) bool {
+	if len(o.Hosts) == 0 {
+		return true
+	}
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	for _, h := range o.Hosts {
+		if strings.EqualFold(h, host) || strings.EqualFold(h, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *MTLSOptions) tlsConfig(base *tls.Config) (*tls.Config, error) {
+	var cfg *tls.Config
+	if base != nil {
+		cfg = base.Clone()
+	} else {
+		cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if (o.ClientCert == "") != (o.ClientKey == "") {
+		return nil, fmt.Errorf("both client certificate and client key must be set")
+	}
+	if o.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate %q: %w", o.ClientCert, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if o.CACert != "" {
+		caPEM, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA certificate %q: %w", o.CACert, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificates found in CA file %q", o.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// hostTransport routes requests towards the mTLS transport only for the configured hosts.
+type hostTransport struct {
+	mtls     *MTLSOptions
+	mTLSRT   http.RoundTripper
+	fallback http.RoundTripper
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.mtls.matches(req.URL.Host) {
+		return t.fallback.RoundTrip(req)
+	}
+	resp, err := t.mTLSRT.RoundTrip(req)
+	if err != nil && isClientCertRejection(err) {
+		return nil, fmt.Errorf("%w (host %q): %w", ErrClientCertRejected, req.URL.Host, err)
+	}
+	return resp, err
+}
+
+// NewHTTPClient returns an http client derived from http.DefaultClient, that presents the mTLS
+// client certificate to the configured hosts. If mtls is not enabled, http.DefaultClient is returned.
+func NewHTTPClient(mtls *MTLSOptions) (*http.Client, error) {
+	if !mtls.Enabled() {
+		return http.DefaultClient, nil
+	}
+
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unable to configure mTLS on a non-standard http transport")
+	}
+	cfg, err := mtls.tlsConfig(base.TLSClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	tr := base.Clone()
+	tr.TLSClientConfig = cfg
+
+	return &http.Client{
+		Transport: &hostTransport{
+			mtls:     mtls,
+			mTLSRT:   utils.InsecureHostsTransport(tr),
+			fallback: utils.Transport(),
+		},
+		Timeout: http.DefaultClient.Timeout,
+	}, nil
+}
+
+// Mirror holds the settings used to download the drivers from a driver repo, in place of the global ones.
+type Mirror struct {
+	// URL is the driver repo the settings apply to.
+	URL string
+	// Headers are added to the requests to the repo, overriding the global ones with the same name.
+	Headers map[string]string
+	// Client, when set, is used instead of the global http client.
+	Client HTTPClient
+}
+
+// NewMirrorClient returns an http client derived from http.DefaultClient, configured with the TLS
+// settings of a single driver repo. The mTLS hosts are ignored, since the client is only used for the repo.
+func NewMirrorClient(mtls *MTLSOptions, insecure bool) (*http.Client, error) {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unable to configure TLS on a non-standard http transport")
+	}
+	cfg, err := mtls.tlsConfig(base.TLSClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	if insecure {
+		cfg.InsecureSkipVerify = true //nolint:gosec // explicitly requested by the user for this repo.
+	}
+	tr := base.Clone()
+	tr.TLSClientConfig = cfg
+
+	return &http.Client{
+		Transport: &hostTransport{
+			mtls:   &MTLSOptions{},
+			mTLSRT: utils.InsecureHostsTransport(tr),
+		},
+		Timeout: http.DefaultClient.Timeout,
+	}, nil
+}
+
+// mirrorFor returns the settings of the given repo, if any.
+func mirrorFor(mirrors []Mirror, repo string) *Mirror {
+	for i := range mirrors {
+		if strings.TrimSuffix(mirrors[i].URL, "/") == strings.TrimSuffix(repo, "/") {
+			return &mirrors[i]
+		}
+	}
+	return nil
+}
+
+// isClientCertRejection returns true if err is a tls alert sent by the server after refusing our certificate.
+func isClientCertRejection(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "remote error" {
+		return false
+	}
+	msg := opErr.Err.Error()
+	for _, alert := range []string{
+		"bad certificate",
+		"certificate required",
+		"unknown certificate authority",
+		"unknown certificate",
+		"expired certificate",
+		"revoked certificate",
+		"unsupported certificate",
+		"access denied",
+	} {
+		if strings.Contains(msg, alert) {
+			return true
+		}
+	}
+	return false
+}
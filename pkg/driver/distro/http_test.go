@@ -0,0 +1,269 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverdistro
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM encoded certificate and key signed by the CA.
+func (ca *testCA) issue(t *testing.T, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	p := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(p, data, 0o600))
+	return p
+}
+
+func TestNewHTTPClientMTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "mirror-ca")
+	otherCA := newTestCA(t, "other-ca")
+
+	serverCert, serverKey := ca.issue(t, x509.ExtKeyUsageServerAuth)
+	serverPair, err := tls.X509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+	clientPool := x509.NewCertPool()
+	clientPool.AddCert(ca.cert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("driver"))
+	}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{serverPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientPool,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	caFile := writeTestFile(t, dir, "ca.crt", ca.pem)
+	goodCert, goodKey := ca.issue(t, x509.ExtKeyUsageClientAuth)
+	badCert, badKey := otherCA.issue(t, x509.ExtKeyUsageClientAuth)
+
+	testCases := []struct {
+		name        string
+		opts        MTLSOptions
+		expectedErr error
+		anyErr      bool
+	}{
+		{
+			name: "valid client certificate",
+			opts: MTLSOptions{
+				ClientCert: writeTestFile(t, dir, "good.crt", goodCert),
+				ClientKey:  writeTestFile(t, dir, "good.key", goodKey),
+				CACert:     caFile,
+			},
+		},
+		{
+			name: "valid client certificate scoped to the server host",
+			opts: MTLSOptions{
+				ClientCert: writeTestFile(t, dir, "good.crt", goodCert),
+				ClientKey:  writeTestFile(t, dir, "good.key", goodKey),
+				CACert:     caFile,
+				Hosts:      []string{srvURL.Hostname()},
+			},
+		},
+		{
+			name: "client certificate rejected",
+			opts: MTLSOptions{
+				ClientCert: writeTestFile(t, dir, "bad.crt", badCert),
+				ClientKey:  writeTestFile(t, dir, "bad.key", badKey),
+				CACert:     caFile,
+			},
+			expectedErr: ErrClientCertRejected,
+		},
+		{
+			name: "mTLS scoped to another host",
+			opts: MTLSOptions{
+				ClientCert: writeTestFile(t, dir, "good.crt", goodCert),
+				ClientKey:  writeTestFile(t, dir, "good.key", goodKey),
+				CACert:     caFile,
+				Hosts:      []string{"mirror.example.com"},
+			},
+			anyErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewHTTPClient(&tc.opts)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, http.NoBody)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			switch {
+			case tc.expectedErr != nil:
+				assert.ErrorIs(t, err, tc.expectedErr)
+			case tc.anyErr:
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, ErrClientCertRejected)
+			default:
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClientInvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	client, err := NewHTTPClient(&MTLSOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, http.DefaultClient, client)
+
+	_, err = NewHTTPClient(&MTLSOptions{ClientCert: "client.crt"})
+	assert.EqualError(t, err, "both client certificate and client key must be set")
+
+	_, err = NewHTTPClient(&MTLSOptions{CACert: writeTestFile(t, dir, "ca.crt", []byte("not a cert"))})
+	assert.ErrorContains(t, err, "no valid certificates found in CA file")
+}
+
+func TestDownloadMirrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	mirror := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "mirror" || r.Header.Get("X-Namespace") != "default" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("driver"))
+	}))
+	mirror.Config.ErrorLog = log.New(io.Discard, "", 0)
+	defer mirror.Close()
+
+	caFile := writeTestFile(t, t.TempDir(), "ca.crt",
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: mirror.Certificate().Raw}))
+	mirrorClient, err := NewMirrorClient(&MTLSOptions{CACert: caFile}, false)
+	require.NoError(t, err)
+
+	d := &generic{targetID: "debian"}
+	kr := kernelrelease.FromString("6.1.0-9-amd64")
+	kr.KernelVersion = "#1 SMP PREEMPT_DYNAMIC Debian 6.1.27-1 (2023-05-08)"
+	kr.Architecture = "amd64"
+	driverType, err := drivertype.Parse(drivertype.TypeKmod)
+	require.NoError(t, err)
+	printer := output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, io.Discard)
+	repos := []string{notFound.URL, unavailable.URL, mirror.URL}
+	headers := "X-Token:global,X-Namespace:default"
+
+	// Without the mirror settings, the mirror is not trusted and every repo fails.
+	_, err = Download(ctx, d, printer, kr, "falco", driverType, "7.0.0+driver", repos, headers, nil, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), notFound.URL+"/7.0.0%2Bdriver/x86_64/")
+	assert.Contains(t, err.Error(), "404 Not Found")
+	assert.Contains(t, err.Error(), "503 Service Unavailable")
+	assert.Contains(t, err.Error(), mirror.URL)
+
+	mirrors := []Mirror{{URL: mirror.URL + "/", Headers: map[string]string{"x-token": "mirror"}, Client: mirrorClient}}
+	dest, err := Download(ctx, d, printer, kr, "falco", driverType, "7.0.0+driver", repos, headers, nil, nil, mirrors)
+	require.NoError(t, err)
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "driver", string(data))
+}
+
+func TestNewMirrorClientInvalidOptions(t *testing.T) {
+	_, err := NewMirrorClient(&MTLSOptions{ClientKey: "client.key"}, true)
+	assert.EqualError(t, err, "both client certificate and client key must be set")
+
+	client, err := NewMirrorClient(&MTLSOptions{}, true)
+	require.NoError(t, err)
+	assert.NotEqual(t, http.DefaultClient, client)
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverdistro
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/repository"
+)
+
+// OCIRepoPrefix is the prefix of the driver repos that are OCI repositories, instead of HTTP servers.
+const OCIRepoPrefix = "oci://"
+
+// maxTagLength is the maximum length of an OCI tag.
+const maxTagLength = 128
+
+// invalidTagChars matches the characters not allowed in an OCI tag.
+var invalidTagChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// OCIOptions configure how the drivers are pulled from and published to OCI repositories.
+type OCIOptions struct {
+	// Client is the client used for the requests to the registries.
+	Client remote.Client
+	// PlainHTTP tells whether the registries are reached via plain http.
+	PlainHTTP bool
+}
+
+// ociRef returns the reference of the driver file in the OCI repository repo. The tag is made of the
+// driver version, the architecture and the file name, with the characters not allowed in tags replaced,
+// and is hashed when too long.
+func ociRef(repo, driverVer, arch, fileName string) string {
+	tag := invalidTagChars.ReplaceAllString(fmt.Sprintf("%s_%s_%s", driverVer, arch, fileName), "_")
+	if len(tag) > maxTagLength {
+		sum := sha256.Sum256([]byte(tag))
+		tag = tag[:maxTagLength-17] + "-" + hex.EncodeToString(sum[:])[:16]
+	}
+	return strings.TrimPrefix(repo, OCIRepoPrefix) + ":" + tag
+}
+
+// Publish pushes the driver built at path to the OCI repository repo, optionally prefixed by OCIRepoPrefix,
+// so that it can be downloaded by the hosts having repo among their driver repos. It returns the reference
+// of the pushed driver, by digest.
+//
+//nolint:gocritic // the method shall not be able to modify kr
+func Publish(ctx context.Context, opts *OCIOptions, repo string, kr kernelrelease.KernelRelease, driverVer, path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	fileName := filepath.Base(path)
+	ref := ociRef(repo, driverVer, kr.Architecture.ToNonDeb(), fileName)
+	remoteRepo, err := repository.NewRepository(ref, repository.WithClient(opts.Client), repository.WithPlainHTTP(opts.PlainHTTP))
+	if err != nil {
+		return "", err
+	}
+	tag := remoteRepo.Reference.Reference
+
+	store := memory.New()
+	layer := content.NewDescriptorFromBytes(oci.FalcoDriverLayerMediaType, data)
+	layer.Annotations = map[string]string{v1.AnnotationTitle: fileName}
+	if err := store.Push(ctx, layer, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+	desc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, oci.FalcoDriverArtifactType,
+		oras.PackManifestOptions{Layers: []v1.Descriptor{layer}})
+	if err != nil {
+		return "", err
+	}
+	if err := store.Tag(ctx, desc, tag); err != nil {
+		return "", err
+	}
+	if _, err := oras.Copy(ctx, store, tag, remoteRepo, tag, oras.DefaultCopyOptions); err != nil {
+		return "", fmt.Errorf("unable to push driver to %s: %w", ref, err)
+	}
+	return fmt.Sprintf("%s/%s@%s", remoteRepo.Reference.Registry, remoteRepo.Reference.Repository, desc.Digest), nil
+}
+
+// pullFromOCI downloads the driver at ref to destination.
+func pullFromOCI(ctx context.Context, opts *OCIOptions, ref, destination string) error {
+	remoteRepo, err := repository.NewRepository(ref, repository.WithClient(opts.Client), repository.WithPlainHTTP(opts.PlainHTTP))
+	if err != nil {
+		return err
+	}
+	_, rc, err := remoteRepo.FetchReference(ctx, remoteRepo.Reference.Reference)
+	if err != nil {
+		return err
+	}
+	manifestBytes, err := io.ReadAll(rc)
+	_ = rc.Close()
+	if err != nil {
+		return err
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != oci.FalcoDriverLayerMediaType {
+		return fmt.Errorf("%s is not a driver", ref)
+	}
+
+	// The content is verified against its digest before being written.
+	data, err := content.FetchAll(ctx, remoteRepo, manifest.Layers[0])
+	if err != nil {
+		return err
+	}
+	return copyDataToLocalPath(destination, io.NopCloser(bytes.NewReader(data)))
+}
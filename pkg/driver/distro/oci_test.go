@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverdistro
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3/configuration"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	drivertype "github.com/falcosecurity/falcoctl/pkg/driver/type"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	testutils "github.com/falcosecurity/falcoctl/pkg/test"
+)
+
+func TestOCIRef(t *testing.T) {
+	assert.Equal(t, "ghcr.io/myorg/drivers:7.0.0_driver_x86_64_falco_debian_6.1.0-9-amd64_1.ko",
+		ociRef("oci://ghcr.io/myorg/drivers", "7.0.0+driver", "x86_64", "falco_debian_6.1.0-9-amd64_1.ko"))
+
+	ref := ociRef("ghcr.io/myorg/drivers", "7.0.0+driver", "x86_64", strings.Repeat("a", 200)+".ko")
+	tag := ref[strings.LastIndex(ref, ":")+1:]
+	assert.Len(t, tag, maxTagLength)
+	assert.True(t, strings.HasPrefix(tag, "7.0.0_driver_x86_64_aaa"))
+}
+
+func TestPublishDownload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+
+	port, err := testutils.FreePort()
+	require.NoError(t, err)
+	cfg := &configuration.Configuration{}
+	cfg.HTTP.Addr = fmt.Sprintf("localhost:%d", port)
+	go func() {
+		_ = testutils.StartRegistry(ctx, cfg)
+	}()
+	require.Eventually(t, func() bool {
+		res, err := http.Get("http://" + cfg.HTTP.Addr)
+		if err != nil {
+			return false
+		}
+		_ = res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+
+	d := &generic{targetID: "debian"}
+	kr := kernelrelease.FromString("6.1.0-9-amd64")
+	kr.KernelVersion = "#1 SMP PREEMPT_DYNAMIC Debian 6.1.27-1 (2023-05-08)"
+	kr.Architecture = "amd64"
+	driverType, err := drivertype.Parse(drivertype.TypeKmod)
+	require.NoError(t, err)
+	opts := &OCIOptions{Client: http.DefaultClient, PlainHTTP: true}
+	repo := OCIRepoPrefix + cfg.HTTP.Addr + "/drivers"
+
+	built := filepath.Join(t.TempDir(), toFilename(d, &kr, "falco", driverType))
+	require.NoError(t, os.WriteFile(built, []byte("kmod"), 0o600))
+	ref, err := Publish(ctx, opts, repo, kr, "7.0.0+driver", built)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ref, cfg.HTTP.Addr+"/drivers@sha256:"))
+
+	printer := output.NewPrinter(pterm.LogLevelInfo, pterm.LogFormatterJSON, io.Discard)
+	dest, err := Download(ctx, d, printer, kr, "falco", driverType, "7.0.0+driver", []string{repo}, "", nil, opts, nil)
+	require.NoError(t, err)
+	assert.Equal(t, LocalPath(d, kr, "falco", driverType, "7.0.0+driver"), dest)
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "kmod", string(data))
+
+	// Without a published driver, nor the OCI options, the download fails.
+	_, err = Download(ctx, d, printer, kr, "falco", driverType, "8.0.0+driver", []string{repo}, "", nil, opts, nil)
+	assert.Error(t, err)
+	_, err = Download(ctx, d, printer, kr, "falco", driverType, "9.0.0+driver", []string{repo}, "", nil, nil, nil)
+	assert.Error(t, err)
+}
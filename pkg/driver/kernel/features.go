@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverkernel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// SecureBoot states.
+const (
+	SecureBootEnabled  = "enabled"
+	SecureBootDisabled = "disabled"
+	// SecureBootUnknown is reported when the state cannot be read, e.g. on non-EFI systems.
+	SecureBootUnknown = "unknown"
+)
+
+// secureBootVar is the EFI variable holding the secure boot state, in the global variable namespace.
+const secureBootVar = "SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+// sysDir is the sysfs mount point; it is a variable to be overridden in tests.
+var sysDir = "/sys"
+
+// Features holds the node features the driver types depend on.
+type Features struct {
+	// BTF is true if the kernel exposes its BTF type information, needed by the modern eBPF probe.
+	BTF bool
+	// Headers is true if the headers of the kernel release are installed, needed to build the drivers.
+	Headers bool
+	// SecureBoot is the secure boot state, preventing unsigned kernel modules from being loaded when enabled.
+	SecureBoot string
+}
+
+// DetectFeatures inspects the node for the features of the given kernel release.
+// The kernel headers are looked up under hostRoot.
+func DetectFeatures(kernelRelease, hostRoot string) Features {
+	return Features{
+		BTF:        exists(filepath.Join(sysDir, "kernel", "btf", "vmlinux")),
+		Headers:    hasHeaders(kernelRelease, hostRoot),
+		SecureBoot: secureBoot(),
+	}
+}
+
+func hasHeaders(kernelRelease, hostRoot string) bool {
+	for _, dir := range []string{
+		filepath.Join(hostRoot, "lib", "modules", kernelRelease, "build"),
+		filepath.Join(hostRoot, "usr", "src", "kernels", kernelRelease),
+		filepath.Join(hostRoot, "usr", "src", "linux-headers-"+kernelRelease),
+	} {
+		if exists(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func secureBoot() string {
+	data, err := os.ReadFile(filepath.Join(sysDir, "firmware", "efi", "efivars", secureBootVar))
+	if errors.Is(err, os.ErrNotExist) && exists(filepath.Join(sysDir, "firmware", "efi")) {
+		// EFI system without the variable, i.e. not supporting secure boot.
+		return SecureBootDisabled
+	}
+	// The content is made of 4 bytes of attributes, followed by the value.
+	if err != nil || len(data) < 5 {
+		return SecureBootUnknown
+	}
+	if data[4] == 1 {
+		return SecureBootEnabled
+	}
+	return SecureBootDisabled
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driverkernel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFeatures(t *testing.T) {
+	prev := sysDir
+	t.Cleanup(func() { sysDir = prev })
+	sysDir = t.TempDir()
+	hostRoot := t.TempDir()
+	const kr = "6.1.0-9-amd64"
+
+	assert.Equal(t, Features{SecureBoot: SecureBootUnknown}, DetectFeatures(kr, hostRoot))
+
+	efiVars := filepath.Join(sysDir, "firmware", "efi", "efivars")
+	require.NoError(t, os.MkdirAll(efiVars, 0o750))
+	assert.Equal(t, SecureBootDisabled, DetectFeatures(kr, hostRoot).SecureBoot)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(sysDir, "kernel", "btf"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(sysDir, "kernel", "btf", "vmlinux"), nil, 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(hostRoot, "usr", "src", "linux-headers-"+kr), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(efiVars, secureBootVar), []byte{0x06, 0, 0, 0, 1}, 0o600))
+	assert.Equal(t, Features{BTF: true, Headers: true, SecureBoot: SecureBootEnabled}, DetectFeatures(kr, hostRoot))
+
+	require.NoError(t, os.WriteFile(filepath.Join(efiVars, secureBootVar), []byte{0x06, 0, 0, 0, 0}, 0o600))
+	assert.Equal(t, SecureBootDisabled, DetectFeatures(kr, hostRoot).SecureBoot)
+}
@@ -31,7 +31,7 @@ import (
 const TypeBpf = "ebpf"
 
 func init() {
-	driverTypes[TypeBpf] = &bpf{}
+	Register(TypeBpf, func() DriverType { return &bpf{} })
 }
 
 type bpf struct{}
@@ -37,7 +37,7 @@ const (
 )
 
 func init() {
-	driverTypes[TypeKmod] = &kmod{}
+	Register(TypeKmod, func() DriverType { return &kmod{} })
 }
 
 type kmod struct{}
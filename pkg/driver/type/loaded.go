@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drivertype
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// bpfProgTypeRawTracepoint is the type of the programs attached by the legacy ebpf probe.
+	bpfProgTypeRawTracepoint = 17
+	// bpfProgTypeTracing is the type of the programs attached by the modern ebpf probe.
+	bpfProgTypeTracing = 26
+	// falcoComm is the process name inspected to find the loaded bpf programs.
+	falcoComm = "falco"
+)
+
+// Loaded returns the driver types currently loaded in the running kernel, sorted by name.
+// The kernel module is detected through /sys/module, while the bpf probes are detected
+// by inspecting the bpf programs held by running Falco processes.
+func Loaded(driverName string) ([]string, error) {
+	return loaded(string(os.PathSeparator), driverName)
+}
+
+func loaded(root, driverName string) ([]string, error) {
+	found := make(map[string]bool)
+
+	kmodName := strings.ReplaceAll(driverName, "-", "_")
+	if _, err := os.Stat(filepath.Join(root, "sys", "module", kmodName)); err == nil {
+		found[TypeKmod] = true
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	procDir := filepath.Join(root, "proc")
+	entries, err := os.ReadDir(procDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+		pidDir := filepath.Join(procDir, e.Name())
+		comm, err := os.ReadFile(filepath.Clean(filepath.Join(pidDir, "comm")))
+		if err != nil || strings.TrimSpace(string(comm)) != falcoComm {
+			continue
+		}
+		for _, progType := range bpfProgTypes(pidDir) {
+			switch progType {
+			case bpfProgTypeRawTracepoint:
+				found[TypeBpf] = true
+			case bpfProgTypeTracing:
+				found[TypeModernBpf] = true
+			}
+		}
+	}
+
+	types := make([]string, 0, len(found))
+	for t := range found {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+// bpfProgTypes returns the types of the bpf programs held by the process; errors are ignored
+// since the process may exit or be not inspectable.
+func bpfProgTypes(pidDir string) []int {
+	fds, err := os.ReadDir(filepath.Join(pidDir, "fd"))
+	if err != nil {
+		return nil
+	}
+	var progTypes []int
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(pidDir, "fd", fd.Name()))
+		if err != nil || target != "anon_inode:bpf-prog" {
+			continue
+		}
+		f, err := os.Open(filepath.Clean(filepath.Join(pidDir, "fdinfo", fd.Name())))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if v, ok := strings.CutPrefix(scanner.Text(), "prog_type:"); ok {
+				if progType, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+					progTypes = append(progTypes, progType)
+				}
+				break
+			}
+		}
+		_ = f.Close()
+	}
+	return progTypes
+}
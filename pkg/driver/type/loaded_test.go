@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drivertype
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeProcess(t *testing.T, root, pid, comm string, progTypes ...string) {
+	pidDir := filepath.Join(root, "proc", pid)
+	require.NoError(t, os.MkdirAll(filepath.Join(pidDir, "fd"), 0o750))
+	require.NoError(t, os.MkdirAll(filepath.Join(pidDir, "fdinfo"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(pidDir, "comm"), []byte(comm+"\n"), 0o600))
+	for i, progType := range progTypes {
+		fd := filepath.Join(pidDir, "fd", strconv.Itoa(3+i))
+		require.NoError(t, os.Symlink("anon_inode:bpf-prog", fd))
+		require.NoError(t, os.WriteFile(filepath.Join(pidDir, "fdinfo", filepath.Base(fd)),
+			[]byte("pos:\t0\nflags:\t02000002\nprog_type:\t"+progType+"\nprog_jited:\t1\n"), 0o600))
+	}
+	// A regular fd that must be ignored.
+	require.NoError(t, os.Symlink("/dev/null", filepath.Join(pidDir, "fd", "0")))
+}
+
+func TestLoaded(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(t *testing.T, root string)
+		expected []string
+	}{
+		{
+			name:     "nothing loaded",
+			setup:    func(t *testing.T, root string) {},
+			expected: []string{},
+		},
+		{
+			name: "kernel module",
+			setup: func(t *testing.T, root string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, "sys", "module", "falco_custom"), 0o750))
+			},
+			expected: []string{TypeKmod},
+		},
+		{
+			name: "modern ebpf held by falco",
+			setup: func(t *testing.T, root string) {
+				fakeProcess(t, root, "42", "falco", "26", "26")
+			},
+			expected: []string{TypeModernBpf},
+		},
+		{
+			name: "legacy ebpf held by falco and kernel module",
+			setup: func(t *testing.T, root string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, "sys", "module", "falco_custom"), 0o750))
+				fakeProcess(t, root, "42", "falco", "17")
+			},
+			expected: []string{TypeBpf, TypeKmod},
+		},
+		{
+			name: "bpf programs held by other processes",
+			setup: func(t *testing.T, root string) {
+				fakeProcess(t, root, "1", "systemd", "17", "26")
+			},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			tc.setup(t, root)
+			types, err := loaded(root, "falco-custom")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, types)
+		})
+	}
+}
@@ -31,7 +31,7 @@ import (
 const TypeModernBpf = "modern_ebpf"
 
 func init() {
-	driverTypes[TypeModernBpf] = &modernBpf{}
+	Register(TypeModernBpf, func() DriverType { return &modernBpf{} })
 }
 
 type modernBpf struct{}
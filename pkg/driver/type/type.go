@@ -18,6 +18,8 @@ package drivertype
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/falcosecurity/driverkit/cmd"
 	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
@@ -28,7 +30,23 @@ import (
 // KernelDirEnv is the env variable set to kernel headers extraction paths.
 const KernelDirEnv = "KERNELDIR"
 
-var driverTypes = map[string]DriverType{}
+var (
+	driverTypesMu sync.RWMutex
+	driverTypes   = map[string]func() DriverType{}
+)
+
+// aliases are the common variants of the driver type names, e.g. as written by other tools,
+// resolved by Parse to the canonical names.
+var aliases = map[string]string{
+	"module":        TypeKmod,
+	"kernel-module": TypeKmod,
+	"kernel_module": TypeKmod,
+	"bpf":           TypeBpf,
+	"modern-ebpf":   TypeModernBpf,
+	"modern_bpf":    TypeModernBpf,
+	"modern-bpf":    TypeModernBpf,
+	"modernbpf":     TypeModernBpf,
+}
 
 // DriverType is the interface that wraps driver types.
 type DriverType interface {
@@ -41,8 +59,33 @@ type DriverType interface {
 	Supported(kr kernelrelease.KernelRelease) bool
 }
 
+// Register makes a driver type available by the provided name, so that Parse accepts it.
+// Names are case-insensitive.
+// It is meant to be called from init functions, allowing out-of-tree builds to add driver types.
+// If Register is called twice with the same name, or if factory is nil, it panics.
+func Register(name string, factory func() DriverType) {
+	driverTypesMu.Lock()
+	defer driverTypesMu.Unlock()
+	if factory == nil {
+		panic("drivertype: Register factory is nil for driver type " + name)
+	}
+	if _, dup := driverTypes[normalize(name)]; dup {
+		panic("drivertype: Register called twice for driver type " + name)
+	}
+	driverTypes[normalize(name)] = factory
+}
+
+// Unregister removes a driver type added by Register, e.g. to undo it in tests.
+func Unregister(name string) {
+	driverTypesMu.Lock()
+	defer driverTypesMu.Unlock()
+	delete(driverTypes, normalize(name))
+}
+
 // GetTypes return the list of supported driver types.
 func GetTypes() []string {
+	driverTypesMu.RLock()
+	defer driverTypesMu.RUnlock()
 	driverTypesSlice := make([]string, 0)
 	for key := range driverTypes {
 		driverTypesSlice = append(driverTypesSlice, key)
@@ -51,9 +94,21 @@ func GetTypes() []string {
 }
 
 // Parse parses a driver type string and returns the corresponding DriverType object or an error.
+// The match is case-insensitive and ignores the surrounding whitespaces, the DriverType String being the canonical form.
+// The common variants of the names, e.g. "modern-ebpf" or "module", are accepted too, unless registered as driver types.
 func Parse(driverType string) (DriverType, error) {
-	if dType, ok := driverTypes[driverType]; ok {
-		return dType, nil
+	driverTypesMu.RLock()
+	defer driverTypesMu.RUnlock()
+	name := normalize(driverType)
+	if factory, ok := driverTypes[name]; ok {
+		return factory(), nil
+	}
+	if factory, ok := driverTypes[aliases[name]]; ok {
+		return factory(), nil
 	}
 	return nil, fmt.Errorf("unsupported driver type specified: %s", driverType)
 }
+
+func normalize(driverType string) string {
+	return strings.ToLower(strings.TrimSpace(driverType))
+}
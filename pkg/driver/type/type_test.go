@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drivertype
+
+import (
+	"testing"
+
+	"github.com/falcosecurity/driverkit/cmd"
+	"github.com/falcosecurity/driverkit/pkg/kernelrelease"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+type fakeType struct{}
+
+func (f *fakeType) String() string                                    { return "fake" }
+func (f *fakeType) Cleanup(_ *output.Printer, _ string) error         { return nil }
+func (f *fakeType) Load(_ *output.Printer, _, _ string, _ bool) error { return nil }
+func (f *fakeType) Extension() string                                 { return ".fake" }
+func (f *fakeType) HasArtifacts() bool                                { return false }
+func (f *fakeType) ToOutput(_ string) cmd.OutputOptions               { return cmd.OutputOptions{} }
+func (f *fakeType) Supported(_ kernelrelease.KernelRelease) bool      { return true }
+
+func TestRegister(t *testing.T) {
+	_, err := Parse("fake")
+	assert.EqualError(t, err, "unsupported driver type specified: fake")
+
+	Register("fake", func() DriverType { return &fakeType{} })
+	dType, err := Parse("fake")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", dType.String())
+	assert.Contains(t, GetTypes(), "fake")
+
+	assert.PanicsWithValue(t, "drivertype: Register called twice for driver type fake", func() {
+		Register("fake", func() DriverType { return &fakeType{} })
+	})
+	assert.PanicsWithValue(t, "drivertype: Register factory is nil for driver type other", func() {
+		Register("other", nil)
+	})
+
+	Unregister("fake")
+	_, err = Parse("fake")
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{input: TypeKmod, expected: TypeKmod},
+		{input: "Kmod", expected: TypeKmod},
+		{input: " KMOD\n", expected: TypeKmod},
+		{input: TypeBpf, expected: TypeBpf},
+		{input: "eBPF", expected: TypeBpf},
+		{input: "\tEBPF ", expected: TypeBpf},
+		{input: TypeModernBpf, expected: TypeModernBpf},
+		{input: "Modern_eBPF", expected: TypeModernBpf},
+		{input: "  MODERN_EBPF", expected: TypeModernBpf},
+		{input: "module", expected: TypeKmod},
+		{input: "Kernel-Module", expected: TypeKmod},
+		{input: "kernel_module", expected: TypeKmod},
+		{input: "BPF", expected: TypeBpf},
+		{input: "modern-ebpf", expected: TypeModernBpf},
+		{input: "Modern-eBPF", expected: TypeModernBpf},
+		{input: "modern_bpf", expected: TypeModernBpf},
+		{input: "modern-bpf", expected: TypeModernBpf},
+		{input: " modernbpf ", expected: TypeModernBpf},
+		{input: "modern", expectedErr: "unsupported driver type specified: modern"},
+		{input: "kmod-module", expectedErr: "unsupported driver type specified: kmod-module"},
+		{input: "", expectedErr: "unsupported driver type specified: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			dType, err := Parse(tt.input)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, dType.String())
+		})
+	}
+}
@@ -42,12 +42,27 @@ type Cache struct {
 	fetchedIndexes []*index.Index
 	// Track the indexes that have been removed, needed when writing the cache to file.
 	removedIndexes []string
+	// Settings of the configured indexes, by index name.
+	configured map[string]*config.Index
+}
+
+// Option configures a cache object.
+type Option func(c *Cache)
+
+// WithConfiguredIndexes makes the cache use the settings of the configured index with the same name:
+// the backend settings, such as credentials, to fetch the index and the priority to merge it.
+func WithConfiguredIndexes(indexes []config.Index) Option {
+	return func(c *Cache) {
+		for i := range indexes {
+			c.configured[indexes[i].Name] = &indexes[i]
+		}
+	}
 }
 
 // New creates a new cache object. For each entry in the indexes.yaml file it loads the respective index file
 // found on the disk or fetches it if not found. If there is an entry in the indexes.yaml file but its index file does not exist on the disk
 // then it will error.
-func New(ctx context.Context, indexFile, indexesDir string) (*Cache, error) {
+func New(ctx context.Context, indexFile, indexesDir string, opts ...Option) (*Cache, error) {
 	var err error
 	var idx *index.Index
 
@@ -62,6 +77,11 @@ func New(ctx context.Context, indexFile, indexesDir string) (*Cache, error) {
 		localIndexesFile: indexFile,
 		indexesDir:       indexesDir,
 		MergedIndexes:    index.NewMergedIndexes(),
+		configured:       make(map[string]*config.Index),
+	}
+
+	for _, o := range opts {
+		o(c)
 	}
 
 	// Load existing indexes in memory and merge them.
@@ -70,7 +90,7 @@ func New(ctx context.Context, indexFile, indexesDir string) (*Cache, error) {
 		if idx, err = c.loadIndex(cfg.Name); err != nil && errors.Is(err, fs.ErrNotExist) {
 			// If the index is not found in the local persistent cache we fetch it from the url.
 			ts := time.Now().Format(consts.TimeFormat)
-			if idx, err = c.fetcher.Fetch(ctx, cfg); err != nil {
+			if idx, err = c.fetch(ctx, cfg, false); err != nil {
 				return nil, fmt.Errorf("unable to fetch index %q with URL %q: %w", cfg.Name, cfg.URL, err)
 			}
 			// If correctly fetched, we need to update the metadata of the config entry.
@@ -103,7 +123,9 @@ func NewFromConfig(ctx context.Context, indexFile, indexesDir string, indexes []
 		localIndexesFile: indexFile,
 		indexesDir:       indexesDir,
 		MergedIndexes:    index.NewMergedIndexes(),
+		configured:       make(map[string]*config.Index),
 	}
+	WithConfiguredIndexes(indexes)(c)
 
 	for i := range indexes {
 		cfg := &indexes[i]
@@ -111,7 +133,7 @@ func NewFromConfig(ctx context.Context, indexFile, indexesDir string, indexes []
 		ts := time.Now().Format(consts.TimeFormat)
 		if idx, err = c.loadIndex(cfg.Name); err != nil && errors.Is(err, fs.ErrNotExist) {
 			// If the index is not found in the local persistent cache we fetch it from the url.
-			if idx, err = c.fetcher.Fetch(ctx, indexConf.EntryFromIndex(cfg)); err != nil {
+			if idx, err = c.fetch(ctx, indexConf.EntryFromIndex(cfg), false); err != nil {
 				return nil, fmt.Errorf("unable to fetch index %q with URL %q: %w", cfg.Name, cfg.URL, err)
 			}
 			c.fetchedIndexes = append(c.fetchedIndexes, idx)
@@ -152,7 +174,7 @@ func (c *Cache) Add(ctx context.Context, name, backend, url string) error {
 	}
 
 	// If the index is not locally cached we fetch it using the provided url.
-	if remoteIndex, err = c.fetcher.Fetch(ctx, entry); err != nil {
+	if remoteIndex, err = c.fetch(ctx, entry, false); err != nil {
 		return fmt.Errorf("unable to fetch index %q with URL %q: %w", name, url, err)
 	}
 
@@ -164,6 +186,9 @@ func (c *Cache) Add(ctx context.Context, name, backend, url string) error {
 		UpdatedTimestamp: ts,
 		URL:              url,
 		Backend:          backend,
+		ETag:             entry.ETag,
+		LastModified:     entry.LastModified,
+		HTTP:             entry.HTTP,
 	}
 	c.localIndexes.Add(entry)
 
@@ -237,20 +262,31 @@ func (c *Cache) Update(ctx context.Context, name string) error {
 		return fmt.Errorf("unable to update index %s: not found in the cache, please make sure to add it before updating", name)
 	}
 
+	// The download can be skipped only if the current content of the index is available.
+	currentIndex := findIndexInSlice(c.fetchedIndexes, name)
+	if currentIndex == nil {
+		if currentIndex, err = c.loadIndex(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
 	ts := time.Now().Format(consts.TimeFormat)
 	// Fetch the index from the remote url.
-	updatedIndex, err := c.fetcher.Fetch(ctx, entry)
-	if err != nil {
+	updatedIndex, err := c.fetch(ctx, entry, currentIndex != nil)
+	switch {
+	case errors.Is(err, fetch.ErrNotModified):
+		updatedIndex = currentIndex
+	case err != nil:
 		return fmt.Errorf("unable to fetch index %q with URL %q: %w", name, entry.URL, err)
+	default:
+		// Track the new fetched index for writing purposes.
+		c.fetchedIndexes = append(c.fetchedIndexes, updatedIndex)
 	}
 
 	// Update the existing index entry by setting the new timestamp.
 	entry.UpdatedTimestamp = ts
 	c.localIndexes.Upsert(entry)
 
-	// Track the new fetched index for writing purposes.
-	c.fetchedIndexes = append(c.fetchedIndexes, updatedIndex)
-
 	// Create a new merged indexes without the one we are removing.
 	for _, cfg := range c.localIndexes.Configs {
 		if cfg.Name != name {
@@ -314,6 +350,7 @@ func (c *Cache) loadIndex(name string) (*index.Index, error) {
 	if err != nil {
 		return nil, fmt.Errorf("an error occurred while loading index %q from file %q: %w", name, indexPath, err)
 	}
+	idx.Priority = c.priority(name)
 
 	return idx, nil
 }
@@ -327,3 +364,32 @@ func findIndexInSlice(indexes []*index.Index, name string) *index.Index {
 
 	return nil
 }
+
+// fetch fetches the index of an entry using the settings of the configured index with the same name, if any.
+// If conditional is set, the validators of the previous fetch are sent and fetch.ErrNotModified is returned
+// when the index has not changed; otherwise they are discarded.
+func (c *Cache) fetch(ctx context.Context, entry *indexConf.Entry, conditional bool) (*index.Index, error) {
+	if cfg, ok := c.configured[entry.Name]; ok && entry.HTTP == nil {
+		entry.HTTP = cfg.HTTP
+	}
+	if !conditional {
+		entry.ETag = ""
+		entry.LastModified = ""
+	}
+
+	idx, err := c.fetcher.Fetch(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	idx.Priority = c.priority(entry.Name)
+
+	return idx, nil
+}
+
+// priority returns the merge priority of the configured index with the given name.
+func (c *Cache) priority(name string) int {
+	if cfg, ok := c.configured[name]; ok {
+		return cfg.Priority
+	}
+	return 0
+}
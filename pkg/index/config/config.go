@@ -33,21 +33,14 @@ type Entry struct {
 	UpdatedTimestamp string `yaml:"updated_timestamp"`
 	URL              string `yaml:"url"`
 	Backend          string `yaml:"backend"`
-	// TODO: add support for HTTP and other backend configs.
-	// HTTP             http.BackendConfig `yaml:"http"`
+	// ETag and LastModified are the validators of the last response, used to skip downloading unchanged indexes.
+	ETag         string `yaml:"etag,omitempty"`
+	LastModified string `yaml:"last_modified,omitempty"`
+	// HTTP holds the settings of the HTTP backend. They come from the falcoctl configuration
+	// and are never written to the indexes file, since they may contain credentials.
+	HTTP *config.IndexHTTP `yaml:"-"`
 }
 
-// TODO: add support for HTTP backend config fields.
-// type BackendConfig struct {
-//    CaFile                string `yaml:"caFile"`
-//    CertFile              string `yaml:"certFile"`
-//    InsecureSkipTLSVerify string `yaml:"insecure_skip_tls_verify"`
-//    KeyFile               string `yaml:"keyFile"`
-//    PassCredentialsAll    string `yaml:"pass_credentials_all"`
-//    Password              string `yaml:"password"`
-//    Username              string `yaml:"username"`
-// }
-
 // Config aggregates the info about ConfigEntries.
 type Config struct {
 	Configs []*Entry `yaml:"configs"`
@@ -77,6 +70,7 @@ func EntryFromIndex(idx *config.Index) *Entry {
 		Name:    idx.Name,
 		URL:     idx.URL,
 		Backend: idx.Backend,
+		HTTP:    idx.HTTP,
 	}
 }
 
@@ -23,10 +23,15 @@ import (
 
 	"github.com/falcosecurity/falcoctl/pkg/index/config"
 	"github.com/falcosecurity/falcoctl/pkg/index/fetch/gcs"
+	"github.com/falcosecurity/falcoctl/pkg/index/fetch/git"
 	"github.com/falcosecurity/falcoctl/pkg/index/fetch/http"
+	"github.com/falcosecurity/falcoctl/pkg/index/fetch/s3"
 	"github.com/falcosecurity/falcoctl/pkg/index/index"
 )
 
+// ErrNotModified is returned when a conditional fetch finds that the index has not changed.
+var ErrNotModified = http.ErrNotModified
+
 // Func is a prototype for fetching indices for a specific index backend.
 type Func func(context.Context, *config.Entry) ([]byte, error)
 
@@ -46,11 +51,18 @@ func NewFetcher() *Fetcher {
 			"http":  http.Fetch,
 			"https": http.Fetch,
 			"gcs":   gcs.Fetch,
+			"git":   git.Fetch,
+			"s3":    s3.Fetch,
 		},
 		schemeDefaultBackends: map[string]string{
-			"http":  "http",
-			"https": "https",
-			"gs":    "gcs",
+			"http":      "http",
+			"https":     "https",
+			"gs":        "gcs",
+			"s3":        "s3",
+			"git+https": "git",
+			"git+http":  "git",
+			"git+ssh":   "git",
+			"git+file":  "git",
 		},
 	}
 }
@@ -65,6 +77,22 @@ func (f *Fetcher) get(backend string) (Func, error) {
 
 // Fetch retrieves a remote index.
 func (f *Fetcher) Fetch(ctx context.Context, conf *config.Entry) (*index.Index, error) {
+	bytes, err := f.FetchRaw(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	i := index.New(conf.Name)
+	err = i.ReadBytes(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// FetchRaw retrieves the content of a remote index, without parsing it.
+func (f *Fetcher) FetchRaw(ctx context.Context, conf *config.Entry) ([]byte, error) {
 	// if we don't have an explicit backend
 	// we try to guess based on the URI scheme
 	if conf.Backend == "" {
@@ -87,11 +115,5 @@ func (f *Fetcher) Fetch(ctx context.Context, conf *config.Entry) (*index.Index,
 		return nil, fmt.Errorf("unable to fetch index: %w", err)
 	}
 
-	i := index.New(conf.Name)
-	err = i.ReadBytes(bytes)
-	if err != nil {
-		return nil, err
-	}
-
-	return i, nil
+	return bytes, nil
 }
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/falcosecurity/falcoctl/pkg/index/config"
+)
+
+const (
+	// TokenEnv is the environment variable holding the token used to authenticate over HTTP/S.
+	TokenEnv = "FALCOCTL_INDEX_GIT_TOKEN"
+	// UsernameEnv is the environment variable holding the username paired with the token.
+	UsernameEnv = "FALCOCTL_INDEX_GIT_USERNAME"
+	// SSHKeyEnv is the environment variable holding the path to the private key used to authenticate over SSH.
+	SSHKeyEnv = "FALCOCTL_INDEX_GIT_SSH_KEY"
+
+	defaultUsername = "x-access-token"
+	// allowedProtocols are the transports git may use, also when following submodules or redirects.
+	allowedProtocols = "file:git:http:https:ssh"
+)
+
+// Fetch fetches the raw index file from a Git repository.
+func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
+	src, err := gitSourceFromURI(conf.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	repoDir, err := os.MkdirTemp("", "falcoctl-index-git-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(repoDir)
+
+	env := authEnv(src)
+	if _, err := run(ctx, repoDir, env, "init", "--quiet"); err != nil {
+		return nil, err
+	}
+
+	// Only the requested ref is fetched, and without history, to keep large catalogs cheap.
+	if _, err := run(ctx, repoDir, env, "fetch", "--quiet", "--depth=1", "--no-tags", "--", src.Remote, src.Ref); err != nil {
+		return nil, err
+	}
+
+	res, err := run(ctx, repoDir, env, "show", "FETCH_HEAD:"+src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %q at ref %q: %w", src.Path, src.Ref, err)
+	}
+
+	return res, nil
+}
+
+// authEnv returns the environment used to run git, adding the configured credentials.
+// Credentials are passed through the environment so that they never show up in the process arguments.
+func authEnv(src *gitSource) []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if _, ok := os.LookupEnv("GIT_ALLOW_PROTOCOL"); !ok {
+		env = append(env, "GIT_ALLOW_PROTOCOL="+allowedProtocols)
+	}
+
+	if token := os.Getenv(TokenEnv); token != "" && strings.HasPrefix(src.Remote, "http") {
+		username := os.Getenv(UsernameEnv)
+		if username == "" {
+			username = defaultUsername
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+
+	if key := os.Getenv(SSHKeyEnv); key != "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -i "+shellQuote(key)+" -o IdentitiesOnly=yes")
+	}
+
+	return env
+}
+
+// shellQuote quotes s for the shell git runs GIT_SSH_COMMAND with.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func run(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s failed: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+
+	return stdout.Bytes(), nil
+}
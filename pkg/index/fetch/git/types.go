@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	gitSchemePrefix = "git+"
+	defaultRef      = "HEAD"
+	defaultPath     = "index.yaml"
+)
+
+type gitSource struct {
+	// Remote is the URL of the repository as understood by git.
+	Remote string
+	// Ref is the branch, tag or commit holding the index.
+	Ref string
+	// Path is the location of the index file inside the repository.
+	Path string
+}
+
+// gitSourceFromURI parses URIs in the form git+<transport>://<repository>?ref=<ref>&path=<path>.
+func gitSourceFromURI(uri string) (*gitSource, error) {
+	parsedURI, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse URI: %w", err)
+	}
+
+	scheme := strings.ToLower(parsedURI.Scheme)
+	if !strings.HasPrefix(scheme, gitSchemePrefix) || scheme == gitSchemePrefix {
+		return nil, fmt.Errorf("invalid git URI: scheme should be in the form '%s<transport>' but got '%s'", gitSchemePrefix, parsedURI.Scheme)
+	}
+
+	if parsedURI.Host == "" && parsedURI.Path == "" {
+		return nil, fmt.Errorf("invalid git URI: missing repository")
+	}
+
+	query := parsedURI.Query()
+	src := &gitSource{
+		Ref:  query.Get("ref"),
+		Path: strings.TrimPrefix(query.Get("path"), "/"),
+	}
+	if src.Ref == "" {
+		src.Ref = defaultRef
+	}
+	// The ref is passed to git as an argument, it must not be taken as an option.
+	if strings.HasPrefix(src.Ref, "-") {
+		return nil, fmt.Errorf("invalid git URI: ref %q must not start with '-'", src.Ref)
+	}
+	if src.Path == "" {
+		src.Path = defaultPath
+	}
+
+	remote := *parsedURI
+	remote.Scheme = strings.TrimPrefix(scheme, gitSchemePrefix)
+	remote.RawQuery = ""
+	remote.Fragment = ""
+	src.Remote = remote.String()
+
+	return src, nil
+}
@@ -17,27 +17,50 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 
-	"github.com/falcosecurity/falcoctl/pkg/index/config"
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	indexConf "github.com/falcosecurity/falcoctl/pkg/index/config"
 )
 
+// ErrNotModified is returned when the index has not changed since it was last fetched.
+var ErrNotModified = errors.New("index not modified")
+
 // Fetch fetches the raw index file from the given HTTP/S url.
-func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
+// When the entry holds the validators of a previous response, the request is conditional and ErrNotModified
+// is returned if the index has not changed. The validators of the entry are updated with the ones of the response.
+func Fetch(ctx context.Context, conf *indexConf.Entry) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", conf.URL, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("cannot fetch index: %w", err)
 	}
 
-	client := &http.Client{}
+	if err := setAuth(req, conf.HTTP); err != nil {
+		return nil, fmt.Errorf("cannot fetch index: %w", err)
+	}
+
+	if conf.ETag != "" {
+		req.Header.Set("If-None-Match", conf.ETag)
+	}
+	if conf.LastModified != "" {
+		req.Header.Set("If-Modified-Since", conf.LastModified)
+	}
+
+	client := &http.Client{Transport: utils.Transport()}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("cannot fetch index: %w", err)
 	}
 	defer resp.Body.Close() // #nosec G307 closing errors should not happen
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+
 	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode <= http.StatusNetworkAuthenticationRequired {
 		return nil, fmt.Errorf("cannot fetch index: %s", resp.Status)
 	}
@@ -47,5 +70,32 @@ func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
 		return nil, fmt.Errorf("cannot read bytes from response body: %w", err)
 	}
 
+	conf.ETag = resp.Header.Get("ETag")
+	conf.LastModified = resp.Header.Get("Last-Modified")
+
 	return bytes, nil
 }
+
+// setAuth adds the custom headers and the credentials configured for the index to the request.
+func setAuth(req *http.Request, conf *config.IndexHTTP) error {
+	if conf == nil {
+		return nil
+	}
+
+	if conf.Token != "" && (conf.Username != "" || conf.Password != "") {
+		return fmt.Errorf("basic auth and bearer token are mutually exclusive")
+	}
+
+	for key, value := range conf.Headers {
+		req.Header.Set(key, value)
+	}
+
+	switch {
+	case conf.Token != "":
+		req.Header.Set("Authorization", "Bearer "+conf.Token)
+	case conf.Username != "" || conf.Password != "":
+		req.SetBasicAuth(conf.Username, conf.Password)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	indexConf "github.com/falcosecurity/falcoctl/pkg/index/config"
+)
+
+func TestFetchWithAuth(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("- name: test\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		conf          *config.IndexHTTP
+		authorization string
+		wantErr       string
+	}{
+		{
+			name:    "no credentials",
+			wantErr: "401 Unauthorized",
+		},
+		{
+			name:          "basic auth",
+			conf:          &config.IndexHTTP{Username: "user", Password: "pass"},
+			authorization: "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:          "bearer token",
+			conf:          &config.IndexHTTP{Token: "secret"},
+			authorization: "Bearer secret",
+		},
+		{
+			name:          "custom headers",
+			conf:          &config.IndexHTTP{Headers: map[string]string{"Authorization": "Custom value", "X-Tenant": "falco"}},
+			authorization: "Custom value",
+		},
+		{
+			name:    "basic auth and token",
+			conf:    &config.IndexHTTP{Username: "user", Token: "secret"},
+			wantErr: "mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Fetch(context.Background(), &indexConf.Entry{Name: "test", URL: server.URL, HTTP: tt.conf})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "- name: test\n", string(b))
+			assert.Equal(t, tt.authorization, got.Header.Get("Authorization"))
+			for key, value := range tt.conf.Headers {
+				assert.Equal(t, value, got.Header.Get(key))
+			}
+		})
+	}
+}
+
+func TestFetchConditional(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 12 Oct 2026 10:00:00 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte("- name: test\n"))
+	}))
+	defer server.Close()
+
+	entry := &indexConf.Entry{Name: "test", URL: server.URL}
+	b, err := Fetch(context.Background(), entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "- name: test\n", string(b))
+	assert.Equal(t, etag, entry.ETag)
+	assert.Equal(t, lastModified, entry.LastModified)
+
+	b, err = Fetch(context.Background(), entry)
+	assert.ErrorIs(t, err, ErrNotModified)
+	assert.Nil(t, b)
+	assert.Equal(t, etag, entry.ETag)
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/falcosecurity/falcoctl/pkg/index/config"
+)
+
+const defaultRegion = "us-east-1"
+
+// endpointEnvs are the environment variables, in order of precedence, used to point
+// the backend at an S3 compatible storage instead of AWS.
+var endpointEnvs = []string{"AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"}
+
+// Fetch fetches the raw index file from an S3 object.
+func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
+	o, err := s3ObjectFromURI(conf.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	// defaults to using the standard AWS credential chain: environment, shared config and instance roles
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create AWS session: %w", err)
+	}
+
+	awsConf := aws.NewConfig()
+	for _, env := range endpointEnvs {
+		if endpoint := os.Getenv(env); endpoint != "" {
+			awsConf = awsConf.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+			break
+		}
+	}
+
+	if aws.StringValue(sess.Config.Region) == "" {
+		region := defaultRegion
+		if awsConf.Endpoint == nil {
+			region, err = s3manager.GetBucketRegion(ctx, sess, o.Bucket, defaultRegion)
+			if err != nil {
+				return nil, fmt.Errorf("unable to find region of S3 bucket %q: %w", o.Bucket, err)
+			}
+		}
+		awsConf = awsConf.WithRegion(region)
+	}
+
+	out, err := s3.New(sess, awsConf).GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(o.Bucket),
+		Key:    aws.String(o.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get S3 object: %w", err)
+	}
+
+	res, err := io.ReadAll(out.Body)
+	closeErr := out.Body.Close()
+	if closeErr != nil {
+		if err != nil {
+			err = fmt.Errorf("%w, %w", err, closeErr)
+		} else {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading S3 object: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/pkg/index/config"
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
+)
+
+func setupEnv(t *testing.T, endpoint string) {
+	t.Setenv("AWS_ENDPOINT_URL_S3", endpoint)
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+}
+
+func TestS3FetchWithValidResponse(t *testing.T) {
+	bucket := "bucket"
+	object := "object/path/index.yaml"
+	entries := []index.Entry{{
+		Name:       "test",
+		Type:       "rulesfile",
+		Registry:   "test.io",
+		Repository: "test",
+		Maintainers: index.Maintainer{
+			{
+				Email: "test@local",
+				Name:  "test",
+			},
+		},
+		Sources:  []string{"/test"},
+		Keywords: []string{"test"},
+	}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !assert.Equal(t, http.MethodGet, r.Method) {
+			return
+		}
+
+		if !assert.Equal(t, fmt.Sprintf("/%s/%s", bucket, object), r.URL.Path) {
+			return
+		}
+
+		// requests must be signed with the credentials taken from the environment
+		assert.Contains(t, r.Header.Get("Authorization"), "Credential=test/")
+
+		entryBytes, err := yaml.Marshal(entries)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		_, err = w.Write(entryBytes)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}))
+	defer server.Close()
+	setupEnv(t, server.URL)
+
+	b, err := Fetch(context.Background(), &config.Entry{
+		Name:    "test",
+		URL:     fmt.Sprintf("s3://%s/%s", bucket, object),
+		Backend: "S3",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var resultEntries []index.Entry
+	err = yaml.Unmarshal(b, &resultEntries)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assert.Equal(t, entries, resultEntries)
+}
+
+func TestS3FetchWithNonExistentObject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}))
+	defer server.Close()
+	setupEnv(t, server.URL)
+
+	b, err := Fetch(context.Background(), &config.Entry{
+		Name:    "test",
+		URL:     "s3://this-bucket/does/not/exist",
+		Backend: "s3",
+	})
+	assert.Nil(t, b)
+	assert.ErrorContains(t, err, "NoSuchKey")
+}
+
+func TestS3ObjectFromURI(t *testing.T) {
+	o, err := s3ObjectFromURI("s3://bucket/path/to/index.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, &s3Object{Bucket: "bucket", Key: "path/to/index.yaml"}, o)
+
+	for _, uri := range []string{"gs://bucket/index.yaml", "s3:///index.yaml", "s3://bucket", "s3://bucket/"} {
+		_, err = s3ObjectFromURI(uri)
+		assert.Error(t, err, uri)
+	}
+}
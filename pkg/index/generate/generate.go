@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+const latestTag = "latest"
+
+// Options configures how the entries are generated.
+type Options struct {
+	// Registry is the registry set in the generated entries.
+	Registry string
+	// Prefix is prepended to the repository names of the source in the generated entries.
+	Prefix string
+}
+
+// Skipped describes a repository of the source that has not been added to the index.
+type Skipped struct {
+	Repository string
+	Reason     string
+}
+
+// Generate builds an index with an entry for each repository of the source holding a Falco artifact.
+// The type of each entry is taken from the artifact pointed by the "latest" tag or, when missing, by the
+// highest version. The repositories that cannot be inspected, or that hold other kinds of artifacts,
+// are returned as skipped.
+func Generate(ctx context.Context, src Source, name string, opts Options) (*index.Index, []Skipped, error) {
+	repos, err := src.Repositories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(repos)
+
+	idx := index.New(name)
+	var skipped []Skipped
+	for _, repo := range repos {
+		entry, err := generateEntry(ctx, src, repo, opts)
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, nil, err
+		} else if err != nil {
+			skipped = append(skipped, Skipped{Repository: repo, Reason: err.Error()})
+			continue
+		}
+
+		if _, ok := idx.EntryByName(entry.Name); ok {
+			// Fall back to the full repository path to keep names unique.
+			entry.Name = strings.ReplaceAll(repo, "/", "-")
+			if _, ok := idx.EntryByName(entry.Name); ok {
+				skipped = append(skipped, Skipped{Repository: repo, Reason: fmt.Sprintf("entry %q already exists", entry.Name)})
+				continue
+			}
+		}
+		idx.Upsert(entry)
+	}
+
+	if err := idx.Normalize(); err != nil {
+		return nil, nil, err
+	}
+
+	return idx, skipped, nil
+}
+
+func generateEntry(ctx context.Context, src Source, repo string, opts Options) (*index.Entry, error) {
+	target, err := src.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := registry.Tags(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list tags: %w", err)
+	}
+	tags = filterTags(tags)
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags found")
+	}
+
+	ref := referenceTag(tags)
+	desc, err := target.Resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve tag %q: %w", ref, err)
+	}
+
+	artifactType, err := typeFromDescriptor(ctx, target, desc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get type of tag %q: %w", ref, err)
+	}
+
+	return &index.Entry{
+		Name:       path.Base(repo),
+		Type:       artifactType,
+		Registry:   opts.Registry,
+		Repository: path.Join(opts.Prefix, repo),
+		Tags:       tags,
+	}, nil
+}
+
+// typeFromDescriptor returns the artifact type of a manifest, or of the first manifest of an image index.
+func typeFromDescriptor(ctx context.Context, target content.Fetcher, desc v1.Descriptor) (string, error) {
+	data, err := content.FetchAll(ctx, target, desc)
+	if err != nil {
+		return "", err
+	}
+
+	if desc.MediaType == v1.MediaTypeImageIndex {
+		var imageIndex v1.Index
+		if err := json.Unmarshal(data, &imageIndex); err != nil {
+			return "", fmt.Errorf("unable to unmarshal image index: %w", err)
+		}
+		if len(imageIndex.Manifests) == 0 {
+			return "", fmt.Errorf("no manifests in image index")
+		}
+		return typeFromDescriptor(ctx, target, imageIndex.Manifests[0])
+	}
+
+	var manifest v1.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+	if len(manifest.Layers) == 0 {
+		return "", fmt.Errorf("no layers in manifest")
+	}
+
+	artifactType := oci.HumanReadableMediaType(manifest.Layers[0].MediaType)
+	if artifactType == "" {
+		return "", fmt.Errorf("not a Falco artifact: unknown layer media type %q", manifest.Layers[0].MediaType)
+	}
+
+	return artifactType, nil
+}
+
+// filterTags drops the tags used to store signatures and attestations and sorts the remaining ones,
+// versions first in ascending order.
+func filterTags(tags []string) []string {
+	var res []string
+	for _, t := range tags {
+		if strings.HasPrefix(t, "sha256-") {
+			continue
+		}
+		res = append(res, t)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		vi, errI := semver.ParseTolerant(res[i])
+		vj, errJ := semver.ParseTolerant(res[j])
+		switch {
+		case errI == nil && errJ == nil:
+			return vi.LT(vj)
+		case errI == nil || errJ == nil:
+			return errI == nil
+		default:
+			return res[i] < res[j]
+		}
+	})
+
+	return res
+}
+
+// referenceTag returns the tag used to inspect a repository: "latest" if present, otherwise the highest version.
+func referenceTag(sortedTags []string) string {
+	for _, t := range sortedTags {
+		if t == latestTag {
+			return t
+		}
+	}
+
+	for i := len(sortedTags) - 1; i >= 0; i-- {
+		if _, err := semver.ParseTolerant(sortedTags[i]); err == nil {
+			return sortedTags[i]
+		}
+	}
+
+	return sortedTags[len(sortedTags)-1]
+}
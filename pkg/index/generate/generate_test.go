@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
+	falcooci "github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// newLayout stores in dir an OCI image layout with an artifact of the given layer media type for each tag.
+func newLayout(ctx context.Context, t *testing.T, dir, layerMediaType string, tags ...string) {
+	t.Helper()
+	store, err := oci.New(dir)
+	require.NoError(t, err)
+
+	layer := content.NewDescriptorFromBytes(layerMediaType, []byte(dir))
+	require.NoError(t, store.Push(ctx, layer, strings.NewReader(dir)))
+
+	desc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_0, "", oras.PackManifestOptions{
+		Layers: []v1.Descriptor{layer},
+	})
+	require.NoError(t, err)
+
+	for _, tag := range tags {
+		require.NoError(t, store.Tag(ctx, desc, tag))
+	}
+}
+
+func TestGenerateFromLocalSource(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	newLayout(ctx, t, filepath.Join(dir, "rules", "k8saudit-rules"), falcooci.FalcoRulesfileLayerMediaType,
+		"0.10.0", "0.9.1", "latest", "sha256-abcd.sig")
+	newLayout(ctx, t, filepath.Join(dir, "plugins", "k8saudit"), falcooci.FalcoPluginLayerMediaType, "0.7.0")
+	newLayout(ctx, t, filepath.Join(dir, "other", "k8saudit"), falcooci.FalcoAssetLayerMediaType, "1.0.0")
+	newLayout(ctx, t, filepath.Join(dir, "images", "nginx"), v1.MediaTypeImageLayerGzip, "1.25")
+	newLayout(ctx, t, filepath.Join(dir, "untagged"), falcooci.FalcoRulesfileLayerMediaType)
+
+	idx, skipped, err := Generate(ctx, NewLocalSource(dir), "test", Options{Registry: "ghcr.io", Prefix: "myorg"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*index.Entry{
+		{
+			Name:       "k8saudit",
+			Type:       "asset",
+			Registry:   "ghcr.io",
+			Repository: "myorg/other/k8saudit",
+			Tags:       []string{"1.0.0"},
+		},
+		{
+			Name:       "k8saudit-rules",
+			Type:       "rulesfile",
+			Registry:   "ghcr.io",
+			Repository: "myorg/rules/k8saudit-rules",
+			Tags:       []string{"0.9.1", "0.10.0", "latest"},
+		},
+		{
+			Name:       "plugins-k8saudit",
+			Type:       "plugin",
+			Registry:   "ghcr.io",
+			Repository: "myorg/plugins/k8saudit",
+			Tags:       []string{"0.7.0"},
+		},
+	}, idx.Entries)
+
+	require.Len(t, skipped, 2)
+	assert.Equal(t, "images/nginx", skipped[0].Repository)
+	assert.Contains(t, skipped[0].Reason, "not a Falco artifact")
+	assert.Equal(t, Skipped{Repository: "untagged", Reason: "no tags found"}, skipped[1])
+}
+
+func TestLocalSourceLayoutRoot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	newLayout(ctx, t, dir, falcooci.FalcoRulesfileLayerMediaType, "latest")
+
+	_, _, err := Generate(ctx, NewLocalSource(dir), "test", Options{})
+	assert.ErrorContains(t, err, "expected a directory of layouts")
+}
+
+func TestReferenceTag(t *testing.T) {
+	assert.Equal(t, "latest", referenceTag(filterTags([]string{"latest", "1.0.0"})))
+	assert.Equal(t, "1.10.0", referenceTag(filterTags([]string{"1.2.0", "1.10.0", "1", "main"})))
+	assert.Equal(t, "main", referenceTag(filterTags([]string{"dev", "main"})))
+}
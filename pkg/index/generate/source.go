@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+
+	ociregistry "github.com/falcosecurity/falcoctl/pkg/oci/registry"
+)
+
+// Target gives access to the tags and to the content of a repository.
+type Target interface {
+	oras.ReadOnlyTarget
+	registry.TagLister
+}
+
+// Source is a set of repositories holding artifacts.
+type Source interface {
+	// Repositories returns the names of the repositories found in the source.
+	Repositories(ctx context.Context) ([]string, error)
+	// Repository returns the repository with the given name.
+	Repository(ctx context.Context, name string) (Target, error)
+}
+
+// RemoteSource is a namespace of a remote registry.
+type RemoteSource struct {
+	registry  *ociregistry.Registry
+	namespace string
+}
+
+// NewRemoteSource returns a source listing the repositories of the registry under the given namespace.
+// An empty namespace selects all the repositories of the registry.
+func NewRemoteSource(reg *ociregistry.Registry, namespace string) *RemoteSource {
+	return &RemoteSource{
+		registry:  reg,
+		namespace: strings.Trim(namespace, "/"),
+	}
+}
+
+// Repositories implements the Source interface using the catalog API of the registry.
+func (s *RemoteSource) Repositories(ctx context.Context) ([]string, error) {
+	var repos []string
+	err := s.registry.Repositories(ctx, "", func(names []string) error {
+		for _, name := range names {
+			if s.namespace == "" || strings.HasPrefix(name, s.namespace+"/") {
+				repos = append(repos, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list repositories of registry %q: %w", s.registry.Reference.Registry, err)
+	}
+
+	return repos, nil
+}
+
+// Repository implements the Source interface.
+func (s *RemoteSource) Repository(ctx context.Context, name string) (Target, error) {
+	return s.registry.Repository(ctx, name)
+}
+
+// LocalSource is a directory of OCI image layouts, stored either as directories or as tarballs.
+// The name of each repository is the path of its layout relative to the directory.
+type LocalSource struct {
+	dir     string
+	layouts map[string]string
+}
+
+// NewLocalSource returns a source for the OCI image layouts found in dir.
+func NewLocalSource(dir string) *LocalSource {
+	return &LocalSource{dir: dir}
+}
+
+// Repositories implements the Source interface.
+func (s *LocalSource) Repositories(_ context.Context) ([]string, error) {
+	s.layouts = make(map[string]string)
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		var name string
+		switch {
+		case d.IsDir():
+			if _, err := os.Stat(filepath.Join(path, v1.ImageLayoutFile)); err != nil {
+				return nil
+			}
+			name = path
+		case strings.HasSuffix(d.Name(), ".tar"):
+			name = strings.TrimSuffix(path, ".tar")
+		default:
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, name)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return fmt.Errorf("%q is an OCI image layout, expected a directory of layouts", s.dir)
+		}
+		s.layouts[filepath.ToSlash(rel)] = path
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan directory %q: %w", s.dir, err)
+	}
+
+	repos := make([]string, 0, len(s.layouts))
+	for name := range s.layouts {
+		repos = append(repos, name)
+	}
+	sort.Strings(repos)
+
+	return repos, nil
+}
+
+// Repository implements the Source interface.
+func (s *LocalSource) Repository(ctx context.Context, name string) (Target, error) {
+	path, ok := s.layouts[name]
+	if !ok {
+		return nil, fmt.Errorf("repository %q not found in %q", name, s.dir)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return oci.NewFromFS(ctx, os.DirFS(path))
+	}
+	return oci.NewFromTar(ctx, path)
+}
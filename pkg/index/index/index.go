@@ -47,6 +47,7 @@ type Entry struct {
 	License     string     `yaml:"license"`
 	Maintainers Maintainer `yaml:"maintainers"`
 	Sources     []string   `yaml:"sources"`
+	Tags        []string   `yaml:"tags,omitempty"`
 }
 
 // Maintainer represents an index maintainer.
@@ -74,7 +75,9 @@ type Signature struct {
 
 // Index represents an index.
 type Index struct {
-	Name        string
+	Name string
+	// Priority is used when merging indexes, see MergedIndexes.Merge.
+	Priority    int
 	Entries     []*Entry
 	entryByName map[string]*Entry
 }
@@ -83,6 +86,18 @@ type Index struct {
 type MergedIndexes struct {
 	Index
 	indexByEntry map[*Entry]*Index
+	rewriteRules []RewriteRule
+	// Names of the indexes whose entries have been shadowed, by entry name.
+	shadowed map[string][]string
+}
+
+// Conflict describes an entry name found in more than one of the merged indexes.
+type Conflict struct {
+	Name string
+	// Index is the name of the index whose entry is used.
+	Index string
+	// Shadowed are the names of the other indexes with an entry with the same name, in merge order.
+	Shadowed []string
 }
 
 // New returns a new empty Index.
@@ -217,21 +232,52 @@ func NewMergedIndexes() *MergedIndexes {
 
 	m.entryByName = make(map[string]*Entry)
 	m.indexByEntry = make(map[*Entry]*Index)
+	m.shadowed = make(map[string][]string)
 
 	return m
 }
 
 // Merge creates a new index by merging all the indexes that are passed.
-// Orders matters. Be sure to pass an ordered list of indexes. For our use case, sort by added time.
+// When more indexes have an entry with the same name, the entry of the index with the highest priority is used.
+// Among indexes with the same priority orders matters: the last one wins. Be sure to pass an ordered list of indexes.
+// For our use case, sort by added time.
 func (m *MergedIndexes) Merge(indexes ...*Index) {
 	for _, index := range indexes {
-		for _, Entry := range index.Entries {
-			m.Upsert(Entry)
-			m.indexByEntry[Entry] = index
+		for _, entry := range index.Entries {
+			if current, ok := m.EntryByName(entry.Name); ok {
+				currentIndex := m.indexByEntry[current]
+				if currentIndex.Priority > index.Priority {
+					m.shadowed[entry.Name] = append(m.shadowed[entry.Name], index.Name)
+					continue
+				}
+				m.shadowed[entry.Name] = append(m.shadowed[entry.Name], currentIndex.Name)
+				delete(m.indexByEntry, current)
+			}
+			m.Upsert(entry)
+			m.indexByEntry[entry] = index
 		}
 	}
 }
 
+// Conflicts returns the entry names found in more than one of the merged indexes, sorted by name.
+func (m *MergedIndexes) Conflicts() []Conflict {
+	conflicts := make([]Conflict, 0, len(m.shadowed))
+	for name, shadowed := range m.shadowed {
+		entry, _ := m.EntryByName(name)
+		conflicts = append(conflicts, Conflict{
+			Name:     name,
+			Index:    m.indexByEntry[entry].Name,
+			Shadowed: shadowed,
+		})
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Name < conflicts[j].Name
+	})
+
+	return conflicts
+}
+
 // SearchByKeywords search for entries matching the given keywords in MergedIndexes.
 // minScore is the minimum score to consider a match between a name of an artifact and a keyword.
 // if minScore is not reached, we fallback to a simple partial matching on keywords.
@@ -260,6 +306,26 @@ func (i *Index) SearchByKeywords(minScore float64, keywords ...string) []*Entry
 	return result
 }
 
+// SearchByMatch returns, in index order, the entries whose name or one of whose keywords satisfies match.
+func (i *Index) SearchByMatch(match func(s string) bool) []*Entry {
+	var result []*Entry
+
+	for _, entry := range i.Entries {
+		if match(entry.Name) {
+			result = append(result, entry)
+			continue
+		}
+		for _, keyword := range entry.Keywords {
+			if match(keyword) {
+				result = append(result, entry)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
 // IndexByEntry is used to retrieve the original index from an entry in MergedIndexes.
 func (m *MergedIndexes) IndexByEntry(entry *Entry) *Index {
 	return m.indexByEntry[entry]
@@ -269,23 +335,29 @@ func (m *MergedIndexes) IndexByEntry(entry *Entry) *Index {
 // corresponding to an entry in the index.
 // Returns nil if not found or if the specified name is a full reference.
 func (m *MergedIndexes) SignatureForIndexRef(name string) *Signature {
+	entry, ok := m.EntryForIndexRef(name)
+	if !ok {
+		return nil
+	}
+
+	return entry.Signature
+}
+
+// EntryForIndexRef returns the entry of the index for the specified name, optionally followed by a tag or
+// a digest. Returns false if not found or if the specified name is a full reference.
+func (m *MergedIndexes) EntryForIndexRef(name string) (*Entry, bool) {
 	_, err := registry.ParseReference(name)
-	// If we have a full reference we cannot determine the signature
+	// If we have a full reference we cannot determine the entry
 	if err == nil {
-		return nil
+		return nil, false
 	}
 
 	entryName, _, _, err := parseIndexRef(name)
 	if err != nil {
-		return nil
+		return nil, false
 	}
 
-	entry, ok := m.EntryByName(entryName)
-	if !ok {
-		return nil
-	}
-
-	return entry.Signature
+	return m.EntryByName(entryName)
 }
 
 // ResolveReference is a helper function that parse with the following logic:
@@ -302,6 +374,8 @@ func (m *MergedIndexes) SignatureForIndexRef(name string) *Signature {
 //     e.g. "ghcr.io/falcosecurity/plugins/cloudtrail" -> "ghcr.io/falcosecurity/plugins/cloudtrail:latest"
 //
 //  3. if name is a complete reference, it will be returned as is.
+//
+// References computed from the indexes are redirected according to the configured rewrite rules.
 func (m *MergedIndexes) ResolveReference(name string) (string, error) {
 	parsedRef, err := registry.ParseReference(name)
 	var ref string
@@ -318,7 +392,7 @@ func (m *MergedIndexes) ResolveReference(name string) (string, error) {
 			return "", fmt.Errorf("cannot find %s among the configured indexes, skipping", name)
 		}
 
-		ref = fmt.Sprintf("%s/%s", entry.Registry, entry.Repository)
+		ref = m.rewrite(fmt.Sprintf("%s/%s", entry.Registry, entry.Repository))
 		switch {
 		case tag == "" && digest == "":
 			ref += ":" + oci.DefaultTag
@@ -18,6 +18,8 @@ package index
 import (
 	"crypto/sha256"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -178,6 +180,46 @@ func TestSearchByKeywords(t *testing.T) {
 	}
 }
 
+func TestSearchByMatch(t *testing.T) {
+	i := New("name")
+
+	i.Upsert(&Entry{
+		Name:     "k8saudit",
+		Keywords: []string{"audit", "kubernetes"},
+	})
+	i.Upsert(&Entry{
+		Name:     "k8saudit-rules",
+		Keywords: []string{"audit", "kubernetes"},
+	})
+	i.Upsert(&Entry{
+		Name:     "cloudtrail",
+		Keywords: []string{"aws", "audit"},
+	})
+
+	// Test match on names, results keep the index order.
+	names := i.SearchByMatch(func(s string) bool { return strings.HasPrefix(s, "k8saudit") })
+	if len(names) != 2 || names[0].Name != "k8saudit" || names[1].Name != "k8saudit-rules" {
+		t.Errorf("error in SearchByMatch, expected to find k8saudit and k8saudit-rules in order, got %v", names)
+	}
+
+	// Test match on keywords.
+	keywords := i.SearchByMatch(func(s string) bool { return s == "aws" })
+	if len(keywords) != 1 || keywords[0].Name != "cloudtrail" {
+		t.Errorf("error in SearchByMatch, expected to find cloudtrail by keyword")
+	}
+
+	// Check that no duplicates are returned when both the name and keywords match.
+	noDuplicates := i.SearchByMatch(func(s string) bool { return strings.Contains(s, "audit") })
+	if len(noDuplicates) != 3 {
+		t.Errorf("error in SearchByMatch, not expecting duplicates, got %d entries", len(noDuplicates))
+	}
+
+	// Test no match.
+	if none := i.SearchByMatch(func(string) bool { return false }); len(none) != 0 {
+		t.Errorf("error in SearchByMatch, expected no match")
+	}
+}
+
 func TestNormalize(t *testing.T) {
 	i := Index{
 		Name:        "name",
@@ -241,3 +283,101 @@ func TestConfig(t *testing.T) {
 		t.Error(fmt.Errorf("entry \"test\" not found"))
 	}
 }
+
+func TestResolveReferenceWithRewriteRules(t *testing.T) {
+	i := New("index")
+	i.Upsert(&Entry{Name: "falco-rules", Registry: "ghcr.io", Repository: "falcosecurity/rules/falco-rules"})
+	i.Upsert(&Entry{Name: "other", Registry: "ghcr.io", Repository: "falcosecurityfoo/other"})
+
+	mergedIndex := NewMergedIndexes()
+	mergedIndex.Merge(i)
+
+	rules, err := ParseRewriteRules([]string{
+		"ghcr.io/falcosecurity/rules=>first.internal/rules",
+		"ghcr.io/falcosecurity => mirror.internal/mirror/falcosecurity/",
+		"ghcr.io=>unused.internal",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mergedIndex.SetRewriteRules(rules...)
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"falco-rules", "first.internal/rules/falco-rules:latest"},
+		{"falco-rules:1.0.0", "first.internal/rules/falco-rules:1.0.0"},
+		// Rules match on path boundaries, the first matching rule wins.
+		{"other", "unused.internal/falcosecurityfoo/other:latest"},
+		// Full references are not rewritten.
+		{"ghcr.io/falcosecurity/rules/falco-rules:1.0.0", "ghcr.io/falcosecurity/rules/falco-rules:1.0.0"},
+	}
+
+	for _, tc := range testCases {
+		ref, err := mergedIndex.ResolveReference(tc.name)
+		if err != nil {
+			t.Fatalf("unexpected error resolving %q: %v", tc.name, err)
+		}
+		if ref != tc.expected {
+			t.Errorf("resolving %q: expected %q, got %q", tc.name, tc.expected, ref)
+		}
+	}
+
+	mergedIndex.SetRewriteRules(rules[1])
+	ref, err := mergedIndex.ResolveReference("falco-rules")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "mirror.internal/mirror/falcosecurity/rules/falco-rules:latest"; ref != expected {
+		t.Errorf("expected %q, got %q", expected, ref)
+	}
+}
+
+func TestParseRewriteRule(t *testing.T) {
+	for _, rule := range []string{"", "ghcr.io", "=>mirror.internal", "ghcr.io=>", "ghcr.io->mirror.internal"} {
+		if _, err := ParseRewriteRule(rule); err == nil {
+			t.Errorf("expected error parsing %q", rule)
+		}
+	}
+}
+
+func TestMergeWithPriority(t *testing.T) {
+	official := New("falcosecurity")
+	official.Upsert(&Entry{Name: "k8saudit", Registry: "ghcr.io"})
+	official.Upsert(&Entry{Name: "okta", Registry: "ghcr.io"})
+
+	internal := New("internal")
+	internal.Priority = 10
+	internal.Upsert(&Entry{Name: "k8saudit", Registry: "registry.internal"})
+
+	mirror := New("mirror")
+	mirror.Upsert(&Entry{Name: "k8saudit", Registry: "mirror.internal"})
+	mirror.Upsert(&Entry{Name: "okta", Registry: "mirror.internal"})
+
+	mergedIndex := NewMergedIndexes()
+	mergedIndex.Merge(official, internal, mirror)
+
+	if len(mergedIndex.Entries) != 2 {
+		t.Errorf("Indexes not properly merged")
+	}
+
+	k8saudit, ok := mergedIndex.EntryByName("k8saudit")
+	if !ok || k8saudit.Registry != "registry.internal" || mergedIndex.IndexByEntry(k8saudit).Name != "internal" {
+		t.Errorf("expected entry of the index with the highest priority, got %+v", k8saudit)
+	}
+
+	// Same priority: the last index wins.
+	okta, ok := mergedIndex.EntryByName("okta")
+	if !ok || okta.Registry != "mirror.internal" {
+		t.Errorf("expected entry of the last merged index, got %+v", okta)
+	}
+
+	expected := []Conflict{
+		{Name: "k8saudit", Index: "internal", Shadowed: []string{"falcosecurity", "mirror"}},
+		{Name: "okta", Index: "mirror", Shadowed: []string{"falcosecurity"}},
+	}
+	if conflicts := mergedIndex.Conflicts(); !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("expected conflicts %+v, got %+v", expected, conflicts)
+	}
+}
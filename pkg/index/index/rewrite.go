@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rewriteRuleSeparator separates the two sides of a rewrite rule, e.g. "ghcr.io/falcosecurity=>mirror.internal/falcosecurity".
+const rewriteRuleSeparator = "=>"
+
+// RewriteRule redirects the references computed from the indexes that start with From, to To.
+type RewriteRule struct {
+	From string
+	To   string
+}
+
+// ParseRewriteRule parses a rewrite rule in the "<from>=><to>" format.
+func ParseRewriteRule(rule string) (RewriteRule, error) {
+	from, to, found := strings.Cut(rule, rewriteRuleSeparator)
+	from = strings.TrimSuffix(strings.TrimSpace(from), "/")
+	to = strings.TrimSuffix(strings.TrimSpace(to), "/")
+	if !found || from == "" || to == "" {
+		return RewriteRule{}, fmt.Errorf("invalid registry rewrite rule %q, expected format is \"<from>%s<to>\"", rule, rewriteRuleSeparator)
+	}
+	return RewriteRule{From: from, To: to}, nil
+}
+
+// ParseRewriteRules parses a list of rewrite rules, keeping their order.
+func ParseRewriteRules(rules []string) ([]RewriteRule, error) {
+	parsed := make([]RewriteRule, 0, len(rules))
+	for _, r := range rules {
+		rule, err := ParseRewriteRule(r)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed, nil
+}
+
+// apply rewrites the prefix of ref, matching on path boundaries, if the rule matches.
+func (r RewriteRule) apply(ref string) (string, bool) {
+	if ref == r.From {
+		return r.To, true
+	}
+	if strings.HasPrefix(ref, r.From+"/") {
+		return r.To + strings.TrimPrefix(ref, r.From), true
+	}
+	return ref, false
+}
+
+// SetRewriteRules sets the rules applied, in order, to the references resolved from the indexes.
+// The first matching rule wins.
+func (m *MergedIndexes) SetRewriteRules(rules ...RewriteRule) {
+	m.rewriteRules = rules
+}
+
+func (m *MergedIndexes) rewrite(ref string) string {
+	for _, rule := range m.rewriteRules {
+		if rewritten, ok := rule.apply(ref); ok {
+			return rewritten
+		}
+	}
+	return ref
+}
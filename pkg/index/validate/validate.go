@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/blang/semver"
+	"gopkg.in/yaml.v3"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/pkg/index/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// Severity tells whether an issue makes the index invalid.
+type Severity string
+
+const (
+	// SeverityError is used for issues that make the index unusable, at least partially.
+	SeverityError Severity = "error"
+	// SeverityWarning is used for issues that do not prevent using the index.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in an index.
+type Issue struct {
+	Severity Severity
+	// Entry is the name of the entry the issue refers to, empty for issues concerning the whole index.
+	Entry   string
+	Message string
+}
+
+// TagLister lists the tags of a repository, given its reference.
+type TagLister func(ctx context.Context, ref string) ([]string, error)
+
+// Options configures the checks run on an index.
+type Options struct {
+	// TagLister is used to check that the repositories are reachable and that their tags are versions.
+	// When nil the remote checks are skipped.
+	TagLister TagLister
+}
+
+// Validate checks the content of an index file and returns the issues found, sorted as the entries in the file.
+// It returns an error only when the content cannot be parsed at all.
+func Validate(ctx context.Context, data []byte, opts Options) ([]Issue, error) {
+	var issues []Issue
+	var entries []*index.Entry
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&entries); err != nil && !errors.Is(err, io.EOF) {
+		// Retry without rejecting unknown fields, to run the other checks anyway.
+		if lenientErr := yaml.Unmarshal(data, &entries); lenientErr != nil {
+			return nil, fmt.Errorf("cannot unmarshal index: %w", lenientErr)
+		}
+		issues = append(issues, Issue{Severity: SeverityError, Message: err.Error()})
+	}
+
+	if len(entries) == 0 {
+		issues = append(issues, Issue{Severity: SeverityWarning, Message: "index has no entries"})
+	}
+
+	names := make(map[string]bool, len(entries))
+	repositories := make(map[string]string, len(entries))
+	for i, e := range entries {
+		if e == nil {
+			issues = append(issues, Issue{Severity: SeverityError, Message: fmt.Sprintf("entry #%d is empty", i+1)})
+			continue
+		}
+
+		entryIssues := checkEntry(e)
+		if e.Name == "" {
+			for j := range entryIssues {
+				entryIssues[j].Message = fmt.Sprintf("entry #%d: %s", i+1, entryIssues[j].Message)
+			}
+		} else if names[e.Name] {
+			entryIssues = append(entryIssues, newIssue(SeverityError, e, "duplicate entry name"))
+		}
+		names[e.Name] = true
+
+		if e.Registry != "" && e.Repository != "" {
+			ref := e.Registry + "/" + e.Repository
+			if other, ok := repositories[ref]; ok {
+				entryIssues = append(entryIssues, newIssue(SeverityWarning, e, fmt.Sprintf("same repository as entry %q", other)))
+			} else {
+				repositories[ref] = e.Name
+			}
+		}
+
+		if opts.TagLister != nil && !hasErrors(entryIssues) {
+			entryIssues = append(entryIssues, checkRemote(ctx, e, opts.TagLister)...)
+		}
+
+		issues = append(issues, entryIssues...)
+	}
+
+	return issues, nil
+}
+
+func newIssue(severity Severity, e *index.Entry, msg string) Issue {
+	return Issue{Severity: severity, Entry: e.Name, Message: msg}
+}
+
+// checkEntry checks the fields of an entry.
+func checkEntry(e *index.Entry) []Issue {
+	var issues []Issue
+
+	mandatory := []struct{ field, value string }{
+		{"name", e.Name}, {"type", e.Type}, {"registry", e.Registry}, {"repository", e.Repository},
+	}
+	for _, m := range mandatory {
+		if m.value == "" {
+			issues = append(issues, newIssue(SeverityError, e, fmt.Sprintf("missing mandatory field %q", m.field)))
+		}
+	}
+
+	var artifactType oci.ArtifactType
+	if e.Type != "" {
+		if err := artifactType.Set(e.Type); err != nil {
+			issues = append(issues, newIssue(SeverityError, e, fmt.Sprintf("invalid type %q: %s", e.Type, err)))
+		}
+	}
+
+	if e.Registry != "" && e.Repository != "" {
+		ref, err := registry.ParseReference(e.Registry + "/" + e.Repository)
+		switch {
+		case err != nil:
+			issues = append(issues, newIssue(SeverityError, e, fmt.Sprintf("invalid registry or repository: %s", err)))
+		case ref.Reference != "":
+			issues = append(issues, newIssue(SeverityError, e, "repository must not contain a tag or a digest"))
+		}
+	}
+
+	for _, t := range nonSemverTags(e.Tags) {
+		issues = append(issues, newIssue(SeverityWarning, e, fmt.Sprintf("tag %q is not a semver version", t)))
+	}
+
+	return issues
+}
+
+// checkRemote checks that the repository of an entry is reachable and that its tags are versions.
+func checkRemote(ctx context.Context, e *index.Entry, lister TagLister) []Issue {
+	ref := e.Registry + "/" + e.Repository
+	tags, err := lister(ctx, ref)
+	if err != nil {
+		return []Issue{newIssue(SeverityError, e, fmt.Sprintf("unreachable repository %q: %s", ref, err))}
+	}
+
+	if len(tags) == 0 {
+		return []Issue{newIssue(SeverityWarning, e, fmt.Sprintf("repository %q has no tags", ref))}
+	}
+
+	listed := make(map[string]bool, len(e.Tags))
+	for _, t := range e.Tags {
+		listed[t] = true
+	}
+
+	var issues []Issue
+	for _, t := range nonSemverTags(tags) {
+		if !listed[t] {
+			issues = append(issues, newIssue(SeverityWarning, e, fmt.Sprintf("tag %q of repository %q is not a semver version", t, ref)))
+		}
+	}
+
+	return issues
+}
+
+// nonSemverTags returns the tags that are not versions, ignoring the "latest" tag and the tags of signatures.
+func nonSemverTags(tags []string) []string {
+	var res []string
+	for _, t := range tags {
+		if t == "latest" || strings.HasPrefix(t, "sha256-") {
+			continue
+		}
+		if _, err := semver.ParseTolerant(t); err != nil {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func hasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
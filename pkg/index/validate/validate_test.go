@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validIndex = `- name: k8saudit-rules
+  type: rulesfile
+  registry: ghcr.io
+  repository: falcosecurity/rules/k8saudit-rules
+  tags:
+    - 0.7.0
+- name: k8saudit
+  type: plugin
+  registry: ghcr.io
+  repository: falcosecurity/plugins/plugin/k8saudit
+`
+
+func TestValidateValidIndex(t *testing.T) {
+	issues, err := Validate(context.Background(), []byte(validIndex), Options{})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestValidateInvalidIndex(t *testing.T) {
+	const data = `- name: k8saudit-rules
+  type: rulesfile
+  registry: ghcr.io
+  repository: falcosecurity/rules/k8saudit-rules:latest
+  tags: [0.7.0, main]
+- name: k8saudit-rules
+  type: rules
+  registry: ghcr.io
+  repository: falcosecurity/rules/k8saudit-rules
+- type: plugin
+  registry: ghcr.io
+  repository: falcosecurity/plugins/plugin/k8saudit
+  homepage: https://falco.org
+`
+
+	issues, err := Validate(context.Background(), []byte(data), Options{})
+	require.NoError(t, err)
+	require.Len(t, issues, 6)
+
+	assert.Equal(t, SeverityError, issues[0].Severity)
+	assert.Contains(t, issues[0].Message, "field homepage not found")
+	assert.Equal(t, []Issue{
+		{Severity: SeverityError, Entry: "k8saudit-rules", Message: "repository must not contain a tag or a digest"},
+		{Severity: SeverityWarning, Entry: "k8saudit-rules", Message: `tag "main" is not a semver version`},
+		{Severity: SeverityError, Entry: "k8saudit-rules", Message: `invalid type "rules": must be one of "rulesfile", "plugin", "asset"`},
+		{Severity: SeverityError, Entry: "k8saudit-rules", Message: "duplicate entry name"},
+		{Severity: SeverityError, Message: `entry #3: missing mandatory field "name"`},
+	}, issues[1:])
+}
+
+func TestValidateUnparsableIndex(t *testing.T) {
+	_, err := Validate(context.Background(), []byte("name: not-a-list"), Options{})
+	assert.Error(t, err)
+}
+
+func TestValidateRemote(t *testing.T) {
+	lister := func(_ context.Context, ref string) ([]string, error) {
+		switch ref {
+		case "ghcr.io/falcosecurity/rules/k8saudit-rules":
+			return []string{"0.7.0", "latest", "sha256-abcd.sig", "nightly"}, nil
+		default:
+			return nil, errors.New("name unknown")
+		}
+	}
+
+	issues, err := Validate(context.Background(), []byte(validIndex), Options{TagLister: lister})
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{
+		{
+			Severity: SeverityWarning,
+			Entry:    "k8saudit-rules",
+			Message:  `tag "nightly" of repository "ghcr.io/falcosecurity/rules/k8saudit-rules" is not a semver version`,
+		},
+		{
+			Severity: SeverityError,
+			Entry:    "k8saudit",
+			Message:  `unreachable repository "ghcr.io/falcosecurity/plugins/plugin/k8saudit": name unknown`,
+		},
+	}, issues)
+}
@@ -60,6 +60,15 @@ const (
 	CertificatePEMHeader = "CERTIFICATE"
 )
 
+// Permissions of the files and directory written by FlushToDisk.
+const (
+	dirPerm          = 0o700
+	groupDirPerm     = 0o750
+	keyFilePerm      = 0o600
+	groupKeyFilePerm = 0o640
+	certFilePerm     = 0o644
+)
+
 var certsFilenames = []string{
 	ServerKey,
 	ClientKey,
@@ -241,20 +241,81 @@ func (g *GRPCTLS) GenerateClient(caTemplate *x509.Certificate, caKey DSAKey, not
 	return nil
 }
 
+// FlushOption configures how FlushToDisk persists the cert material.
+type FlushOption func(*flushOptions)
+
+type flushOptions struct {
+	keysGroup int
+}
+
+// WithKeysGroup makes the private keys, and the directory containing them, owned by
+// and readable from the given group id.
+func WithKeysGroup(gid int) FlushOption {
+	return func(o *flushOptions) {
+		o.keysGroup = gid
+	}
+}
+
 // FlushToDisk is used to persist the cert material from a GRPCTLS to disk given a path.
-func (g *GRPCTLS) FlushToDisk(path string, logger *pterm.Logger) error {
-	p, err := satisfyDir(path)
+// The directory is created if missing. Private keys are only readable by the owner
+// (and by the keys group, if any), while certificates are world readable.
+func (g *GRPCTLS) FlushToDisk(path string, logger *pterm.Logger, opts ...FlushOption) error {
+	o := flushOptions{keysGroup: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	perm := os.FileMode(dirPerm)
+	if o.keysGroup >= 0 {
+		perm = groupDirPerm
+	}
+	p, created, err := satisfyDir(path, perm)
 	if err != nil {
 		return fmt.Errorf("invalid path: %w", err)
 	}
 	path = p
+	// Only change the group of directories we created, not of pre-existing ones,
+	// warning when the keys group is not allowed to enter them.
+	switch {
+	case o.keysGroup < 0:
+	case created:
+		if err := os.Chown(path, -1, o.keysGroup); err != nil {
+			return fmt.Errorf("unable to set group of %q: %w", path, err)
+		}
+	default:
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		if info.Mode().Perm()&0o011 == 0 {
+			logger.Warn("The keys group is not allowed to enter the existing directory, the private keys are not readable by it",
+				logger.Args("directory", path, "mode", fmt.Sprintf("%#o", info.Mode().Perm())))
+		}
+	}
 
 	for _, name := range certsFilenames {
 		f := filepath.Join(path, name)
 		logger.Info("Saving file", logger.Args("name", name, "directory", path))
-		if err := os.WriteFile(f, g.certs[name].Bytes(), 0o600); err != nil {
+
+		perm := os.FileMode(certFilePerm)
+		if isKey(name) {
+			perm = keyFilePerm
+			if o.keysGroup >= 0 {
+				perm = groupKeyFilePerm
+			}
+		}
+		if err := os.WriteFile(f, g.certs[name].Bytes(), perm); err != nil {
 			return fmt.Errorf("unable to write %q: %w", name, err)
 		}
+		// Enforce the permissions also on files that already existed, and regardless of the umask.
+		if err := os.Chmod(f, perm); err != nil {
+			return fmt.Errorf("unable to set permissions of %q: %w", name, err)
+		}
+		if isKey(name) && o.keysGroup >= 0 {
+			if err := os.Chown(f, -1, o.keysGroup); err != nil {
+				return fmt.Errorf("unable to set group of %q: %w", name, err)
+			}
+		}
 	}
 
 	logger.Info("Done generating the TLS certificates")
@@ -266,15 +327,26 @@ func (g *GRPCTLS) Certs() map[string]*bytes.Buffer {
 	return g.certs
 }
 
-func satisfyDir(dirName string) (string, error) {
-	abs, err := filepath.Abs(dirName)
+// satisfyDir ensures the directory exists, creating it with the given permissions if missing.
+// It returns whether the directory has been created.
+func satisfyDir(dirName string, perm os.FileMode) (abs string, created bool, err error) {
+	abs, err = filepath.Abs(dirName)
 	if err != nil {
-		return "", fmt.Errorf("unable to calculate absolute path: %w", err)
+		return "", false, fmt.Errorf("unable to calculate absolute path: %w", err)
+	}
+	if _, err = os.Stat(abs); err == nil {
+		return abs, false, nil
 	}
-	err = os.MkdirAll(abs, 0o700)
-	if err == nil || os.IsExist(err) {
-		return abs, nil
+	if err = os.MkdirAll(abs, perm); err != nil {
+		return "", false, fmt.Errorf("unable to ensure dir: %w", err)
 	}
+	// Enforce the permissions regardless of the umask.
+	if err = os.Chmod(abs, perm); err != nil {
+		return "", false, fmt.Errorf("unable to set permissions of dir: %w", err)
+	}
+	return abs, true, nil
+}
 
-	return "", fmt.Errorf("unable to ensure dir: %w", err)
+func isKey(name string) bool {
+	return name == ServerKey || name == ClientKey || name == CAKey
 }
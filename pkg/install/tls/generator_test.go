@@ -16,12 +16,17 @@
 package tls_test
 
 import (
+	"bytes"
 	"crypto/x509"
 	"encoding/pem"
+	"io"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/pterm/pterm"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/falcosecurity/falcoctl/pkg/install/tls"
@@ -86,3 +91,63 @@ func getCertsParams(gen *tls.GRPCTLS) (notBefore, notAfter time.Time, serialNumb
 
 	return
 }
+
+func TestFlushToDisk(t *testing.T) {
+	gen := buildGRPCTLSGenerator()
+	assert.Nil(t, gen.Generate())
+
+	testCases := []struct {
+		name            string
+		opts            []tls.FlushOption
+		expectedDirPerm os.FileMode
+		expectedKeyPerm os.FileMode
+	}{
+		{
+			name:            "default",
+			expectedDirPerm: 0o700,
+			expectedKeyPerm: 0o600,
+		},
+		{
+			name:            "with keys group",
+			opts:            []tls.FlushOption{tls.WithKeysGroup(os.Getgid())},
+			expectedDirPerm: 0o750,
+			expectedKeyPerm: 0o640,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "not", "existing")
+			assert.Nil(t, gen.FlushToDisk(dir, pterm.DefaultLogger.WithWriter(io.Discard), tc.opts...))
+
+			info, err := os.Stat(dir)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedDirPerm, info.Mode().Perm())
+
+			for _, name := range []string{tls.CAKey, tls.ServerKey, tls.ClientKey} {
+				info, err := os.Stat(filepath.Join(dir, name))
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expectedKeyPerm, info.Mode().Perm(), name)
+			}
+			for _, name := range []string{tls.CACert, tls.ServerCert, tls.ClientCert} {
+				info, err := os.Stat(filepath.Join(dir, name))
+				assert.Nil(t, err)
+				assert.Equal(t, os.FileMode(0o644), info.Mode().Perm(), name)
+			}
+		})
+	}
+
+	t.Run("existing directory not reachable by the keys group", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Nil(t, os.Chmod(dir, 0o700))
+		var out bytes.Buffer
+		assert.Nil(t, gen.FlushToDisk(dir, pterm.DefaultLogger.WithFormatter(pterm.LogFormatterJSON).WithWriter(&out),
+			tls.WithKeysGroup(os.Getgid())))
+
+		// The directory is left untouched, the mismatch is reported.
+		info, err := os.Stat(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+		assert.Contains(t, out.String(), "The keys group is not allowed to enter the existing directory")
+	})
+}
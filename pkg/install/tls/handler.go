@@ -19,6 +19,8 @@ import (
 	"crypto/elliptic"
 	"fmt"
 	"os"
+	"os/user"
+	"strconv"
 
 	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
 )
@@ -34,6 +36,8 @@ type Options struct {
 	DNSSANs   []string
 	IPSANs    []string
 	Algorithm string
+	// Group, if set, owns the private keys and can read them.
+	Group string
 
 	Common *commonoptions.Common
 }
@@ -85,5 +89,29 @@ func (o *Options) Run() error {
 		return err
 	}
 
-	return generator.FlushToDisk(o.Path, logger)
+	var flushOpts []FlushOption
+	if o.Group != "" {
+		gid, err := lookupGroup(o.Group)
+		if err != nil {
+			return err
+		}
+		flushOpts = append(flushOpts, WithKeysGroup(gid))
+	}
+
+	return generator.FlushToDisk(o.Path, logger, flushOpts...)
+}
+
+// lookupGroup returns the id of a group given its name or id.
+func lookupGroup(group string) (int, error) {
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		if g, err = user.LookupGroupId(group); err != nil {
+			return 0, fmt.Errorf("unable to find group %q: %w", group, err)
+		}
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse id of group %q: %w", group, err)
+	}
+	return gid, nil
 }
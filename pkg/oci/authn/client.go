@@ -23,6 +23,8 @@ import (
 
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras-go/v2/registry/remote/credentials"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
 )
 
 const (
@@ -50,8 +52,8 @@ func NewClient(options ...func(*Options)) *auth.Client {
 
 	authClient := auth.Client{
 		Client: &http.Client{
-			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
+			Transport: utils.InsecureHostsTransport(&http.Transport{
+				Proxy: utils.Proxy,
 				DialContext: (&net.Dialer{
 					Timeout:   30 * time.Second,
 					KeepAlive: 30 * time.Second,
@@ -61,8 +63,8 @@ func NewClient(options ...func(*Options)) *auth.Client {
 				IdleConnTimeout:       90 * time.Second,
 				TLSHandshakeTimeout:   10 * time.Second,
 				ExpectContinueTimeout: 1 * time.Second,
-				// TODO(loresuso, alacuku): tls config.
-			},
+				TLSClientConfig:       utils.TLSConfig(),
+			}),
 		},
 		Cache: opt.ClientTokenCache,
 		Credential: func(ctx context.Context, reg string) (auth.Credential, error) {
@@ -45,6 +45,11 @@ func GCPCredential(ctx context.Context, reg string) (auth.Credential, error) {
 	if err != nil {
 		return auth.EmptyCredential, fmt.Errorf("unable to retrieve gcp authentication config %w", err)
 	}
+	_, _, regGcpAuths, err := config.RegistriesAuths()
+	if err != nil {
+		return auth.EmptyCredential, fmt.Errorf("unable to retrieve gcp authentication config %w", err)
+	}
+	gcpAuths = append(gcpAuths, regGcpAuths...)
 
 	idx := slices.IndexFunc(gcpAuths, func(c config.GcpAuth) bool { return c.Registry == reg })
 
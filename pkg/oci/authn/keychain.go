@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"context"
+
+	ggcrauthn "github.com/google/go-containerregistry/pkg/authn"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// keychain resolves the credentials of the registries through the ones of an auth.Client.
+type keychain struct {
+	ctx        context.Context
+	credential auth.CredentialFunc
+}
+
+// NewKeychain returns a go-containerregistry keychain, as used by cosign, resolving the credentials
+// of the registries as the given client does: the ones of the falcoctl credential store,
+// OAuth2.0 client credentials and gcp credentials.
+func NewKeychain(ctx context.Context, client *auth.Client) ggcrauthn.Keychain {
+	return &keychain{ctx: ctx, credential: client.Credential}
+}
+
+// Resolve returns the authenticator for the registry of the resource, anonymous if no credentials are found.
+func (k *keychain) Resolve(res ggcrauthn.Resource) (ggcrauthn.Authenticator, error) {
+	if k.credential == nil {
+		return ggcrauthn.Anonymous, nil
+	}
+	cred, err := k.credential(k.ctx, res.RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+	if cred == auth.EmptyCredential {
+		return ggcrauthn.Anonymous, nil
+	}
+	return ggcrauthn.FromConfig(ggcrauthn.AuthConfig{
+		Username:      cred.Username,
+		Password:      cred.Password,
+		IdentityToken: cred.RefreshToken,
+		RegistryToken: cred.AccessToken,
+	}), nil
+}
@@ -35,6 +35,12 @@ const (
 	// FalcoAssetLayerMediaType is the MediaType for assets.
 	FalcoAssetLayerMediaType = "application/vnd.cncf.falco.asset.layer.v1+tar.gz"
 
+	// FalcoDriverArtifactType is the artifact type of the drivers published by driver install.
+	FalcoDriverArtifactType = "application/vnd.cncf.falco.driver.v1"
+
+	// FalcoDriverLayerMediaType is the MediaType for drivers, a kernel module or an eBPF probe.
+	FalcoDriverLayerMediaType = "application/vnd.cncf.falco.driver.layer.v1"
+
 	// DefaultTag is the default tag reference to be used when none is provided.
 	DefaultTag = "latest"
 )
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package puller
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/repository"
+)
+
+// Layer describes a layer of an artifact, as reported by Inspect.
+type Layer struct {
+	MediaType   string            `json:"mediaType" yaml:"mediaType"`
+	Digest      string            `json:"digest" yaml:"digest"`
+	Size        int64             `json:"size" yaml:"size"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// Files are the names of the files the layer would install. It is empty when
+	// the contents of the layers have not been requested.
+	Files []string `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// Inspection describes an artifact without installing it.
+type Inspection struct {
+	Ref          string                    `json:"ref" yaml:"ref"`
+	Digest       string                    `json:"digest" yaml:"digest"`
+	Type         oci.ArtifactType          `json:"type" yaml:"type"`
+	Annotations  map[string]string         `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Dependencies []oci.ArtifactDependency  `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Requirements []oci.ArtifactRequirement `json:"requirements,omitempty" yaml:"requirements,omitempty"`
+	Layers       []Layer                   `json:"layers" yaml:"layers"`
+}
+
+// Inspect fetches the manifest and the config layer of an artifact and describes its layers.
+// When withFiles is true the layers are also streamed, without being written to disk,
+// to list the files they contain.
+// If the artifact has a v1.MediaTypeImageIndex descriptor then it inspects the manifest for the
+// specified platform.
+func (p *Puller) Inspect(ctx context.Context, ref, os, arch string, withFiles bool) (*Inspection, error) {
+	repo, err := repository.NewRepository(ref, repository.WithClient(p.Client), repository.WithPlainHTTP(p.plainHTTP))
+	if err != nil {
+		return nil, err
+	}
+
+	manifestBytes, err := p.RawManifest(ctx, ref, os, arch)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get manifest: %w", err)
+	}
+
+	var manifest v1.Manifest
+	if err = json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+
+	inspection := &Inspection{
+		Ref:         ref,
+		Digest:      digest.FromBytes(manifestBytes).String(),
+		Annotations: manifest.Annotations,
+		Layers:      []Layer{},
+	}
+
+	if len(manifest.Layers) > 0 {
+		inspection.Type = artifactTypeFromMediaType(manifest.Layers[0].MediaType)
+	}
+
+	// An artifact without a valid config layer can still be inspected.
+	if configBytes, err := p.RawConfigLayer(ctx, ref, os, arch); err == nil {
+		var cfg oci.ArtifactConfig
+		if json.Unmarshal(configBytes, &cfg) == nil {
+			inspection.Dependencies = cfg.Dependencies
+			inspection.Requirements = cfg.Requirements
+		}
+	}
+
+	for _, desc := range manifest.Layers {
+		layer := Layer{
+			MediaType:   desc.MediaType,
+			Digest:      desc.Digest.String(),
+			Size:        desc.Size,
+			Annotations: desc.Annotations,
+		}
+
+		if withFiles {
+			rc, err := repo.Fetch(ctx, desc)
+			if err != nil {
+				return nil, fmt.Errorf("unable to fetch layer with digest %q: %w", desc.Digest, err)
+			}
+			layer.Files, err = layerFiles(rc, desc.Annotations[v1.AnnotationTitle])
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("unable to read layer with digest %q: %w", desc.Digest, err)
+			}
+		}
+
+		inspection.Layers = append(inspection.Layers, layer)
+	}
+
+	return inspection, nil
+}
+
+// artifactTypeFromMediaType returns the type of artifact whose first layer has the given media type,
+// or an empty type if it is unknown.
+func artifactTypeFromMediaType(mediaType string) oci.ArtifactType {
+	switch mediaType {
+	case oci.FalcoPluginLayerMediaType:
+		return oci.Plugin
+	case oci.FalcoRulesfileLayerMediaType:
+		return oci.Rulesfile
+	case oci.FalcoAssetLayerMediaType:
+		return oci.Asset
+	default:
+		return ""
+	}
+}
+
+// layerFiles returns the names of the regular files in a tar.gz layer. Layers that are not
+// gzip compressed tarballs are installed as is, so their only file is the layer title.
+func layerFiles(r io.Reader, title string) ([]string, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil || !bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return []string{title}, nil
+	}
+
+	gzr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+
+	var files []string
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg {
+			files = append(files, header.Name)
+		}
+	}
+
+	return files, nil
+}
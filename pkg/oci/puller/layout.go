@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package puller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+	ocilayout "oras.land/oras-go/v2/content/oci"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/repository"
+)
+
+// layoutIngestDir is where the OCI layout store keeps its temporary files, not part of the layout.
+const layoutIngestDir = "ingest"
+
+// PullLayout copies the artifact at ref, with all its platforms, to the OCI image layout in dir,
+// preserving its digests. The artifact is tagged in the layout with the tag of ref, or with its
+// digest when ref has none.
+func (p *Puller) PullLayout(ctx context.Context, ref, dir string) (*oci.RegistryResult, error) {
+	repo, err := repository.NewRepository(ref, repository.WithClient(p.Client), repository.WithPlainHTTP(p.plainHTTP))
+	if err != nil {
+		return nil, err
+	}
+	if repo.Reference.Reference == "" {
+		ref += ":" + oci.DefaultTag
+		repo.Reference.Reference = oci.DefaultTag
+	}
+
+	desc, err := p.resolve(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := ocilayout.NewWithContext(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create OCI layout in %q: %w", dir, err)
+	}
+	dst := oras.Target(store)
+	if p.tracker != nil {
+		dst = p.tracker(dst)
+	}
+	copyOpts := oras.DefaultCopyGraphOptions
+	copyOpts.Concurrency = p.concurrency
+	if err := oras.CopyGraph(ctx, repo, dst, desc, copyOpts); err != nil {
+		return nil, fmt.Errorf("unable to copy artifact %s to %q: %w", ref, dir, err)
+	}
+	if err := store.Tag(ctx, desc, repo.Reference.Reference); err != nil {
+		return nil, err
+	}
+
+	artifactType, err := layoutArtifactType(ctx, store, desc)
+	if err != nil {
+		return nil, err
+	}
+	return &oci.RegistryResult{RootDigest: string(desc.Digest), Type: artifactType}, nil
+}
+
+// PullLayoutTar is like PullLayout, but writes the OCI image layout as a tar archive to path.
+func (p *Puller) PullLayoutTar(ctx context.Context, ref, path string) (*oci.RegistryResult, error) {
+	dir, err := os.MkdirTemp("", "falcoctl-layout-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	res, err := p.PullLayout(ctx, ref, dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTar(dir, path); err != nil {
+		return nil, fmt.Errorf("unable to write OCI layout archive %q: %w", path, err)
+	}
+	return res, nil
+}
+
+// PullFromLayout pulls to destDir, as Pull does from a registry, the artifact tagged reference, or
+// with the digest reference, in the OCI image layout in dir. Only the manifest for the given
+// platform is pulled when the artifact has several of them.
+func (p *Puller) PullFromLayout(ctx context.Context, dir, reference, destDir, os, arch string) (*oci.RegistryResult, error) {
+	store, err := ocilayout.NewWithContext(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open OCI layout %q: %w", dir, err)
+	}
+	refDesc, err := store.Resolve(ctx, reference)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find %q in OCI layout %q: %w", reference, dir, err)
+	}
+
+	fileStore, err := file.New(destDir)
+	if err != nil {
+		return nil, err
+	}
+	localTarget := oras.Target(fileStore)
+	if p.tracker != nil {
+		localTarget = p.tracker(localTarget)
+	}
+	copyOpts := oras.CopyOptions{}
+	copyOpts.Concurrency = p.concurrency
+	if refDesc.MediaType == v1.MediaTypeImageIndex {
+		copyOpts.WithTargetPlatform(&v1.Platform{OS: os, Architecture: arch})
+	}
+	desc, err := oras.Copy(ctx, store, reference, localTarget, reference, copyOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to pull %q from OCI layout %q: %w", reference, dir, err)
+	}
+
+	return pulledResult(ctx, localTarget, refDesc, desc)
+}
+
+// layoutArtifactType returns the type of the artifact described by desc, from its first manifest.
+func layoutArtifactType(ctx context.Context, store content.Fetcher, desc v1.Descriptor) (oci.ArtifactType, error) {
+	if desc.MediaType == v1.MediaTypeImageIndex {
+		data, err := content.FetchAll(ctx, store, desc)
+		if err != nil {
+			return "", err
+		}
+		var index v1.Index
+		if err := json.Unmarshal(data, &index); err != nil {
+			return "", err
+		}
+		if len(index.Manifests) == 0 {
+			return "", fmt.Errorf("index %q has no manifests", desc.Digest)
+		}
+		desc = index.Manifests[0]
+	}
+	data, err := content.FetchAll(ctx, store, desc)
+	if err != nil {
+		return "", err
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", err
+	}
+	if len(manifest.Layers) == 0 {
+		return "", fmt.Errorf("manifest %q has no layers", desc.Digest)
+	}
+	return artifactTypeFromMediaType(manifest.Layers[0].MediaType), nil
+}
+
+// writeTar archives the content of the OCI layout in dir in the tar file at path.
+func writeTar(dir, path string) error {
+	out, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	err = utils.TarDirectory(out, dir, layoutIngestDir)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -23,6 +23,7 @@ import (
 
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/file"
 	"oras.land/oras-go/v2/registry/remote"
 
@@ -33,19 +34,37 @@ import (
 
 // Puller implements pull operations.
 type Puller struct {
-	Client    remote.Client
-	tracker   output.Tracker
-	plainHTTP bool
+	Client      remote.Client
+	tracker     output.Tracker
+	plainHTTP   bool
+	concurrency int
+	backoff     Backoff
+}
+
+// Option customizes a Puller.
+type Option func(p *Puller)
+
+// WithConcurrency sets how many blobs of an artifact are fetched in parallel. Defaults to 1.
+func WithConcurrency(n int) Option {
+	return func(p *Puller) {
+		p.concurrency = n
+	}
 }
 
 // NewPuller create a new puller that can be used for pull operations.
 // The client must be ready to be used by the puller.
-func NewPuller(client remote.Client, plainHTTP bool, tracker output.Tracker) *Puller {
-	return &Puller{
-		Client:    client,
-		tracker:   tracker,
-		plainHTTP: plainHTTP,
-	}
+func NewPuller(client remote.Client, plainHTTP bool, tracker output.Tracker, opts ...Option) *Puller {
+	p := &Puller{
+		Client:      client,
+		tracker:     tracker,
+		plainHTTP:   plainHTTP,
+		concurrency: 1,
+		backoff:     DefaultBackoff,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
 }
 
 // Pull an artifact from a remote registry.
@@ -69,13 +88,13 @@ func (p *Puller) Pull(ctx context.Context, ref, destDir, os, arch string) (*oci.
 		repo.Reference.Reference = oci.DefaultTag
 	}
 
-	refDesc, _, err := repo.FetchReference(ctx, ref)
+	refDesc, err := p.resolve(ctx, repo, ref)
 	if err != nil {
 		return nil, err
 	}
 
 	copyOpts := oras.CopyOptions{}
-	copyOpts.Concurrency = 1
+	copyOpts.Concurrency = p.concurrency
 	if refDesc.MediaType == v1.MediaTypeImageIndex {
 		plt := &v1.Platform{
 			OS:           os,
@@ -89,54 +108,75 @@ func (p *Puller) Pull(ctx context.Context, ref, destDir, os, arch string) (*oci.
 	if p.tracker != nil {
 		localTarget = p.tracker(localTarget)
 	}
-	desc, err := oras.Copy(ctx, repo, ref, localTarget, ref, copyOpts)
+	// Transient failures are retried, and interrupted downloads resumed.
+	src := &resumableSource{ReadOnlyTarget: repo, puller: p, dir: destDir}
+	desc, err := oras.Copy(ctx, src, ref, localTarget, ref, copyOpts)
 
 	if err != nil {
 		return nil, fmt.Errorf("unable to pull artifact %s with tag %s from repo %s: %w",
 			repo.Reference.Repository, repo.Reference.Reference, repo.Reference.Repository, err)
 	}
 
-	manifest, err := manifestFromDesc(ctx, localTarget, &desc)
+	return pulledResult(ctx, localTarget, refDesc, desc)
+}
+
+// Descriptor retrieves the descriptor of an artifact from a remote repository.
+func (p *Puller) Descriptor(ctx context.Context, ref string) (*v1.Descriptor, error) {
+	repo, err := repository.NewRepository(ref, repository.WithClient(p.Client), repository.WithPlainHTTP(p.plainHTTP))
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := p.resolve(ctx, repo, ref)
 	if err != nil {
 		return nil, err
 	}
+	return &desc, nil
+}
 
-	var artifactType oci.ArtifactType
-	switch manifest.Layers[0].MediaType {
-	case oci.FalcoPluginLayerMediaType:
-		artifactType = oci.Plugin
-	case oci.FalcoRulesfileLayerMediaType:
-		artifactType = oci.Rulesfile
-	case oci.FalcoAssetLayerMediaType:
-		artifactType = oci.Asset
-	default:
+// resolve returns the descriptor of ref, retrying the transient failures.
+func (p *Puller) resolve(ctx context.Context, repo *repository.Repository, ref string) (desc v1.Descriptor, err error) {
+	err = p.retry(ctx, func() error {
+		var rc io.ReadCloser
+		if desc, rc, err = repo.FetchReference(ctx, ref); err != nil {
+			return err
+		}
+		return rc.Close()
+	})
+	return desc, err
+}
+
+// pulledResult describes the artifact pulled to target: refDesc is the descriptor of the reference,
+// desc the one of the manifest pulled for the platform.
+func pulledResult(ctx context.Context, target oras.Target, refDesc, desc v1.Descriptor) (*oci.RegistryResult, error) {
+	manifest, err := manifestFromDesc(ctx, target, &desc)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactType := artifactTypeFromMediaType(manifest.Layers[0].MediaType)
+	if artifactType == "" {
 		return nil, fmt.Errorf("unknown media type: %q", manifest.Layers[0].MediaType)
 	}
 
 	filename := manifest.Layers[0].Annotations[v1.AnnotationTitle]
 
+	// The config layer has been pulled along with the artifact. It is only informative here, such as for
+	// the version of the artifact, so an artifact without a valid one is still pulled.
+	var artifactConfig oci.ArtifactConfig
+	if configBytes, err := content.FetchAll(ctx, target, manifest.Config); err == nil {
+		_ = json.Unmarshal(configBytes, &artifactConfig)
+	}
+
 	return &oci.RegistryResult{
 		RootDigest: string(refDesc.Digest),
 		Digest:     string(desc.Digest),
+		Config:     artifactConfig,
 		Type:       artifactType,
 		Filename:   filename,
 	}, nil
 }
 
-// Descriptor retrieves the descriptor of an artifact from a remote repository.
-func (p *Puller) Descriptor(ctx context.Context, ref string) (*v1.Descriptor, error) {
-	repo, err := repository.NewRepository(ref, repository.WithClient(p.Client), repository.WithPlainHTTP(p.plainHTTP))
-	if err != nil {
-		return nil, err
-	}
-
-	desc, _, err := repo.FetchReference(ctx, ref)
-	if err != nil {
-		return nil, err
-	}
-	return &desc, nil
-}
-
 func manifestFromDesc(ctx context.Context, target oras.Target, desc *v1.Descriptor) (*v1.Manifest, error) {
 	var manifest v1.Manifest
 
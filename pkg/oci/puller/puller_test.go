@@ -29,6 +29,7 @@ import (
 	"github.com/falcosecurity/falcoctl/pkg/oci"
 	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
 	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
+	ocipusher "github.com/falcosecurity/falcoctl/pkg/oci/pusher"
 	"github.com/falcosecurity/falcoctl/pkg/output"
 )
 
@@ -113,6 +114,8 @@ var _ = Describe("Puller", func() {
 					Expect(err).Should(BeNil())
 					Expect(result).ShouldNot(BeNil())
 					Expect(result.Type).Should(Equal(oci.Rulesfile))
+					// The config layer is returned along with the artifact.
+					Expect(result.Config.Dependencies).Should(HaveLen(2))
 					// Check that config file and plugins exists.
 					_, err := os.Stat(filepath.Join(destinationDir, result.Filename))
 					Expect(err).ShouldNot(HaveOccurred())
@@ -124,6 +127,25 @@ var _ = Describe("Puller", func() {
 			})
 		})
 
+		Describe("with concurrency", func() {
+			BeforeEach(func() {
+				ref = rulesRef
+			})
+
+			It("should succeed", func() {
+				puller = ocipuller.NewPuller(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), plainHTTP, nil,
+					ocipuller.WithConcurrency(4))
+				result, err = puller.Pull(ctx, ref, destinationDir, OS, ARCH)
+				Expect(err).Should(BeNil())
+				Expect(result).ShouldNot(BeNil())
+				Expect(result.Type).Should(Equal(oci.Rulesfile))
+				_, err := os.Stat(filepath.Join(destinationDir, result.Filename))
+				Expect(err).ShouldNot(HaveOccurred())
+				// Remove downloaded files from temporary directory.
+				Expect(os.Remove(filepath.Join(destinationDir, result.Filename))).ShouldNot(HaveOccurred())
+			})
+		})
+
 		Describe("artifact without config layer", func() {
 			BeforeEach(func() {
 				ref = artifactWithuoutConfigRef
@@ -352,4 +374,146 @@ var _ = Describe("Puller", func() {
 			})
 		})
 	})
+
+	Context("Inspect func", func() {
+		var (
+			ref        string
+			withFiles  bool
+			inspection *ocipuller.Inspection
+			err        error
+		)
+		JustBeforeEach(func() {
+			puller = ocipuller.NewPuller(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), plainHTTP, tracker)
+			tokens := strings.Split(testPluginPlatform1, "/")
+			inspection, err = puller.Inspect(ctx, ref, tokens[0], tokens[1], withFiles)
+		})
+
+		JustAfterEach(func() {
+			inspection = nil
+			withFiles = false
+			err = nil
+		})
+
+		When("Artifact does not exist", func() {
+			BeforeEach(func() {
+				ref = nonExistingArtifact
+			})
+
+			It("should error", func() {
+				Expect(err).Should(HaveOccurred())
+				Expect(inspection).Should(BeNil())
+			})
+		})
+
+		When("without the files", func() {
+			BeforeEach(func() {
+				ref = pluginMultiPlatformRef
+			})
+
+			It("should describe the layers and the config", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(inspection.Type).Should(Equal(oci.Plugin))
+				Expect(inspection.Digest).ShouldNot(BeEmpty())
+				Expect(inspection.Layers).Should(HaveLen(1))
+				Expect(inspection.Layers[0].MediaType).Should(Equal(oci.FalcoPluginLayerMediaType))
+				Expect(inspection.Layers[0].Annotations).Should(HaveKeyWithValue(v1.AnnotationTitle, filepath.Base(testPluginTarball)))
+				Expect(inspection.Layers[0].Files).Should(BeEmpty())
+				Expect(inspection.Requirements).Should(ConsistOf(oci.ArtifactRequirement{Name: "my-req", Version: "7.8.9"}))
+				Expect(inspection.Dependencies).Should(HaveLen(1))
+				Expect(inspection.Dependencies[0].Name).Should(Equal("my-dep"))
+			})
+		})
+
+		When("with the files", func() {
+			BeforeEach(func() {
+				ref = rulesRef
+				withFiles = true
+			})
+
+			It("should list the files of the layers", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(inspection.Type).Should(Equal(oci.Rulesfile))
+				Expect(inspection.Layers).Should(HaveLen(1))
+				Expect(inspection.Layers[0].Files).Should(ConsistOf("aws_cloudtrail_rules.yaml"))
+			})
+		})
+
+		When("config layer is not set", func() {
+			BeforeEach(func() {
+				ref = artifactWithuoutConfigRef
+			})
+
+			It("should still describe the layers", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(inspection.Layers).Should(HaveLen(1))
+				Expect(inspection.Dependencies).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("PullLayout func", func() {
+		var (
+			layout string
+			result *oci.RegistryResult
+			err    error
+		)
+		BeforeEach(func() {
+			puller = ocipuller.NewPuller(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), plainHTTP, tracker)
+			layout = GinkgoT().TempDir()
+		})
+
+		It("should error when the artifact does not exist", func() {
+			_, err = puller.PullLayout(ctx, localRegistryHost+"/"+nonExistingArtifact, layout)
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should copy all the platforms and push them back with the same digest", func() {
+			result, err = puller.PullLayout(ctx, pluginMultiPlatformRef, layout)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.Type).Should(Equal(oci.Plugin))
+			Expect(filepath.Join(layout, v1.ImageLayoutFile)).Should(BeARegularFile())
+			index, err := os.ReadFile(filepath.Join(layout, v1.ImageIndexFile))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(index)).Should(ContainSubstring(`"org.opencontainers.image.ref.name":"multiplatform"`))
+
+			pusher := ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), plainHTTP, nil)
+			pushed, err := pusher.PushLayout(ctx, localRegistryHost+"/plugins-from-layout", layout, "copy")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pushed.RootDigest).Should(Equal(result.RootDigest))
+
+			copied, err := puller.PullLayout(ctx, localRegistryHost+"/plugins-from-layout:copy", GinkgoT().TempDir())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(copied.RootDigest).Should(Equal(result.RootDigest))
+		})
+
+		It("should push back a tar archive with the same digest", func() {
+			archive := filepath.Join(layout, "rules.tar")
+			result, err = puller.PullLayoutTar(ctx, rulesRef, archive)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.Type).Should(Equal(oci.Rulesfile))
+
+			pusher := ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), plainHTTP, nil)
+			pushed, err := pusher.PushLayout(ctx, localRegistryHost+"/rules-from-layout:other", archive)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pushed.RootDigest).Should(Equal(result.RootDigest))
+		})
+
+		It("should pull the files of a platform from the layout", func() {
+			result, err = puller.PullLayout(ctx, pluginMultiPlatformRef, layout)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			tokens := strings.Split(testPluginPlatform2, "/")
+			destDir := GinkgoT().TempDir()
+			pulled, err := puller.PullFromLayout(ctx, layout, result.RootDigest, destDir, tokens[0], tokens[1])
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pulled.RootDigest).Should(Equal(result.RootDigest))
+			Expect(pulled.Type).Should(Equal(oci.Plugin))
+			Expect(filepath.Join(destDir, pulled.Filename)).Should(BeARegularFile())
+
+			_, err = puller.PullFromLayout(ctx, layout, result.RootDigest, GinkgoT().TempDir(), "linux", "non-existing")
+			Expect(err).Should(HaveOccurred())
+			_, err = puller.PullFromLayout(ctx, layout, "non-existing", GinkgoT().TempDir(), tokens[0], tokens[1])
+			Expect(err).Should(HaveOccurred())
+		})
+	})
 })
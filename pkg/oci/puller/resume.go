@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package puller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+)
+
+// partialPrefix is the prefix of the files holding the partially downloaded blobs.
+const partialPrefix = ".falcoctl-partial-"
+
+// errCorruptedDownload is returned when a downloaded blob does not match its digest.
+var errCorruptedDownload = errors.New("downloaded content does not match its digest")
+
+// resumableSource wraps a remote repository, retrying its transient failures. The blobs are first
+// downloaded in a partial file in dir: an interrupted download is resumed where it stopped
+// through a range request, if the registry supports them, instead of starting over.
+type resumableSource struct {
+	oras.ReadOnlyTarget
+	puller *Puller
+	dir    string
+}
+
+// Resolve resolves reference, retrying the transient failures.
+func (s *resumableSource) Resolve(ctx context.Context, reference string) (desc v1.Descriptor, err error) {
+	err = s.puller.retry(ctx, func() error {
+		desc, err = s.ReadOnlyTarget.Resolve(ctx, reference)
+		return err
+	})
+	return desc, err
+}
+
+// Fetch downloads the content of desc, resuming it on the transient failures.
+// The partial file is kept when the download fails, to resume it on the next pull.
+func (s *resumableSource) Fetch(ctx context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	// An empty dir is the current directory.
+	if s.dir != "" {
+		if err := os.MkdirAll(s.dir, 0o750); err != nil {
+			return nil, err
+		}
+	}
+	partial := filepath.Join(s.dir, partialPrefix+desc.Digest.Encoded())
+	f, err := os.OpenFile(filepath.Clean(partial), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.puller.retry(ctx, func() error {
+		return s.download(ctx, desc, f)
+	}); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &partialFile{File: f}, nil
+}
+
+// download completes the partial file f, then verifies it.
+func (s *resumableSource) download(ctx context.Context, desc v1.Descriptor, f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size()
+	if offset > desc.Size {
+		if offset, err = 0, f.Truncate(0); err != nil {
+			return err
+		}
+	}
+
+	if offset < desc.Size {
+		rc, err := s.ReadOnlyTarget.Fetch(ctx, desc)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		if offset > 0 {
+			if seeker, ok := rc.(io.Seeker); ok {
+				if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+					return err
+				}
+			} else if offset, err = 0, f.Truncate(0); err != nil {
+				// The registry does not support range requests, start over.
+				return err
+			}
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(f, rc, desc.Size-offset); errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	verifier := desc.Digest.Verifier()
+	if _, err := io.Copy(verifier, f); err != nil {
+		return err
+	}
+	if !verifier.Verified() {
+		// Start over at the next attempt.
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s", errCorruptedDownload, desc.Digest)
+	}
+	return nil
+}
+
+// partialFile is a completely downloaded blob, removed once read.
+type partialFile struct {
+	*os.File
+}
+
+// Close closes and removes the file.
+func (f *partialFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.Name()))
+}
@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package puller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// flakyTarget serves content, failing the first fetches after cut bytes.
+type flakyTarget struct {
+	oras.ReadOnlyTarget
+	content  []byte
+	seekable bool
+	failures int
+	cut      int
+	// corrupt, if set, is served instead of content by the first fetch.
+	corrupt []byte
+	err     error
+	fetches int
+	offsets []int64
+}
+
+func (t *flakyTarget) Fetch(_ context.Context, _ v1.Descriptor) (io.ReadCloser, error) {
+	t.fetches++
+	if t.err != nil {
+		return nil, t.err
+	}
+	content := t.content
+	if t.corrupt != nil && t.fetches == 1 {
+		content = t.corrupt
+	}
+	r := &flakyReader{target: t, content: content}
+	if t.fetches <= t.failures {
+		r.cut = t.cut
+	} else {
+		r.cut = len(content)
+	}
+	if t.seekable {
+		return &seekableReader{r}, nil
+	}
+	return io.NopCloser(r), nil
+}
+
+type flakyReader struct {
+	target  *flakyTarget
+	content []byte
+	offset  int
+	cut     int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if r.offset >= r.cut {
+		if r.cut < len(r.content) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, io.EOF
+	}
+	n := copy(p, r.content[r.offset:r.cut])
+	r.offset += n
+	return n, nil
+}
+
+type seekableReader struct {
+	*flakyReader
+}
+
+func (r *seekableReader) Seek(offset int64, _ int) (int64, error) {
+	r.target.offsets = append(r.target.offsets, offset)
+	r.offset = int(offset)
+	return offset, nil
+}
+
+func (r *seekableReader) Close() error {
+	return nil
+}
+
+func newTestSource(t *testing.T, target *flakyTarget) *resumableSource {
+	p := NewPuller(nil, false, nil, WithBackoff(Backoff{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	return &resumableSource{ReadOnlyTarget: target, puller: p, dir: t.TempDir()}
+}
+
+func descriptorOf(content []byte) v1.Descriptor {
+	return v1.Descriptor{MediaType: v1.MediaTypeImageLayerGzip, Digest: digest.FromBytes(content), Size: int64(len(content))}
+}
+
+func fetchAll(t *testing.T, src *resumableSource, desc v1.Descriptor) ([]byte, error) {
+	rc, err := src.Fetch(context.Background(), desc)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	return data, nil
+}
+
+func TestResumableFetch(t *testing.T) {
+	content := bytes.Repeat([]byte("falco"), 1000)
+
+	testCases := []struct {
+		name            string
+		target          *flakyTarget
+		expectedFetches int
+		expectedOffsets []int64
+	}{
+		{
+			name:            "resumes with range requests",
+			target:          &flakyTarget{content: content, seekable: true, failures: 2, cut: 1000},
+			expectedFetches: 3,
+			expectedOffsets: []int64{1000, 1000},
+		},
+		{
+			name:            "starts over without range requests",
+			target:          &flakyTarget{content: content, failures: 2, cut: 1000},
+			expectedFetches: 3,
+		},
+		{
+			name:            "starts over on corrupted downloads",
+			target:          &flakyTarget{content: content, seekable: true, corrupt: bytes.Repeat([]byte("x"), len(content))},
+			expectedFetches: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := newTestSource(t, tc.target)
+			data, err := fetchAll(t, src, descriptorOf(content))
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+			assert.Equal(t, tc.expectedFetches, tc.target.fetches)
+			assert.Equal(t, tc.expectedOffsets, tc.target.offsets)
+
+			// The partial file is removed once read.
+			entries, err := os.ReadDir(src.dir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestResumableFetchKeepsPartialFile(t *testing.T) {
+	content := bytes.Repeat([]byte("falco"), 1000)
+	desc := descriptorOf(content)
+	target := &flakyTarget{content: content, seekable: true, failures: 10, cut: 1000}
+	src := newTestSource(t, target)
+
+	_, err := fetchAll(t, src, desc)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, 4, target.fetches)
+
+	info, err := os.Stat(filepath.Join(src.dir, partialPrefix+desc.Digest.Encoded()))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1000, info.Size())
+
+	// The next pull resumes the download.
+	target.failures, target.fetches, target.offsets = 0, 0, nil
+	data, err := fetchAll(t, src, desc)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Equal(t, []int64{1000}, target.offsets)
+}
+
+func TestResumableFetchCreatesDir(t *testing.T) {
+	content := []byte("falco")
+	src := newTestSource(t, &flakyTarget{content: content})
+	src.dir = filepath.Join(src.dir, "new", "dir")
+
+	data, err := fetchAll(t, src, descriptorOf(content))
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestResumableFetchNotTransient(t *testing.T) {
+	target := &flakyTarget{err: errdef.ErrNotFound}
+	src := newTestSource(t, target)
+
+	_, err := fetchAll(t, src, descriptorOf([]byte("falco")))
+	require.ErrorIs(t, err, errdef.ErrNotFound)
+	assert.Equal(t, 1, target.fetches)
+}
+
+func TestIsTransient(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{&errcode.ErrorResponse{StatusCode: http.StatusServiceUnavailable}, true},
+		{&errcode.ErrorResponse{StatusCode: http.StatusTooManyRequests}, true},
+		{&errcode.ErrorResponse{StatusCode: http.StatusUnauthorized}, false},
+		{fmt.Errorf("fetching: %w", io.ErrUnexpectedEOF), true},
+		{&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, true},
+		{&net.DNSError{Name: "noregistry", IsNotFound: true}, false},
+		{&net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}, false},
+		{errdef.ErrNotFound, false},
+		{context.Canceled, false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, isTransient(tc.err), tc.err.Error())
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{MaxRetries: 10, InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, b.delay(0))
+	assert.Equal(t, 2*time.Second, b.delay(1))
+	assert.Equal(t, 4*time.Second, b.delay(2))
+	assert.Equal(t, 5*time.Second, b.delay(3))
+	assert.Equal(t, 5*time.Second, b.delay(20))
+}
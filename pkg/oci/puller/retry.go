@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package puller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// Backoff configures the retries of the transient registry failures. The delay between
+// two attempts doubles at each retry, starting from InitialDelay and up to MaxDelay.
+type Backoff struct {
+	MaxRetries   int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// DefaultBackoff is the Backoff of a Puller created without WithBackoff.
+var DefaultBackoff = Backoff{
+	MaxRetries:   5,
+	InitialDelay: time.Second,
+	MaxDelay:     30 * time.Second,
+}
+
+// WithBackoff sets how the transient registry failures are retried. A zero MaxRetries disables the retries.
+func WithBackoff(b Backoff) Option {
+	return func(p *Puller) {
+		p.backoff = b
+	}
+}
+
+// delay returns the time to wait before the given retry, starting from 0.
+func (b Backoff) delay(retry int) time.Duration {
+	d := b.InitialDelay
+	for i := 0; i < retry && d < b.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > b.MaxDelay {
+		d = b.MaxDelay
+	}
+	return d
+}
+
+// retry calls fn until it succeeds, fails with a non transient error or the retries are exhausted.
+func (p *Puller) retry(ctx context.Context, fn func() error) error {
+	for retry := 0; ; retry++ {
+		err := fn()
+		if err == nil || retry >= p.backoff.MaxRetries || !isTransient(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(p.backoff.delay(retry)):
+		}
+	}
+}
+
+// isTransient reports whether err is worth retrying: network errors, truncated or corrupted
+// downloads, and the registry responses asking to try again later.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var errResp *errcode.ErrorResponse
+	if errors.As(err, &errResp) {
+		return errResp.StatusCode == http.StatusRequestTimeout ||
+			errResp.StatusCode == http.StatusTooManyRequests ||
+			errResp.StatusCode >= http.StatusInternalServerError
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errCorruptedDownload) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	// An unreachable registry or an unknown host are configuration errors rather than network ones.
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
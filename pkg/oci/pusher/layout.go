@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pusher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"oras.land/oras-go/v2"
+	ocilayout "oras.land/oras-go/v2/content/oci"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/repository"
+)
+
+// ErrAmbiguousLayout is returned when the artifact to push from an OCI image layout cannot be told from the reference.
+var ErrAmbiguousLayout = errors.New("cannot tell which artifact of the OCI layout to push")
+
+// PushLayout copies to ref an artifact of the OCI image layout at path, either a directory or a tar
+// archive, preserving its digests. The artifact is the one tagged in the layout with the tag of ref,
+// or the only one of the layout. It is then tagged with tags too.
+func (p *Pusher) PushLayout(ctx context.Context, ref, path string, tags ...string) (*oci.RegistryResult, error) {
+	store, err := openLayout(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open OCI layout %q: %w", path, err)
+	}
+	var layoutTags []string
+	if err := store.Tags(ctx, "", func(t []string) error {
+		layoutTags = append(layoutTags, t...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewRepository(ref, repository.WithClient(p.Client), repository.WithPlainHTTP(p.plainHTTP))
+	if err != nil {
+		return nil, err
+	}
+
+	srcRef := repo.Reference.Reference
+	switch {
+	case srcRef != "" && (slices.Contains(layoutTags, srcRef) || strings.Contains(srcRef, ":")):
+		// Either a tag of the layout or a digest.
+	case len(layoutTags) == 1:
+		srcRef = layoutTags[0]
+	case len(layoutTags) == 0:
+		return nil, fmt.Errorf("%w: it has no tagged artifact", ErrAmbiguousLayout)
+	default:
+		return nil, fmt.Errorf("%w: set one of its tags (%s) in the reference", ErrAmbiguousLayout, strings.Join(layoutTags, ", "))
+	}
+	dstRef := repo.Reference.Reference
+	if dstRef == "" {
+		dstRef = srcRef
+	}
+
+	remoteTarget := oras.Target(repo)
+	if p.tracker != nil {
+		remoteTarget = p.tracker(repo)
+	}
+	copyOpts := oras.DefaultCopyOptions
+	copyOpts.Concurrency = 1
+	desc, err := oras.Copy(ctx, store, srcRef, remoteTarget, dstRef, copyOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to push %q of OCI layout %q: %w", srcRef, path, err)
+	}
+
+	if len(tags) > 0 {
+		tagNOptions := oras.DefaultTagNOptions
+		tagNOptions.Concurrency = 1
+		if _, err = oras.TagN(ctx, remoteTarget, dstRef, tags, tagNOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	return &oci.RegistryResult{RootDigest: string(desc.Digest)}, nil
+}
+
+// openLayout opens the OCI image layout at path, a directory or a tar archive.
+func openLayout(ctx context.Context, path string) (*ocilayout.ReadOnlyStore, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return ocilayout.NewFromFS(ctx, os.DirFS(path))
+	}
+	return ocilayout.NewFromTar(ctx, path)
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 
+	ggcrauthn "github.com/google/go-containerregistry/pkg/authn"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras-go/v2/registry/remote/credentials"
@@ -53,6 +54,19 @@ func Pusher(plainHTTP bool, printer *output.Printer) (*ocipusher.Pusher, error)
 // Client returns a new auth.Client.
 // It authenticates the client if credentials are found in the system.
 func Client(enableClientTokenCache bool) (remote.Client, error) {
+	return authClient(enableClientTokenCache)
+}
+
+// Keychain returns a keychain, as used by cosign, authenticating with the same credentials as Client.
+func Keychain(ctx context.Context) (ggcrauthn.Keychain, error) {
+	client, err := authClient(false)
+	if err != nil {
+		return nil, err
+	}
+	return authn.NewKeychain(ctx, client), nil
+}
+
+func authClient(enableClientTokenCache bool) (*auth.Client, error) {
 	credentialStore, err := credentials.NewStore(config.RegistryCredentialConfPath(), credentials.StoreOptions{
 		AllowPlaintextPut: true,
 	})
@@ -30,6 +30,10 @@ import (
 	"github.com/falcosecurity/falcoctl/pkg/enum"
 )
 
+// DriverInspectOnlyAnnotation marks the driver commands only inspecting the node: they run even when
+// none of the allowed driver types is supported or the driver version is unknown.
+const DriverInspectOnlyAnnotation = "falcoctl.driver.inspectOnly"
+
 // DriverTypes data structure for driver types.
 type DriverTypes struct {
 	*enum.Enum
@@ -48,24 +52,42 @@ func NewDriverTypes() *DriverTypes {
 
 // Driver defines options that are common while interacting with driver commands.
 type Driver struct {
-	Type     drivertype.DriverType
-	Name     string
-	Repos    []string
-	Version  string
-	HostRoot string
-	Distro   driverdistro.Distro
-	Kr       kernelrelease.KernelRelease
+	Type         drivertype.DriverType
+	Name         string
+	Repos        []string
+	PrependRepos []string
+	Version      string
+	HostRoot     string
+	Distro       driverdistro.Distro
+	Kr           kernelrelease.KernelRelease
 }
 
 // ToDriverConfig maps a Driver options to Driver config struct.
 func (d *Driver) ToDriverConfig() *config.Driver {
 	return &config.Driver{
-		Type:     []string{d.Type.String()},
-		Name:     d.Name,
-		Repos:    d.Repos,
-		Version:  d.Version,
-		HostRoot: d.HostRoot,
+		Type:         []string{d.Type.String()},
+		Name:         d.Name,
+		Repos:        d.Repos,
+		PrependRepos: d.PrependRepos,
+		Version:      d.Version,
+		HostRoot:     d.HostRoot,
+	}
+}
+
+// EffectiveRepos returns the ordered list of repos the driver is looked up in:
+// the prepended repos come first, followed by the regular ones. Duplicates are
+// dropped, keeping the position with the highest priority.
+func (d *Driver) EffectiveRepos() []string {
+	repos := make([]string, 0, len(d.PrependRepos)+len(d.Repos))
+	seen := make(map[string]struct{}, cap(repos))
+	for _, repo := range append(append([]string{}, d.PrependRepos...), d.Repos...) {
+		if _, ok := seen[repo]; ok {
+			continue
+		}
+		seen[repo] = struct{}{}
+		repos = append(repos, repo)
 	}
+	return repos
 }
 
 // Validate runs all validators steps for Driver options.
@@ -78,7 +100,7 @@ func (d *Driver) Validate() error {
 		return errors.New("version is mandatory and cannot be empty")
 	}
 
-	for _, repo := range d.Repos {
+	for _, repo := range d.EffectiveRepos() {
 		_, err := url.ParseRequestURI(repo)
 		if err != nil {
 			return fmt.Errorf("repo must be a valid url (%s): %w", repo, err)
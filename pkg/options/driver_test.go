@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Driver", func() {
+	Context("EffectiveRepos", func() {
+		It("should keep the regular repos when nothing is prepended", func() {
+			d := &Driver{Repos: []string{"https://download.falco.org/driver"}}
+			Expect(d.EffectiveRepos()).To(Equal([]string{"https://download.falco.org/driver"}))
+		})
+
+		It("should try the prepended repos first", func() {
+			d := &Driver{
+				Repos:        []string{"https://download.falco.org/driver", "https://other.example.com/driver"},
+				PrependRepos: []string{"https://mirror.internal/driver"},
+			}
+			Expect(d.EffectiveRepos()).To(Equal([]string{
+				"https://mirror.internal/driver",
+				"https://download.falco.org/driver",
+				"https://other.example.com/driver",
+			}))
+		})
+
+		It("should drop duplicates keeping the highest priority", func() {
+			d := &Driver{
+				Repos:        []string{"https://download.falco.org/driver", "https://mirror.internal/driver"},
+				PrependRepos: []string{"https://mirror.internal/driver", "https://mirror.internal/driver"},
+			}
+			Expect(d.EffectiveRepos()).To(Equal([]string{
+				"https://mirror.internal/driver",
+				"https://download.falco.org/driver",
+			}))
+		})
+	})
+})
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+// Output defines the options to print the result of a command in a machine-readable format.
+type Output struct {
+	Format string
+}
+
+// AddFlags registers the output flags.
+func (o *Output) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Format, "output", "o", "", "Print the output in a machine-readable format instead of text. One of 'yaml' or 'json'")
+}
+
+// Validate checks the output options.
+func (o *Output) Validate() error {
+	return output.ValidateFormat(o.Format)
+}
+
+// Structured tells whether a machine-readable format has been requested.
+func (o *Output) Structured() bool {
+	return o.Format != ""
+}
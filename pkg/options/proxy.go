@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+// FlagSOCKS5Proxy is the name of the flag used to set the SOCKS5 proxy.
+const FlagSOCKS5Proxy = "socks5-proxy"
+
+// Proxy defines the options used to reach registries and indexes through a proxy.
+type Proxy struct {
+	SOCKS5 string
+}
+
+// AddFlags registers the proxy flags as persistent flags of the given command.
+func (p *Proxy) AddFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&p.SOCKS5, FlagSOCKS5Proxy, "",
+		"SOCKS5 proxy, in the \"[user:password@]host:port\" format, used to reach registries and indexes (defaults to ALL_PROXY)")
+}
+
+// Configure sets up the proxy used by the outgoing requests. The flag value takes
+// precedence over the configuration file.
+func (p *Proxy) Configure(cmd *cobra.Command) error {
+	f := cmd.Flags().Lookup(FlagSOCKS5Proxy)
+	if f == nil {
+		// should never happen
+		return fmt.Errorf("unable to retrieve flag %q", FlagSOCKS5Proxy)
+	} else if !f.Changed && viper.IsSet(config.RegistrySOCKS5ProxyKey) {
+		if err := cmd.Flags().Set(f.Name, config.RegistrySOCKS5Proxy()); err != nil {
+			return fmt.Errorf("unable to overwrite %q flag: %w", f.Name, err)
+		}
+	}
+
+	var u *url.URL
+	if p.SOCKS5 != "" {
+		var err error
+		if u, err = utils.ParseSOCKS5Proxy(p.SOCKS5); err != nil {
+			return err
+		}
+	}
+	utils.SetSOCKS5Proxy(u)
+
+	return nil
+}
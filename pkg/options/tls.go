@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/falcosecurity/falcoctl/internal/config"
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+// ConfigureTLS sets up the CA bundle and the insecure hosts of the configuration file for all the outgoing
+// requests, towards registries, indexes and driver repos alike.
+func ConfigureTLS() error {
+	hosts, err := config.TLSInsecureHosts()
+	if err != nil {
+		return err
+	}
+	return utils.SetTLS(config.TLSCABundle(), hosts)
+}
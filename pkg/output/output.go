@@ -37,6 +37,20 @@ const (
 	IndexList
 	// ArtifactInfo identifies the header for artifact info.
 	ArtifactInfo
+	// DriverList identifies the header for driver list.
+	DriverList
+	// DriverConfigShow identifies the header for driver config show.
+	DriverConfigShow
+	// DriverConfigHistory identifies the header for driver config history.
+	DriverConfigHistory
+	// DriverConfigValidate identifies the header for driver config validate.
+	DriverConfigValidate
+	// DriverCheck identifies the header for driver check.
+	DriverCheck
+	// ArtifactInstalled identifies the header for artifact list --installed.
+	ArtifactInstalled
+	// ArtifactSearchTags identifies the header for artifact search --tags.
+	ArtifactSearchTags
 )
 
 var spinnerCharset = []string{"⠈⠁", "⠈⠑", "⠈⠱", "⠈⡱", "⢀⡱", "⢄⡱", "⢄⡱", "⢆⡱", "⢎⡱", "⢎⡰", "⢎⡠", "⢎⡀", "⢎⠁", "⠎⠁", "⠊⠁"}
@@ -159,6 +173,20 @@ func (p *Printer) PrintTable(header TableHeader, data [][]string) error {
 		table = [][]string{{"NAME", "URL", "ADDED", "UPDATED"}}
 	case ArtifactInfo:
 		table = [][]string{{"REF", "TAGS"}}
+	case DriverList:
+		table = [][]string{{"NAME", "TYPE", "VERSION", "ARCH", "DISTRO", "KERNEL RELEASE", "KERNEL VERSION", "PATH"}}
+	case DriverConfigShow:
+		table = [][]string{{"NAME", "VERSION", "TYPE", "HOST ROOT", "REPOS", "LIVE SOURCE", "LIVE TYPE", "DRIFT"}}
+	case DriverConfigHistory:
+		table = [][]string{{"TIME", "PREVIOUS", "TYPE", "TARGET", "USER"}}
+	case DriverConfigValidate:
+		table = [][]string{{"NAMESPACE", "RESOURCE", "VERB", "ALLOWED"}}
+	case DriverCheck:
+		table = [][]string{{"TYPE", "VIABLE", "NOTES"}}
+	case ArtifactInstalled:
+		table = [][]string{{"ARTIFACT", "TYPE", "VERSION", "DIGEST", "DIRECTORY", "REF", "INSTALLED"}}
+	case ArtifactSearchTags:
+		table = [][]string{{"INDEX", "ARTIFACT", "TYPE", "REGISTRY", "REPOSITORY", "TAGS"}}
 	default:
 		return fmt.Errorf("unsupported output table")
 	}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FormatYAML prints the output of a command as YAML.
+	FormatYAML = "yaml"
+	// FormatJSON prints the output of a command as JSON.
+	FormatJSON = "json"
+)
+
+// ErrInvalidFormat is returned when an unsupported structured output format is requested.
+var ErrInvalidFormat = errors.New("--output must be 'yaml' or 'json'")
+
+// ValidateFormat checks that format is a supported structured output format.
+// The empty format, which selects the human readable output, is valid.
+func ValidateFormat(format string) error {
+	if format != "" && format != FormatYAML && format != FormatJSON {
+		return ErrInvalidFormat
+	}
+	return nil
+}
+
+// PrintStructured serializes v in the given structured output format.
+func (p *Printer) PrintStructured(format string, v interface{}) error {
+	var (
+		marshaled []byte
+		err       error
+	)
+	switch format {
+	case FormatYAML:
+		marshaled, err = yaml.Marshal(v)
+	case FormatJSON:
+		marshaled, err = json.MarshalIndent(v, "", "  ")
+		marshaled = append(marshaled, '\n')
+	default:
+		// We should never hit this case.
+		return fmt.Errorf("options were not validated: --output=%q should have been rejected", format)
+	}
+	if err != nil {
+		return err
+	}
+	p.DefaultText.Print(string(marshaled))
+	return nil
+}
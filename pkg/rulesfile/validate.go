@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rulesfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/blang/semver/v4"
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/internal/utils"
+)
+
+const (
+	ruleKey           = "rule"
+	macroKey          = "macro"
+	listKey           = "list"
+	engineKey         = "required_engine_version"
+	pluginVersionsKey = "required_plugin_versions"
+)
+
+// requiredRuleFields are the fields a rule must have when it is defined, rather than appended to or overridden.
+var requiredRuleFields = []string{"desc", "condition", "output", "priority"}
+
+// File is a rules file to validate.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Issue is a problem found in a rules file.
+type Issue struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// String returns the issue prefixed by its location.
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.File, i.Message)
+}
+
+// Options configures the validation.
+type Options struct {
+	// EngineVersion is the engine version of the target Falco. When set, rules files
+	// requiring a newer engine are reported.
+	EngineVersion *semver.Version
+}
+
+// location is where a rule has been defined.
+type location struct {
+	file string
+	line int
+}
+
+// Validate checks the syntax of the rules files, their required_engine_version against the target
+// engine version and that no rule is defined twice, across all the files.
+// Files are checked in the given order, so the issues are reported in the order they are found.
+func Validate(files []File, opts Options) []Issue {
+	var issues []Issue
+	rules := make(map[string]location)
+
+	for _, f := range files {
+		issues = append(issues, validateFile(f, opts, rules)...)
+	}
+
+	return issues
+}
+
+func validateFile(f File, opts Options, rules map[string]location) []Issue {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(f.Data, &doc); err != nil {
+		return []Issue{{File: f.Name, Message: fmt.Sprintf("invalid YAML: %v", err)}}
+	}
+
+	// An empty file is a valid rules file.
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		return []Issue{{File: f.Name, Line: root.Line, Message: "a rules file must be a list of items"}}
+	}
+
+	var issues []Issue
+	report := func(line int, format string, args ...interface{}) {
+		issues = append(issues, Issue{File: f.Name, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, item := range root.Content {
+		if item.Kind != yaml.MappingNode {
+			report(item.Line, "an item must be a mapping")
+			continue
+		}
+		fields := mappingFields(item)
+
+		switch {
+		case fields[ruleKey] != nil:
+			validateRule(f.Name, item, fields, rules, report)
+		case fields[macroKey] != nil, fields[listKey] != nil, fields[pluginVersionsKey] != nil:
+		case fields[engineKey] != nil:
+			validateEngineVersion(fields[engineKey], opts, report)
+		default:
+			report(item.Line, "unknown item, expected one of %q, %q, %q, %q or %q",
+				ruleKey, macroKey, listKey, engineKey, pluginVersionsKey)
+		}
+	}
+
+	return issues
+}
+
+func validateRule(file string, item *yaml.Node, fields map[string]*yaml.Node, rules map[string]location,
+	report func(line int, format string, args ...interface{})) {
+	nameNode := fields[ruleKey]
+	name := nameNode.Value
+	if nameNode.Kind != yaml.ScalarNode || name == "" {
+		report(item.Line, "a rule must have a non empty name")
+		return
+	}
+
+	// Appending to or overriding a rule modifies a rule defined elsewhere, as disabling it does.
+	if isTrue(fields["append"]) || fields["override"] != nil || (len(fields) == 2 && fields["enabled"] != nil) {
+		return
+	}
+
+	var missing []string
+	for _, field := range requiredRuleFields {
+		if fields[field] == nil {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		report(item.Line, "rule %q is missing the required fields %v", name, missing)
+	}
+
+	if prev, ok := rules[name]; ok {
+		report(item.Line, "rule %q is already defined at %s:%d", name, prev.file, prev.line)
+		return
+	}
+	rules[name] = location{file: file, line: item.Line}
+}
+
+func validateEngineVersion(node *yaml.Node, opts Options, report func(line int, format string, args ...interface{})) {
+	required, err := engineVersion(node)
+	if err != nil {
+		report(node.Line, "%v", err)
+		return
+	}
+
+	if opts.EngineVersion != nil && required.GT(*opts.EngineVersion) {
+		report(node.Line, "%s %s is newer than the target engine version %s", engineKey, required, opts.EngineVersion)
+	}
+}
+
+// engineVersion parses a required_engine_version. It used to be an int, internally used by Falco
+// as a semver minor version: 15 is 0.15.0.
+func engineVersion(node *yaml.Node) (semver.Version, error) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!int" {
+		minor, err := strconv.ParseUint(node.Value, 10, 64)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("invalid %s %q: %w", engineKey, node.Value, err)
+		}
+		return semver.Version{Minor: minor}, nil
+	}
+
+	v, err := semver.Parse(node.Value)
+	if node.Kind != yaml.ScalarNode || err != nil {
+		return semver.Version{}, fmt.Errorf("%s must be an int or a string respecting the semver specification, got %q", engineKey, node.Value)
+	}
+	return v, nil
+}
+
+// mappingFields returns the values of a mapping node by key.
+func mappingFields(node *yaml.Node) map[string]*yaml.Node {
+	fields := make(map[string]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields[node.Content[i].Value] = node.Content[i+1]
+	}
+	return fields
+}
+
+func isTrue(node *yaml.Node) bool {
+	if node == nil {
+		return false
+	}
+	var b bool
+	return node.Decode(&b) == nil && b
+}
+
+// Load reads the rules files at path. A gzip compressed tarball, as shipped by rulesfile artifacts,
+// is extracted in a temporary directory and its YAML files are returned, sorted by name.
+// Any other file is read as a rules file.
+func Load(ctx context.Context, path string) ([]File, error) {
+	if err := utils.IsTarGz(filepath.Clean(path)); err != nil {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read rules file %q: %w", path, err)
+		}
+		return []File{{Name: path, Data: data}}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "falcoctl-rulesfile-")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tarball, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer tarball.Close()
+
+	paths, err := utils.ExtractTarGz(ctx, tarball, dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot extract %q: %w", path, err)
+	}
+	sort.Strings(paths)
+
+	var files []File
+	for _, p := range paths {
+		if ext := filepath.Ext(p); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Clean(p))
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: fmt.Sprintf("%s:%s", path, rel), Data: data})
+	}
+	return files, nil
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rulesfile
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validRules = `- required_engine_version: 0.31.0
+- required_plugin_versions:
+    - name: k8saudit
+      version: 0.7.0
+- list: allowed_users
+  items: [root]
+- macro: is_root
+  condition: user.name in (allowed_users)
+- rule: Root shell
+  desc: A shell is spawned by root
+  condition: is_root and proc.name = bash
+  output: Root shell (user=%user.name)
+  priority: WARNING
+- rule: Root shell
+  condition: and proc.tty != 0
+  append: true
+- rule: Root shell
+  enabled: false
+`
+
+func TestValidateValidRules(t *testing.T) {
+	v := semver.MustParse("0.40.0")
+	issues := Validate([]File{{Name: "rules.yaml", Data: []byte(validRules)}}, Options{EngineVersion: &v})
+	assert.Empty(t, issues)
+
+	issues = Validate([]File{{Name: "empty.yaml"}}, Options{})
+	assert.Empty(t, issues)
+}
+
+func TestValidateInvalidRules(t *testing.T) {
+	const data = `- required_engine_version: 0.45.0
+- rule: Root shell
+  desc: A shell is spawned by root
+  condition: proc.name = bash
+  output: Root shell
+  priority: WARNING
+- rule: Root shell
+  desc: Defined twice
+  condition: proc.name = sh
+  output: Root shell
+  priority: NOTICE
+- rule: Incomplete
+  condition: proc.name = zsh
+- something: else
+`
+	v := semver.MustParse("0.40.0")
+	issues := Validate([]File{{Name: "rules.yaml", Data: []byte(data)}}, Options{EngineVersion: &v})
+
+	var messages []string
+	for _, i := range issues {
+		messages = append(messages, i.String())
+	}
+	assert.Equal(t, []string{
+		"rules.yaml:1: required_engine_version 0.45.0 is newer than the target engine version 0.40.0",
+		`rules.yaml:7: rule "Root shell" is already defined at rules.yaml:2`,
+		`rules.yaml:12: rule "Incomplete" is missing the required fields [desc output priority]`,
+		`rules.yaml:14: unknown item, expected one of "rule", "macro", "list", "required_engine_version" or "required_plugin_versions"`,
+	}, messages)
+}
+
+func TestValidateDuplicatesAcrossFiles(t *testing.T) {
+	const rule = `- rule: Root shell
+  desc: A shell is spawned by root
+  condition: proc.name = bash
+  output: Root shell
+  priority: WARNING
+`
+	issues := Validate([]File{{Name: "a.yaml", Data: []byte(rule)}, {Name: "b.yaml", Data: []byte(rule)}}, Options{})
+	require.Len(t, issues, 1)
+	assert.Equal(t, `b.yaml:1: rule "Root shell" is already defined at a.yaml:1`, issues[0].String())
+}
+
+func TestValidateSyntax(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		message string
+	}{
+		{name: "invalid yaml", data: "- rule: [unclosed\n", message: "invalid YAML"},
+		{name: "not a list", data: "rule: x\n", message: "a rules file must be a list of items"},
+		{name: "not a mapping", data: "- rule\n", message: "an item must be a mapping"},
+		{name: "int engine version", data: "- required_engine_version: 50\n", message: "is newer than the target engine version"},
+		{name: "invalid engine version", data: "- required_engine_version: latest\n", message: "must be an int or a string respecting the semver specification"},
+	}
+
+	v := semver.MustParse("0.40.0")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Validate([]File{{Name: "rules.yaml", Data: []byte(tt.data)}}, Options{EngineVersion: &v})
+			require.Len(t, issues, 1)
+			assert.Contains(t, issues[0].Message, tt.message)
+		})
+	}
+}
+
+// writeTarGz writes a gzip compressed tarball containing files, by name.
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	rulesPath := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(validRules), 0o600))
+
+	files, err := Load(context.Background(), rulesPath)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, rulesPath, files[0].Name)
+
+	tarball := filepath.Join(dir, "rules.tar.gz")
+	writeTarGz(t, tarball, map[string]string{"rules.yaml": validRules, "README.md": "readme"})
+
+	files, err = Load(context.Background(), tarball)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, tarball+":rules.yaml", files[0].Name)
+	assert.Equal(t, validRules, string(files[0].Data))
+
+	_, err = Load(context.Background(), filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}